@@ -2,12 +2,16 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/gorilla/handlers"
 	"github.com/gorilla/mux"
 	"github.com/srodrmendz/api-product-catalog/conf"
+	internalErrors "github.com/srodrmendz/api-product-catalog/errors"
+	"github.com/srodrmendz/api-product-catalog/model"
 	"github.com/srodrmendz/api-product-catalog/repository"
 	"github.com/srodrmendz/api-product-catalog/server"
 	"github.com/srodrmendz/api-product-catalog/service"
@@ -27,24 +31,47 @@ var (
 func main() {
 	ctx := context.TODO()
 
-	// Create mongo connection
-	mongoClient := createMongoClient(
-		ctx,
-		conf.GetProps().Database.URI,
-	)
+	// Load properties first, it parses the command line flags
+	props := conf.GetProps()
 
-	// Disconnect after server is shutdown
-	defer mongoClient.Disconnect(ctx)
+	// Apply repository indexes without starting the server
+	if flag.Arg(0) == "indexes" {
+		applyIndexes(ctx, props)
 
-	// Create users repository
-	repository := repository.New(
-		mongoClient,
-		conf.GetProps().Database.DB,
-		conf.GetProps().Database.Collection,
-	)
+		return
+	}
+
+	// Create products repository
+	repository, closeRepository := createRepository(ctx, props)
+
+	// Release repository resources after server is shutdown
+	defer closeRepository()
+
+	// Prices are converted only with valid exchange rates
+	if rates := props.Currency.ExchangeRates; rates != nil {
+		if err := rates.Validate(); err != nil {
+			panic(err)
+		}
+	}
+
+	defaultLocale, locales := parseLocales(props)
 
 	// Create service
-	service := service.New(repository)
+	service := service.New(repository, service.Config{
+		DefaultCurrency: props.Currency.Default,
+		ExchangeRates:   props.Currency.ExchangeRates,
+		DefaultLocale:   defaultLocale,
+		Locales:         locales,
+		MaxImages:       props.Images.Max,
+	})
+
+	// Purge deleted products once their retention period is over
+	if props.Purge.Retention > 0 {
+		go schedulePurge(ctx, service, props.Purge.Retention, props.Purge.Interval)
+	}
+
+	// Write the scheduled prices once they start or end, so search sorts on the prices in effect
+	go scheduleDuePrices(ctx, service, props.Prices.Interval)
 
 	// Create app
 	app := server.New(
@@ -53,6 +80,7 @@ func main() {
 		conf.GetProps().Path,
 		version,
 		buildDate,
+		props.Admin.Token,
 	)
 
 	fmt.Println("running on 8080 port")
@@ -60,7 +88,8 @@ func main() {
 	corsHandler := handlers.CORS(
 		handlers.AllowedOrigins([]string{"*"}),
 		handlers.AllowedMethods([]string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}),
-		handlers.AllowedHeaders([]string{"Content-Type", "Authorization"}),
+		handlers.AllowedHeaders([]string{"Content-Type", "Authorization", "If-Match", "Accept-Language", "X-Actor"}),
+		handlers.ExposedHeaders([]string{"ETag", "Content-Language", "Location"}),
 	)
 
 	http.Handle("/", corsHandler(app.Router))
@@ -70,6 +99,221 @@ func main() {
 	http.ListenAndServe(":8080", nil)
 }
 
+// Create the repository for the configured store, cached when the cache is enabled
+// The returned function releases its resources
+func createRepository(ctx context.Context, props conf.Props) (repository.Repository, func()) {
+	store, closeStore := openStore(ctx, props)
+
+	if !props.Cache.Enabled {
+		return store, closeStore
+	}
+
+	cache := repository.NewCache(store, repository.CacheConfig{
+		TTL:    props.Cache.TTL,
+		Size:   props.Cache.Size,
+		Search: props.Cache.Search,
+	})
+
+	if props.Cache.ReportInterval > 0 {
+		go reportCacheStats(cache, props.Cache.ReportInterval)
+	}
+
+	return cache, closeStore
+}
+
+// Open the repository for the configured store, the returned function releases its resources
+func openStore(ctx context.Context, props conf.Props) (repository.Repository, func()) {
+	switch props.Database.Store {
+	case conf.MemoryStore:
+		return repository.NewMemory(), func() {}
+	case conf.BoltStore:
+		bolt, err := repository.NewBolt(props.Database.Path)
+		if err != nil {
+			panic(err)
+		}
+
+		if props.Database.Backup.Path != "" {
+			go scheduleBackups(bolt, props.Database.Backup.Path, props.Database.Backup.Interval)
+		}
+
+		return bolt, func() { bolt.Close() }
+	case conf.SQLiteStore:
+		db, err := repository.OpenSQLite(props.Database.Path)
+		if err != nil {
+			panic(err)
+		}
+
+		sql, err := repository.NewSQL(ctx, db, repository.SQLite)
+		if err != nil {
+			panic(err)
+		}
+
+		return sql, func() { db.Close() }
+	case conf.MongoStore, "":
+		mongoClient := createMongoClient(ctx, props.Database.URI)
+
+		repository := repository.New(
+			mongoClient,
+			props.Database.DB,
+			props.Database.Collection,
+		)
+
+		// Reconcile collection indexes, drift is reported but does not stop the server
+		report, err := repository.SyncIndexes(ctx)
+		if err != nil {
+			fmt.Println("syncing indexes", err)
+		} else if report.HasDrift() {
+			fmt.Println("indexes drift reconciled", report)
+		}
+
+		// Writes run on transactions, so a standalone server could not write any product
+		supported, err := repository.SupportsTransactions(ctx)
+		if err != nil {
+			panic(err)
+		}
+
+		if !supported {
+			panic(internalErrors.ErrTransactionsUnsupported)
+		}
+
+		return repository, func() { mongoClient.Disconnect(ctx) }
+	default:
+		panic(fmt.Sprintf("unknown database store %s", props.Database.Store))
+	}
+}
+
+// Apply the products collection indexes, used to build them offline before a deploy
+// nolint: forbidigo
+func applyIndexes(ctx context.Context, props conf.Props) {
+	if props.Database.Store != conf.MongoStore && props.Database.Store != "" {
+		fmt.Printf("%s store manages its own indexes\n", props.Database.Store)
+
+		return
+	}
+
+	mongoClient := createMongoClient(ctx, props.Database.URI)
+
+	defer mongoClient.Disconnect(ctx)
+
+	repository := repository.New(
+		mongoClient,
+		props.Database.DB,
+		props.Database.Collection,
+	)
+
+	report, err := repository.SyncIndexes(ctx)
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println("indexes applied, drift found before applying:", report)
+}
+
+// Parse the configured default and supported locales, the default one is always supported
+func parseLocales(props conf.Props) (string, []string) {
+	var defaultLocale string
+
+	if props.Locale.Default != "" {
+		locale, err := model.ParseLocale(props.Locale.Default)
+		if err != nil {
+			panic(fmt.Sprintf("default locale %s %s", props.Locale.Default, err))
+		}
+
+		defaultLocale = locale
+	}
+
+	var locales []string
+
+	for _, value := range props.Locale.Supported {
+		locale, err := model.ParseLocale(value)
+		if err != nil {
+			panic(fmt.Sprintf("supported locale %s %s", value, err))
+		}
+
+		if locale != defaultLocale {
+			locales = append(locales, locale)
+		}
+	}
+
+	if defaultLocale != "" && len(locales) > 0 {
+		locales = append([]string{defaultLocale}, locales...)
+	}
+
+	return defaultLocale, locales
+}
+
+// Periodically backup the data file while the server is running
+// nolint: forbidigo
+func scheduleBackups(bolt *repository.Bolt, path string, interval time.Duration) {
+	if interval <= 0 {
+		interval = time.Hour
+	}
+
+	for range time.Tick(interval) {
+		if err := bolt.Backup(path); err != nil {
+			fmt.Println("backing up data file", err)
+		}
+	}
+}
+
+// Periodically print the cache hit and miss counts
+// nolint: forbidigo
+func reportCacheStats(cache *repository.Cache, interval time.Duration) {
+	for range time.Tick(interval) {
+		stats := cache.Stats()
+
+		fmt.Printf(
+			"cache stats hits: %d misses: %d products: %d searches: %d\n",
+			stats.Hits,
+			stats.Misses,
+			stats.Products,
+			stats.Searches,
+		)
+	}
+}
+
+// Periodically purge the products deleted longer than retention ago
+// nolint: forbidigo
+func schedulePurge(ctx context.Context, service service.Service, retention time.Duration, interval time.Duration) {
+	if interval <= 0 {
+		interval = time.Hour
+	}
+
+	for range time.Tick(interval) {
+		purged, err := service.Purge(ctx, retention)
+		if err != nil {
+			fmt.Println("purging deleted products", err)
+
+			continue
+		}
+
+		if purged > 0 {
+			fmt.Println("purged deleted products", purged)
+		}
+	}
+}
+
+// Periodically write the scheduled prices that started or ended
+// nolint: forbidigo
+func scheduleDuePrices(ctx context.Context, service service.Service, interval time.Duration) {
+	if interval <= 0 {
+		interval = time.Minute
+	}
+
+	for range time.Tick(interval) {
+		applied, err := service.ApplyDuePrices(ctx)
+		if err != nil {
+			fmt.Println("applying due prices", err)
+
+			continue
+		}
+
+		if applied > 0 {
+			fmt.Println("applied due prices", applied)
+		}
+	}
+}
+
 func createMongoClient(ctx context.Context, uri string) *mongo.Client {
 	clientOptions := options.Client().ApplyURI(uri)
 
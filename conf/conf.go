@@ -1,7 +1,18 @@
 package conf
 
 import (
+	"time"
+
 	"github.com/basset-la/tools/env"
+	"github.com/srodrmendz/api-product-catalog/model"
+)
+
+// Database stores supported by the application
+const (
+	MongoStore  = "mongo"
+	MemoryStore = "memory"
+	BoltStore   = "bolt"
+	SQLiteStore = "sqlite"
 )
 
 var properties *Props
@@ -21,8 +32,42 @@ func GetProps() Props {
 type Props struct {
 	Path     string `yaml:"path"`
 	Database struct {
+		Store      string `yaml:"store"`
 		URI        string `json:"uri"`
 		DB         string `json:"db"`
 		Collection string `json:"collection"`
+		Path       string `yaml:"path"`
+		Backup     struct {
+			Path     string        `yaml:"path"`
+			Interval time.Duration `yaml:"interval"`
+		} `yaml:"backup"`
 	} `yaml:"database"`
+	Cache struct {
+		Enabled        bool          `yaml:"enabled"`
+		TTL            time.Duration `yaml:"ttl"`
+		Size           int           `yaml:"size"`
+		Search         bool          `yaml:"search"`
+		ReportInterval time.Duration `yaml:"report_interval"`
+	} `yaml:"cache"`
+	Purge struct {
+		Retention time.Duration `yaml:"retention"`
+		Interval  time.Duration `yaml:"interval"`
+	} `yaml:"purge"`
+	Prices struct {
+		Interval time.Duration `yaml:"interval"`
+	} `yaml:"prices"`
+	Currency struct {
+		Default       string               `yaml:"default"`
+		ExchangeRates *model.ExchangeRates `yaml:"exchange_rates"`
+	} `yaml:"currency"`
+	Locale struct {
+		Default   string   `yaml:"default"`
+		Supported []string `yaml:"supported"`
+	} `yaml:"locale"`
+	Images struct {
+		Max int `yaml:"max"`
+	} `yaml:"images"`
+	Admin struct {
+		Token string `yaml:"token"`
+	} `yaml:"admin"`
 }
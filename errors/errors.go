@@ -1,8 +1,75 @@
 package errors
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 var (
-	ErrProductSKUAlreadyExist = errors.New("product sku already exist")
-	ErrProductNotFound        = errors.New("product not found")
+	ErrProductSKUAlreadyExist  = errors.New("product sku already exist")
+	ErrProductNotFound         = errors.New("product not found")
+	ErrVersionConflict         = errors.New("product version conflict")
+	ErrVariantNotFound         = errors.New("product variant not found")
+	ErrVariantRequired         = errors.New("product has variants, variant sku must be provided")
+	ErrCategoryNotFound        = errors.New("category not found")
+	ErrCategoryParentNotFound  = errors.New("category parent not found")
+	ErrCategoryHasChildren     = errors.New("category has subcategories")
+	ErrCategoryInvalidParent   = errors.New("category can not be moved under itself or its subcategories")
+	ErrAttributeInvalid        = errors.New("product attributes invalid")
+	ErrPriceNotAvailable       = errors.New("product price not available on the requested currency")
+	ErrPriceScheduleVariants   = errors.New("product has variants, its prices can not be scheduled")
+	ErrScheduledPriceNotFound  = errors.New("scheduled price not found or already started")
+	ErrStatusTransition        = errors.New("product status does not allow the transition")
+	ErrTranslationNotFound     = errors.New("product translation not found")
+	ErrTranslationLocale       = errors.New("product can not be translated to its own locale")
+	ErrImageNotFound           = errors.New("product image not found")
+	ErrImageAlreadyExist       = errors.New("product image url already exist")
+	ErrImageLimit              = errors.New("product images exceed the maximum allowed")
+	ErrImageOrder              = errors.New("product image ids must list every product image once")
+	ErrBrandNotFound           = errors.New("brand not found")
+	ErrBrandAlreadyExist       = errors.New("brand name already exist")
+	ErrBrandInUse              = errors.New("brand has products")
+	ErrComponentNotFound       = errors.New("bundle component not found")
+	ErrComponentInvalid        = errors.New("bundle components can not be bundles, have variants, be archived or be priced on another currency")
+	ErrProductInBundle         = errors.New("product is a component of bundles")
+	ErrBundlePricing           = errors.New("product bundle is priced on its components, its price can not be changed or scheduled")
+	ErrRelatedNotFound         = errors.New("related product not found")
+	ErrRelationNotFound        = errors.New("product relation not found")
+	ErrRelationAlreadyExist    = errors.New("product relation already exist")
+	ErrRelationOrder           = errors.New("product relation ids must list every product related on the type once")
+	ErrSlugAlreadyExist        = errors.New("product slug already exist")
+	ErrSlugMoved               = errors.New("product slug moved")
+	ErrInsufficientStock       = errors.New("product stock is insufficient")
+	ErrBundleStock             = errors.New("product bundle stock is computed from its components, it can not be moved")
+	ErrStockLimit              = errors.New("product stock would exceed the maximum qty")
+	ErrTransactionsUnsupported = errors.New("repository does not run transactions, MongoDB must run as a replica set or sharded cluster")
 )
+
+// Returned when a write expected another product version, Current holds the stored version
+type VersionConflictError struct {
+	Current int64
+}
+
+func (e *VersionConflictError) Error() string {
+	return fmt.Sprintf("%s, current version is %d", ErrVersionConflict, e.Current)
+}
+
+// Match ErrVersionConflict with errors.Is
+func (e *VersionConflictError) Is(target error) bool {
+	return target == ErrVersionConflict
+}
+
+// Returned when a product is looked up by one of its previous slugs, Slug holds the current slug of its locale
+type SlugMovedError struct {
+	Slug      string
+	ProductID string
+}
+
+func (e *SlugMovedError) Error() string {
+	return fmt.Sprintf("%s, current slug is %s", ErrSlugMoved, e.Slug)
+}
+
+// Match ErrSlugMoved with errors.Is
+func (e *SlugMovedError) Is(target error) bool {
+	return target == ErrSlugMoved
+}
@@ -0,0 +1,236 @@
+package model
+
+import (
+	"fmt"
+	"math"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Type of the values an attribute takes
+type AttributeType string
+
+const (
+	AttributeString AttributeType = "string"
+	AttributeNumber AttributeType = "number"
+	AttributeBool   AttributeType = "bool"
+	// Strings restricted to the definition values
+	AttributeEnum AttributeType = "enum"
+)
+
+// Operators of the attribute search filters
+const (
+	AttributeEqual          = "eq"
+	AttributeGreaterOrEqual = "gte"
+	AttributeLessOrEqual    = "lte"
+)
+
+// Query params prefix of the attribute search filters, attr.material=cotton or attr.screen_size_gte=13
+const attributeFilterPrefix = "attr."
+
+// Attribute names are used as document keys and JSON paths, so they are kept to lowercase letters, digits and underscores
+var attributeNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// Represent an attribute definition of a category, it applies to the products on the category and its descendants
+type AttributeDefinition struct {
+	Name     string        `json:"name" bson:"name"`
+	Type     AttributeType `json:"type" bson:"type"`
+	Unit     string        `json:"unit,omitempty" bson:"unit,omitempty"`
+	Required bool          `json:"required" bson:"required"`
+	// Allowed values of enum attributes
+	Values []string `json:"values,omitempty" bson:"values,omitempty"`
+}
+
+// Represent a search filter on a product attribute, Value holds the raw query value
+type AttributeFilter struct {
+	Name     string
+	Operator string
+	Value    string
+}
+
+// Number of a range filter, parsed when the filter was built
+func (f AttributeFilter) Number() float64 {
+	number, _ := strconv.ParseFloat(f.Value, 64)
+
+	return number
+}
+
+// Validate the product attributes against the definitions of its categories
+// Every attribute must be defined and every required definition must have a value
+func ValidateAttributes(attributes map[string]interface{}, definitions []AttributeDefinition) error {
+	defined := make(map[string]bool, len(definitions))
+
+	for _, definition := range definitions {
+		defined[definition.Name] = true
+
+		value, ok := attributes[definition.Name]
+		if !ok {
+			if definition.Required {
+				return fmt.Errorf("attribute %s is required", definition.Name)
+			}
+
+			continue
+		}
+
+		if err := definition.validate(value); err != nil {
+			return err
+		}
+	}
+
+	for name := range attributes {
+		if !defined[name] {
+			return fmt.Errorf("attribute %s is not defined on the product categories", name)
+		}
+	}
+
+	return nil
+}
+
+func (d AttributeDefinition) validate(value interface{}) error {
+	switch d.Type {
+	case AttributeString:
+		if _, ok := value.(string); ok {
+			return nil
+		}
+	case AttributeNumber:
+		if _, ok := value.(float64); ok {
+			return nil
+		}
+	case AttributeBool:
+		if _, ok := value.(bool); ok {
+			return nil
+		}
+	case AttributeEnum:
+		if text, ok := value.(string); ok {
+			for _, allowed := range d.Values {
+				if text == allowed {
+					return nil
+				}
+			}
+
+			return fmt.Errorf("attribute %s must be one of %s", d.Name, strings.Join(d.Values, ", "))
+		}
+	}
+
+	return fmt.Errorf("attribute %s must be a %s", d.Name, d.Type)
+}
+
+// Validate category attribute definitions
+func validateAttributeDefinitions(definitions []AttributeDefinition) error {
+	names := make(map[string]bool, len(definitions))
+
+	for _, definition := range definitions {
+		if err := validateAttributeName(definition.Name); err != nil {
+			return err
+		}
+
+		if names[definition.Name] {
+			return fmt.Errorf("attribute %s cannot be repeated", definition.Name)
+		}
+
+		names[definition.Name] = true
+
+		switch definition.Type {
+		case AttributeString, AttributeNumber, AttributeBool:
+			if len(definition.Values) > 0 {
+				return fmt.Errorf("attribute %s values are only allowed on enum attributes", definition.Name)
+			}
+		case AttributeEnum:
+			if len(definition.Values) == 0 {
+				return fmt.Errorf("attribute %s values cannot be empty", definition.Name)
+			}
+
+			for _, value := range definition.Values {
+				if value == "" {
+					return fmt.Errorf("attribute %s value cannot be empty", definition.Name)
+				}
+			}
+		default:
+			return fmt.Errorf("attribute %s type must be string, number, bool or enum", definition.Name)
+		}
+	}
+
+	return nil
+}
+
+// Validate the product attribute values are strings, finite numbers or bools, their types are checked by ValidateAttributes
+func validateAttributeValues(attributes map[string]interface{}) error {
+	for name, value := range attributes {
+		if err := validateAttributeName(name); err != nil {
+			return err
+		}
+
+		switch value := value.(type) {
+		case string, bool:
+		case float64:
+			if math.IsInf(value, 0) || math.IsNaN(value) {
+				return fmt.Errorf("attribute %s must be a finite number", name)
+			}
+		default:
+			return fmt.Errorf("attribute %s must be a string, number or bool", name)
+		}
+	}
+
+	return nil
+}
+
+// Range filters are parsed from the name suffix, so names can not end with an operator suffix
+func validateAttributeName(name string) error {
+	if !attributeNamePattern.MatchString(name) {
+		return fmt.Errorf("attribute name %s must hold lowercase letters, digits and underscores", name)
+	}
+
+	if strings.HasSuffix(name, "_"+AttributeGreaterOrEqual) || strings.HasSuffix(name, "_"+AttributeLessOrEqual) {
+		return fmt.Errorf("attribute name %s cannot end with _gte or _lte", name)
+	}
+
+	return nil
+}
+
+// Parse the attribute filters of the search query, sorted so equal searches build equal requests
+func parseAttributeFilters(query url.Values) ([]AttributeFilter, error) {
+	var filters []AttributeFilter
+
+	for param, values := range query {
+		if !strings.HasPrefix(param, attributeFilterPrefix) {
+			continue
+		}
+
+		filter := AttributeFilter{
+			Name:     strings.TrimPrefix(param, attributeFilterPrefix),
+			Operator: AttributeEqual,
+			Value:    values[0],
+		}
+
+		for _, operator := range []string{AttributeGreaterOrEqual, AttributeLessOrEqual} {
+			if name := strings.TrimSuffix(filter.Name, "_"+operator); name != filter.Name {
+				filter.Name, filter.Operator = name, operator
+			}
+		}
+
+		if !attributeNamePattern.MatchString(filter.Name) {
+			return nil, fmt.Errorf("incorrect %s filter format", param)
+		}
+
+		if filter.Operator != AttributeEqual {
+			if _, err := strconv.ParseFloat(filter.Value, 64); err != nil {
+				return nil, fmt.Errorf("incorrect %s filter format, it must be a number", param)
+			}
+		}
+
+		filters = append(filters, filter)
+	}
+
+	sort.Slice(filters, func(i, j int) bool {
+		if filters[i].Name != filters[j].Name {
+			return filters[i].Name < filters[j].Name
+		}
+
+		return filters[i].Operator < filters[j].Operator
+	})
+
+	return filters, nil
+}
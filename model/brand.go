@@ -0,0 +1,58 @@
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// Represent product brand, its name is unique ignoring case
+type Brand struct {
+	ID          string    `json:"id" bson:"_id"`
+	Name        string    `json:"name" bson:"name"`
+	Description *string   `json:"description,omitempty" bson:"description,omitempty"`
+	CreatedAt   time.Time `json:"created_at" bson:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at" bson:"updated_at"`
+}
+
+// Represent brand create and update request
+type BrandRequest struct {
+	Name        string  `json:"name"`
+	Description *string `json:"description,omitempty"`
+}
+
+// Build brand create and update request and validate all requested data
+type BrandBuilder struct {
+	r *http.Request
+}
+
+func NewBrandBuilder(r *http.Request) *BrandBuilder {
+	return &BrandBuilder{
+		r: r,
+	}
+}
+
+// Build the requested brand, its id is taken from the route when updating
+func (b *BrandBuilder) Build() (*Brand, error) {
+	var request BrandRequest
+
+	if err := json.NewDecoder(b.r.Body).Decode(&request); err != nil {
+		return nil, errors.New("incorrect brand body format")
+	}
+
+	name := strings.TrimSpace(request.Name)
+
+	if name == "" {
+		return nil, errors.New("brand name cannot be empty")
+	}
+
+	return &Brand{
+		ID:          mux.Vars(b.r)["id"],
+		Name:        name,
+		Description: request.Description,
+	}, nil
+}
@@ -0,0 +1,160 @@
+package model
+
+import (
+	"errors"
+)
+
+// Represent how a bundle is priced
+type BundlePricing string
+
+const (
+	// The bundle keeps its own price
+	BundlePricingFixed BundlePricing = "fixed"
+	// The bundle costs the sum of its components minus its discount
+	BundlePricingComponents BundlePricing = "components"
+)
+
+// Represent a product sold as a set of other products, its qty and in_stock are computed from the stock of its components
+// Discount is the amount taken from the components sum when it is priced on them
+type Bundle struct {
+	Components []BundleComponent `json:"components" bson:"components"`
+	Pricing    BundlePricing     `json:"pricing" bson:"pricing"`
+	Discount   int64             `json:"discount,omitempty" bson:"discount,omitempty"`
+}
+
+// Represent how many units of a product a bundle holds
+type BundleComponent struct {
+	ProductID string `json:"product_id" bson:"product_id"`
+	Qty       uint64 `json:"qty" bson:"qty"`
+}
+
+// Reports whether the product is a bundle
+func (p *Product) IsBundle() bool {
+	return p.Bundle != nil
+}
+
+// Get the ids of the bundle components, nil when the product is not a bundle
+func (p *Product) ComponentIDs() []string {
+	if p.Bundle == nil {
+		return nil
+	}
+
+	ids := make([]string, 0, len(p.Bundle.Components))
+
+	for _, component := range p.Bundle.Components {
+		ids = append(ids, component.ProductID)
+	}
+
+	return ids
+}
+
+// Reports whether the product is a bundle priced on its components
+func (p *Product) PricedOnComponents() bool {
+	return p.Bundle != nil && p.Bundle.Pricing == BundlePricingComponents
+}
+
+// Compute the bundle qty and in_stock from its components, and its price when it is priced on them
+// The bundle qty is how many complete sets the components stock makes, missing components make none
+// Reports whether any of them changed
+func (p *Product) ResolveBundle(components map[string]Product) bool {
+	if p.Bundle == nil {
+		return false
+	}
+
+	var qty, price uint64
+
+	for i, component := range p.Bundle.Components {
+		product, ok := components[component.ProductID]
+		if !ok {
+			qty = 0
+
+			break
+		}
+
+		sets := product.Qty / component.Qty
+
+		if i == 0 || sets < qty {
+			qty = sets
+		}
+
+		price += uint64(product.Price) * component.Qty
+	}
+
+	changed := p.Qty != qty || p.InStock != (qty > 0)
+
+	p.Qty, p.InStock = qty, qty > 0
+
+	if p.Bundle.Pricing == BundlePricingComponents {
+		total := int64(price) - p.Bundle.Discount
+
+		if total < 0 {
+			total = 0
+		}
+
+		changed = changed || p.Price != total
+
+		p.Price = total
+	}
+
+	return changed
+}
+
+// Validate the bundle components and pricing, bundles without pricing keep their price
+// Their qty is computed from their components, so the requested one is dropped
+func validateBundle(product *Product) error {
+	bundle := product.Bundle
+
+	if bundle == nil {
+		return nil
+	}
+
+	if len(bundle.Components) == 0 {
+		return errors.New("product bundle components cannot be empty")
+	}
+
+	if len(product.Variants) > 0 {
+		return errors.New("product bundle cannot have variants")
+	}
+
+	components := make(map[string]bool, len(bundle.Components))
+
+	for _, component := range bundle.Components {
+		if component.ProductID == "" {
+			return errors.New("product bundle component product id cannot be empty")
+		}
+
+		if components[component.ProductID] {
+			return errors.New("product bundle component product ids cannot be repeated")
+		}
+
+		components[component.ProductID] = true
+
+		if component.Qty == 0 {
+			return errors.New("product bundle component qty must be greater than 0")
+		}
+	}
+
+	if bundle.Discount < 0 {
+		return errors.New("product bundle discount cannot be negative")
+	}
+
+	switch bundle.Pricing {
+	case "":
+		bundle.Pricing = BundlePricingFixed
+	case BundlePricingFixed, BundlePricingComponents:
+	default:
+		return errors.New("product bundle pricing must be fixed or components")
+	}
+
+	if bundle.Pricing == BundlePricingFixed && bundle.Discount > 0 {
+		return errors.New("product bundle discount only applies to bundles priced on their components")
+	}
+
+	if bundle.Pricing == BundlePricingComponents && (product.Price != 0 || len(product.Prices) > 0) {
+		return errors.New("product bundle priced on its components cannot have a price")
+	}
+
+	product.Qty = 0
+
+	return nil
+}
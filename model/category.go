@@ -0,0 +1,70 @@
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// Represent product category, Path holds the ids of its ancestors starting from the root category
+// Attributes define the attributes of the products on the category and its descendants
+type Category struct {
+	ID         string                `json:"id" bson:"_id"`
+	Name       string                `json:"name" bson:"name"`
+	ParentID   string                `json:"parent_id,omitempty" bson:"parent_id,omitempty"`
+	Path       []string              `json:"path" bson:"path"`
+	Attributes []AttributeDefinition `json:"attributes,omitempty" bson:"attributes,omitempty"`
+	CreatedAt  time.Time             `json:"created_at" bson:"created_at"`
+	UpdatedAt  time.Time             `json:"updated_at" bson:"updated_at"`
+}
+
+// Represent category create and update request, an empty parent creates a root category
+type CategoryRequest struct {
+	Name       string                `json:"name"`
+	ParentID   string                `json:"parent_id"`
+	Attributes []AttributeDefinition `json:"attributes,omitempty"`
+}
+
+// Build category create and update request and validate all requested data
+type CategoryBuilder struct {
+	r *http.Request
+}
+
+func NewCategoryBuilder(r *http.Request) *CategoryBuilder {
+	return &CategoryBuilder{
+		r: r,
+	}
+}
+
+// Build the requested category, its id is taken from the route when updating
+func (b *CategoryBuilder) Build() (*Category, error) {
+	var request CategoryRequest
+
+	if err := json.NewDecoder(b.r.Body).Decode(&request); err != nil {
+		return nil, errors.New("incorrect category body format")
+	}
+
+	if request.Name == "" {
+		return nil, errors.New("category name cannot be empty")
+	}
+
+	if err := validateAttributeDefinitions(request.Attributes); err != nil {
+		return nil, err
+	}
+
+	id := mux.Vars(b.r)["id"]
+
+	if id != "" && id == request.ParentID {
+		return nil, errors.New("category cannot be its own parent")
+	}
+
+	return &Category{
+		ID:         id,
+		Name:       request.Name,
+		ParentID:   request.ParentID,
+		Attributes: request.Attributes,
+	}, nil
+}
@@ -0,0 +1,86 @@
+package model
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+)
+
+// Represent a position on the search results, which are sorted by price and id.
+// Backward cursors point to the products placed before the position, Price is on the searched currency
+type Cursor struct {
+	Price    int64  `json:"p"`
+	ID       string `json:"i"`
+	Sort     string `json:"s,omitempty"`
+	Currency string `json:"c,omitempty"`
+	Backward bool   `json:"b,omitempty"`
+}
+
+// Create a cursor placed on product
+func NewCursor(product Product, sort string, backward bool) *Cursor {
+	return &Cursor{
+		Price:    product.Price,
+		ID:       product.ID,
+		Sort:     sort,
+		Backward: backward,
+	}
+}
+
+// Parse a cursor returned on search metadata
+func ParseCursor(token string) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, errors.New("incorrect cursor format")
+	}
+
+	var cursor Cursor
+
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.ID == "" {
+		return nil, errors.New("incorrect cursor format")
+	}
+
+	return &cursor, nil
+}
+
+// Opaque representation of the cursor, clients send it back as is
+func (c *Cursor) String() string {
+	if c == nil {
+		return ""
+	}
+
+	data, _ := json.Marshal(c)
+
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// Reports whether product is placed after the cursor position on the sort order
+func (c *Cursor) Precedes(product Product) bool {
+	return c.compare(product) > 0
+}
+
+// Reports whether product is placed before the cursor position on the sort order
+func (c *Cursor) Follows(product Product) bool {
+	return c.compare(product) < 0
+}
+
+// Compare product position with the cursor one, negative when product is placed before it
+func (c *Cursor) compare(product Product) int {
+	result := 0
+
+	switch {
+	case product.Price < c.Price:
+		result = -1
+	case product.Price > c.Price:
+		result = 1
+	case product.ID < c.ID:
+		result = -1
+	case product.ID > c.ID:
+		result = 1
+	}
+
+	if c.Sort == "desc" {
+		return -result
+	}
+
+	return result
+}
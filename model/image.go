@@ -0,0 +1,347 @@
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+)
+
+// Represent a product image, Alt holds its alternative text by locale
+// Images are sorted by Position and one of them is the Primary one
+type Image struct {
+	ID       string            `json:"id" bson:"id"`
+	URL      string            `json:"url" bson:"url"`
+	Alt      map[string]string `json:"alt,omitempty" bson:"alt,omitempty"`
+	Width    int               `json:"width,omitempty" bson:"width,omitempty"`
+	Height   int               `json:"height,omitempty" bson:"height,omitempty"`
+	MimeType string            `json:"mime_type,omitempty" bson:"mime_type,omitempty"`
+	Position int               `json:"position" bson:"position"`
+	Primary  bool              `json:"primary,omitempty" bson:"primary,omitempty"`
+}
+
+// Image fields without custom decoding
+type imageFields Image
+
+// Decode an image, images stored and requested before they had metadata are their url
+func (i *Image) UnmarshalJSON(data []byte) error {
+	var value string
+
+	if err := json.Unmarshal(data, &value); err == nil {
+		*i = urlImage(value)
+
+		return nil
+	}
+
+	return json.Unmarshal(data, (*imageFields)(i))
+}
+
+// Decode an image stored by MongoDB, images stored before they had metadata are their url
+func (i *Image) UnmarshalBSONValue(t bsontype.Type, data []byte) error {
+	value := bson.RawValue{Type: t, Value: data}
+
+	if location, ok := value.StringValueOK(); ok {
+		*i = urlImage(location)
+
+		return nil
+	}
+
+	return value.Unmarshal((*imageFields)(i))
+}
+
+// Images given by their url have an id derived from it, so it does not change between reads
+func urlImage(location string) Image {
+	return Image{ID: uuid.NewSHA1(uuid.NameSpaceURL, []byte(location)).String(), URL: location}
+}
+
+// Sort images by position, numbering them from 0, the first image is the primary one when none is
+func sortImages(images []Image) {
+	sort.SliceStable(images, func(i, j int) bool {
+		return images[i].Position < images[j].Position
+	})
+
+	primary := false
+
+	for i := range images {
+		images[i].Position = i
+
+		primary = primary || images[i].Primary
+	}
+
+	if !primary && len(images) > 0 {
+		images[0].Primary = true
+	}
+}
+
+// Get the image with the given id, nil when there is none
+func (p *Product) Image(id string) *Image {
+	for i := range p.Images {
+		if p.Images[i].ID == id {
+			return &p.Images[i]
+		}
+	}
+
+	return nil
+}
+
+// Add an image after the product images, a primary image replaces the product primary one
+// Reports false when the product already has an image with its url
+func (p *Product) AddImage(image Image) bool {
+	for _, current := range p.Images {
+		if current.URL == image.URL {
+			return false
+		}
+	}
+
+	if image.Primary {
+		for i := range p.Images {
+			p.Images[i].Primary = false
+		}
+	}
+
+	image.Position = len(p.Images)
+
+	p.Images = append(p.Images, image)
+
+	sortImages(p.Images)
+
+	return true
+}
+
+// Remove the product image with the given id, reports false when there is none
+// Removing the primary image makes the first one primary
+func (p *Product) RemoveImage(id string) bool {
+	for i, image := range p.Images {
+		if image.ID == id {
+			p.Images = append(append([]Image(nil), p.Images[:i]...), p.Images[i+1:]...)
+
+			sortImages(p.Images)
+
+			return true
+		}
+	}
+
+	return false
+}
+
+// Sort the product images on the given ids order and make primaryID the primary one when it is set
+// Reports false when the ids are not the product image ids or primaryID is not one of them
+func (p *Product) ReorderImages(ids []string, primaryID string) bool {
+	if len(ids) != len(p.Images) {
+		return false
+	}
+
+	positions := make(map[string]int, len(ids))
+
+	for position, id := range ids {
+		if _, ok := positions[id]; ok || p.Image(id) == nil {
+			return false
+		}
+
+		positions[id] = position
+	}
+
+	if primaryID != "" && p.Image(primaryID) == nil {
+		return false
+	}
+
+	for i := range p.Images {
+		image := &p.Images[i]
+
+		image.Position = positions[image.ID]
+
+		if primaryID != "" {
+			image.Primary = image.ID == primaryID
+		}
+	}
+
+	sortImages(p.Images)
+
+	return true
+}
+
+// Validate the images and sort them, images without id get one
+// Urls can not be repeated and only one image can be the primary one
+func validateImages(images []Image) error {
+	urls := make(map[string]bool, len(images))
+
+	primary := false
+
+	for i := range images {
+		image := &images[i]
+
+		if err := validateImage(image); err != nil {
+			return err
+		}
+
+		if urls[image.URL] {
+			return errors.New("product image urls cannot be repeated")
+		}
+
+		urls[image.URL] = true
+
+		if image.Primary && primary {
+			return errors.New("product can only have one primary image")
+		}
+
+		primary = primary || image.Primary
+
+		if image.ID == "" {
+			image.ID = uuid.NewString()
+		}
+	}
+
+	sortImages(images)
+
+	return nil
+}
+
+func validateImage(image *Image) error {
+	if image.URL == "" {
+		return errors.New("product image url cannot be empty")
+	}
+
+	location, err := url.Parse(image.URL)
+	if err != nil || !location.IsAbs() || location.Host == "" || location.Scheme != "http" && location.Scheme != "https" {
+		return fmt.Errorf("product image url %s must be an absolute http or https url", image.URL)
+	}
+
+	if image.Width < 0 || image.Height < 0 {
+		return errors.New("product image width and height cannot be negative")
+	}
+
+	if image.MimeType != "" && !strings.HasPrefix(image.MimeType, "image/") {
+		return errors.New("product image mime type must be an image one")
+	}
+
+	alt := make(map[string]string, len(image.Alt))
+
+	for key, text := range image.Alt {
+		locale, err := ParseLocale(key)
+		if err != nil {
+			return errors.New("product image alt locale invalid format")
+		}
+
+		if text == "" {
+			return errors.New("product image alt text cannot be empty")
+		}
+
+		alt[locale] = text
+	}
+
+	if len(alt) > 0 {
+		image.Alt = alt
+	}
+
+	return nil
+}
+
+// Represent a request to add or remove a product image, only the image id is set to remove it
+type ImageRequest struct {
+	ID      string
+	Version int64
+	Image
+}
+
+// Represent a request to reorder the product images, ImageIDs holds every image id on its new order
+type ImageOrderRequest struct {
+	ID        string   `json:"-"`
+	Version   int64    `json:"-"`
+	ImageIDs  []string `json:"image_ids"`
+	PrimaryID string   `json:"primary_id,omitempty"`
+}
+
+// Build product image request and validate all requested data, the image id to remove is taken from the route
+type ImageBuilder struct {
+	r *http.Request
+}
+
+func NewImageBuilder(r *http.Request) *ImageBuilder {
+	return &ImageBuilder{
+		r: r,
+	}
+}
+
+// Build the requested image, its body is only read when it is added
+func (b *ImageBuilder) Build() (*ImageRequest, error) {
+	vars := mux.Vars(b.r)
+
+	if vars["id"] == "" {
+		return nil, errors.New("product id must be provided")
+	}
+
+	version, err := ParseIfMatch(b.r.Header.Get("If-Match"))
+	if err != nil {
+		return nil, err
+	}
+
+	request := ImageRequest{ID: vars["id"], Version: version}
+
+	// Removing an image only takes its id
+	if b.r.Method != http.MethodPost {
+		if vars["image_id"] == "" {
+			return nil, errors.New("product image id must be provided")
+		}
+
+		request.Image.ID = vars["image_id"]
+
+		return &request, nil
+	}
+
+	if err := json.NewDecoder(b.r.Body).Decode(&request.Image); err != nil {
+		return nil, errors.New("incorrect product image body format")
+	}
+
+	if err := validateImage(&request.Image); err != nil {
+		return nil, err
+	}
+
+	request.Image.ID = uuid.NewString()
+
+	return &request, nil
+}
+
+// Build product image order request and validate all requested data
+type ImageOrderBuilder struct {
+	r *http.Request
+}
+
+func NewImageOrderBuilder(r *http.Request) *ImageOrderBuilder {
+	return &ImageOrderBuilder{
+		r: r,
+	}
+}
+
+func (b *ImageOrderBuilder) Build() (*ImageOrderRequest, error) {
+	id := mux.Vars(b.r)["id"]
+	if id == "" {
+		return nil, errors.New("product id must be provided")
+	}
+
+	version, err := ParseIfMatch(b.r.Header.Get("If-Match"))
+	if err != nil {
+		return nil, err
+	}
+
+	var request ImageOrderRequest
+
+	if err := json.NewDecoder(b.r.Body).Decode(&request); err != nil {
+		return nil, errors.New("incorrect product image order body format")
+	}
+
+	if len(request.ImageIDs) == 0 {
+		return nil, errors.New("product image ids cannot be empty")
+	}
+
+	request.ID, request.Version = id, version
+
+	return &request, nil
+}
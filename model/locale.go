@@ -0,0 +1,345 @@
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// Locales are language tags like en, es or pt-BR
+var localeFormat = regexp.MustCompile(`^[a-zA-Z]{2,3}(-[a-zA-Z0-9]{2,8})*$`)
+
+// Slugs are lowercase words of letters and numbers joined by hyphens
+var slugFormat = regexp.MustCompile(`^[\p{Ll}\p{Lo}\p{N}]+(-[\p{Ll}\p{Lo}\p{N}]+)*$`)
+
+// Represent the product content on a locale other than the product one
+// Language is the text search language of the locale, it is only stored by MongoDB
+type Translation struct {
+	Locale      string  `json:"locale" bson:"locale"`
+	Name        string  `json:"name" bson:"name"`
+	Description *string `json:"description,omitempty" bson:"description,omitempty"`
+	Slug        string  `json:"slug,omitempty" bson:"slug,omitempty"`
+	Language    string  `json:"-" bson:"language,omitempty"`
+}
+
+// Parse a locale, the language is lowercased and two letter regions are uppercased
+func ParseLocale(value string) (string, error) {
+	if !localeFormat.MatchString(value) {
+		return "", errors.New("incorrect locale format")
+	}
+
+	parts := strings.Split(value, "-")
+
+	parts[0] = strings.ToLower(parts[0])
+
+	for i := 1; i < len(parts); i++ {
+		if len(parts[i]) == 2 {
+			parts[i] = strings.ToUpper(parts[i])
+		} else {
+			parts[i] = strings.ToLower(parts[i])
+		}
+	}
+
+	return strings.Join(parts, "-"), nil
+}
+
+// Get the language of a locale, es for es-AR
+func LocaleLanguage(locale string) string {
+	language, _, _ := strings.Cut(locale, "-")
+
+	return language
+}
+
+// Parse the locales requested by the locale query param, or by the Accept-Language header sorted by preference
+// Invalid header entries are skipped, an invalid locale param is an error
+func ParseLocales(r *http.Request) ([]string, error) {
+	if value := r.URL.Query().Get("locale"); value != "" {
+		locale, err := ParseLocale(value)
+		if err != nil {
+			return nil, err
+		}
+
+		return []string{locale}, nil
+	}
+
+	type weighted struct {
+		locale  string
+		quality float64
+	}
+
+	var entries []weighted
+
+	for _, entry := range strings.Split(r.Header.Get("Accept-Language"), ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(entry), ";")
+
+		locale, err := ParseLocale(strings.TrimSpace(tag))
+		if err != nil {
+			continue
+		}
+
+		quality := 1.0
+
+		if params = strings.TrimSpace(params); strings.HasPrefix(params, "q=") {
+			if quality, err = strconv.ParseFloat(strings.TrimPrefix(params, "q="), 64); err != nil {
+				continue
+			}
+		}
+
+		if quality > 0 {
+			entries = append(entries, weighted{locale: locale, quality: quality})
+		}
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].quality > entries[j].quality
+	})
+
+	var locales []string
+
+	for _, entry := range entries {
+		locales = append(locales, entry.locale)
+	}
+
+	return locales, nil
+}
+
+// Choose the first requested locale available, a requested language matches its regional locales and the other way around
+// Returns false when none is available
+func NegotiateLocale(requested []string, available []string) (string, bool) {
+	for _, locale := range requested {
+		if match, ok := matchLocale(locale, available); ok {
+			return match, true
+		}
+	}
+
+	return "", false
+}
+
+// Exact matches are preferred over the ones on the same language
+func matchLocale(locale string, available []string) (string, bool) {
+	for _, candidate := range available {
+		if strings.EqualFold(candidate, locale) {
+			return candidate, true
+		}
+	}
+
+	for _, candidate := range available {
+		if LocaleLanguage(candidate) == LocaleLanguage(locale) {
+			return candidate, true
+		}
+	}
+
+	return "", false
+}
+
+// Get the product locale, products stored without one are on the default locale
+func (p *Product) ContentLocale(defaultLocale string) string {
+	if p.Locale == "" {
+		return defaultLocale
+	}
+
+	return p.Locale
+}
+
+// Localize the product content to locale, the product content is kept when it is on that locale or it has no translation to it
+// Translations without description or slug keep the product ones
+func (p *Product) Localize(locale string, defaultLocale string) {
+	current := p.ContentLocale(defaultLocale)
+
+	if locale == "" || current == locale {
+		return
+	}
+
+	translation := p.Translation(locale)
+
+	// A translation on the requested locale is preferred over the product content on another locale of its language
+	if translation == nil || translation.Locale != locale && current != "" && LocaleLanguage(current) == LocaleLanguage(locale) {
+		return
+	}
+
+	p.Locale, p.Name = translation.Locale, translation.Name
+
+	if translation.Description != nil {
+		p.Description = translation.Description
+	}
+
+	if translation.Slug != "" {
+		p.Slug = translation.Slug
+	}
+}
+
+// Get the product translation to locale, or to another locale of its language when there is none
+func (p *Product) Translation(locale string) *Translation {
+	locales := make([]string, 0, len(p.Translations))
+
+	for _, translation := range p.Translations {
+		locales = append(locales, translation.Locale)
+	}
+
+	match, ok := matchLocale(locale, locales)
+	if !ok {
+		return nil
+	}
+
+	for i := range p.Translations {
+		if p.Translations[i].Locale == match {
+			return &p.Translations[i]
+		}
+	}
+
+	return nil
+}
+
+// Get the product translation on exactly locale, nil when there is none
+func (p *Product) TranslationOn(locale string) *Translation {
+	for i := range p.Translations {
+		if p.Translations[i].Locale == locale {
+			return &p.Translations[i]
+		}
+	}
+
+	return nil
+}
+
+// Set the product translation to its locale, replacing the one already on it
+func (p *Product) SetTranslation(translation Translation) {
+	for i := range p.Translations {
+		if p.Translations[i].Locale == translation.Locale {
+			p.Translations[i] = translation
+
+			return
+		}
+	}
+
+	p.Translations = append(p.Translations, translation)
+}
+
+// Remove the product translation to locale, reports false when there is none
+func (p *Product) RemoveTranslation(locale string) bool {
+	for i, translation := range p.Translations {
+		if translation.Locale == locale {
+			p.Translations = append(append([]Translation(nil), p.Translations[:i]...), p.Translations[i+1:]...)
+
+			return true
+		}
+	}
+
+	return false
+}
+
+// Validate the product locale, slug and translations
+func validateContent(product *Product) error {
+	if product.Locale != "" {
+		locale, err := ParseLocale(product.Locale)
+		if err != nil {
+			return errors.New("product locale invalid format")
+		}
+
+		product.Locale = locale
+	}
+
+	if product.Slug != "" && !slugFormat.MatchString(product.Slug) {
+		return errors.New("product slug invalid format")
+	}
+
+	locales := map[string]bool{product.Locale: true}
+
+	for i := range product.Translations {
+		translation := &product.Translations[i]
+
+		if err := validateTranslation(translation); err != nil {
+			return err
+		}
+
+		if locales[translation.Locale] {
+			return errors.New("product translation locales cannot be repeated")
+		}
+
+		locales[translation.Locale] = true
+	}
+
+	return nil
+}
+
+func validateTranslation(translation *Translation) error {
+	locale, err := ParseLocale(translation.Locale)
+	if err != nil {
+		return errors.New("product translation locale invalid format")
+	}
+
+	translation.Locale = locale
+
+	if translation.Name == "" {
+		return errors.New("product translation name cannot be empty")
+	}
+
+	if translation.Slug != "" && !slugFormat.MatchString(translation.Slug) {
+		return errors.New("product translation slug invalid format")
+	}
+
+	translation.Language = ""
+
+	return nil
+}
+
+// Represent a request to set or remove a product translation
+type TranslationRequest struct {
+	ID      string
+	Version int64
+	Translation
+}
+
+// Build product translation request and validate all requested data, the locale is taken from the route
+type TranslationBuilder struct {
+	r *http.Request
+}
+
+func NewTranslationBuilder(r *http.Request) *TranslationBuilder {
+	return &TranslationBuilder{
+		r: r,
+	}
+}
+
+// Build the requested translation, its body is only read when it is set
+func (b *TranslationBuilder) Build() (*TranslationRequest, error) {
+	vars := mux.Vars(b.r)
+
+	if vars["id"] == "" {
+		return nil, errors.New("product id must be provided")
+	}
+
+	version, err := ParseIfMatch(b.r.Header.Get("If-Match"))
+	if err != nil {
+		return nil, err
+	}
+
+	locale, err := ParseLocale(vars["locale"])
+	if err != nil {
+		return nil, err
+	}
+
+	request := TranslationRequest{ID: vars["id"], Version: version}
+
+	// Removing a translation only takes its locale
+	if b.r.Method == http.MethodPut {
+		if err := json.NewDecoder(b.r.Body).Decode(&request.Translation); err != nil {
+			return nil, errors.New("incorrect product translation body format")
+		}
+
+		request.Locale = locale
+
+		if err := validateTranslation(&request.Translation); err != nil {
+			return nil, err
+		}
+	}
+
+	request.Locale = locale
+
+	return &request, nil
+}
@@ -0,0 +1,237 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+)
+
+// ISO 4217 currency codes
+var currencyPattern = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// Minor unit digits of the currencies that do not use cents
+var currencyExponents = map[string]int{
+	"BHD": 3,
+	"CLP": 0,
+	"ISK": 0,
+	"JOD": 3,
+	"JPY": 0,
+	"KRW": 0,
+	"KWD": 3,
+	"OMR": 3,
+	"PYG": 0,
+	"TND": 3,
+	"UGX": 0,
+	"VND": 0,
+	"XAF": 0,
+	"XOF": 0,
+}
+
+// Represent an amount of money in the minor units of its ISO 4217 currency
+type Money struct {
+	Amount   int64  `json:"amount" bson:"amount"`
+	Currency string `json:"currency" bson:"currency"`
+}
+
+// Represent exchange rates as the units of each currency worth one unit of the base currency
+type ExchangeRates struct {
+	Base  string             `yaml:"base"`
+	Rates map[string]float64 `yaml:"rates"`
+}
+
+// Represent the currency reads select product prices on
+// Products without a price on Code are converted with Factors, indexed by the currency they convert from
+type CurrencySelection struct {
+	Code string
+	// Currency of the products stored without one
+	Default string
+	Factors map[string]float64
+}
+
+// Parse a requested currency code, an empty code selects no currency
+func ParseCurrency(code string) (string, error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+
+	if code != "" && !currencyPattern.MatchString(code) {
+		return "", errors.New("incorrect currency format")
+	}
+
+	return code, nil
+}
+
+// Factor converting minor units of from into minor units of to, ok is false when a rate is missing
+func (r ExchangeRates) Factor(from string, to string) (float64, bool) {
+	fromRate, ok := r.rate(from)
+	if !ok {
+		return 0, false
+	}
+
+	toRate, ok := r.rate(to)
+	if !ok {
+		return 0, false
+	}
+
+	return toRate / fromRate * math.Pow10(currencyExponent(to)-currencyExponent(from)), true
+}
+
+func (r ExchangeRates) rate(currency string) (float64, bool) {
+	if currency == r.Base {
+		return 1, true
+	}
+
+	rate, ok := r.Rates[currency]
+
+	return rate, ok && rate > 0
+}
+
+// Validate the configured rates, an empty table disables conversion
+func (r ExchangeRates) Validate() error {
+	if len(r.Rates) == 0 {
+		return nil
+	}
+
+	if !currencyPattern.MatchString(r.Base) {
+		return fmt.Errorf("exchange rates base currency %s invalid", r.Base)
+	}
+
+	for currency, rate := range r.Rates {
+		if !currencyPattern.MatchString(currency) || rate <= 0 {
+			return fmt.Errorf("exchange rate of %s invalid", currency)
+		}
+	}
+
+	return nil
+}
+
+// Selection of code that converts from every currency with a rate, a nil rates table selects without conversion
+func NewCurrencySelection(code string, defaultCurrency string, rates *ExchangeRates) CurrencySelection {
+	selection := CurrencySelection{
+		Code:    code,
+		Default: defaultCurrency,
+	}
+
+	if rates == nil || len(rates.Rates) == 0 {
+		return selection
+	}
+
+	selection.Factors = make(map[string]float64, len(rates.Rates))
+
+	for _, from := range append(rates.currencies(), rates.Base) {
+		if from == code {
+			continue
+		}
+
+		if factor, ok := rates.Factor(from, code); ok {
+			selection.Factors[from] = factor
+		}
+	}
+
+	return selection
+}
+
+func (r ExchangeRates) currencies() []string {
+	currencies := make([]string, 0, len(r.Rates))
+
+	for currency := range r.Rates {
+		currencies = append(currencies, currency)
+	}
+
+	return currencies
+}
+
+// Reports whether a currency is selected
+func (s CurrencySelection) Selected() bool {
+	return s.Code != ""
+}
+
+// Convert an amount with a factor, rounding half up. Repositories sorting by converted prices round the same way
+func Convert(amount int64, factor float64) int64 {
+	return int64(math.Floor(float64(amount)*factor + 0.5))
+}
+
+// Set the product price to the one on the selected currency, the price on that currency or its price list first
+// and the converted product price otherwise. Variant prices are converted too
+// Reports false when the product has no price on the selected currency
+func (p *Product) SelectCurrency(selection CurrencySelection) bool {
+	if !selection.Selected() {
+		return true
+	}
+
+	currency := p.Currency
+
+	if currency == "" {
+		currency = selection.Default
+	}
+
+	if currency == selection.Code {
+		p.Currency = currency
+
+		return true
+	}
+
+	for _, price := range p.Prices {
+		if price.Currency == selection.Code {
+			p.Price, p.Currency = price.Amount, price.Currency
+
+			return true
+		}
+	}
+
+	factor, ok := selection.Factors[currency]
+	if !ok || currency == "" {
+		return false
+	}
+
+	p.Price, p.Currency = Convert(p.Price, factor), selection.Code
+
+	for i := range p.Variants {
+		p.Variants[i].Price = Convert(p.Variants[i].Price, factor)
+	}
+
+	if p.PriceRange != nil {
+		p.PriceRange = &PriceRange{Min: Convert(p.PriceRange.Min, factor), Max: Convert(p.PriceRange.Max, factor)}
+	}
+
+	return true
+}
+
+// Validate the product currency and its price list
+func validatePrices(product Product) error {
+	if product.Currency != "" && !currencyPattern.MatchString(product.Currency) {
+		return errors.New("product currency must be an ISO 4217 code")
+	}
+
+	if len(product.Prices) > 0 && len(product.Variants) > 0 {
+		return errors.New("product with variants cannot have a price list")
+	}
+
+	currencies := map[string]bool{product.Currency: true}
+
+	for _, price := range product.Prices {
+		if !currencyPattern.MatchString(price.Currency) {
+			return errors.New("product price list currency must be an ISO 4217 code")
+		}
+
+		if currencies[price.Currency] {
+			return fmt.Errorf("product price list currency %s cannot be repeated", price.Currency)
+		}
+
+		currencies[price.Currency] = true
+
+		if price.Amount <= 0 {
+			return fmt.Errorf("product price list %s amount invalid value", price.Currency)
+		}
+	}
+
+	return nil
+}
+
+func currencyExponent(currency string) int {
+	if exponent, ok := currencyExponents[currency]; ok {
+		return exponent
+	}
+
+	return 2
+}
@@ -0,0 +1,326 @@
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// Header holding who made the request, recorded on the price changes
+const ActorHeader = "X-Actor"
+
+// Represent a price the product takes from StartsAt until EndsAt
+// Scheduled prices without end become the product regular price once they start
+type ScheduledPrice struct {
+	ID        string     `json:"id" bson:"id"`
+	Price     int64      `json:"price" bson:"price"`
+	StartsAt  time.Time  `json:"starts_at" bson:"starts_at"`
+	EndsAt    *time.Time `json:"ends_at,omitempty" bson:"ends_at,omitempty"`
+	Actor     string     `json:"actor,omitempty" bson:"actor,omitempty"`
+	CreatedAt time.Time  `json:"created_at" bson:"created_at"`
+}
+
+// Represent a change of the product price, or of one of its variants when Sku is set
+// ScheduleID is set when the change was made by a scheduled price
+type PriceChange struct {
+	Price         int64     `json:"price" bson:"price"`
+	PreviousPrice int64     `json:"previous_price,omitempty" bson:"previous_price,omitempty"`
+	Sku           string    `json:"sku,omitempty" bson:"sku,omitempty"`
+	ChangedAt     time.Time `json:"changed_at" bson:"changed_at"`
+	Actor         string    `json:"actor,omitempty" bson:"actor,omitempty"`
+	ScheduleID    string    `json:"schedule_id,omitempty" bson:"schedule_id,omitempty"`
+}
+
+// Represent the pricing written by a price change, it replaces the stored one as a whole
+// Price is set on the updated variant when the update has a variant sku
+type Pricing struct {
+	Price          int64
+	RegularPrice   int64
+	PriceSchedule  []ScheduledPrice
+	PriceHistory   []PriceChange
+	PriceChangesAt *time.Time
+}
+
+// Represent the product price timeline, the past changes and the scheduled ones
+type PriceTimeline struct {
+	ProductID    string           `json:"product_id"`
+	Price        int64            `json:"price"`
+	RegularPrice int64            `json:"regular_price"`
+	Currency     string           `json:"currency,omitempty"`
+	History      []PriceChange    `json:"history"`
+	Scheduled    []ScheduledPrice `json:"scheduled"`
+}
+
+// Get the product price out of its scheduled prices
+func (p *Product) regularPrice() int64 {
+	if p.RegularPrice == 0 || len(p.Variants) > 0 {
+		return p.Price
+	}
+
+	return p.RegularPrice
+}
+
+// Resolve the product price at the given time. Started scheduled prices without end become the regular price,
+// ended ones are dropped and the started one with the latest start is the product price, the regular price otherwise
+// The price change is recorded on the history at the time it took effect. Reports whether the pricing changed
+func (p *Product) ResolvePrice(at time.Time) bool {
+	if len(p.PriceSchedule) == 0 || len(p.Variants) > 0 {
+		return false
+	}
+
+	regular := p.regularPrice()
+
+	schedule := append([]ScheduledPrice(nil), p.PriceSchedule...)
+
+	sort.SliceStable(schedule, func(i, j int) bool {
+		return schedule[i].StartsAt.Before(schedule[j].StartsAt)
+	})
+
+	var (
+		kept      []ScheduledPrice
+		active    *ScheduledPrice
+		source    *ScheduledPrice
+		changedAt time.Time
+	)
+
+	for i := range schedule {
+		scheduled := &schedule[i]
+
+		switch {
+		case scheduled.StartsAt.After(at):
+			kept = append(kept, *scheduled)
+
+			continue
+		case scheduled.EndsAt == nil:
+			regular = scheduled.Price
+		case !scheduled.EndsAt.After(at):
+			// Ended prices take effect on their end
+			if scheduled.EndsAt.After(changedAt) {
+				source, changedAt = scheduled, *scheduled.EndsAt
+			}
+
+			continue
+		default:
+			kept = append(kept, *scheduled)
+
+			active = scheduled
+		}
+
+		if scheduled.StartsAt.After(changedAt) {
+			source, changedAt = scheduled, scheduled.StartsAt
+		}
+	}
+
+	price := regular
+
+	if active != nil {
+		price = active.Price
+	}
+
+	next := nextPriceChange(kept, at)
+
+	changed := len(kept) != len(p.PriceSchedule) || regular != p.regularPrice() || !sameTime(next, p.PriceChangesAt)
+
+	if price != p.Price && source != nil {
+		p.PriceHistory = append(p.PriceHistory, PriceChange{
+			Price:         price,
+			PreviousPrice: p.Price,
+			ChangedAt:     changedAt,
+			Actor:         source.Actor,
+			ScheduleID:    source.ID,
+		})
+
+		changed = true
+	}
+
+	p.Price, p.RegularPrice, p.PriceSchedule = price, regular, kept
+
+	p.PriceChangesAt = next
+
+	return changed
+}
+
+func sameTime(a *time.Time, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	return a.Equal(*b)
+}
+
+// Earliest start or end of the scheduled prices after the given time
+func nextPriceChange(schedule []ScheduledPrice, at time.Time) *time.Time {
+	var next *time.Time
+
+	for _, scheduled := range schedule {
+		for _, boundary := range []*time.Time{&scheduled.StartsAt, scheduled.EndsAt} {
+			if boundary == nil || !boundary.After(at) {
+				continue
+			}
+
+			if next == nil || boundary.Before(*next) {
+				value := *boundary
+
+				next = &value
+			}
+		}
+	}
+
+	return next
+}
+
+// Set the price of the product, or of its variant with the given sku, recording the change on the history
+// While a scheduled price is running the product keeps it and the regular price is changed
+func (p *Product) ChangePrice(price int64, variantSKU string, actor string, at time.Time) {
+	change := PriceChange{
+		Price:     price,
+		Sku:       variantSKU,
+		ChangedAt: at,
+		Actor:     actor,
+	}
+
+	if variantSKU != "" {
+		variant := p.Variant(variantSKU)
+
+		change.PreviousPrice, variant.Price = variant.Price, price
+
+		p.ApplyVariants()
+	} else {
+		p.ResolvePrice(at)
+
+		change.PreviousPrice, p.RegularPrice = p.regularPrice(), price
+
+		// Once resolved, every scheduled price that already started is running
+		running := false
+
+		for _, scheduled := range p.PriceSchedule {
+			running = running || !scheduled.StartsAt.After(at)
+		}
+
+		if !running {
+			p.Price = price
+		}
+	}
+
+	p.PriceHistory = append(p.PriceHistory, change)
+}
+
+// Add a scheduled price and resolve the product price with it, products with variants take their prices from them
+// so they have no scheduled prices
+func (p *Product) SchedulePrice(scheduled ScheduledPrice, at time.Time) {
+	p.RegularPrice = p.regularPrice()
+
+	p.PriceSchedule = append(p.PriceSchedule, scheduled)
+
+	p.ResolvePrice(at)
+}
+
+// Remove a scheduled price that did not start yet, reports false when there is none with the given id
+func (p *Product) CancelScheduledPrice(id string, at time.Time) bool {
+	for i, scheduled := range p.PriceSchedule {
+		if scheduled.ID != id || !scheduled.StartsAt.After(at) {
+			continue
+		}
+
+		p.PriceSchedule = append(append([]ScheduledPrice(nil), p.PriceSchedule[:i]...), p.PriceSchedule[i+1:]...)
+
+		p.PriceChangesAt = nextPriceChange(p.PriceSchedule, at)
+
+		return true
+	}
+
+	return false
+}
+
+// Get the product pricing, written on updates that change it
+func (p *Product) Pricing() *Pricing {
+	return &Pricing{
+		Price:          p.Price,
+		RegularPrice:   p.regularPrice(),
+		PriceSchedule:  p.PriceSchedule,
+		PriceHistory:   p.PriceHistory,
+		PriceChangesAt: p.PriceChangesAt,
+	}
+}
+
+// Get the product price timeline, history sorted by change time and scheduled prices by start
+func (p *Product) PriceTimeline() *PriceTimeline {
+	timeline := &PriceTimeline{
+		ProductID:    p.ID,
+		Price:        p.Price,
+		RegularPrice: p.regularPrice(),
+		Currency:     p.Currency,
+		History:      append([]PriceChange{}, p.PriceHistory...),
+		Scheduled:    append([]ScheduledPrice{}, p.PriceSchedule...),
+	}
+
+	sort.SliceStable(timeline.History, func(i, j int) bool {
+		return timeline.History[i].ChangedAt.Before(timeline.History[j].ChangedAt)
+	})
+
+	sort.SliceStable(timeline.Scheduled, func(i, j int) bool {
+		return timeline.Scheduled[i].StartsAt.Before(timeline.Scheduled[j].StartsAt)
+	})
+
+	return timeline
+}
+
+// Represent a request to schedule a product price
+type SchedulePriceRequest struct {
+	ID string
+	ScheduledPrice
+}
+
+// Build schedule price request and validate all requested data
+type SchedulePriceBuilder struct {
+	r *http.Request
+}
+
+func NewSchedulePriceBuilder(r *http.Request) *SchedulePriceBuilder {
+	return &SchedulePriceBuilder{
+		r: r,
+	}
+}
+
+func (b *SchedulePriceBuilder) Build() (*SchedulePriceRequest, error) {
+	id := mux.Vars(b.r)["id"]
+	if id == "" {
+		return nil, errors.New("product id must be provided")
+	}
+
+	var scheduled ScheduledPrice
+
+	if err := json.NewDecoder(b.r.Body).Decode(&scheduled); err != nil {
+		return nil, errors.New("incorrect scheduled price body format")
+	}
+
+	if scheduled.Price <= 0 {
+		return nil, errors.New("scheduled price invalid value")
+	}
+
+	now := time.Now()
+
+	if !scheduled.StartsAt.After(now) {
+		return nil, errors.New("scheduled price must start in the future")
+	}
+
+	if scheduled.EndsAt != nil && !scheduled.EndsAt.After(scheduled.StartsAt) {
+		return nil, errors.New("scheduled price must end after its start")
+	}
+
+	scheduled.ID = uuid.NewString()
+
+	scheduled.Actor = b.r.Header.Get(ActorHeader)
+
+	scheduled.CreatedAt = now
+
+	return &SchedulePriceRequest{
+		ID:             id,
+		ScheduledPrice: scheduled,
+	}, nil
+}
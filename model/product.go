@@ -3,25 +3,64 @@ package model
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gorilla/mux"
 )
 
-// Represent product structure
+// Represent product structure, products with variants take their qty, price and in_stock from them
+// Price is on Currency, Prices holds its price on other currencies as Money
+// Price and Currency are kept as two fields instead of Money so the price stays a number on the API and the stores,
+// where clients, the price indexes and the price sorts read it
+// Price is the one in effect, RegularPrice the one out of the PriceSchedule and PriceChangesAt when the next one starts or ends
+// Published products are seen by shoppers from PublishAt until UnpublishAt, when they are set
+// Name, Description and Slug are on Locale, Translations hold them on other locales and SlugHistory the slugs they had before
+// Images are sorted by their position
+// Weight is stored on kilograms and Dimensions on centimeters, they are read on the requested unit system
+// Bundles take their qty and in_stock from their components, and their price too when they are priced on them
+// Relations link the product to related ones, they are sorted by type and position
 type Product struct {
-	ID          string    `json:"id" bson:"_id"`
-	Name        string    `json:"name" bson:"name"`
-	Description *string   `json:"description,omitempty" bson:"description,omitempty"`
-	Sku         string    `json:"sku" bson:"sku"`
-	Qty         uint64    `json:"qty" bson:"qty"`
-	Images      []string  `json:"images,omitempty" bson:"images"`
-	CreatedAt   time.Time `json:"created_at" bson:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at" bson:"updated_at"`
-	Price       int64     `json:"price" bson:"price"`
-	InStock     bool      `json:"in_stock" bson:"in_stock"`
+	ID             string                 `json:"id" bson:"_id"`
+	Name           string                 `json:"name" bson:"name"`
+	Description    *string                `json:"description,omitempty" bson:"description,omitempty"`
+	Slug           string                 `json:"slug,omitempty" bson:"slug,omitempty"`
+	Locale         string                 `json:"locale,omitempty" bson:"locale,omitempty"`
+	Translations   []Translation          `json:"translations,omitempty" bson:"translations,omitempty"`
+	SlugHistory    []SlugChange           `json:"slug_history,omitempty" bson:"slug_history,omitempty"`
+	Sku            string                 `json:"sku" bson:"sku"`
+	Qty            uint64                 `json:"qty" bson:"qty"`
+	Images         []Image                `json:"images,omitempty" bson:"images"`
+	CategoryIDs    []string               `json:"category_ids,omitempty" bson:"category_ids,omitempty"`
+	BrandID        string                 `json:"brand_id,omitempty" bson:"brand_id,omitempty"`
+	Tags           []string               `json:"tags,omitempty" bson:"tags,omitempty"`
+	Attributes     map[string]interface{} `json:"attributes,omitempty" bson:"attributes,omitempty"`
+	Weight         *Weight                `json:"weight,omitempty" bson:"weight,omitempty"`
+	Dimensions     *Dimensions            `json:"dimensions,omitempty" bson:"dimensions,omitempty"`
+	ShippingClass  ShippingClass          `json:"shipping_class,omitempty" bson:"shipping_class,omitempty"`
+	Options        []Option               `json:"options,omitempty" bson:"options,omitempty"`
+	Variants       []Variant              `json:"variants,omitempty" bson:"variants,omitempty"`
+	Bundle         *Bundle                `json:"bundle,omitempty" bson:"bundle,omitempty"`
+	Relations      []Relation             `json:"relations,omitempty" bson:"relations,omitempty"`
+	PriceRange     *PriceRange            `json:"price_range,omitempty" bson:"price_range,omitempty"`
+	CreatedAt      time.Time              `json:"created_at" bson:"created_at"`
+	UpdatedAt      time.Time              `json:"updated_at" bson:"updated_at"`
+	Price          int64                  `json:"price" bson:"price"`
+	Currency       string                 `json:"currency,omitempty" bson:"currency,omitempty"`
+	Prices         []Money                `json:"prices,omitempty" bson:"prices,omitempty"`
+	RegularPrice   int64                  `json:"regular_price,omitempty" bson:"regular_price,omitempty"`
+	PriceSchedule  []ScheduledPrice       `json:"price_schedule,omitempty" bson:"price_schedule,omitempty"`
+	PriceHistory   []PriceChange          `json:"price_history,omitempty" bson:"price_history,omitempty"`
+	PriceChangesAt *time.Time             `json:"price_changes_at,omitempty" bson:"price_changes_at,omitempty"`
+	Status         Status                 `json:"status,omitempty" bson:"status,omitempty"`
+	PublishAt      *time.Time             `json:"publish_at,omitempty" bson:"publish_at,omitempty"`
+	UnpublishAt    *time.Time             `json:"unpublish_at,omitempty" bson:"unpublish_at,omitempty"`
+	InStock        bool                   `json:"in_stock" bson:"in_stock"`
+	Version        int64                  `json:"version" bson:"version"`
+	DeletedAt      *time.Time             `json:"deleted_at,omitempty" bson:"deleted_at,omitempty"`
 }
 
 // Represent search structure
@@ -32,29 +71,134 @@ type SearchResponse struct {
 
 // Represent metadata structure used for pagination
 type Metadata struct {
-	Total  int64 `json:"total"`
-	Limit  int   `json:"limit"`
-	Offset int   `json:"offset"`
+	Total      int64  `json:"total"`
+	Limit      int    `json:"limit"`
+	Offset     int    `json:"offset"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	PrevCursor string `json:"prev_cursor,omitempty"`
 }
 
-// Represent product update request
+// Represent a page of search results, Next and Prev are set when there are more products on that direction
+type Page struct {
+	Products []Product
+	Total    int64
+	Next     *Cursor
+	Prev     *Cursor
+}
+
+// Represent product update request, products with variants update the qty and price of the variant with VariantSKU
+// Qty replaces the product stock, Attributes the product attributes and Price its regular price when they are set
+// BrandID and Tags replace the product ones when they are set, an empty brand id removes the product brand
 type UpdateRequest struct {
-	Qty uint64 `json:"qty"`
+	Qty        *uint64                `json:"qty,omitempty"`
+	VariantSKU string                 `json:"variant_sku,omitempty"`
+	Attributes map[string]interface{} `json:"attributes,omitempty"`
+	Price      *int64                 `json:"price,omitempty"`
+	BrandID    *string                `json:"brand_id,omitempty"`
+	Tags       []string               `json:"tags,omitempty"`
 }
 
-// Represent product update
+// Represent product update, when Version is set the update only applies to that product version
+// Pricing replaces the product pricing when it is set, Actor is who requested the update
 type Update struct {
-	ID string
+	ID      string
+	Version int64
 	UpdateRequest
+	Pricing *Pricing
+	Actor   string
 }
 
-// Represent search request
+// Represent product read options
+type ReadOptions struct {
+	// Currency the product price is selected on, empty keeps the stored price
+	Currency string
+	// Admin callers read products on every status, others only the ones visible to shoppers
+	Admin bool
+	// Locales requested by preference, the product content is localized to the first one available
+	Locales []string
+	// Unit system the product weight and dimensions are read on
+	Units UnitSystem
+	// Inline the related products visible to the caller on the product relations
+	ExpandRelations bool
+}
+
+// Parse the read options from the request query and headers
+func ParseReadOptions(r *http.Request) (ReadOptions, error) {
+	currency, err := ParseCurrency(r.URL.Query().Get("currency"))
+	if err != nil {
+		return ReadOptions{}, err
+	}
+
+	locales, err := ParseLocales(r)
+	if err != nil {
+		return ReadOptions{}, err
+	}
+
+	units, err := ParseUnitSystem(r.URL.Query().Get("units"))
+	if err != nil {
+		return ReadOptions{}, err
+	}
+
+	expandRelations, err := parseExpand(r.URL.Query().Get("expand"))
+	if err != nil {
+		return ReadOptions{}, err
+	}
+
+	return ReadOptions{Currency: currency, Admin: IsAdmin(r), Locales: locales, Units: units, ExpandRelations: expandRelations}, nil
+}
+
+// Parse the comma separated fields to expand, relations is the only one, reports whether it is requested
+func parseExpand(value string) (bool, error) {
+	if value == "" {
+		return false, nil
+	}
+
+	for _, field := range strings.Split(value, ",") {
+		if strings.TrimSpace(field) != "relations" {
+			return false, errors.New("incorrect expand format, it must be relations")
+		}
+	}
+
+	return true, nil
+}
+
+// Represent search request, when Cursor is set Offset is ignored
 type SearchRequest struct {
-	Limit   int
-	Offset  int
-	Name    string
-	InStock bool
-	Sort    string
+	Limit          int
+	Offset         int
+	Name           string
+	InStock        bool
+	Sort           string
+	Cursor         *Cursor
+	IncludeDeleted bool
+	// Requested category, the service resolves it to CategoryIDs with all its descendants
+	// Repositories only filter by CategoryIDs
+	Category    string
+	CategoryIDs []string
+	Attributes  []AttributeFilter
+	// Currency products are priced and sorted on, products without a price on it are not matched
+	Currency CurrencySelection
+	// Admin callers search products on every status, the service restricts the others to the visible ones
+	Admin bool
+	// Statuses matched, empty matches every status
+	Statuses []Status
+	// When set only the products published and inside their publication window at that time are matched
+	VisibleAt *time.Time
+	// Locales requested by preference, the service resolves them to Locale
+	Locales []string
+	// Locale products are localized to, the name is searched with its language
+	Locale string
+	// Brand matched, empty matches every brand
+	BrandID string
+	// Tags matched as TagMatch says, empty matches every product
+	Tags     []string
+	TagMatch TagMatch
+	// Unit system products weight and dimensions are read on
+	Units UnitSystem
+	// Weight and dimension ranges matched, products without the measure are not matched
+	Measures []MeasureFilter
+	// Bundles holding the product matched, empty matches every product
+	ComponentID string
 }
 
 // Build product create request and validate all requested data
@@ -83,16 +227,87 @@ func (b *CreateBuilder) Build() (*Product, error) {
 		return nil, errors.New("product sku cannot be empty")
 	}
 
-	for _, image := range product.Images {
-		if image == "" {
-			return nil, errors.New("product image cannot be empty")
-		}
+	if err := validateContent(&product); err != nil {
+		return nil, err
+	}
+
+	if err := validateImages(product.Images); err != nil {
+		return nil, err
+	}
+
+	if err := validateTags(product.Tags); err != nil {
+		return nil, err
+	}
+
+	if err := validateShipping(&product); err != nil {
+		return nil, err
+	}
+
+	if err := validateVariants(product); err != nil {
+		return nil, err
+	}
+
+	if err := validateBundle(&product); err != nil {
+		return nil, err
+	}
+
+	// Attribute types are checked by the service against the definitions of the product categories
+	if err := validateAttributeValues(product.Attributes); err != nil {
+		return nil, err
+	}
+
+	if err := validateQty(product); err != nil {
+		return nil, err
+	}
+
+	// Products with variants take their price from them
+	product.ApplyVariants()
+
+	if err := validatePrices(product); err != nil {
+		return nil, err
+	}
+
+	// The price history and schedule are kept by the catalog, prices are scheduled once the product exists
+	product.RegularPrice, product.PriceSchedule, product.PriceHistory, product.PriceChangesAt = 0, nil, nil, nil
+
+	// Related products are linked once the product exists
+	product.Relations = nil
+
+	// The slug history is kept by the catalog as slugs change
+	product.SlugHistory = nil
+
+	// Products are drafts until published, they can not be created archived
+	switch product.Status {
+	case "":
+		product.Status = StatusDraft
+	case StatusDraft, StatusPublished:
+	default:
+		return nil, errors.New("product status must be draft or published")
+	}
+
+	if err := validatePublicationWindow(product.PublishAt, product.UnpublishAt); err != nil {
+		return nil, err
 	}
 
-	if product.Price <= 0 {
+	// Bundles priced on their components get their price once the service reads them
+	if product.Price <= 0 && !product.PricedOnComponents() {
 		return nil, errors.New("product price invalid value")
 	}
 
+	categories := make(map[string]bool)
+
+	for _, id := range product.CategoryIDs {
+		if id == "" {
+			return nil, errors.New("product category id cannot be empty")
+		}
+
+		if categories[id] {
+			return nil, errors.New("product category ids cannot be repeated")
+		}
+
+		categories[id] = true
+	}
+
 	return &product, nil
 }
 
@@ -113,15 +328,38 @@ func (b *UpdateBuilder) Build() (*Update, error) {
 		return nil, errors.New("product id must be provided")
 	}
 
+	version, err := ParseIfMatch(b.r.Header.Get("If-Match"))
+	if err != nil {
+		return nil, err
+	}
+
 	var request UpdateRequest
 
 	if err := json.NewDecoder(b.r.Body).Decode(&request); err != nil {
 		return nil, errors.New("incorrect product update body format")
 	}
 
+	if err := validateAttributeValues(request.Attributes); err != nil {
+		return nil, err
+	}
+
+	if request.Price != nil && *request.Price <= 0 {
+		return nil, errors.New("product price invalid value")
+	}
+
+	if request.Qty != nil && *request.Qty > MaxQty {
+		return nil, fmt.Errorf("product qty must not exceed %d", uint64(MaxQty))
+	}
+
+	if err := validateTags(request.Tags); err != nil {
+		return nil, err
+	}
+
 	return &Update{
 		ID:            id,
+		Version:       version,
 		UpdateRequest: request,
+		Actor:         b.r.Header.Get(ActorHeader),
 	}, nil
 }
 
@@ -137,7 +375,9 @@ func NewSearchBuilder(r *http.Request) *SearchBuilder {
 }
 
 func (b *SearchBuilder) Build() (*SearchRequest, error) {
-	limit, err := strconv.Atoi(b.r.URL.Query().Get("limit"))
+	query := b.r.URL.Query()
+
+	limit, err := strconv.Atoi(query.Get("limit"))
 	if err != nil {
 		return nil, errors.New("incorrect limit format")
 	}
@@ -146,22 +386,161 @@ func (b *SearchBuilder) Build() (*SearchRequest, error) {
 		return nil, errors.New("incorrect limit format")
 	}
 
-	offset, err := strconv.Atoi(b.r.URL.Query().Get("offset"))
+	var cursor *Cursor
+
+	if token := query.Get("cursor"); token != "" {
+		cursor, err = ParseCursor(token)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Offset is ignored when paginating with a cursor, so it is optional
+	offset := 0
+
+	if cursor == nil || query.Get("offset") != "" {
+		offset, err = strconv.Atoi(query.Get("offset"))
+		if err != nil {
+			return nil, errors.New("incorrect offset format")
+		}
+
+		if offset < 0 {
+			return nil, errors.New("incorrect offset format")
+		}
+	}
+
+	request, err := parseSearchFilters(b.r)
+	if err != nil {
+		return nil, err
+	}
+
+	request.Limit, request.Offset, request.Cursor, request.Sort = limit, offset, cursor, query.Get("sort")
+
+	// Cursors keep the sort and currency of the page they were created from
+	if cursor != nil {
+		request.Sort, request.Currency = cursor.Sort, CurrencySelection{Code: cursor.Currency}
+	}
+
+	return request, nil
+}
+
+// Build tag counts request, it takes the search filters without pagination
+type TagCountBuilder struct {
+	r *http.Request
+}
+
+func NewTagCountBuilder(r *http.Request) *TagCountBuilder {
+	return &TagCountBuilder{
+		r: r,
+	}
+}
+
+func (b *TagCountBuilder) Build() (*SearchRequest, error) {
+	return parseSearchFilters(b.r)
+}
+
+// Parse the search filters from the request query and headers
+func parseSearchFilters(r *http.Request) (*SearchRequest, error) {
+	query := r.URL.Query()
+
+	inStock, _ := strconv.ParseBool(query.Get("in_stock"))
+
+	includeDeleted, _ := strconv.ParseBool(query.Get("include_deleted"))
+
+	attributes, err := parseAttributeFilters(query)
+	if err != nil {
+		return nil, err
+	}
+
+	currency, err := ParseCurrency(query.Get("currency"))
 	if err != nil {
-		return nil, errors.New("incorrect offset format")
+		return nil, err
 	}
 
-	if offset < 0 {
-		return nil, errors.New("incorrect offset format")
+	var statuses []Status
+
+	for _, value := range query["status"] {
+		status, ok := ParseStatus(value)
+		if !ok {
+			return nil, errors.New("incorrect status format")
+		}
+
+		statuses = append(statuses, status)
 	}
 
-	inStock, _ := strconv.ParseBool(b.r.URL.Query().Get("in_stock"))
+	locales, err := ParseLocales(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var tags []string
+
+	for _, value := range query["tag"] {
+		tag, err := ParseTag(value)
+		if err != nil {
+			return nil, err
+		}
+
+		tags = append(tags, tag)
+	}
+
+	tagMatch := TagMatch(strings.ToLower(query.Get("tag_match")))
+
+	switch tagMatch {
+	case "":
+		tagMatch = TagMatchAny
+	case TagMatchAny, TagMatchAll:
+	default:
+		return nil, errors.New("incorrect tag match format")
+	}
+
+	units, err := ParseUnitSystem(query.Get("units"))
+	if err != nil {
+		return nil, err
+	}
+
+	measures, err := parseMeasureFilters(query, units)
+	if err != nil {
+		return nil, err
+	}
 
 	return &SearchRequest{
-		Limit:   limit,
-		Offset:  offset,
-		Name:    b.r.URL.Query().Get("name"),
-		InStock: inStock,
-		Sort:    b.r.URL.Query().Get("sort"),
+		Name:           query.Get("name"),
+		InStock:        inStock,
+		IncludeDeleted: includeDeleted,
+		Category:       query.Get("category"),
+		Attributes:     attributes,
+		Currency:       CurrencySelection{Code: currency},
+		Admin:          IsAdmin(r),
+		Statuses:       statuses,
+		Locales:        locales,
+		BrandID:        query.Get("brand"),
+		Tags:           tags,
+		TagMatch:       tagMatch,
+		Units:          units,
+		Measures:       measures,
 	}, nil
 }
+
+// Check the product qty, or the sum of its variants qty, does not exceed MaxQty
+func validateQty(product Product) error {
+	total := product.Qty
+
+	if len(product.Variants) > 0 {
+		total = 0
+
+		for _, variant := range product.Variants {
+			if variant.Qty > MaxQty-total {
+				return fmt.Errorf("product qty must not exceed %d", uint64(MaxQty))
+			}
+
+			total += variant.Qty
+		}
+	}
+
+	if total > MaxQty {
+		return fmt.Errorf("product qty must not exceed %d", uint64(MaxQty))
+	}
+
+	return nil
+}
@@ -0,0 +1,314 @@
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// Represent how a product relates to another one
+type RelationType string
+
+const (
+	RelationAccessory      RelationType = "accessory"
+	RelationUpsell         RelationType = "upsell"
+	RelationCrossSell      RelationType = "cross-sell"
+	RelationReplacementFor RelationType = "replacement-for"
+)
+
+// Relation types on the order product relations are sorted by
+var relationTypes = []RelationType{RelationAccessory, RelationUpsell, RelationCrossSell, RelationReplacementFor}
+
+// Represent a link from a product to a related one, relations are sorted by Position within their type
+// Bidirectional relations are held by both products with the same type, Product is only set when relations are expanded
+type Relation struct {
+	ProductID     string       `json:"product_id" bson:"product_id"`
+	Type          RelationType `json:"type" bson:"type"`
+	Position      int          `json:"position" bson:"position"`
+	Bidirectional bool         `json:"bidirectional,omitempty" bson:"bidirectional,omitempty"`
+	Product       *Product     `json:"product,omitempty" bson:"-"`
+}
+
+// Parse a relation type, an empty one is kept empty
+func ParseRelationType(value string) (RelationType, error) {
+	relationType := RelationType(strings.ToLower(value))
+
+	if relationType == "" || relationRank(relationType) >= 0 {
+		return relationType, nil
+	}
+
+	return "", errors.New("incorrect relation type format, it must be accessory, upsell, cross-sell or replacement-for")
+}
+
+func relationRank(relationType RelationType) int {
+	for rank, current := range relationTypes {
+		if current == relationType {
+			return rank
+		}
+	}
+
+	return -1
+}
+
+// Sort relations by type and position, numbering the positions of each type from 0
+func sortRelations(relations []Relation) {
+	sort.SliceStable(relations, func(i, j int) bool {
+		if relations[i].Type != relations[j].Type {
+			return relationRank(relations[i].Type) < relationRank(relations[j].Type)
+		}
+
+		return relations[i].Position < relations[j].Position
+	})
+
+	for i := range relations {
+		relations[i].Position = 0
+
+		if i > 0 && relations[i-1].Type == relations[i].Type {
+			relations[i].Position = relations[i-1].Position + 1
+		}
+	}
+}
+
+// Get the relation of the given type to the product with the given id, nil when there is none
+func (p *Product) Relation(relationType RelationType, productID string) *Relation {
+	for i := range p.Relations {
+		if p.Relations[i].Type == relationType && p.Relations[i].ProductID == productID {
+			return &p.Relations[i]
+		}
+	}
+
+	return nil
+}
+
+// Get the product relations of the given type, or every relation when it is empty
+func (p *Product) RelationsOf(relationType RelationType) []Relation {
+	relations := []Relation{}
+
+	for _, relation := range p.Relations {
+		if relationType == "" || relation.Type == relationType {
+			relations = append(relations, relation)
+		}
+	}
+
+	return relations
+}
+
+// Get the ids of the related products, each of them once
+func (p *Product) RelatedIDs() []string {
+	var ids []string
+
+	seen := make(map[string]bool, len(p.Relations))
+
+	for _, relation := range p.Relations {
+		if !seen[relation.ProductID] {
+			seen[relation.ProductID] = true
+
+			ids = append(ids, relation.ProductID)
+		}
+	}
+
+	return ids
+}
+
+// Add a relation after the product relations of its type, reports false when the product already has it
+func (p *Product) AddRelation(relation Relation) bool {
+	if p.Relation(relation.Type, relation.ProductID) != nil {
+		return false
+	}
+
+	relation.Position, relation.Product = len(p.Relations), nil
+
+	p.Relations = append(append([]Relation(nil), p.Relations...), relation)
+
+	sortRelations(p.Relations)
+
+	return true
+}
+
+// Remove the relation of the given type to the product with the given id, reports false when there is none
+func (p *Product) RemoveRelation(relationType RelationType, productID string) (Relation, bool) {
+	for i, relation := range p.Relations {
+		if relation.Type == relationType && relation.ProductID == productID {
+			p.Relations = append(append([]Relation(nil), p.Relations[:i]...), p.Relations[i+1:]...)
+
+			sortRelations(p.Relations)
+
+			return relation, true
+		}
+	}
+
+	return Relation{}, false
+}
+
+// Remove every relation to the product with the given id, reports whether there was any
+func (p *Product) RemoveRelationsTo(productID string) bool {
+	var relations []Relation
+
+	for _, relation := range p.Relations {
+		if relation.ProductID != productID {
+			relations = append(relations, relation)
+		}
+	}
+
+	if len(relations) == len(p.Relations) {
+		return false
+	}
+
+	sortRelations(relations)
+
+	p.Relations = relations
+
+	return true
+}
+
+// Sort the product relations of the given type on the given related product ids order
+// Reports false when the ids are not the ones related on the type
+func (p *Product) ReorderRelations(relationType RelationType, productIDs []string) bool {
+	if len(productIDs) != len(p.RelationsOf(relationType)) {
+		return false
+	}
+
+	positions := make(map[string]int, len(productIDs))
+
+	for position, id := range productIDs {
+		if _, ok := positions[id]; ok || p.Relation(relationType, id) == nil {
+			return false
+		}
+
+		positions[id] = position
+	}
+
+	relations := append([]Relation(nil), p.Relations...)
+
+	for i := range relations {
+		if relations[i].Type == relationType {
+			relations[i].Position = positions[relations[i].ProductID]
+		}
+	}
+
+	sortRelations(relations)
+
+	p.Relations = relations
+
+	return true
+}
+
+// Represent a request to link or unlink a related product, only the type and related product id are set to unlink it
+type RelationRequest struct {
+	ID      string
+	Version int64
+	Relation
+}
+
+// Represent a request to reorder the product relations of a type, ProductIDs holds every product related on it on its new order
+type RelationOrderRequest struct {
+	ID         string       `json:"-"`
+	Version    int64        `json:"-"`
+	Type       RelationType `json:"-"`
+	ProductIDs []string     `json:"product_ids"`
+}
+
+// Build product relation request and validate all requested data, the relation to unlink is taken from the route
+type RelationBuilder struct {
+	r *http.Request
+}
+
+func NewRelationBuilder(r *http.Request) *RelationBuilder {
+	return &RelationBuilder{
+		r: r,
+	}
+}
+
+// Build the requested relation, its body is only read when it is linked
+func (b *RelationBuilder) Build() (*RelationRequest, error) {
+	vars := mux.Vars(b.r)
+
+	if vars["id"] == "" {
+		return nil, errors.New("product id must be provided")
+	}
+
+	version, err := ParseIfMatch(b.r.Header.Get("If-Match"))
+	if err != nil {
+		return nil, err
+	}
+
+	request := RelationRequest{ID: vars["id"], Version: version}
+
+	// Unlinking a product only takes the relation type and related product id
+	if b.r.Method != http.MethodPost {
+		request.Relation = Relation{Type: RelationType(vars["type"]), ProductID: vars["product_id"]}
+	} else if err := json.NewDecoder(b.r.Body).Decode(&request.Relation); err != nil {
+		return nil, errors.New("incorrect product relation body format")
+	}
+
+	if request.Relation.Type, err = ParseRelationType(string(request.Relation.Type)); err != nil {
+		return nil, err
+	}
+
+	if request.Relation.Type == "" {
+		return nil, errors.New("product relation type must be provided")
+	}
+
+	if request.Relation.ProductID == "" {
+		return nil, errors.New("product relation product id must be provided")
+	}
+
+	if request.Relation.ProductID == request.ID {
+		return nil, errors.New("product cannot be related to itself")
+	}
+
+	request.Relation.Position, request.Relation.Product = 0, nil
+
+	return &request, nil
+}
+
+// Build product relation order request and validate all requested data
+type RelationOrderBuilder struct {
+	r *http.Request
+}
+
+func NewRelationOrderBuilder(r *http.Request) *RelationOrderBuilder {
+	return &RelationOrderBuilder{
+		r: r,
+	}
+}
+
+func (b *RelationOrderBuilder) Build() (*RelationOrderRequest, error) {
+	vars := mux.Vars(b.r)
+
+	if vars["id"] == "" {
+		return nil, errors.New("product id must be provided")
+	}
+
+	relationType, err := ParseRelationType(vars["type"])
+	if err != nil {
+		return nil, err
+	}
+
+	if relationType == "" {
+		return nil, errors.New("product relation type must be provided")
+	}
+
+	version, err := ParseIfMatch(b.r.Header.Get("If-Match"))
+	if err != nil {
+		return nil, err
+	}
+
+	var request RelationOrderRequest
+
+	if err := json.NewDecoder(b.r.Body).Decode(&request); err != nil {
+		return nil, errors.New("incorrect product relation order body format")
+	}
+
+	if len(request.ProductIDs) == 0 {
+		return nil, errors.New("product relation product ids cannot be empty")
+	}
+
+	request.ID, request.Version, request.Type = vars["id"], version, relationType
+
+	return &request, nil
+}
@@ -0,0 +1,296 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Represent a weight unit, weights are stored on kilograms
+type WeightUnit string
+
+const (
+	Gram     WeightUnit = "g"
+	Kilogram WeightUnit = "kg"
+	Ounce    WeightUnit = "oz"
+	Pound    WeightUnit = "lb"
+)
+
+// Kilograms on each weight unit
+var weightUnits = map[WeightUnit]float64{
+	Gram:     0.001,
+	Kilogram: 1,
+	Ounce:    0.028349523125,
+	Pound:    0.45359237,
+}
+
+// Represent a length unit, dimensions are stored on centimeters
+type LengthUnit string
+
+const (
+	Millimeter LengthUnit = "mm"
+	Centimeter LengthUnit = "cm"
+	Meter      LengthUnit = "m"
+	Inch       LengthUnit = "in"
+	Foot       LengthUnit = "ft"
+)
+
+// Centimeters on each length unit
+var lengthUnits = map[LengthUnit]float64{
+	Millimeter: 0.1,
+	Centimeter: 1,
+	Meter:      100,
+	Inch:       2.54,
+	Foot:       30.48,
+}
+
+// Represent the unit system weights and dimensions are read on
+// Metric reads them on kilograms and centimeters, imperial on pounds and inches
+type UnitSystem string
+
+const (
+	UnitsMetric   UnitSystem = "metric"
+	UnitsImperial UnitSystem = "imperial"
+)
+
+// Units weights and dimensions are read on for each unit system
+var unitSystems = map[UnitSystem]struct {
+	weight WeightUnit
+	length LengthUnit
+}{
+	UnitsMetric:   {weight: Kilogram, length: Centimeter},
+	UnitsImperial: {weight: Pound, length: Inch},
+}
+
+// Represent how a product is shipped
+type ShippingClass string
+
+const (
+	ShippingStandard  ShippingClass = "standard"
+	ShippingOversized ShippingClass = "oversized"
+	ShippingFragile   ShippingClass = "fragile"
+	ShippingHazardous ShippingClass = "hazardous"
+)
+
+// Represent the product package weight
+type Weight struct {
+	Value float64    `json:"value" bson:"value"`
+	Unit  WeightUnit `json:"unit" bson:"unit"`
+}
+
+// Represent the product package length, width and height
+type Dimensions struct {
+	Length float64    `json:"length" bson:"length"`
+	Width  float64    `json:"width" bson:"width"`
+	Height float64    `json:"height" bson:"height"`
+	Unit   LengthUnit `json:"unit" bson:"unit"`
+}
+
+// Represent a measure search filters by, weights are on kilograms and dimensions on centimeters
+type Measure string
+
+const (
+	MeasureWeight Measure = "weight"
+	MeasureLength Measure = "length"
+	MeasureWidth  Measure = "width"
+	MeasureHeight Measure = "height"
+)
+
+var measures = []Measure{MeasureWeight, MeasureLength, MeasureWidth, MeasureHeight}
+
+// Represent a weight or dimension range filter, Operator is AttributeGreaterOrEqual or AttributeLessOrEqual
+// Value is on kilograms for weights and on centimeters for dimensions, whatever unit system was requested
+type MeasureFilter struct {
+	Measure  Measure
+	Operator string
+	Value    float64
+}
+
+// Reports whether value passes the filter
+func (f MeasureFilter) Match(value float64) bool {
+	if f.Operator == AttributeGreaterOrEqual {
+		return value >= f.Value
+	}
+
+	return value <= f.Value
+}
+
+// Parse a unit system, an empty one is metric
+func ParseUnitSystem(value string) (UnitSystem, error) {
+	if value == "" {
+		return UnitsMetric, nil
+	}
+
+	system := UnitSystem(strings.ToLower(value))
+
+	if _, ok := unitSystems[system]; !ok {
+		return "", errors.New("incorrect units format, it must be metric or imperial")
+	}
+
+	return system, nil
+}
+
+// Get the stored product measure, reports false when the product has no weight or dimensions
+func (p *Product) Measure(measure Measure) (float64, bool) {
+	switch {
+	case measure == MeasureWeight && p.Weight != nil:
+		return p.Weight.Value, true
+	case measure == MeasureLength && p.Dimensions != nil:
+		return p.Dimensions.Length, true
+	case measure == MeasureWidth && p.Dimensions != nil:
+		return p.Dimensions.Width, true
+	case measure == MeasureHeight && p.Dimensions != nil:
+		return p.Dimensions.Height, true
+	default:
+		return 0, false
+	}
+}
+
+// Convert the stored weight and dimensions to the units of the system, they are stored on the metric ones
+func (p *Product) ConvertUnits(system UnitSystem) {
+	units, ok := unitSystems[system]
+	if !ok {
+		return
+	}
+
+	if p.Weight != nil {
+		weight := p.Weight.convert(units.weight, readPrecision)
+
+		p.Weight = &weight
+	}
+
+	if p.Dimensions != nil {
+		dimensions := p.Dimensions.convert(units.length, readPrecision)
+
+		p.Dimensions = &dimensions
+	}
+}
+
+func (w Weight) convert(unit WeightUnit, precision float64) Weight {
+	factor := weightUnits[w.Unit] / weightUnits[unit]
+
+	return Weight{Value: roundMeasure(w.Value*factor, precision), Unit: unit}
+}
+
+func (d Dimensions) convert(unit LengthUnit, precision float64) Dimensions {
+	factor := lengthUnits[d.Unit] / lengthUnits[unit]
+
+	return Dimensions{
+		Length: roundMeasure(d.Length*factor, precision),
+		Width:  roundMeasure(d.Width*factor, precision),
+		Height: roundMeasure(d.Height*factor, precision),
+		Unit:   unit,
+	}
+}
+
+// Stored measures are kept to a billionth of their unit and read ones to a millionth
+// so converting them back and forth does not carry floating point noise
+const (
+	storedPrecision = 1e9
+	readPrecision   = 1e6
+)
+
+func roundMeasure(value float64, precision float64) float64 {
+	return math.Round(value*precision) / precision
+}
+
+// Validate the product weight, dimensions and shipping class, and normalize weight and dimensions to kilograms and centimeters
+// Products without shipping class are standard
+func validateShipping(product *Product) error {
+	if weight := product.Weight; weight != nil {
+		if _, ok := weightUnits[weight.Unit]; !ok {
+			return errors.New("product weight unit must be g, kg, oz or lb")
+		}
+
+		if weight.Value < 0 {
+			return errors.New("product weight cannot be negative")
+		}
+
+		if weight.Value == 0 {
+			return errors.New("product weight must be greater than 0")
+		}
+
+		normalized := weight.convert(Kilogram, storedPrecision)
+
+		product.Weight = &normalized
+	}
+
+	if dimensions := product.Dimensions; dimensions != nil {
+		if _, ok := lengthUnits[dimensions.Unit]; !ok {
+			return errors.New("product dimensions unit must be mm, cm, m, in or ft")
+		}
+
+		if dimensions.Length < 0 || dimensions.Width < 0 || dimensions.Height < 0 {
+			return errors.New("product dimensions cannot be negative")
+		}
+
+		if dimensions.Length == 0 || dimensions.Width == 0 || dimensions.Height == 0 {
+			return errors.New("product dimensions length, width and height must all be greater than 0")
+		}
+
+		normalized := dimensions.convert(Centimeter, storedPrecision)
+
+		product.Dimensions = &normalized
+	}
+
+	switch product.ShippingClass {
+	case "":
+		product.ShippingClass = ShippingStandard
+	case ShippingStandard, ShippingOversized, ShippingFragile, ShippingHazardous:
+	default:
+		return errors.New("product shipping class must be standard, oversized, fragile or hazardous")
+	}
+
+	return nil
+}
+
+// Parse the weight and dimension range filters, weight_gte={number} or height_lte={number}
+// Their values are on the units of the requested system and are converted to kilograms and centimeters
+func parseMeasureFilters(query url.Values, system UnitSystem) ([]MeasureFilter, error) {
+	units := unitSystems[system]
+
+	var filters []MeasureFilter
+
+	for _, measure := range measures {
+		factor := lengthUnits[units.length]
+
+		if measure == MeasureWeight {
+			factor = weightUnits[units.weight]
+		}
+
+		bounds := make(map[string]float64)
+
+		for _, operator := range []string{AttributeGreaterOrEqual, AttributeLessOrEqual} {
+			param := string(measure) + "_" + operator
+
+			value := query.Get(param)
+			if value == "" {
+				continue
+			}
+
+			number, err := strconv.ParseFloat(value, 64)
+			if err != nil || math.IsNaN(number) || math.IsInf(number, 0) {
+				return nil, fmt.Errorf("incorrect %s filter format, it must be a number", param)
+			}
+
+			if number < 0 {
+				return nil, fmt.Errorf("incorrect %s filter format, it cannot be negative", param)
+			}
+
+			bounds[operator] = number
+
+			filters = append(filters, MeasureFilter{Measure: measure, Operator: operator, Value: roundMeasure(number*factor, storedPrecision)})
+		}
+
+		min, hasMin := bounds[AttributeGreaterOrEqual]
+
+		if max, hasMax := bounds[AttributeLessOrEqual]; hasMin && hasMax && min > max {
+			return nil, fmt.Errorf("incorrect %s filter range, %s_gte cannot be greater than %s_lte", measure, measure, measure)
+		}
+	}
+
+	return filters, nil
+}
@@ -0,0 +1,247 @@
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/gorilla/mux"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Letters spelled out on slugs of every locale, they have no accent to drop
+var slugLetters = strings.NewReplacer("ß", "ss", "æ", "ae", "œ", "oe", "ø", "o", "đ", "d", "ł", "l", "þ", "th", "ı", "i")
+
+// Letters spelled out on slugs of a language before their accents are dropped
+var slugTransliterations = map[string]*strings.Replacer{
+	"de": strings.NewReplacer("ä", "ae", "ö", "oe", "ü", "ue"),
+	"da": strings.NewReplacer("å", "aa"),
+	"nb": strings.NewReplacer("å", "aa"),
+}
+
+// Represent a slug the product content had before, Locale is the locale of that content
+// Looking a previous slug up redirects to the current slug of its locale
+type SlugChange struct {
+	Slug      string    `json:"slug" bson:"slug"`
+	Locale    string    `json:"locale,omitempty" bson:"locale,omitempty"`
+	ChangedAt time.Time `json:"changed_at" bson:"changed_at"`
+}
+
+// Generate a slug from a product name on its locale, letters are lowercased with the casing rules of the locale language
+// Letters the language spells out are transliterated and the accents of the rest are dropped
+// Anything other than letters and numbers joins words with a hyphen, names without any of them have no slug
+func Slugify(name string, locale string) string {
+	language := LocaleLanguage(locale)
+
+	name = norm.NFC.String(name)
+
+	if language == "tr" || language == "az" {
+		name = strings.ToLowerSpecial(unicode.TurkishCase, name)
+	} else {
+		name = strings.ToLower(name)
+	}
+
+	if transliteration, ok := slugTransliterations[language]; ok {
+		name = transliteration.Replace(name)
+	}
+
+	name = slugLetters.Replace(name)
+
+	var slug strings.Builder
+
+	hyphen := false
+
+	for _, r := range norm.NFD.String(name) {
+		switch {
+		case unicode.Is(unicode.Mn, r):
+		case unicode.IsLower(r) || unicode.Is(unicode.Lo, r) || unicode.IsNumber(r):
+			if hyphen && slug.Len() > 0 {
+				slug.WriteByte('-')
+			}
+
+			hyphen = false
+
+			slug.WriteRune(r)
+		default:
+			hyphen = true
+		}
+	}
+
+	return norm.NFC.String(slug.String())
+}
+
+// Get every slug the product holds, its own, the ones of its translations and the previous ones, each of them once
+func (p *Product) Slugs() []string {
+	var slugs []string
+
+	seen := make(map[string]bool)
+
+	add := func(slug string) {
+		if slug != "" && !seen[slug] {
+			seen[slug] = true
+
+			slugs = append(slugs, slug)
+		}
+	}
+
+	add(p.Slug)
+
+	for _, translation := range p.Translations {
+		add(translation.Slug)
+	}
+
+	for _, change := range p.SlugHistory {
+		add(change.Slug)
+	}
+
+	return slugs
+}
+
+// Find the locale of the product content holding slug, reports whether it is its current slug or a previous one
+// The product slug is preferred over the translation ones, and ok is false when the product does not hold it
+func (p *Product) FindSlug(slug string) (locale string, current bool, ok bool) {
+	if slug == "" {
+		return "", false, false
+	}
+
+	if p.Slug == slug {
+		return p.Locale, true, true
+	}
+
+	for _, translation := range p.Translations {
+		if translation.Slug == slug {
+			return translation.Locale, true, true
+		}
+	}
+
+	for _, change := range p.SlugHistory {
+		if change.Slug == slug {
+			return change.Locale, false, true
+		}
+	}
+
+	return "", false, false
+}
+
+// Get the current slug of the product content on locale, the product slug when it has no translation with a slug on it
+func (p *Product) SlugOn(locale string) string {
+	if translation := p.TranslationOn(locale); translation != nil && translation.Slug != "" {
+		return translation.Slug
+	}
+
+	return p.Slug
+}
+
+// Change the slug of the product content on locale, the product own content when locale is empty or the product locale
+// The replaced slug is kept on the slug history, and a previous slug taken again leaves it
+// Reports false when the product has no translation on locale
+func (p *Product) ChangeSlug(slug string, locale string, at time.Time) bool {
+	if locale == "" || locale == p.Locale {
+		p.RetireSlug(p.Slug, p.Locale, at)
+
+		p.Slug = slug
+	} else {
+		translation := p.TranslationOn(locale)
+		if translation == nil {
+			return false
+		}
+
+		p.RetireSlug(translation.Slug, locale, at)
+
+		p.Translations = append([]Translation(nil), p.Translations...)
+
+		p.TranslationOn(locale).Slug = slug
+	}
+
+	p.SlugHistory = removeSlugChange(p.SlugHistory, slug)
+
+	return true
+}
+
+// Keep a slug the product content on locale no longer has on the slug history, empty slugs are not kept
+func (p *Product) RetireSlug(slug string, locale string, at time.Time) {
+	if slug == "" {
+		return
+	}
+
+	p.SlugHistory = append(removeSlugChange(p.SlugHistory, slug), SlugChange{Slug: slug, Locale: locale, ChangedAt: at})
+}
+
+func removeSlugChange(history []SlugChange, slug string) []SlugChange {
+	var kept []SlugChange
+
+	for _, change := range history {
+		if change.Slug != slug {
+			kept = append(kept, change)
+		}
+	}
+
+	return kept
+}
+
+// Parse a slug, it must be lowercase words of letters and numbers joined by hyphens
+func ParseSlug(value string) (string, error) {
+	if !slugFormat.MatchString(value) {
+		return "", errors.New("incorrect slug format")
+	}
+
+	return value, nil
+}
+
+// Represent a request to change the slug of the product content on Locale, the product own content when it is empty
+// An empty slug is generated again from the content name
+type SlugRequest struct {
+	ID      string `json:"-"`
+	Version int64  `json:"-"`
+	Slug    string `json:"slug"`
+	Locale  string `json:"locale,omitempty"`
+}
+
+// Build product slug request and validate all requested data
+type SlugBuilder struct {
+	r *http.Request
+}
+
+func NewSlugBuilder(r *http.Request) *SlugBuilder {
+	return &SlugBuilder{
+		r: r,
+	}
+}
+
+func (b *SlugBuilder) Build() (*SlugRequest, error) {
+	vars := mux.Vars(b.r)
+
+	if vars["id"] == "" {
+		return nil, errors.New("product id must be provided")
+	}
+
+	version, err := ParseIfMatch(b.r.Header.Get("If-Match"))
+	if err != nil {
+		return nil, err
+	}
+
+	var request SlugRequest
+
+	if err := json.NewDecoder(b.r.Body).Decode(&request); err != nil {
+		return nil, errors.New("incorrect product slug body format")
+	}
+
+	if request.Slug != "" {
+		if request.Slug, err = ParseSlug(request.Slug); err != nil {
+			return nil, err
+		}
+	}
+
+	if request.Locale != "" {
+		if request.Locale, err = ParseLocale(request.Locale); err != nil {
+			return nil, err
+		}
+	}
+
+	request.ID, request.Version = vars["id"], version
+
+	return &request, nil
+}
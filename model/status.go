@@ -0,0 +1,176 @@
+package model
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// Context key marking the requests authenticated as admin, admin callers see products on every status
+type adminKey struct{}
+
+// Represent product publication status, products stored without one are published
+type Status string
+
+const (
+	StatusDraft     Status = "draft"
+	StatusPublished Status = "published"
+	StatusArchived  Status = "archived"
+)
+
+// Represent a change of the product publication status
+type Transition string
+
+const (
+	TransitionPublish   Transition = "publish"
+	TransitionUnpublish Transition = "unpublish"
+	TransitionArchive   Transition = "archive"
+	TransitionUnarchive Transition = "unarchive"
+)
+
+// Statuses each transition can start from and the status it ends on
+// Published products can be published again to change their publication window
+var transitions = map[Transition]struct {
+	from []Status
+	to   Status
+}{
+	TransitionPublish:   {from: []Status{StatusDraft, StatusPublished}, to: StatusPublished},
+	TransitionUnpublish: {from: []Status{StatusPublished}, to: StatusDraft},
+	TransitionArchive:   {from: []Status{StatusDraft, StatusPublished}, to: StatusArchived},
+	TransitionUnarchive: {from: []Status{StatusArchived}, to: StatusDraft},
+}
+
+// Parse a product status, reports false when it is not a known one
+func ParseStatus(value string) (Status, bool) {
+	switch status := Status(strings.ToLower(value)); status {
+	case StatusDraft, StatusPublished, StatusArchived:
+		return status, true
+	default:
+		return "", false
+	}
+}
+
+// Get the product publication status, products stored before statuses existed are published
+func (p *Product) PublicationStatus() Status {
+	if p.Status == "" {
+		return StatusPublished
+	}
+
+	return p.Status
+}
+
+// Reports whether shoppers see the product at the given time, it must be published and inside its publication window
+func (p *Product) IsVisible(at time.Time) bool {
+	if p.PublicationStatus() != StatusPublished {
+		return false
+	}
+
+	if p.PublishAt != nil && p.PublishAt.After(at) {
+		return false
+	}
+
+	return p.UnpublishAt == nil || p.UnpublishAt.After(at)
+}
+
+// Apply a status transition, reports false when the product status does not allow it
+// Publishing sets the publication window, leaving published clears it
+func (p *Product) Transition(request StatusRequest) bool {
+	transition, ok := transitions[request.Transition]
+	if !ok {
+		return false
+	}
+
+	allowed := false
+
+	for _, from := range transition.from {
+		allowed = allowed || p.PublicationStatus() == from
+	}
+
+	if !allowed {
+		return false
+	}
+
+	p.Status = transition.to
+
+	p.PublishAt, p.UnpublishAt = request.PublishAt, request.UnpublishAt
+
+	return true
+}
+
+// Represent a product status transition request, the publication window only applies to publish
+// When Version is set the transition only applies to that product version
+type StatusRequest struct {
+	ID          string     `json:"-"`
+	Version     int64      `json:"-"`
+	Transition  Transition `json:"-"`
+	PublishAt   *time.Time `json:"publish_at,omitempty"`
+	UnpublishAt *time.Time `json:"unpublish_at,omitempty"`
+}
+
+// Build product status transition request and validate all requested data
+type StatusBuilder struct {
+	r          *http.Request
+	transition Transition
+}
+
+func NewStatusBuilder(r *http.Request, transition Transition) *StatusBuilder {
+	return &StatusBuilder{
+		r:          r,
+		transition: transition,
+	}
+}
+
+func (b *StatusBuilder) Build() (*StatusRequest, error) {
+	id := mux.Vars(b.r)["id"]
+	if id == "" {
+		return nil, errors.New("product id must be provided")
+	}
+
+	version, err := ParseIfMatch(b.r.Header.Get("If-Match"))
+	if err != nil {
+		return nil, err
+	}
+
+	var request StatusRequest
+
+	// Only publish takes a body, and it is optional
+	if b.transition == TransitionPublish && b.r.Body != nil {
+		if err := json.NewDecoder(b.r.Body).Decode(&request); err != nil && !errors.Is(err, io.EOF) {
+			return nil, errors.New("incorrect product status body format")
+		}
+	}
+
+	if err := validatePublicationWindow(request.PublishAt, request.UnpublishAt); err != nil {
+		return nil, err
+	}
+
+	request.ID, request.Version, request.Transition = id, version, b.transition
+
+	return &request, nil
+}
+
+func validatePublicationWindow(publishAt *time.Time, unpublishAt *time.Time) error {
+	if publishAt != nil && unpublishAt != nil && !unpublishAt.After(*publishAt) {
+		return errors.New("product unpublish_at must be after publish_at")
+	}
+
+	return nil
+}
+
+// Mark the context of a request authenticated as admin, it is set by the server and never taken from the request
+func WithAdmin(ctx context.Context) context.Context {
+	return context.WithValue(ctx, adminKey{}, true)
+}
+
+// Reports whether the request was authenticated as admin
+func IsAdmin(r *http.Request) bool {
+	admin, _ := r.Context().Value(adminKey{}).(bool)
+
+	return admin
+}
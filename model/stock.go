@@ -0,0 +1,373 @@
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// Represent why the stock of a product changed
+type MovementType string
+
+const (
+	MovementReceipt    MovementType = "receipt"
+	MovementSale       MovementType = "sale"
+	MovementReturn     MovementType = "return"
+	MovementAdjustment MovementType = "adjustment"
+	MovementDamage     MovementType = "damage"
+)
+
+// Sign the delta of each movement type must have, adjustments take either sign
+var movementSigns = map[MovementType]int64{
+	MovementReceipt:    1,
+	MovementSale:       -1,
+	MovementReturn:     1,
+	MovementAdjustment: 0,
+	MovementDamage:     -1,
+}
+
+// Reasons of the adjustments recorded by the catalog itself
+// Most stock a product, the sum of its variants included, can hold, qty is stored as a signed 64 bit integer
+const MaxQty = math.MaxInt64
+
+const (
+	// Recorded when the ledger of a product starts, it adds the qty the product had
+	OpeningBalanceReason = "opening balance"
+	// Recorded when a product update sets another qty
+	UpdateReason = "product update"
+)
+
+// Represent a change on the stock of a product, or of one of its variants when VariantSKU is set
+// Delta is the qty added, negative when it is taken, and Qty the qty left once it applied
+type StockMovement struct {
+	ID         string       `json:"id" bson:"_id"`
+	ProductID  string       `json:"product_id" bson:"product_id"`
+	VariantSKU string       `json:"variant_sku,omitempty" bson:"variant_sku,omitempty"`
+	Type       MovementType `json:"type" bson:"type"`
+	Delta      int64        `json:"delta" bson:"delta"`
+	Qty        uint64       `json:"qty" bson:"qty"`
+	Reason     string       `json:"reason,omitempty" bson:"reason,omitempty"`
+	Reference  string       `json:"reference,omitempty" bson:"reference,omitempty"`
+	Actor      string       `json:"actor,omitempty" bson:"actor,omitempty"`
+	CreatedAt  time.Time    `json:"created_at" bson:"created_at"`
+}
+
+// Represent the stock movements search structure
+type MovementsResponse struct {
+	Movements []StockMovement `json:"movements"`
+	Metadata  Metadata        `json:"metadata"`
+}
+
+// Represent a page of stock movements
+type MovementPage struct {
+	Movements []StockMovement
+	Total     int64
+}
+
+// Represent how the stored qty of a product compares with the qty its stock movements add up to
+// Products with variants compare each variant, Consistent reports whether every qty matches
+type StockLedger struct {
+	ProductID  string          `json:"product_id"`
+	Qty        uint64          `json:"qty"`
+	LedgerQty  int64           `json:"ledger_qty"`
+	Variants   []VariantLedger `json:"variants,omitempty"`
+	Consistent bool            `json:"consistent"`
+}
+
+// Represent how the stored qty of a variant compares with the qty its stock movements add up to
+type VariantLedger struct {
+	SKU       string `json:"sku"`
+	Qty       uint64 `json:"qty"`
+	LedgerQty int64  `json:"ledger_qty"`
+}
+
+// Compare the stored qty of the product and its variants with the qty the movements add up to
+func NewStockLedger(product Product, movements []StockMovement) StockLedger {
+	sums := make(map[string]int64)
+
+	var total int64
+
+	for _, movement := range movements {
+		sums[movement.VariantSKU] += movement.Delta
+
+		total += movement.Delta
+	}
+
+	ledger := StockLedger{ProductID: product.ID, Qty: product.Qty, LedgerQty: total, Consistent: int64(product.Qty) == total}
+
+	for _, variant := range product.Variants {
+		ledger.Variants = append(ledger.Variants, VariantLedger{SKU: variant.Sku, Qty: variant.Qty, LedgerQty: sums[variant.Sku]})
+
+		ledger.Consistent = ledger.Consistent && int64(variant.Qty) == sums[variant.Sku]
+	}
+
+	return ledger
+}
+
+// Parse a movement type
+func ParseMovementType(value string) (MovementType, error) {
+	movementType := MovementType(strings.ToLower(value))
+
+	if _, ok := movementSigns[movementType]; !ok {
+		return "", errors.New("incorrect movement type format, it must be receipt, sale, return, adjustment or damage")
+	}
+
+	return movementType, nil
+}
+
+// Represent an atomic change of the qty of a product, or of its variant when VariantSKU is set, by Delta
+type StockAdjustment struct {
+	ID         string
+	VariantSKU string
+	Delta      int64
+}
+
+// Represent a request to record a stock movement on a product, Actor is who requested it
+// The product is found by SKU when there is no ID, a variant sku addresses the variant
+type MovementRequest struct {
+	ID         string       `json:"-"`
+	SKU        string       `json:"-"`
+	VariantSKU string       `json:"variant_sku,omitempty"`
+	Type       MovementType `json:"type"`
+	Delta      int64        `json:"delta"`
+	Reason     string       `json:"reason,omitempty"`
+	Reference  string       `json:"reference,omitempty"`
+	Actor      string       `json:"-"`
+}
+
+// Build stock movement request and validate all requested data
+type MovementBuilder struct {
+	r *http.Request
+}
+
+func NewMovementBuilder(r *http.Request) *MovementBuilder {
+	return &MovementBuilder{
+		r: r,
+	}
+}
+
+// Build the requested movement, receipts and returns add stock, sales and damages take it and adjustments do either
+func (b *MovementBuilder) Build() (*MovementRequest, error) {
+	id := mux.Vars(b.r)["id"]
+	if id == "" {
+		return nil, errors.New("product id must be provided")
+	}
+
+	var request MovementRequest
+
+	if err := json.NewDecoder(b.r.Body).Decode(&request); err != nil {
+		return nil, errors.New("incorrect stock movement body format")
+	}
+
+	movementType, err := ParseMovementType(string(request.Type))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkMovementDelta(movementType, request.Delta); err != nil {
+		return nil, err
+	}
+
+	request.ID, request.Type, request.Actor = id, movementType, b.r.Header.Get(ActorHeader)
+
+	return &request, nil
+}
+
+// Check the delta has the sign its movement type requires and moves no more than MaxQty
+func checkMovementDelta(movementType MovementType, delta int64) error {
+	if delta == 0 {
+		return errors.New("stock movement delta cannot be 0")
+	}
+
+	if delta < -MaxQty {
+		return fmt.Errorf("stock movement delta must be between -%d and %d", uint64(MaxQty), uint64(MaxQty))
+	}
+
+	if sign := movementSigns[movementType]; sign*delta < 0 {
+		return errors.New("stock movement delta must be positive on receipts and returns, and negative on sales and damages")
+	}
+
+	return nil
+}
+
+// Represent the body of stock increment and decrement requests, the type defaults to receipt on increments and sale on decrements
+type StockChangeRequest struct {
+	Qty        uint64       `json:"qty"`
+	VariantSKU string       `json:"variant_sku,omitempty"`
+	Type       MovementType `json:"type,omitempty"`
+	Reason     string       `json:"reason,omitempty"`
+	Reference  string       `json:"reference,omitempty"`
+}
+
+// Build the stock movement of an increment or decrement request and validate all requested data
+// The product is addressed by the id route variable, or by the sku one
+type StockChangeBuilder struct {
+	r         *http.Request
+	decrement bool
+}
+
+func NewStockChangeBuilder(r *http.Request, decrement bool) *StockChangeBuilder {
+	return &StockChangeBuilder{
+		r:         r,
+		decrement: decrement,
+	}
+}
+
+func (b *StockChangeBuilder) Build() (*MovementRequest, error) {
+	vars := mux.Vars(b.r)
+
+	id, sku := vars["id"], vars["sku"]
+	if id == "" && sku == "" {
+		return nil, errors.New("product id or sku must be provided")
+	}
+
+	var body StockChangeRequest
+
+	if err := json.NewDecoder(b.r.Body).Decode(&body); err != nil {
+		return nil, errors.New("incorrect stock change body format")
+	}
+
+	if body.Qty == 0 || body.Qty > MaxQty {
+		return nil, errors.New("incorrect stock change qty, it must be greater than 0")
+	}
+
+	if sku != "" && body.VariantSKU != "" {
+		return nil, errors.New("variant sku must not be provided when the product is addressed by sku")
+	}
+
+	request := MovementRequest{
+		ID:         id,
+		SKU:        sku,
+		VariantSKU: body.VariantSKU,
+		Type:       MovementReceipt,
+		Delta:      int64(body.Qty),
+		Reason:     body.Reason,
+		Reference:  body.Reference,
+		Actor:      b.r.Header.Get(ActorHeader),
+	}
+
+	if b.decrement {
+		request.Type, request.Delta = MovementSale, -request.Delta
+	}
+
+	if body.Type != "" {
+		movementType, err := ParseMovementType(string(body.Type))
+		if err != nil {
+			return nil, err
+		}
+
+		if err := checkMovementDelta(movementType, request.Delta); err != nil {
+			return nil, errors.New("incorrect movement type, increments take receipts, returns or adjustments and decrements sales, damages or adjustments")
+		}
+
+		request.Type = movementType
+	}
+
+	return &request, nil
+}
+
+// Represent stock movements search request, movements are matched from From until before To when they are set
+// Types matched, empty matches every type
+type MovementSearchRequest struct {
+	ProductID string
+	Types     []MovementType
+	From      *time.Time
+	To        *time.Time
+	Limit     int
+	Offset    int
+}
+
+// Reports whether the movement matches the search filters
+func (r MovementSearchRequest) Match(movement StockMovement) bool {
+	if movement.ProductID != r.ProductID {
+		return false
+	}
+
+	if len(r.Types) > 0 && !containsMovementType(r.Types, movement.Type) {
+		return false
+	}
+
+	if r.From != nil && movement.CreatedAt.Before(*r.From) {
+		return false
+	}
+
+	return r.To == nil || movement.CreatedAt.Before(*r.To)
+}
+
+func containsMovementType(types []MovementType, movementType MovementType) bool {
+	for _, current := range types {
+		if current == movementType {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Build stock movements search request and validate all requested data
+type MovementSearchBuilder struct {
+	r *http.Request
+}
+
+func NewMovementSearchBuilder(r *http.Request) *MovementSearchBuilder {
+	return &MovementSearchBuilder{
+		r: r,
+	}
+}
+
+func (b *MovementSearchBuilder) Build() (*MovementSearchRequest, error) {
+	id := mux.Vars(b.r)["id"]
+	if id == "" {
+		return nil, errors.New("product id must be provided")
+	}
+
+	query := b.r.URL.Query()
+
+	limit, err := strconv.Atoi(query.Get("limit"))
+	if err != nil || limit < 0 {
+		return nil, errors.New("incorrect limit format")
+	}
+
+	offset, err := strconv.Atoi(query.Get("offset"))
+	if err != nil || offset < 0 {
+		return nil, errors.New("incorrect offset format")
+	}
+
+	request := MovementSearchRequest{ProductID: id, Limit: limit, Offset: offset}
+
+	for _, value := range query["type"] {
+		movementType, err := ParseMovementType(value)
+		if err != nil {
+			return nil, err
+		}
+
+		request.Types = append(request.Types, movementType)
+	}
+
+	for param, bound := range map[string]**time.Time{"from": &request.From, "to": &request.To} {
+		value := query.Get(param)
+		if value == "" {
+			continue
+		}
+
+		at, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return nil, errors.New("incorrect " + param + " format, it must be a RFC 3339 time")
+		}
+
+		*bound = &at
+	}
+
+	if request.From != nil && request.To != nil && !request.From.Before(*request.To) {
+		return nil, errors.New("incorrect movement dates range, from must be before to")
+	}
+
+	return &request, nil
+}
@@ -0,0 +1,72 @@
+package model
+
+import (
+	"errors"
+	"sort"
+	"strings"
+)
+
+// Represent how the requested tags are matched by search
+type TagMatch string
+
+const (
+	// Products with any of the requested tags are matched
+	TagMatchAny TagMatch = "any"
+	// Only products with every requested tag are matched
+	TagMatchAll TagMatch = "all"
+)
+
+// Represent how many of the searched products have a tag
+type TagCount struct {
+	Tag   string `json:"tag" bson:"_id"`
+	Count int64  `json:"count" bson:"count"`
+}
+
+// Represent tag counts structure
+type TagCountsResponse struct {
+	Tags []TagCount `json:"tags"`
+}
+
+// Parse a tag, tags are lowercase words of letters and numbers joined by hyphens
+func ParseTag(value string) (string, error) {
+	tag := strings.ToLower(strings.TrimSpace(value))
+
+	if !slugFormat.MatchString(tag) {
+		return "", errors.New("incorrect tag format")
+	}
+
+	return tag, nil
+}
+
+// Sort tag counts by count, most used first, ties are sorted by tag
+func SortTagCounts(counts []TagCount) {
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Count != counts[j].Count {
+			return counts[i].Count > counts[j].Count
+		}
+
+		return counts[i].Tag < counts[j].Tag
+	})
+}
+
+// Parse the product tags in place, they can not be repeated
+func validateTags(tags []string) error {
+	seen := make(map[string]bool, len(tags))
+
+	for i, value := range tags {
+		tag, err := ParseTag(value)
+		if err != nil {
+			return errors.New("product tag invalid format")
+		}
+
+		if seen[tag] {
+			return errors.New("product tags cannot be repeated")
+		}
+
+		seen[tag] = true
+
+		tags[i] = tag
+	}
+
+	return nil
+}
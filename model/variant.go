@@ -0,0 +1,179 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Represent a product option axis, like size or color, with the values its variants can take
+type Option struct {
+	Name   string   `json:"name" bson:"name"`
+	Values []string `json:"values" bson:"values"`
+}
+
+// Represent a variant of a product, Options holds one value for each product option
+type Variant struct {
+	Sku     string            `json:"sku" bson:"sku"`
+	Options map[string]string `json:"options" bson:"options"`
+	Qty     uint64            `json:"qty" bson:"qty"`
+	Price   int64             `json:"price" bson:"price"`
+	Images  []Image           `json:"images,omitempty" bson:"images,omitempty"`
+	InStock bool              `json:"in_stock" bson:"in_stock"`
+}
+
+// Represent the lowest and highest price of the product variants
+type PriceRange struct {
+	Min int64 `json:"min" bson:"min"`
+	Max int64 `json:"max" bson:"max"`
+}
+
+// Represent the product a sku belongs to, Variant is set when the sku is one of its variants
+type SKUProduct struct {
+	Product
+	Variant *Variant `json:"variant,omitempty"`
+}
+
+// Derive the product stock and prices from its variants, qty is their sum and price the lowest one
+// Products without variants are left unchanged
+func (p *Product) ApplyVariants() {
+	if len(p.Variants) == 0 {
+		p.PriceRange = nil
+
+		return
+	}
+
+	p.Qty = 0
+
+	p.PriceRange = &PriceRange{Min: p.Variants[0].Price, Max: p.Variants[0].Price}
+
+	for i := range p.Variants {
+		variant := &p.Variants[i]
+
+		variant.InStock = variant.Qty > 0
+
+		p.Qty += variant.Qty
+
+		if variant.Price < p.PriceRange.Min {
+			p.PriceRange.Min = variant.Price
+		}
+
+		if variant.Price > p.PriceRange.Max {
+			p.PriceRange.Max = variant.Price
+		}
+	}
+
+	p.Price = p.PriceRange.Min
+}
+
+// Get the variant with the given sku, nil when the product has none
+func (p *Product) Variant(sku string) *Variant {
+	for i := range p.Variants {
+		if p.Variants[i].Sku == sku {
+			return &p.Variants[i]
+		}
+	}
+
+	return nil
+}
+
+// Every sku of the product, its own one first followed by the ones of its variants
+func (p *Product) SKUs() []string {
+	skus := make([]string, 0, len(p.Variants)+1)
+
+	skus = append(skus, p.Sku)
+
+	for _, variant := range p.Variants {
+		skus = append(skus, variant.Sku)
+	}
+
+	return skus
+}
+
+// Validate product options and variants
+func validateVariants(product Product) error {
+	if len(product.Options) > 0 && len(product.Variants) == 0 {
+		return errors.New("product options require variants")
+	}
+
+	if len(product.Variants) > 0 && len(product.Options) == 0 {
+		return errors.New("product variants require options")
+	}
+
+	values := make(map[string]map[string]bool, len(product.Options))
+
+	for _, option := range product.Options {
+		if option.Name == "" {
+			return errors.New("product option name cannot be empty")
+		}
+
+		if values[option.Name] != nil {
+			return fmt.Errorf("product option %s cannot be repeated", option.Name)
+		}
+
+		if len(option.Values) == 0 {
+			return fmt.Errorf("product option %s values cannot be empty", option.Name)
+		}
+
+		values[option.Name] = make(map[string]bool, len(option.Values))
+
+		for _, value := range option.Values {
+			if value == "" || values[option.Name][value] {
+				return fmt.Errorf("product option %s values must be unique and not empty", option.Name)
+			}
+
+			values[option.Name][value] = true
+		}
+	}
+
+	skus := map[string]bool{product.Sku: true}
+
+	combinations := make(map[string]bool, len(product.Variants))
+
+	for _, variant := range product.Variants {
+		if variant.Sku == "" {
+			return errors.New("product variant sku cannot be empty")
+		}
+
+		if skus[variant.Sku] {
+			return fmt.Errorf("product variant sku %s cannot be repeated", variant.Sku)
+		}
+
+		skus[variant.Sku] = true
+
+		if variant.Price <= 0 {
+			return fmt.Errorf("product variant %s price invalid value", variant.Sku)
+		}
+
+		if err := validateImages(variant.Images); err != nil {
+			return fmt.Errorf("%w on variant %s", err, variant.Sku)
+		}
+
+		if len(variant.Options) != len(product.Options) {
+			return fmt.Errorf("product variant %s must have a value for every option", variant.Sku)
+		}
+
+		combination := make([]string, 0, len(variant.Options))
+
+		for name, value := range variant.Options {
+			if !values[name][value] {
+				return fmt.Errorf("product variant %s option %s has an unknown value", variant.Sku, name)
+			}
+
+			combination = append(combination, name+"="+value)
+		}
+
+		sort.Strings(combination)
+
+		key := strings.Join(combination, "&")
+
+		if combinations[key] {
+			return fmt.Errorf("product variant %s options cannot be repeated", variant.Sku)
+		}
+
+		combinations[key] = true
+	}
+
+	return nil
+}
@@ -0,0 +1,31 @@
+package model
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// Format a product version as a strong entity tag
+func ETag(version int64) string {
+	return strconv.Quote(strconv.FormatInt(version, 10))
+}
+
+// Parse the product version from an If-Match header value, an empty value or * returns 0 so the write is not conditioned
+// Weak tags match the version they hold too, proxies compressing responses weaken the ETag clients send back
+func ParseIfMatch(value string) (int64, error) {
+	value = strings.TrimSpace(value)
+
+	if value == "" || value == "*" {
+		return 0, nil
+	}
+
+	value = strings.TrimPrefix(value, "W/")
+
+	version, err := strconv.ParseInt(strings.Trim(value, `"`), 10, 64)
+	if err != nil || version < 0 {
+		return 0, errors.New("incorrect If-Match format")
+	}
+
+	return version, nil
+}
@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"strconv"
+
+	"github.com/srodrmendz/api-product-catalog/model"
+)
+
+// Reports whether product matches every attribute filter, the same way getAttributeFilter does on MongoDB
+func matchAttributes(product model.Product, filters []model.AttributeFilter) bool {
+	for _, filter := range filters {
+		if !matchAttribute(product.Attributes[filter.Name], filter) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Equality filters match strings, numbers and bools written as the filter value, range filters only match numbers
+func matchAttribute(value interface{}, filter model.AttributeFilter) bool {
+	switch value := value.(type) {
+	case string:
+		return filter.Operator == model.AttributeEqual && value == filter.Value
+	case bool:
+		return filter.Operator == model.AttributeEqual && strconv.FormatBool(value) == filter.Value
+	case float64:
+		number, err := strconv.ParseFloat(filter.Value, 64)
+		if err != nil {
+			return false
+		}
+
+		switch filter.Operator {
+		case model.AttributeGreaterOrEqual:
+			return value >= number
+		case model.AttributeLessOrEqual:
+			return value <= number
+		default:
+			return value == number
+		}
+	}
+
+	return false
+}
+
+// Values an equality filter matches, the raw value plus the number and bool it is written as
+func attributeValues(filter model.AttributeFilter) []interface{} {
+	values := []interface{}{filter.Value}
+
+	if number, err := strconv.ParseFloat(filter.Value, 64); err == nil {
+		values = append(values, number)
+	}
+
+	if filter.Value == "true" || filter.Value == "false" {
+		values = append(values, filter.Value == "true")
+	}
+
+	return values
+}
+
+// Copy attributes so callers can not modify stored data
+func copyAttributes(attributes map[string]interface{}) map[string]interface{} {
+	if attributes == nil {
+		return nil
+	}
+
+	copied := make(map[string]interface{}, len(attributes))
+
+	for name, value := range attributes {
+		copied[name] = value
+	}
+
+	return copied
+}
@@ -0,0 +1,266 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/srodrmendz/api-product-catalog/model"
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	// Products by id
+	productsBucket = []byte("products")
+
+	// Product ids by sku, used as the unique sku index
+	skusBucket = []byte("skus")
+
+	// Product ids by every current or previous slug they hold, used as the unique slug index
+	slugsBucket = []byte("slugs")
+
+	// Empty values keyed by price and id, used to walk products sorted by price
+	pricesBucket = []byte("prices")
+
+	// Categories by id
+	categoriesBucket = []byte("categories")
+
+	// Brands by id
+	brandsBucket = []byte("brands")
+
+	// Stock movements keyed by product id and recording sequence, so the ones of a product are walked in order
+	stockMovementsBucket = []byte("stock_movements")
+)
+
+// Create new embedded product repository stored on a single data file, the file is created if it does not exist
+func NewBolt(path string) (*Bolt, error) {
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("opening data file %s %w", path, err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, bucket := range [][]byte{productsBucket, skusBucket, pricesBucket, categoriesBucket, brandsBucket, stockMovementsBucket} {
+			if _, err := tx.CreateBucketIfNotExists(bucket); err != nil {
+				return err
+			}
+		}
+
+		return createSlugsBucket(tx)
+	})
+	if err != nil {
+		db.Close()
+
+		return nil, fmt.Errorf("creating buckets on data file %s %w", path, err)
+	}
+
+	return &Bolt{
+		db: db,
+	}, nil
+}
+
+// Close the data file
+func (r *Bolt) Close() error {
+	return r.db.Close()
+}
+
+// Create a new product
+func (r *Bolt) Create(ctx context.Context, product model.Product) (*model.Product, error) {
+	var created *model.Product
+
+	err := r.update(func(t *boltTransaction) (err error) {
+		created, err = t.Create(ctx, product)
+
+		return err
+	})
+
+	return created, err
+}
+
+// Get a product by id
+func (r *Bolt) GetByID(ctx context.Context, id string) (*model.Product, error) {
+	var product *model.Product
+
+	err := r.view(func(t *boltTransaction) (err error) {
+		product, err = t.GetByID(ctx, id)
+
+		return err
+	})
+
+	return product, err
+}
+
+// Get a product by sku
+func (r *Bolt) GetBySKU(ctx context.Context, sku string) (*model.Product, error) {
+	var product *model.Product
+
+	err := r.view(func(t *boltTransaction) (err error) {
+		product, err = t.GetBySKU(ctx, sku)
+
+		return err
+	})
+
+	return product, err
+}
+
+// Get the product holding a slug, deleted products included
+func (r *Bolt) GetBySlug(ctx context.Context, slug string) (*model.Product, error) {
+	var product *model.Product
+
+	err := r.view(func(t *boltTransaction) (err error) {
+		product, err = t.GetBySlug(ctx, slug)
+
+		return err
+	})
+
+	return product, err
+}
+
+// Delete product, it is kept until purged so it can be restored
+func (r *Bolt) Delete(ctx context.Context, id string, version int64) error {
+	return r.update(func(t *boltTransaction) error {
+		return t.Delete(ctx, id, version)
+	})
+}
+
+// Restore a deleted product, restoring a product that is not deleted returns it unchanged
+func (r *Bolt) Restore(ctx context.Context, id string) (*model.Product, error) {
+	var product *model.Product
+
+	err := r.update(func(t *boltTransaction) (err error) {
+		product, err = t.Restore(ctx, id)
+
+		return err
+	})
+
+	return product, err
+}
+
+// Permanently remove the products deleted before the given time
+func (r *Bolt) Purge(ctx context.Context, before time.Time) (int64, error) {
+	var purged int64
+
+	err := r.update(func(t *boltTransaction) (err error) {
+		purged, err = t.Purge(ctx, before)
+
+		return err
+	})
+
+	return purged, err
+}
+
+// List the products with a scheduled price starting or ending at the given time or before
+func (r *Bolt) ListDuePrices(ctx context.Context, at time.Time) ([]model.Product, error) {
+	var products []model.Product
+
+	err := r.view(func(t *boltTransaction) (err error) {
+		products, err = t.ListDuePrices(ctx, at)
+
+		return err
+	})
+
+	return products, err
+}
+
+// Update a product
+func (r *Bolt) Update(ctx context.Context, update model.Update) (*model.Product, error) {
+	var product *model.Product
+
+	err := r.update(func(t *boltTransaction) (err error) {
+		product, err = t.Update(ctx, update)
+
+		return err
+	})
+
+	return product, err
+}
+
+// Replace a product
+func (r *Bolt) Replace(ctx context.Context, product model.Product) (*model.Product, error) {
+	var replaced *model.Product
+
+	err := r.update(func(t *boltTransaction) (err error) {
+		replaced, err = t.Replace(ctx, product)
+
+		return err
+	})
+
+	return replaced, err
+}
+
+// Search products walking the price index, so only the requested page is kept in memory
+func (r *Bolt) Search(ctx context.Context, request model.SearchRequest) (*model.Page, error) {
+	var page *model.Page
+
+	err := r.view(func(t *boltTransaction) (err error) {
+		page, err = t.Search(ctx, request)
+
+		return err
+	})
+
+	return page, err
+}
+
+// Run fn on a single read-write bbolt transaction, it is committed only when fn returns no error
+func (r *Bolt) WithTransaction(ctx context.Context, fn func(txRepo Repository) error) error {
+	return r.update(func(t *boltTransaction) error {
+		return fn(t)
+	})
+}
+
+func (r *Bolt) update(fn func(t *boltTransaction) error) error {
+	return r.db.Update(func(tx *bolt.Tx) error {
+		return fn(&boltTransaction{tx: tx})
+	})
+}
+
+func (r *Bolt) view(fn func(t *boltTransaction) error) error {
+	return r.db.View(func(tx *bolt.Tx) error {
+		return fn(&boltTransaction{tx: tx})
+	})
+}
+
+// Write a consistent copy of the data file to w while the repository keeps serving requests
+func (r *Bolt) WriteTo(w io.Writer) (int64, error) {
+	var written int64
+
+	err := r.db.View(func(tx *bolt.Tx) error {
+		var err error
+
+		written, err = tx.WriteTo(w)
+
+		return err
+	})
+
+	return written, err
+}
+
+// Backup the data file to path, the previous backup is only replaced once the new one is complete
+func (r *Bolt) Backup(path string) error {
+	tmp := path + ".tmp"
+
+	file, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("creating backup file %s %w", tmp, err)
+	}
+
+	if _, err := r.WriteTo(file); err != nil {
+		file.Close()
+
+		return fmt.Errorf("writing backup file %s %w", tmp, err)
+	}
+
+	if err := file.Sync(); err != nil {
+		file.Close()
+
+		return fmt.Errorf("syncing backup file %s %w", tmp, err)
+	}
+
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("closing backup file %s %w", tmp, err)
+	}
+
+	return os.Rename(tmp, path)
+}
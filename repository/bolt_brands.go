@@ -0,0 +1,215 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	internalError "github.com/srodrmendz/api-product-catalog/errors"
+	"github.com/srodrmendz/api-product-catalog/model"
+	bolt "go.etcd.io/bbolt"
+)
+
+// Count how many of the products matching the search filters have each tag
+func (r *Bolt) CountTags(ctx context.Context, request model.SearchRequest) ([]model.TagCount, error) {
+	var tags []model.TagCount
+
+	err := r.view(func(t *boltTransaction) (err error) {
+		tags, err = t.CountTags(ctx, request)
+
+		return err
+	})
+
+	return tags, err
+}
+
+// Create a new brand
+func (r *Bolt) CreateBrand(ctx context.Context, brand model.Brand) (*model.Brand, error) {
+	var created *model.Brand
+
+	err := r.update(func(t *boltTransaction) (err error) {
+		created, err = t.CreateBrand(ctx, brand)
+
+		return err
+	})
+
+	return created, err
+}
+
+// Get a brand by id
+func (r *Bolt) GetBrand(ctx context.Context, id string) (*model.Brand, error) {
+	var brand *model.Brand
+
+	err := r.view(func(t *boltTransaction) (err error) {
+		brand, err = t.GetBrand(ctx, id)
+
+		return err
+	})
+
+	return brand, err
+}
+
+// List every brand sorted by name
+func (r *Bolt) ListBrands(ctx context.Context) ([]model.Brand, error) {
+	var brands []model.Brand
+
+	err := r.view(func(t *boltTransaction) (err error) {
+		brands, err = t.ListBrands(ctx)
+
+		return err
+	})
+
+	return brands, err
+}
+
+// Update a brand name and description
+func (r *Bolt) UpdateBrand(ctx context.Context, brand model.Brand) (*model.Brand, error) {
+	var updated *model.Brand
+
+	err := r.update(func(t *boltTransaction) (err error) {
+		updated, err = t.UpdateBrand(ctx, brand)
+
+		return err
+	})
+
+	return updated, err
+}
+
+// Delete a brand
+func (r *Bolt) DeleteBrand(ctx context.Context, id string) error {
+	return r.update(func(t *boltTransaction) error {
+		return t.DeleteBrand(ctx, id)
+	})
+}
+
+// Count how many of the products matching the search filters have each tag
+func (t *boltTransaction) CountTags(ctx context.Context, request model.SearchRequest) ([]model.TagCount, error) {
+	var products []model.Product
+
+	err := t.tx.Bucket(productsBucket).ForEach(func(_, data []byte) error {
+		var product model.Product
+
+		if err := json.Unmarshal(data, &product); err != nil {
+			return fmt.Errorf("decoding product from repository %w", err)
+		}
+
+		// Products without a price on the selected currency are not counted
+		if request.Currency.Selected() && !product.SelectCurrency(request.Currency) {
+			return nil
+		}
+
+		if matchSearch(product, request) {
+			products = append(products, product)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("counting product tags on repository %w", err)
+	}
+
+	return countTags(products), nil
+}
+
+// Create a new brand
+func (t *boltTransaction) CreateBrand(ctx context.Context, brand model.Brand) (*model.Brand, error) {
+	brand.ID = uuid.NewString()
+
+	now := time.Now()
+
+	brand.CreatedAt = now
+
+	brand.UpdatedAt = now
+
+	brand = copyBrand(brand)
+
+	if err := putBrand(t.tx, brand); err != nil {
+		return nil, fmt.Errorf("creating brand %s on repository %w", brand.Name, err)
+	}
+
+	return &brand, nil
+}
+
+// Get a brand by id
+func (t *boltTransaction) GetBrand(ctx context.Context, id string) (*model.Brand, error) {
+	data := t.tx.Bucket(brandsBucket).Get([]byte(id))
+	if data == nil {
+		return nil, internalError.ErrBrandNotFound
+	}
+
+	var brand model.Brand
+
+	if err := json.Unmarshal(data, &brand); err != nil {
+		return nil, fmt.Errorf("decoding brand from repository %w", err)
+	}
+
+	return &brand, nil
+}
+
+// List every brand sorted by name
+func (t *boltTransaction) ListBrands(ctx context.Context) ([]model.Brand, error) {
+	brands := []model.Brand{}
+
+	err := t.tx.Bucket(brandsBucket).ForEach(func(_, data []byte) error {
+		var brand model.Brand
+
+		if err := json.Unmarshal(data, &brand); err != nil {
+			return fmt.Errorf("decoding brand from repository %w", err)
+		}
+
+		brands = append(brands, brand)
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sortBrands(brands)
+
+	return brands, nil
+}
+
+// Update a brand name and description
+func (t *boltTransaction) UpdateBrand(ctx context.Context, brand model.Brand) (*model.Brand, error) {
+	stored, err := t.GetBrand(ctx, brand.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	stored.Name = brand.Name
+
+	stored.Description = copyBrand(brand).Description
+
+	stored.UpdatedAt = time.Now()
+
+	if err := putBrand(t.tx, *stored); err != nil {
+		return nil, fmt.Errorf("updating brand on repository %w", err)
+	}
+
+	return stored, nil
+}
+
+// Delete a brand
+func (t *boltTransaction) DeleteBrand(ctx context.Context, id string) error {
+	if _, err := t.GetBrand(ctx, id); err != nil {
+		return err
+	}
+
+	if err := t.tx.Bucket(brandsBucket).Delete([]byte(id)); err != nil {
+		return fmt.Errorf("deleting brand from repository %w", err)
+	}
+
+	return nil
+}
+
+func putBrand(tx *bolt.Tx, brand model.Brand) error {
+	data, err := json.Marshal(brand)
+	if err != nil {
+		return fmt.Errorf("encoding brand %w", err)
+	}
+
+	return tx.Bucket(brandsBucket).Put([]byte(brand.ID), data)
+}
@@ -0,0 +1,274 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	internalError "github.com/srodrmendz/api-product-catalog/errors"
+	"github.com/srodrmendz/api-product-catalog/model"
+	bolt "go.etcd.io/bbolt"
+)
+
+// Create a new category
+func (r *Bolt) CreateCategory(ctx context.Context, category model.Category) (*model.Category, error) {
+	var created *model.Category
+
+	err := r.update(func(t *boltTransaction) (err error) {
+		created, err = t.CreateCategory(ctx, category)
+
+		return err
+	})
+
+	return created, err
+}
+
+// Get a category by id
+func (r *Bolt) GetCategory(ctx context.Context, id string) (*model.Category, error) {
+	var category *model.Category
+
+	err := r.view(func(t *boltTransaction) (err error) {
+		category, err = t.GetCategory(ctx, id)
+
+		return err
+	})
+
+	return category, err
+}
+
+// List every category sorted by name
+func (r *Bolt) ListCategories(ctx context.Context) ([]model.Category, error) {
+	var categories []model.Category
+
+	err := r.view(func(t *boltTransaction) (err error) {
+		categories, err = t.ListCategories(ctx)
+
+		return err
+	})
+
+	return categories, err
+}
+
+// Get a category and all its descendants
+func (r *Bolt) GetCategorySubtree(ctx context.Context, id string) ([]model.Category, error) {
+	var categories []model.Category
+
+	err := r.view(func(t *boltTransaction) (err error) {
+		categories, err = t.GetCategorySubtree(ctx, id)
+
+		return err
+	})
+
+	return categories, err
+}
+
+// Update a category, the paths of its descendants are updated to follow it
+func (r *Bolt) UpdateCategory(ctx context.Context, category model.Category) (*model.Category, error) {
+	var updated *model.Category
+
+	err := r.update(func(t *boltTransaction) (err error) {
+		updated, err = t.UpdateCategory(ctx, category)
+
+		return err
+	})
+
+	return updated, err
+}
+
+// Delete a category
+func (r *Bolt) DeleteCategory(ctx context.Context, id string) error {
+	return r.update(func(t *boltTransaction) error {
+		return t.DeleteCategory(ctx, id)
+	})
+}
+
+// Replace category from by to on every product, an empty to removes it
+func (r *Bolt) ReplaceProductCategory(ctx context.Context, from string, to string) (int64, error) {
+	var replaced int64
+
+	err := r.update(func(t *boltTransaction) (err error) {
+		replaced, err = t.ReplaceProductCategory(ctx, from, to)
+
+		return err
+	})
+
+	return replaced, err
+}
+
+// Create a new category
+func (t *boltTransaction) CreateCategory(ctx context.Context, category model.Category) (*model.Category, error) {
+	category.ID = uuid.NewString()
+
+	now := time.Now()
+
+	category.CreatedAt = now
+
+	category.UpdatedAt = now
+
+	category = copyCategory(category)
+
+	if err := putCategory(t.tx, category); err != nil {
+		return nil, fmt.Errorf("creating category %s on repository %w", category.Name, err)
+	}
+
+	return &category, nil
+}
+
+// Get a category by id
+func (t *boltTransaction) GetCategory(ctx context.Context, id string) (*model.Category, error) {
+	data := t.tx.Bucket(categoriesBucket).Get([]byte(id))
+	if data == nil {
+		return nil, internalError.ErrCategoryNotFound
+	}
+
+	var category model.Category
+
+	if err := json.Unmarshal(data, &category); err != nil {
+		return nil, fmt.Errorf("decoding category from repository %w", err)
+	}
+
+	category = copyCategory(category)
+
+	return &category, nil
+}
+
+// List every category sorted by name
+func (t *boltTransaction) ListCategories(ctx context.Context) ([]model.Category, error) {
+	return t.findCategories(func(model.Category) bool { return true })
+}
+
+// Get a category and all its descendants
+func (t *boltTransaction) GetCategorySubtree(ctx context.Context, id string) ([]model.Category, error) {
+	if _, err := t.GetCategory(ctx, id); err != nil {
+		return nil, err
+	}
+
+	return t.findCategories(func(category model.Category) bool {
+		return inSubtree(category, id)
+	})
+}
+
+// Update a category, the paths of its descendants are updated to follow it
+func (t *boltTransaction) UpdateCategory(ctx context.Context, category model.Category) (*model.Category, error) {
+	stored, err := t.GetCategory(ctx, category.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	stored.Name = category.Name
+
+	stored.ParentID = category.ParentID
+
+	stored.Path = append([]string{}, category.Path...)
+
+	stored.Attributes = category.Attributes
+
+	stored.UpdatedAt = time.Now()
+
+	if err := putCategory(t.tx, *stored); err != nil {
+		return nil, fmt.Errorf("updating category on repository %w", err)
+	}
+
+	descendants, err := t.findCategories(func(descendant model.Category) bool {
+		return contains(descendant.Path, stored.ID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, descendant := range descendants {
+		descendant.Path = movedPath(descendant.Path, *stored)
+
+		if err := putCategory(t.tx, descendant); err != nil {
+			return nil, fmt.Errorf("updating category %s path on repository %w", descendant.ID, err)
+		}
+	}
+
+	return stored, nil
+}
+
+// Delete a category
+func (t *boltTransaction) DeleteCategory(ctx context.Context, id string) error {
+	if _, err := t.GetCategory(ctx, id); err != nil {
+		return err
+	}
+
+	if err := t.tx.Bucket(categoriesBucket).Delete([]byte(id)); err != nil {
+		return fmt.Errorf("deleting category from repository %w", err)
+	}
+
+	return nil
+}
+
+// Replace category from by to on every product, an empty to removes it
+func (t *boltTransaction) ReplaceProductCategory(ctx context.Context, from string, to string) (int64, error) {
+	var products []model.Product
+
+	err := t.tx.Bucket(productsBucket).ForEach(func(_, data []byte) error {
+		var product model.Product
+
+		if err := json.Unmarshal(data, &product); err != nil {
+			return fmt.Errorf("decoding product from repository %w", err)
+		}
+
+		if categoryIDs, ok := replaceCategory(product.CategoryIDs, from, to); ok {
+			product.CategoryIDs = categoryIDs
+
+			products = append(products, product)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	// Buckets can not be modified while they are iterated, so products are written afterwards
+	for _, product := range products {
+		product.UpdatedAt = time.Now()
+
+		product.Version++
+
+		if err := putProduct(t.tx, product); err != nil {
+			return 0, fmt.Errorf("replacing product category on repository %w", err)
+		}
+	}
+
+	return int64(len(products)), nil
+}
+
+func (t *boltTransaction) findCategories(match func(model.Category) bool) ([]model.Category, error) {
+	categories := []model.Category{}
+
+	err := t.tx.Bucket(categoriesBucket).ForEach(func(_, data []byte) error {
+		var category model.Category
+
+		if err := json.Unmarshal(data, &category); err != nil {
+			return fmt.Errorf("decoding category from repository %w", err)
+		}
+
+		if match(category) {
+			categories = append(categories, copyCategory(category))
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sortCategories(categories)
+
+	return categories, nil
+}
+
+func putCategory(tx *bolt.Tx, category model.Category) error {
+	data, err := json.Marshal(category)
+	if err != nil {
+		return fmt.Errorf("encoding category %w", err)
+	}
+
+	return tx.Bucket(categoriesBucket).Put([]byte(category.ID), data)
+}
@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/srodrmendz/api-product-catalog/model"
+)
+
+// Adjust the stock of a product
+func (r *Bolt) AdjustStock(ctx context.Context, adjustment model.StockAdjustment) (*model.Product, error) {
+	var adjusted *model.Product
+
+	err := r.update(func(t *boltTransaction) (err error) {
+		adjusted, err = t.AdjustStock(ctx, adjustment)
+
+		return err
+	})
+
+	return adjusted, err
+}
+
+// Record a stock movement
+func (r *Bolt) CreateStockMovement(ctx context.Context, movement model.StockMovement) (*model.StockMovement, error) {
+	var created *model.StockMovement
+
+	err := r.update(func(t *boltTransaction) (err error) {
+		created, err = t.CreateStockMovement(ctx, movement)
+
+		return err
+	})
+
+	return created, err
+}
+
+// List the stock movements of a product, newest first
+func (r *Bolt) ListStockMovements(ctx context.Context, request model.MovementSearchRequest) (*model.MovementPage, error) {
+	var page *model.MovementPage
+
+	err := r.view(func(t *boltTransaction) (err error) {
+		page, err = t.ListStockMovements(ctx, request)
+
+		return err
+	})
+
+	return page, err
+}
+
+// Adjust the stock of a product, bbolt runs a single writable transaction at a time so the qty checked is the one written
+// The price index is left unchanged, adjustments do not change prices
+func (t *boltTransaction) AdjustStock(ctx context.Context, adjustment model.StockAdjustment) (*model.Product, error) {
+	product, err := getActiveProduct(t.tx, []byte(adjustment.ID))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkAdjustment(*product, adjustment); err != nil {
+		return nil, err
+	}
+
+	applyAdjustment(product, adjustment)
+
+	if err := putProduct(t.tx, *product); err != nil {
+		return nil, fmt.Errorf("adjusting product stock on repository %w", err)
+	}
+
+	return product, nil
+}
+
+// Record a stock movement, keyed after the movements already recorded for its product
+func (t *boltTransaction) CreateStockMovement(ctx context.Context, movement model.StockMovement) (*model.StockMovement, error) {
+	bucket := t.tx.Bucket(stockMovementsBucket)
+
+	sequence, err := bucket.NextSequence()
+	if err != nil {
+		return nil, fmt.Errorf("recording stock movement on repository %w", err)
+	}
+
+	movement.ID = uuid.NewString()
+
+	movement.CreatedAt = time.Now()
+
+	data, err := json.Marshal(movement)
+	if err != nil {
+		return nil, fmt.Errorf("encoding stock movement %w", err)
+	}
+
+	if err := bucket.Put([]byte(fmt.Sprintf("%s/%020d", movement.ProductID, sequence)), data); err != nil {
+		return nil, fmt.Errorf("recording stock movement on repository %w", err)
+	}
+
+	return &movement, nil
+}
+
+// List the stock movements of a product, walking only the keys of the product
+func (t *boltTransaction) ListStockMovements(ctx context.Context, request model.MovementSearchRequest) (*model.MovementPage, error) {
+	prefix := []byte(request.ProductID + "/")
+
+	var movements []model.StockMovement
+
+	cursor := t.tx.Bucket(stockMovementsBucket).Cursor()
+
+	for key, data := cursor.Seek(prefix); key != nil && bytes.HasPrefix(key, prefix); key, data = cursor.Next() {
+		var movement model.StockMovement
+
+		if err := json.Unmarshal(data, &movement); err != nil {
+			return nil, fmt.Errorf("decoding stock movement from repository %w", err)
+		}
+
+		movements = append(movements, movement)
+	}
+
+	return pageMovements(movements, request), nil
+}
@@ -0,0 +1,133 @@
+package repository_test
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/srodrmendz/api-product-catalog/model"
+	"github.com/srodrmendz/api-product-catalog/repository"
+	"github.com/srodrmendz/api-product-catalog/repository/repotest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test Bolt Repository against the repository contract
+func TestBolt(t *testing.T) {
+	repotest.Run(t, func(t *testing.T) repository.Repository {
+		return openBolt(t, filepath.Join(t.TempDir(), "catalog.db"))
+	})
+}
+
+func TestBolt_Reopen(t *testing.T) {
+	t.Run("successfully keep products after reopening the data file", func(t *testing.T) {
+		// Given
+		ctx := context.TODO()
+
+		path := filepath.Join(t.TempDir(), "catalog.db")
+
+		repo, err := repository.NewBolt(path)
+
+		require.NoError(t, err)
+
+		product, err := repo.Create(ctx, model.Product{
+			Qty:   10,
+			Name:  "product 1",
+			Sku:   "sku1",
+			Price: 100,
+		})
+
+		require.NoError(t, err)
+
+		require.NoError(t, repo.Close())
+
+		// When
+		repo = openBolt(t, path)
+
+		// Then
+		response, err := repo.GetBySKU(ctx, product.Sku)
+
+		require.NoError(t, err)
+
+		assert.Equal(t, product.ID, response.ID)
+
+		page, err := repo.Search(ctx, model.SearchRequest{Sort: "asc", InStock: true, Limit: 10})
+
+		require.NoError(t, err)
+
+		assert.Equal(t, int64(1), page.Total)
+
+		assert.Len(t, page.Products, 1)
+	})
+}
+
+func TestBolt_Backup(t *testing.T) {
+	t.Run("successfully backup data file while it is open", func(t *testing.T) {
+		// Given
+		ctx := context.TODO()
+
+		dir := t.TempDir()
+
+		repo := openBolt(t, filepath.Join(dir, "catalog.db"))
+
+		product, err := repo.Create(ctx, model.Product{
+			Name: "product 1",
+			Sku:  "sku1",
+		})
+
+		require.NoError(t, err)
+
+		backupPath := filepath.Join(dir, "backup.db")
+
+		// When
+		err = repo.Backup(backupPath)
+
+		// Then
+		require.NoError(t, err)
+
+		backup := openBolt(t, backupPath)
+
+		response, err := backup.GetByID(ctx, product.ID)
+
+		require.NoError(t, err)
+
+		assert.Equal(t, product.Sku, response.Sku)
+
+		_, err = os.Stat(backupPath + ".tmp")
+
+		assert.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("successfully write data file to a writer", func(t *testing.T) {
+		// Given
+		repo := openBolt(t, filepath.Join(t.TempDir(), "catalog.db"))
+
+		var buffer bytes.Buffer
+
+		// When
+		written, err := repo.WriteTo(&buffer)
+
+		// Then
+		require.NoError(t, err)
+
+		assert.Equal(t, int64(buffer.Len()), written)
+
+		assert.Less(t, int64(0), written)
+	})
+}
+
+func openBolt(t *testing.T, path string) *repository.Bolt {
+	t.Helper()
+
+	repo, err := repository.NewBolt(path)
+
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		repo.Close()
+	})
+
+	return repo
+}
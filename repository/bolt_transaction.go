@@ -0,0 +1,447 @@
+package repository
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	internalError "github.com/srodrmendz/api-product-catalog/errors"
+	"github.com/srodrmendz/api-product-catalog/model"
+	bolt "go.etcd.io/bbolt"
+)
+
+// Create a new product
+func (t *boltTransaction) Create(ctx context.Context, product model.Product) (*model.Product, error) {
+	product.ID = uuid.NewString()
+
+	now := time.Now()
+
+	product.CreatedAt = now
+
+	product.UpdatedAt = now
+
+	product.InStock = product.Qty > 0
+
+	product.Version = 1
+
+	skus := t.tx.Bucket(skusBucket)
+
+	for _, sku := range product.SKUs() {
+		if skus.Get([]byte(sku)) != nil {
+			return nil, internalError.ErrProductSKUAlreadyExist
+		}
+
+		if err := skus.Put([]byte(sku), []byte(product.ID)); err != nil {
+			return nil, fmt.Errorf("creating product %s on repository %w", product.Name, err)
+		}
+	}
+
+	if err := putSlugs(t.tx, model.Product{}, product); err != nil {
+		return nil, err
+	}
+
+	if err := t.tx.Bucket(pricesBucket).Put(priceKey(product), nil); err != nil {
+		return nil, fmt.Errorf("creating product %s on repository %w", product.Name, err)
+	}
+
+	if err := putProduct(t.tx, product); err != nil {
+		return nil, fmt.Errorf("creating product %s on repository %w", product.Name, err)
+	}
+
+	return &product, nil
+}
+
+// Get a product by id
+func (t *boltTransaction) GetByID(ctx context.Context, id string) (*model.Product, error) {
+	return getActiveProduct(t.tx, []byte(id))
+}
+
+// Get a product by sku
+func (t *boltTransaction) GetBySKU(ctx context.Context, sku string) (*model.Product, error) {
+	id := t.tx.Bucket(skusBucket).Get([]byte(sku))
+	if id == nil {
+		return nil, internalError.ErrProductNotFound
+	}
+
+	return getActiveProduct(t.tx, id)
+}
+
+// Get the product holding a slug, deleted products included
+func (t *boltTransaction) GetBySlug(ctx context.Context, slug string) (*model.Product, error) {
+	id := t.tx.Bucket(slugsBucket).Get([]byte(slug))
+	if id == nil {
+		return nil, internalError.ErrProductNotFound
+	}
+
+	return getProduct(t.tx, id)
+}
+
+// Delete product, it is kept until purged so it can be restored
+func (t *boltTransaction) Delete(ctx context.Context, id string, version int64) error {
+	product, err := getActiveProduct(t.tx, []byte(id))
+	if err != nil {
+		return err
+	}
+
+	if err := checkVersion(*product, version); err != nil {
+		return err
+	}
+
+	markDeleted(product)
+
+	if err := putProduct(t.tx, *product); err != nil {
+		return fmt.Errorf("deleting product from repository %w", err)
+	}
+
+	var related []model.Product
+
+	err = t.tx.Bucket(productsBucket).ForEach(func(_, data []byte) error {
+		var product model.Product
+
+		if err := json.Unmarshal(data, &product); err != nil {
+			return fmt.Errorf("decoding product from repository %w", err)
+		}
+
+		if markUnlinked(&product, id) {
+			related = append(related, product)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// Buckets can not be modified while they are iterated, so related products are written afterwards
+	for _, product := range related {
+		if err := putProduct(t.tx, product); err != nil {
+			return fmt.Errorf("unlinking deleted product on repository %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Restore a deleted product, restoring a product that is not deleted returns it unchanged
+func (t *boltTransaction) Restore(ctx context.Context, id string) (*model.Product, error) {
+	product, err := getProduct(t.tx, []byte(id))
+	if err != nil || product.DeletedAt == nil {
+		return product, err
+	}
+
+	markRestored(product)
+
+	if err := putProduct(t.tx, *product); err != nil {
+		return nil, fmt.Errorf("restoring product on repository %w", err)
+	}
+
+	return product, nil
+}
+
+// Permanently remove the products deleted before the given time
+func (t *boltTransaction) Purge(ctx context.Context, before time.Time) (int64, error) {
+	var products []model.Product
+
+	err := t.tx.Bucket(productsBucket).ForEach(func(_, data []byte) error {
+		var product model.Product
+
+		if err := json.Unmarshal(data, &product); err != nil {
+			return fmt.Errorf("decoding product from repository %w", err)
+		}
+
+		if product.DeletedAt != nil && product.DeletedAt.Before(before) {
+			products = append(products, product)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	// Buckets can not be modified while they are iterated, so products are removed afterwards
+	for _, product := range products {
+		for _, sku := range product.SKUs() {
+			if err := t.tx.Bucket(skusBucket).Delete([]byte(sku)); err != nil {
+				return 0, fmt.Errorf("purging products from repository %w", err)
+			}
+		}
+
+		for _, slug := range product.Slugs() {
+			if err := t.tx.Bucket(slugsBucket).Delete([]byte(slug)); err != nil {
+				return 0, fmt.Errorf("purging products from repository %w", err)
+			}
+		}
+
+		if err := t.tx.Bucket(pricesBucket).Delete(priceKey(product)); err != nil {
+			return 0, fmt.Errorf("purging products from repository %w", err)
+		}
+
+		if err := t.tx.Bucket(productsBucket).Delete([]byte(product.ID)); err != nil {
+			return 0, fmt.Errorf("purging products from repository %w", err)
+		}
+	}
+
+	return int64(len(products)), nil
+}
+
+// List the products with a scheduled price starting or ending at the given time or before
+func (t *boltTransaction) ListDuePrices(ctx context.Context, at time.Time) ([]model.Product, error) {
+	var products []model.Product
+
+	err := t.tx.Bucket(productsBucket).ForEach(func(_, data []byte) error {
+		var product model.Product
+
+		if err := json.Unmarshal(data, &product); err != nil {
+			return fmt.Errorf("decoding product from repository %w", err)
+		}
+
+		if product.DeletedAt == nil && isPriceDue(product, at) {
+			products = append(products, product)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("listing due prices on repository %w", err)
+	}
+
+	return products, nil
+}
+
+// Update a product
+func (t *boltTransaction) Update(ctx context.Context, update model.Update) (*model.Product, error) {
+	product, err := getActiveProduct(t.tx, []byte(update.ID))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkUpdate(*product, update); err != nil {
+		return nil, err
+	}
+
+	previous := priceKey(*product)
+
+	applyUpdate(product, update)
+
+	// The price index entry moves with the product price
+	if err := t.tx.Bucket(pricesBucket).Delete(previous); err != nil {
+		return nil, fmt.Errorf("updating product on repository %w", err)
+	}
+
+	if err := t.tx.Bucket(pricesBucket).Put(priceKey(*product), nil); err != nil {
+		return nil, fmt.Errorf("updating product on repository %w", err)
+	}
+
+	if err := putProduct(t.tx, *product); err != nil {
+		return nil, fmt.Errorf("updating product on repository %w", err)
+	}
+
+	return product, nil
+}
+
+// Replace a product
+func (t *boltTransaction) Replace(ctx context.Context, product model.Product) (*model.Product, error) {
+	stored, err := getActiveProduct(t.tx, []byte(product.ID))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkReplace(*stored, product); err != nil {
+		return nil, err
+	}
+
+	if err := putSlugs(t.tx, *stored, product); err != nil {
+		return nil, err
+	}
+
+	prepareReplace(&product)
+
+	// The price index entry moves with the product price
+	if err := t.tx.Bucket(pricesBucket).Delete(priceKey(*stored)); err != nil {
+		return nil, fmt.Errorf("replacing product on repository %w", err)
+	}
+
+	if err := t.tx.Bucket(pricesBucket).Put(priceKey(product), nil); err != nil {
+		return nil, fmt.Errorf("replacing product on repository %w", err)
+	}
+
+	if err := putProduct(t.tx, product); err != nil {
+		return nil, fmt.Errorf("replacing product on repository %w", err)
+	}
+
+	return &product, nil
+}
+
+// Search products walking the price index, every product is decoded and matched since the total counts them all
+// Products come sorted by price, so only the requested page is kept in memory instead of every match
+func (t *boltTransaction) Search(ctx context.Context, request model.SearchRequest) (*model.Page, error) {
+	if request.Currency.Selected() {
+		return t.searchInCurrency(request)
+	}
+
+	pager := newPager(request)
+
+	cursor := t.tx.Bucket(pricesBucket).Cursor()
+
+	first, next := cursor.First, cursor.Next
+
+	if request.Sort == "desc" {
+		first, next = cursor.Last, cursor.Prev
+	}
+
+	for key, _ := first(); key != nil; key, _ = next() {
+		product, err := getProduct(t.tx, key[8:])
+		if err != nil {
+			return nil, fmt.Errorf("searching products on repository %w", err)
+		}
+
+		if matchSearch(*product, request) {
+			pager.add(*product)
+		}
+	}
+
+	return pager.page(), nil
+}
+
+// The price index holds the stored prices, so products priced on another currency are sorted in memory
+func (t *boltTransaction) searchInCurrency(request model.SearchRequest) (*model.Page, error) {
+	var products []model.Product
+
+	err := t.tx.Bucket(productsBucket).ForEach(func(key []byte, data []byte) error {
+		var product model.Product
+
+		if err := json.Unmarshal(data, &product); err != nil {
+			return fmt.Errorf("decoding product from repository %w", err)
+		}
+
+		if product.SelectCurrency(request.Currency) && matchSearch(product, request) {
+			products = append(products, product)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("searching products on repository %w", err)
+	}
+
+	sortByPrice(products, request.Sort)
+
+	pager := newPager(request)
+
+	for _, product := range products {
+		pager.add(product)
+	}
+
+	return pager.page(), nil
+}
+
+// Already running on a transaction, fn joins it
+func (t *boltTransaction) WithTransaction(ctx context.Context, fn func(txRepo Repository) error) error {
+	return fn(t)
+}
+
+func getProduct(tx *bolt.Tx, id []byte) (*model.Product, error) {
+	data := tx.Bucket(productsBucket).Get(id)
+	if data == nil {
+		return nil, internalError.ErrProductNotFound
+	}
+
+	var product model.Product
+
+	if err := json.Unmarshal(data, &product); err != nil {
+		return nil, fmt.Errorf("decoding product from repository %w", err)
+	}
+
+	return &product, nil
+}
+
+// Get a product that is not deleted
+func getActiveProduct(tx *bolt.Tx, id []byte) (*model.Product, error) {
+	product, err := getProduct(tx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if product.DeletedAt != nil {
+		return nil, internalError.ErrProductNotFound
+	}
+
+	return product, nil
+}
+
+// Move the slug index entries of the stored product to the slugs product holds, they must not be held by another product
+func putSlugs(tx *bolt.Tx, stored model.Product, product model.Product) error {
+	slugs := tx.Bucket(slugsBucket)
+
+	holder := func(slug string) string {
+		return string(slugs.Get([]byte(slug)))
+	}
+
+	if err := checkSlugs(product.ID, product.Slugs(), holder); err != nil {
+		return err
+	}
+
+	for _, slug := range stored.Slugs() {
+		if err := slugs.Delete([]byte(slug)); err != nil {
+			return fmt.Errorf("indexing product slugs on repository %w", err)
+		}
+	}
+
+	for _, slug := range product.Slugs() {
+		if err := slugs.Put([]byte(slug), []byte(product.ID)); err != nil {
+			return fmt.Errorf("indexing product slugs on repository %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Create the slug index, indexing the slugs of the products stored before it existed
+func createSlugsBucket(tx *bolt.Tx) error {
+	if tx.Bucket(slugsBucket) != nil {
+		return nil
+	}
+
+	slugs, err := tx.CreateBucket(slugsBucket)
+	if err != nil {
+		return err
+	}
+
+	return tx.Bucket(productsBucket).ForEach(func(key []byte, data []byte) error {
+		var product model.Product
+
+		if err := json.Unmarshal(data, &product); err != nil {
+			return fmt.Errorf("decoding product from repository %w", err)
+		}
+
+		for _, slug := range product.Slugs() {
+			if err := slugs.Put([]byte(slug), key); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func putProduct(tx *bolt.Tx, product model.Product) error {
+	data, err := json.Marshal(product)
+	if err != nil {
+		return fmt.Errorf("encoding product %w", err)
+	}
+
+	return tx.Bucket(productsBucket).Put([]byte(product.ID), data)
+}
+
+// Price index key, the sign bit is flipped so negative prices sort before positive ones byte by byte
+func priceKey(product model.Product) []byte {
+	key := make([]byte, 8, 8+len(product.ID))
+
+	binary.BigEndian.PutUint64(key, uint64(product.Price)^(1<<63))
+
+	return append(key, product.ID...)
+}
@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"sort"
+
+	"github.com/srodrmendz/api-product-catalog/model"
+)
+
+// Reports whether product has the requested tags, any or all of them as the request says
+// An empty list matches every product
+func matchTags(product model.Product, tags []string, match model.TagMatch) bool {
+	if len(tags) == 0 {
+		return true
+	}
+
+	for _, tag := range tags {
+		found := contains(product.Tags, tag)
+
+		if found && match != model.TagMatchAll {
+			return true
+		}
+
+		if !found && match == model.TagMatchAll {
+			return false
+		}
+	}
+
+	return match == model.TagMatchAll
+}
+
+// Count the products having each tag, sorted the same way CountTags does on MongoDB
+func countTags(products []model.Product) []model.TagCount {
+	counts := make(map[string]int64)
+
+	for _, product := range products {
+		for _, tag := range product.Tags {
+			counts[tag]++
+		}
+	}
+
+	tags := make([]model.TagCount, 0, len(counts))
+
+	for tag, count := range counts {
+		tags = append(tags, model.TagCount{Tag: tag, Count: count})
+	}
+
+	model.SortTagCounts(tags)
+
+	return tags
+}
+
+// Sort brands by name, the same way ListBrands does on MongoDB
+func sortBrands(brands []model.Brand) {
+	sort.Slice(brands, func(i, j int) bool {
+		if brands[i].Name != brands[j].Name {
+			return brands[i].Name < brands[j].Name
+		}
+
+		return brands[i].ID < brands[j].ID
+	})
+}
+
+// Copy brand so callers can not modify stored data
+func copyBrand(brand model.Brand) model.Brand {
+	if brand.Description != nil {
+		description := *brand.Description
+
+		brand.Description = &description
+	}
+
+	return brand
+}
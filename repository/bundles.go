@@ -0,0 +1,32 @@
+package repository
+
+import (
+	"github.com/srodrmendz/api-product-catalog/model"
+)
+
+// Reports whether the product is a bundle holding the component, an empty component matches every product
+func matchComponent(product model.Product, componentID string) bool {
+	if componentID == "" {
+		return true
+	}
+
+	for _, id := range product.ComponentIDs() {
+		if id == componentID {
+			return true
+		}
+	}
+
+	return false
+}
+
+func copyBundle(bundle *model.Bundle) *model.Bundle {
+	if bundle == nil {
+		return nil
+	}
+
+	copied := *bundle
+
+	copied.Components = append([]model.BundleComponent(nil), bundle.Components...)
+
+	return &copied
+}
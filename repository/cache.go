@@ -0,0 +1,515 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/srodrmendz/api-product-catalog/model"
+)
+
+// Default cache bounds, used when the config leaves them empty
+const (
+	defaultCacheTTL  = time.Minute
+	defaultCacheSize = 1000
+)
+
+// Represent cache configuration
+type CacheConfig struct {
+	// How long an entry is served before it is read again from the repository
+	TTL time.Duration
+
+	// Max entries kept for each of products, skus and search pages
+	Size int
+
+	// Cache search pages too, every write clears them
+	Search bool
+}
+
+// Represent cache usage since it was created
+type CacheStats struct {
+	Hits     uint64 `json:"hits"`
+	Misses   uint64 `json:"misses"`
+	Products int    `json:"products"`
+	Searches int    `json:"searches"`
+}
+
+// Create new read-through cache on top of repository
+func NewCache(repository Repository, config CacheConfig) *Cache {
+	if config.TTL <= 0 {
+		config.TTL = defaultCacheTTL
+	}
+
+	if config.Size <= 0 {
+		config.Size = defaultCacheSize
+	}
+
+	return &Cache{
+		repository: repository,
+		config:     config,
+		products:   newLRU(config.Size, config.TTL),
+		skus:       newLRU(config.Size, config.TTL),
+		searches:   newLRU(config.Size, config.TTL),
+	}
+}
+
+// Cache usage since it was created
+func (c *Cache) Stats() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stats := c.stats
+
+	stats.Products = c.products.len()
+
+	stats.Searches = c.searches.len()
+
+	return stats
+}
+
+// Create a new product
+func (c *Cache) Create(ctx context.Context, product model.Product) (*model.Product, error) {
+	defer c.invalidate()
+
+	return c.repository.Create(ctx, product)
+}
+
+// Get a product by id
+func (c *Cache) GetByID(ctx context.Context, id string) (*model.Product, error) {
+	c.mu.Lock()
+
+	cached, ok := c.products.get(id)
+
+	generation := c.count(ok)
+
+	c.mu.Unlock()
+
+	if ok {
+		product := copyProduct(cached.(model.Product))
+
+		return &product, nil
+	}
+
+	product, err := c.repository.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	c.putProduct(*product, generation)
+
+	return product, nil
+}
+
+// Get a product by sku
+func (c *Cache) GetBySKU(ctx context.Context, sku string) (*model.Product, error) {
+	c.mu.Lock()
+
+	var cached interface{}
+
+	id, ok := c.skus.get(sku)
+	if ok {
+		cached, ok = c.products.get(id.(string))
+	}
+
+	generation := c.count(ok)
+
+	c.mu.Unlock()
+
+	if ok {
+		product := copyProduct(cached.(model.Product))
+
+		return &product, nil
+	}
+
+	product, err := c.repository.GetBySKU(ctx, sku)
+	if err != nil {
+		return nil, err
+	}
+
+	c.putProduct(*product, generation)
+
+	return product, nil
+}
+
+// Get the product holding a slug, it is not cached since deleted products are found too
+func (c *Cache) GetBySlug(ctx context.Context, slug string) (*model.Product, error) {
+	return c.repository.GetBySlug(ctx, slug)
+}
+
+// Delete product, the cached products related to it are invalidated too since their relations to it are removed
+func (c *Cache) Delete(ctx context.Context, id string, version int64) error {
+	defer c.invalidateDeleted(id)
+
+	return c.repository.Delete(ctx, id, version)
+}
+
+// Restore a deleted product
+func (c *Cache) Restore(ctx context.Context, id string) (*model.Product, error) {
+	defer c.invalidate(id)
+
+	return c.repository.Restore(ctx, id)
+}
+
+// Permanently remove the products deleted before the given time
+func (c *Cache) Purge(ctx context.Context, before time.Time) (int64, error) {
+	defer c.clear()
+
+	return c.repository.Purge(ctx, before)
+}
+
+// List the products with a scheduled price starting or ending at the given time or before, they are never cached
+func (c *Cache) ListDuePrices(ctx context.Context, at time.Time) ([]model.Product, error) {
+	return c.repository.ListDuePrices(ctx, at)
+}
+
+// Update a product
+func (c *Cache) Update(ctx context.Context, update model.Update) (*model.Product, error) {
+	defer c.invalidate(update.ID)
+
+	return c.repository.Update(ctx, update)
+}
+
+// Replace a product
+func (c *Cache) Replace(ctx context.Context, product model.Product) (*model.Product, error) {
+	defer c.invalidate(product.ID)
+
+	return c.repository.Replace(ctx, product)
+}
+
+// Adjust the stock of a product
+func (c *Cache) AdjustStock(ctx context.Context, adjustment model.StockAdjustment) (*model.Product, error) {
+	defer c.invalidate(adjustment.ID)
+
+	return c.repository.AdjustStock(ctx, adjustment)
+}
+
+// Search products, pages are cached only when the config enables it
+func (c *Cache) Search(ctx context.Context, request model.SearchRequest) (*model.Page, error) {
+	if !c.config.Search {
+		return c.repository.Search(ctx, request)
+	}
+
+	key := searchKey(request)
+
+	c.mu.Lock()
+
+	cached, ok := c.searches.get(key)
+
+	generation := c.count(ok)
+
+	c.mu.Unlock()
+
+	if ok {
+		return copyPage(cached.(*model.Page)), nil
+	}
+
+	page, err := c.repository.Search(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if generation == c.generation {
+		c.searches.put(key, copyPage(page))
+	}
+
+	return page, nil
+}
+
+// Create a new category, categories are not cached
+func (c *Cache) CreateCategory(ctx context.Context, category model.Category) (*model.Category, error) {
+	return c.repository.CreateCategory(ctx, category)
+}
+
+// Get a category by id
+func (c *Cache) GetCategory(ctx context.Context, id string) (*model.Category, error) {
+	return c.repository.GetCategory(ctx, id)
+}
+
+// List every category sorted by name
+func (c *Cache) ListCategories(ctx context.Context) ([]model.Category, error) {
+	return c.repository.ListCategories(ctx)
+}
+
+// Get a category and all its descendants
+func (c *Cache) GetCategorySubtree(ctx context.Context, id string) ([]model.Category, error) {
+	return c.repository.GetCategorySubtree(ctx, id)
+}
+
+// Update a category, search pages are keyed by the resolved category ids so they do not need to be cleared
+func (c *Cache) UpdateCategory(ctx context.Context, category model.Category) (*model.Category, error) {
+	return c.repository.UpdateCategory(ctx, category)
+}
+
+// Delete a category
+func (c *Cache) DeleteCategory(ctx context.Context, id string) error {
+	return c.repository.DeleteCategory(ctx, id)
+}
+
+// Replace category from by to on every product, the whole cache is cleared once it returns
+func (c *Cache) ReplaceProductCategory(ctx context.Context, from string, to string) (int64, error) {
+	defer c.clear()
+
+	return c.repository.ReplaceProductCategory(ctx, from, to)
+}
+
+// Count how many of the products matching the search filters have each tag, counts are not cached
+func (c *Cache) CountTags(ctx context.Context, request model.SearchRequest) ([]model.TagCount, error) {
+	return c.repository.CountTags(ctx, request)
+}
+
+// Create a new brand, brands are not cached
+func (c *Cache) CreateBrand(ctx context.Context, brand model.Brand) (*model.Brand, error) {
+	return c.repository.CreateBrand(ctx, brand)
+}
+
+// Get a brand by id
+func (c *Cache) GetBrand(ctx context.Context, id string) (*model.Brand, error) {
+	return c.repository.GetBrand(ctx, id)
+}
+
+// List every brand sorted by name
+func (c *Cache) ListBrands(ctx context.Context) ([]model.Brand, error) {
+	return c.repository.ListBrands(ctx)
+}
+
+// Update a brand name and description, products only hold the brand id so they do not need to be cleared
+func (c *Cache) UpdateBrand(ctx context.Context, brand model.Brand) (*model.Brand, error) {
+	return c.repository.UpdateBrand(ctx, brand)
+}
+
+// Delete a brand
+func (c *Cache) DeleteBrand(ctx context.Context, id string) error {
+	return c.repository.DeleteBrand(ctx, id)
+}
+
+// Record a stock movement, movements are not cached and do not change products
+func (c *Cache) CreateStockMovement(ctx context.Context, movement model.StockMovement) (*model.StockMovement, error) {
+	return c.repository.CreateStockMovement(ctx, movement)
+}
+
+// List the stock movements of a product, newest first
+func (c *Cache) ListStockMovements(ctx context.Context, request model.MovementSearchRequest) (*model.MovementPage, error) {
+	return c.repository.ListStockMovements(ctx, request)
+}
+
+// Run fn on the wrapped repository transaction without caching, the products it writes are invalidated once it returns
+func (c *Cache) WithTransaction(ctx context.Context, fn func(txRepo Repository) error) error {
+	writes := &cacheWrites{}
+
+	defer c.invalidateWrites(writes)
+
+	return c.repository.WithTransaction(ctx, func(txRepo Repository) error {
+		return fn(&cacheTransaction{Repository: txRepo, writes: writes})
+	})
+}
+
+// Count a lookup and return the generation a miss has to match to store what it reads
+func (c *Cache) count(hit bool) uint64 {
+	if hit {
+		c.stats.Hits++
+	} else {
+		c.stats.Misses++
+	}
+
+	return c.generation
+}
+
+// Store a product read from the repository, unless the cache was invalidated while it was read
+func (c *Cache) putProduct(product model.Product, generation uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if generation != c.generation {
+		return
+	}
+
+	c.products.put(product.ID, copyProduct(product))
+
+	for _, sku := range product.SKUs() {
+		c.skus.put(sku, product.ID)
+	}
+}
+
+// Remove the products written and every search page, skus are kept since they resolve through the product id
+func (c *Cache) invalidate(ids ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.generation++
+
+	for _, id := range ids {
+		c.products.remove(id)
+	}
+
+	c.searches.clear()
+}
+
+// Remove the deleted products, the cached products related to them and every search page
+func (c *Cache) invalidateDeleted(ids ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.generation++
+
+	c.removeDeleted(ids)
+
+	c.searches.clear()
+}
+
+// Remove the entries of the writes made on a transaction, every entry when one of them can change any product
+func (c *Cache) invalidateWrites(writes *cacheWrites) {
+	if writes.all {
+		c.clear()
+
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.generation++
+
+	for _, id := range writes.ids {
+		c.products.remove(id)
+	}
+
+	c.removeDeleted(writes.deleted)
+
+	c.searches.clear()
+}
+
+// Remove the deleted products and the cached products related to them
+func (c *Cache) removeDeleted(ids []string) {
+	if len(ids) == 0 {
+		return
+	}
+
+	deleted := make(map[string]bool, len(ids))
+
+	for _, id := range ids {
+		deleted[id] = true
+
+		c.products.remove(id)
+	}
+
+	c.products.removeIf(func(value interface{}) bool {
+		for _, relation := range value.(model.Product).Relations {
+			if deleted[relation.ProductID] {
+				return true
+			}
+		}
+
+		return false
+	})
+}
+
+func (c *Cache) clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.generation++
+
+	c.products.clear()
+
+	c.skus.clear()
+
+	c.searches.clear()
+}
+
+// Create a new product on the transaction
+func (t *cacheTransaction) Create(ctx context.Context, product model.Product) (*model.Product, error) {
+	created, err := t.Repository.Create(ctx, product)
+	if err == nil {
+		t.writes.ids = append(t.writes.ids, created.ID)
+	}
+
+	return created, err
+}
+
+// Delete product on the transaction
+func (t *cacheTransaction) Delete(ctx context.Context, id string, version int64) error {
+	t.writes.deleted = append(t.writes.deleted, id)
+
+	return t.Repository.Delete(ctx, id, version)
+}
+
+// Restore a deleted product on the transaction
+func (t *cacheTransaction) Restore(ctx context.Context, id string) (*model.Product, error) {
+	t.writes.ids = append(t.writes.ids, id)
+
+	return t.Repository.Restore(ctx, id)
+}
+
+// Permanently remove the products deleted before the given time on the transaction
+func (t *cacheTransaction) Purge(ctx context.Context, before time.Time) (int64, error) {
+	t.writes.all = true
+
+	return t.Repository.Purge(ctx, before)
+}
+
+// Update a product on the transaction
+func (t *cacheTransaction) Update(ctx context.Context, update model.Update) (*model.Product, error) {
+	t.writes.ids = append(t.writes.ids, update.ID)
+
+	return t.Repository.Update(ctx, update)
+}
+
+// Replace a product on the transaction
+func (t *cacheTransaction) Replace(ctx context.Context, product model.Product) (*model.Product, error) {
+	t.writes.ids = append(t.writes.ids, product.ID)
+
+	return t.Repository.Replace(ctx, product)
+}
+
+// Adjust the stock of a product on the transaction
+func (t *cacheTransaction) AdjustStock(ctx context.Context, adjustment model.StockAdjustment) (*model.Product, error) {
+	t.writes.ids = append(t.writes.ids, adjustment.ID)
+
+	return t.Repository.AdjustStock(ctx, adjustment)
+}
+
+// Replace category from by to on every product on the transaction
+func (t *cacheTransaction) ReplaceProductCategory(ctx context.Context, from string, to string) (int64, error) {
+	t.writes.all = true
+
+	return t.Repository.ReplaceProductCategory(ctx, from, to)
+}
+
+// Already running on a transaction, fn joins it and its writes are recorded with the ones of the transaction
+func (t *cacheTransaction) WithTransaction(ctx context.Context, fn func(txRepo Repository) error) error {
+	return t.Repository.WithTransaction(ctx, func(txRepo Repository) error {
+		return fn(&cacheTransaction{Repository: txRepo, writes: t.writes})
+	})
+}
+
+// Search pages key, cursors are pointers so they are keyed by their token and visibility times by their value
+func searchKey(request model.SearchRequest) string {
+	cursor := request.Cursor.String()
+
+	var visibleAt time.Time
+
+	if request.VisibleAt != nil {
+		visibleAt = *request.VisibleAt
+	}
+
+	request.Cursor, request.VisibleAt = nil, nil
+
+	return fmt.Sprintf("%+v %s %d", request, cursor, visibleAt.UnixNano())
+}
+
+func copyPage(page *model.Page) *model.Page {
+	copied := *page
+
+	if page.Products != nil {
+		copied.Products = make([]model.Product, len(page.Products))
+	}
+
+	for i, product := range page.Products {
+		copied.Products[i] = copyProduct(product)
+	}
+
+	return &copied
+}
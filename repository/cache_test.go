@@ -0,0 +1,190 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+
+	internalError "github.com/srodrmendz/api-product-catalog/errors"
+	"github.com/srodrmendz/api-product-catalog/model"
+	"github.com/srodrmendz/api-product-catalog/repository"
+	"github.com/srodrmendz/api-product-catalog/repository/repotest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test Cache on top of Memory Repository against the repository contract
+func TestCache(t *testing.T) {
+	repotest.Run(t, func(t *testing.T) repository.Repository {
+		return repository.NewCache(repository.NewMemory(), repository.CacheConfig{Search: true})
+	})
+}
+
+func TestCache_Stats(t *testing.T) {
+	t.Run("successfully serve repeated reads from cache", func(t *testing.T) {
+		// Given
+		ctx := context.TODO()
+
+		cache := repository.NewCache(repository.NewMemory(), repository.CacheConfig{Search: true})
+
+		product, err := cache.Create(ctx, model.Product{Qty: 10, Name: "product 1", Sku: "sku1"})
+
+		require.NoError(t, err)
+
+		// When
+		_, err = cache.GetByID(ctx, product.ID)
+
+		require.NoError(t, err)
+
+		_, err = cache.GetByID(ctx, product.ID)
+
+		require.NoError(t, err)
+
+		_, err = cache.GetBySKU(ctx, product.Sku)
+
+		require.NoError(t, err)
+
+		_, err = cache.Search(ctx, model.SearchRequest{InStock: true})
+
+		require.NoError(t, err)
+
+		_, err = cache.Search(ctx, model.SearchRequest{InStock: true})
+
+		require.NoError(t, err)
+
+		// Then
+		assert.Equal(t, repository.CacheStats{Hits: 3, Misses: 2, Products: 1, Searches: 1}, cache.Stats())
+	})
+
+	t.Run("successfully read again products and searches after a write", func(t *testing.T) {
+		// Given
+		ctx := context.TODO()
+
+		cache := repository.NewCache(repository.NewMemory(), repository.CacheConfig{Search: true})
+
+		product, err := cache.Create(ctx, model.Product{Qty: 10, Name: "product 1", Sku: "sku1"})
+
+		require.NoError(t, err)
+
+		_, err = cache.GetBySKU(ctx, product.Sku)
+
+		require.NoError(t, err)
+
+		_, err = cache.Search(ctx, model.SearchRequest{InStock: true})
+
+		require.NoError(t, err)
+
+		// When
+		qty := uint64(0)
+
+		_, err = cache.Update(ctx, model.Update{ID: product.ID, UpdateRequest: model.UpdateRequest{Qty: &qty}})
+
+		require.NoError(t, err)
+
+		stored, err := cache.GetBySKU(ctx, product.Sku)
+
+		require.NoError(t, err)
+
+		page, err := cache.Search(ctx, model.SearchRequest{InStock: true})
+
+		require.NoError(t, err)
+
+		// Then
+		assert.Equal(t, false, stored.InStock)
+
+		assert.Empty(t, page.Products)
+
+		assert.Equal(t, uint64(0), cache.Stats().Hits)
+
+		require.NoError(t, cache.Delete(ctx, product.ID, 0))
+
+		_, err = cache.GetByID(ctx, product.ID)
+
+		assert.ErrorIs(t, err, internalError.ErrProductNotFound)
+	})
+
+	t.Run("successfully keep serving the products a write does not change", func(t *testing.T) {
+		// Given
+		ctx := context.TODO()
+
+		cache := repository.NewCache(repository.NewMemory(), repository.CacheConfig{Search: true})
+
+		written, err := cache.Create(ctx, model.Product{Qty: 10, Name: "product 1", Sku: "sku1"})
+
+		require.NoError(t, err)
+
+		untouched, err := cache.Create(ctx, model.Product{Qty: 10, Name: "product 2", Sku: "sku2"})
+
+		require.NoError(t, err)
+
+		related, err := cache.Create(ctx, model.Product{
+			Qty:       10,
+			Name:      "product 3",
+			Sku:       "sku3",
+			Relations: []model.Relation{{ProductID: written.ID, Type: model.RelationAccessory}},
+		})
+
+		require.NoError(t, err)
+
+		for _, id := range []string{written.ID, untouched.ID, related.ID} {
+			_, err = cache.GetByID(ctx, id)
+
+			require.NoError(t, err)
+		}
+
+		// When
+		err = cache.WithTransaction(ctx, func(txRepo repository.Repository) error {
+			qty := uint64(5)
+
+			_, err := txRepo.Update(ctx, model.Update{ID: written.ID, UpdateRequest: model.UpdateRequest{Qty: &qty}})
+
+			return err
+		})
+
+		require.NoError(t, err)
+
+		stored, err := cache.GetByID(ctx, written.ID)
+
+		require.NoError(t, err)
+
+		_, err = cache.GetByID(ctx, untouched.ID)
+
+		require.NoError(t, err)
+
+		// Then
+		assert.Equal(t, uint64(5), stored.Qty)
+
+		assert.Equal(t, repository.CacheStats{Hits: 1, Misses: 4, Products: 3}, cache.Stats())
+
+		// Deleting a product invalidates the cached products related to it
+		require.NoError(t, cache.Delete(ctx, written.ID, 0))
+
+		stored, err = cache.GetByID(ctx, related.ID)
+
+		require.NoError(t, err)
+
+		_, err = cache.GetByID(ctx, untouched.ID)
+
+		require.NoError(t, err)
+
+		assert.Empty(t, stored.Relations)
+
+		assert.Equal(t, repository.CacheStats{Hits: 2, Misses: 5, Products: 2}, cache.Stats())
+	})
+
+	t.Run("successfully skip search cache when it is disabled", func(t *testing.T) {
+		// Given
+		ctx := context.TODO()
+
+		cache := repository.NewCache(repository.NewMemory(), repository.CacheConfig{})
+
+		// When
+		for i := 0; i < 2; i++ {
+			_, err := cache.Search(ctx, model.SearchRequest{InStock: true})
+
+			require.NoError(t, err)
+		}
+
+		// Then
+		assert.Equal(t, repository.CacheStats{}, cache.Stats())
+	})
+}
@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"sort"
+
+	"github.com/srodrmendz/api-product-catalog/model"
+)
+
+// Reports whether category is id or one of its descendants
+func inSubtree(category model.Category, id string) bool {
+	return category.ID == id || contains(category.Path, id)
+}
+
+// Path of a descendant of moved once moved is placed on its new path
+func movedPath(path []string, moved model.Category) []string {
+	for i, id := range path {
+		if id == moved.ID {
+			return append(append([]string{}, moved.Path...), path[i:]...)
+		}
+	}
+
+	return path
+}
+
+// Replace category from by to on product category ids, an empty to removes it
+// Reports whether ids changed
+func replaceCategory(ids []string, from string, to string) ([]string, bool) {
+	if !contains(ids, from) {
+		return ids, false
+	}
+
+	var replaced []string
+
+	for _, id := range ids {
+		if id == from {
+			id = to
+		}
+
+		if id == "" || contains(replaced, id) {
+			continue
+		}
+
+		replaced = append(replaced, id)
+	}
+
+	return replaced, true
+}
+
+// Reports whether product is on any of the categories, an empty list matches every product
+func matchCategories(product model.Product, ids []string) bool {
+	if len(ids) == 0 {
+		return true
+	}
+
+	for _, id := range product.CategoryIDs {
+		if contains(ids, id) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Sort categories by name, the same way ListCategories does on MongoDB
+func sortCategories(categories []model.Category) {
+	sort.Slice(categories, func(i, j int) bool {
+		if categories[i].Name != categories[j].Name {
+			return categories[i].Name < categories[j].Name
+		}
+
+		return categories[i].ID < categories[j].ID
+	})
+}
+
+// Copy category so callers can not modify stored data
+func copyCategory(category model.Category) model.Category {
+	category.Path = append([]string{}, category.Path...)
+
+	if category.Attributes != nil {
+		attributes := make([]model.AttributeDefinition, len(category.Attributes))
+
+		for i, attribute := range category.Attributes {
+			attribute.Values = append([]string(nil), attribute.Values...)
+
+			attributes[i] = attribute
+		}
+
+		category.Attributes = attributes
+	}
+
+	return category
+}
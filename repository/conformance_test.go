@@ -0,0 +1,49 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/srodrmendz/api-product-catalog/repository"
+	"github.com/srodrmendz/api-product-catalog/repository/repotest"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Test MongoDB Repository against the repository contract, every test runs on its own collection
+func TestRepository_Integration_Conformance(t *testing.T) {
+	ctx := context.TODO()
+
+	clientOptions := options.Client().ApplyURI("mongodb://localhost:27017")
+
+	client, err := mongo.Connect(ctx, clientOptions)
+	if err != nil {
+		t.Fatalf("connecting mongo %s", err)
+	}
+
+	defer client.Disconnect(ctx)
+
+	if err := client.Ping(ctx, nil); err != nil {
+		t.Fatalf("connecting mongo %s", err)
+	}
+
+	repotest.Run(t, func(t *testing.T) repository.Repository {
+		collection := uuid.NewString()
+
+		// The repository stores categories, brands and stock movements on collections named after the products one
+		t.Cleanup(func() {
+			for _, suffix := range []string{"", "_categories", "_brands", "_stock_movements"} {
+				client.Database("ecommerce_test").Collection(collection + suffix).Drop(ctx)
+			}
+		})
+
+		repo := repository.New(client, "ecommerce_test", collection)
+
+		if _, err := repo.SyncIndexes(ctx); err != nil {
+			t.Fatalf("creating indexes %s", err)
+		}
+
+		return repo
+	})
+}
@@ -0,0 +1,338 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Represent the differences between the declared indexes and the ones found on the collection
+type IndexReport struct {
+	// Declared indexes not found on the collection
+	Missing []string `json:"missing"`
+
+	// Declared indexes found with a different definition
+	Changed []string `json:"changed"`
+
+	// Indexes found on the collection that are not declared, they are never dropped
+	Extra []string `json:"extra"`
+}
+
+// Reports whether the collection indexes differ from the declared ones
+func (r IndexReport) HasDrift() bool {
+	return len(r.Missing) > 0 || len(r.Changed) > 0 || len(r.Extra) > 0
+}
+
+func (r IndexReport) String() string {
+	if !r.HasDrift() {
+		return "indexes in sync"
+	}
+
+	return fmt.Sprintf(
+		"missing: [%s] changed: [%s] extra: [%s]",
+		strings.Join(r.Missing, ", "),
+		strings.Join(r.Changed, ", "),
+		strings.Join(r.Extra, ", "),
+	)
+}
+
+// Indexes required by the repository queries
+func (r *ProductsCatalogRepository) Indexes() []mongo.IndexModel {
+	return []mongo.IndexModel{
+		// Create rejects duplicated product and variant skus with this index, get by sku uses it too
+		{
+			Keys:    bson.D{{Key: "skus", Value: 1}},
+			Options: options.Index().SetName("sku_unique").SetUnique(true),
+		},
+		// Used by the $text search filter, translations are indexed with the language on their language field
+		{
+			Keys: bson.D{
+				{Key: "name", Value: "text"},
+				{Key: "description", Value: "text"},
+				{Key: "translations.name", Value: "text"},
+				{Key: "translations.description", Value: "text"},
+			},
+			Options: options.Index().SetName("name_description_text"),
+		},
+		// Used by search to filter by in_stock and sort by price, on both directions
+		{
+			Keys:    bson.D{{Key: "in_stock", Value: 1}, {Key: "price", Value: 1}, {Key: "_id", Value: 1}},
+			Options: options.Index().SetName("in_stock_price"),
+		},
+		// Used by purge to find the products deleted before the retention period
+		{
+			Keys:    bson.D{{Key: "deleted_at", Value: 1}},
+			Options: options.Index().SetName("deleted_at"),
+		},
+		// Used by the search category filter and to move products off a deleted category
+		{
+			Keys:    bson.D{{Key: "category_ids", Value: 1}},
+			Options: options.Index().SetName("category_ids"),
+		},
+		// Used by the search brand filter
+		{
+			Keys:    bson.D{{Key: "brand_id", Value: 1}},
+			Options: options.Index().SetName("brand_id"),
+		},
+		// Used by the search tags filter
+		{
+			Keys:    bson.D{{Key: "tags", Value: 1}},
+			Options: options.Index().SetName("tags"),
+		},
+		// Used to find the products with a scheduled price starting or ending
+		{
+			Keys:    bson.D{{Key: "price_changes_at", Value: 1}},
+			Options: options.Index().SetName("price_changes_at"),
+		},
+		// Used to find the bundles holding a product
+		{
+			Keys:    bson.D{{Key: "bundle.components.product_id", Value: 1}},
+			Options: options.Index().SetName("bundle_components"),
+		},
+		// Used to remove the relations to a deleted product
+		{
+			Keys:    bson.D{{Key: "relations.product_id", Value: 1}},
+			Options: options.Index().SetName("relations"),
+		},
+		// Rejects a slug already held by another product, get by slug uses it too
+		// Sparse since products without slugs do not store the field
+		{
+			Keys:    bson.D{{Key: "slugs", Value: 1}},
+			Options: options.Index().SetName(slugsIndexName).SetUnique(true).SetSparse(true),
+		},
+	}
+}
+
+// Index used to list the stock movements of a product newest first, it lives on the stock movements collection
+func (r *ProductsCatalogRepository) movementsIndex() mongo.IndexModel {
+	return mongo.IndexModel{
+		Keys:    bson.D{{Key: "product_id", Value: 1}, {Key: "created_at", Value: -1}},
+		Options: options.Index().SetName("product_created_at"),
+	}
+}
+
+// Compare the declared indexes with the ones found on the collection
+func (r *ProductsCatalogRepository) CheckIndexes(ctx context.Context) (*IndexReport, error) {
+	existing, err := r.listIndexes(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	report := diffIndexes(r.Indexes(), existing)
+
+	return &report, nil
+}
+
+// Create missing indexes and recreate the changed ones, returns the drift found before applying them
+// The stock movements collection index is created too, it is not part of the drift report
+func (r *ProductsCatalogRepository) SyncIndexes(ctx context.Context) (*IndexReport, error) {
+	report, err := r.CheckIndexes(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.backfillSKUs(ctx); err != nil {
+		return nil, err
+	}
+
+	for _, name := range report.Changed {
+		if _, err := r.collection.Indexes().DropOne(ctx, name); err != nil {
+			return nil, fmt.Errorf("dropping index %s %w", name, err)
+		}
+	}
+
+	for _, index := range r.Indexes() {
+		name := *index.Options.Name
+
+		if !contains(report.Missing, name) && !contains(report.Changed, name) {
+			continue
+		}
+
+		if _, err := r.collection.Indexes().CreateOne(ctx, index); err != nil {
+			return nil, fmt.Errorf("creating index %s %w", name, err)
+		}
+	}
+
+	// Creating an index that already exists with the same definition is a no-op
+	if _, err := r.movements.Indexes().CreateOne(ctx, r.movementsIndex()); err != nil {
+		return nil, fmt.Errorf("creating stock movements index %w", err)
+	}
+
+	return report, nil
+}
+
+// Products created before variants have no skus, without them they can not be found by sku and would break the unique index
+func (r *ProductsCatalogRepository) backfillSKUs(ctx context.Context) error {
+	update := mongo.Pipeline{{{Key: "$set", Value: bson.M{"skus": bson.A{"$sku"}}}}}
+
+	if _, err := r.collection.UpdateMany(ctx, bson.M{"skus": bson.M{"$exists": false}}, update); err != nil {
+		return fmt.Errorf("backfilling product skus %w", err)
+	}
+
+	return nil
+}
+
+func (r *ProductsCatalogRepository) listIndexes(ctx context.Context) ([]indexDocument, error) {
+	cursor, err := r.collection.Indexes().List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("listing indexes %w", err)
+	}
+
+	var indexes []indexDocument
+
+	if err := cursor.All(ctx, &indexes); err != nil {
+		return nil, fmt.Errorf("decoding indexes %w", err)
+	}
+
+	return indexes, nil
+}
+
+// Compare declared indexes with the index documents returned by listIndexes.
+// An existing index with the same definition but another name satisfies the declared one
+func diffIndexes(declared []mongo.IndexModel, existing []indexDocument) IndexReport {
+	var report IndexReport
+
+	existingByName := make(map[string]string)
+
+	existingBySpec := make(map[string]string)
+
+	for _, index := range existing {
+		if index.Name == "_id_" {
+			continue
+		}
+
+		spec := existingIndexSpec(index)
+
+		existingByName[index.Name] = spec
+
+		existingBySpec[spec] = index.Name
+	}
+
+	used := make(map[string]bool)
+
+	for _, index := range declared {
+		name := *index.Options.Name
+
+		spec := declaredIndexSpec(index)
+
+		if existingName, ok := existingBySpec[spec]; ok {
+			used[existingName] = true
+
+			continue
+		}
+
+		if _, ok := existingByName[name]; ok {
+			used[name] = true
+
+			report.Changed = append(report.Changed, name)
+
+			continue
+		}
+
+		report.Missing = append(report.Missing, name)
+	}
+
+	for name := range existingByName {
+		if !used[name] {
+			report.Extra = append(report.Extra, name)
+		}
+	}
+
+	sort.Strings(report.Extra)
+
+	return report
+}
+
+// Normalized definition of a declared index, comparable with existingIndexSpec
+func declaredIndexSpec(index mongo.IndexModel) string {
+	var keys []string
+
+	var textFields []string
+
+	for _, key := range index.Keys.(bson.D) {
+		if key.Value == "text" {
+			textFields = append(textFields, key.Key)
+
+			continue
+		}
+
+		keys = append(keys, fmt.Sprintf("%s:%v", key.Key, key.Value))
+	}
+
+	unique := index.Options.Unique != nil && *index.Options.Unique
+
+	sparse := index.Options.Sparse != nil && *index.Options.Sparse
+
+	return indexSpec(keys, textFields, unique, sparse)
+}
+
+// Normalized definition of an index document returned by listIndexes, text indexes list their fields on weights
+func existingIndexSpec(index indexDocument) string {
+	var keys []string
+
+	var textFields []string
+
+	for _, key := range index.Key {
+		if key.Key == "_fts" || key.Key == "_ftsx" {
+			continue
+		}
+
+		keys = append(keys, fmt.Sprintf("%s:%v", key.Key, normalizeIndexValue(key.Value)))
+	}
+
+	for field := range index.Weights {
+		textFields = append(textFields, field)
+	}
+
+	return indexSpec(keys, textFields, index.Unique, index.Sparse)
+}
+
+// Key order is kept since it defines compound indexes, text fields are compared as a set
+func indexSpec(keys []string, textFields []string, unique bool, sparse bool) string {
+	sort.Strings(textFields)
+
+	spec := strings.Join(keys, ",")
+
+	if len(textFields) > 0 {
+		spec += " text:" + strings.Join(textFields, ",")
+	}
+
+	if unique {
+		spec += " unique"
+	}
+
+	if sparse {
+		spec += " sparse"
+	}
+
+	return spec
+}
+
+// Index directions are stored as int32, int64 or double depending on the client that created them
+func normalizeIndexValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case int32:
+		return int(v)
+	case int64:
+		return int(v)
+	case float64:
+		return int(v)
+	default:
+		return v
+	}
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,198 @@
+package repository
+
+import (
+	"errors"
+	"testing"
+
+	internalErrors "github.com/srodrmendz/api-product-catalog/errors"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Test drift detection between declared and existing indexes
+func TestRepository_DiffIndexes(t *testing.T) {
+	declared := (&ProductsCatalogRepository{}).Indexes()
+
+	inSync := []indexDocument{
+		{Name: "_id_", Key: bson.D{{Key: "_id", Value: int32(1)}}},
+		{Name: "sku_unique", Key: bson.D{{Key: "skus", Value: int32(1)}}, Unique: true},
+		{
+			Name:    "name_description_text",
+			Key:     bson.D{{Key: "_fts", Value: "text"}, {Key: "_ftsx", Value: int32(1)}},
+			Weights: bson.M{"description": int32(1), "name": int32(1), "translations.description": int32(1), "translations.name": int32(1)},
+		},
+		{Name: "in_stock_price", Key: bson.D{{Key: "in_stock", Value: int32(1)}, {Key: "price", Value: int32(1)}, {Key: "_id", Value: int32(1)}}},
+		{Name: "deleted_at", Key: bson.D{{Key: "deleted_at", Value: int32(1)}}},
+		{Name: "category_ids", Key: bson.D{{Key: "category_ids", Value: int32(1)}}},
+		{Name: "brand_id", Key: bson.D{{Key: "brand_id", Value: int32(1)}}},
+		{Name: "tags", Key: bson.D{{Key: "tags", Value: int32(1)}}},
+		{Name: "price_changes_at", Key: bson.D{{Key: "price_changes_at", Value: int32(1)}}},
+		{Name: "bundle_components", Key: bson.D{{Key: "bundle.components.product_id", Value: int32(1)}}},
+		{Name: "relations", Key: bson.D{{Key: "relations.product_id", Value: int32(1)}}},
+		{Name: "slugs", Key: bson.D{{Key: "slugs", Value: int32(1)}}, Unique: true, Sparse: true},
+	}
+
+	dataTable := []struct {
+		name           string
+		existing       []indexDocument
+		expectedReport IndexReport
+	}{
+		{
+			name:           "indexes in sync",
+			existing:       inSync,
+			expectedReport: IndexReport{},
+		},
+		{
+			name:     "fresh collection misses every index",
+			existing: inSync[:1],
+			expectedReport: IndexReport{
+				Missing: []string{"sku_unique", "name_description_text", "in_stock_price", "deleted_at", "category_ids", "brand_id", "tags", "price_changes_at", "bundle_components", "relations", "slugs"},
+			},
+		},
+		{
+			name: "same definition with another name is not drift",
+			existing: append(
+				[]indexDocument{{Name: "skus_1", Key: bson.D{{Key: "skus", Value: float64(1)}}, Unique: true}},
+				inSync[2:]...,
+			),
+			expectedReport: IndexReport{},
+		},
+		{
+			name: "sku index without unique constraint changed",
+			existing: append(
+				[]indexDocument{{Name: "sku_unique", Key: bson.D{{Key: "skus", Value: int32(1)}}}},
+				inSync[2:]...,
+			),
+			expectedReport: IndexReport{
+				Changed: []string{"sku_unique"},
+			},
+		},
+		{
+			name: "sku index without variant skus changed",
+			existing: append(
+				[]indexDocument{{Name: "sku_unique", Key: bson.D{{Key: "sku", Value: int32(1)}}, Unique: true}},
+				inSync[2:]...,
+			),
+			expectedReport: IndexReport{
+				Changed: []string{"sku_unique"},
+			},
+		},
+		{
+			name: "text index only on name changed",
+			existing: []indexDocument{
+				inSync[1],
+				{
+					Name:    "name_description_text",
+					Key:     bson.D{{Key: "_fts", Value: "text"}, {Key: "_ftsx", Value: int32(1)}},
+					Weights: bson.M{"name": int32(1)},
+				},
+				inSync[3],
+				inSync[4],
+				inSync[5],
+				inSync[6],
+				inSync[7],
+				inSync[8],
+				inSync[9],
+				inSync[10],
+				inSync[11],
+			},
+			expectedReport: IndexReport{
+				Changed: []string{"name_description_text"},
+			},
+		},
+		{
+			name: "compound index keys in another order changed",
+			existing: []indexDocument{
+				inSync[1],
+				inSync[2],
+				{Name: "in_stock_price", Key: bson.D{{Key: "price", Value: int32(1)}, {Key: "in_stock", Value: int32(1)}, {Key: "_id", Value: int32(1)}}},
+				inSync[4],
+				inSync[5],
+				inSync[6],
+				inSync[7],
+				inSync[8],
+				inSync[9],
+				inSync[10],
+				inSync[11],
+			},
+			expectedReport: IndexReport{
+				Changed: []string{"in_stock_price"},
+			},
+		},
+		{
+			name: "slugs index without unique constraint changed",
+			existing: append(
+				append([]indexDocument{}, inSync[:11]...),
+				indexDocument{Name: "slugs", Key: bson.D{{Key: "slugs", Value: int32(1)}}},
+			),
+			expectedReport: IndexReport{
+				Changed: []string{"slugs"},
+			},
+		},
+		{
+			name: "undeclared indexes are extra",
+			existing: append(
+				append([]indexDocument{}, inSync...),
+				indexDocument{Name: "qty_1", Key: bson.D{{Key: "qty", Value: int32(1)}}},
+			),
+			expectedReport: IndexReport{
+				Extra: []string{"qty_1"},
+			},
+		},
+	}
+
+	for _, dt := range dataTable {
+		t.Run(dt.name, func(t *testing.T) {
+			// When
+			report := diffIndexes(declared, dt.existing)
+
+			// Then
+			assert.Equal(t, dt.expectedReport, report)
+
+			assert.Equal(t, dt.expectedReport.HasDrift(), report.HasDrift())
+		})
+	}
+}
+
+// Test duplicated key errors are told apart by the unique index they violate
+func TestRepository_GetDuplicatedKeyError(t *testing.T) {
+	duplicated := func(message string) error {
+		return mongo.WriteException{WriteErrors: mongo.WriteErrors{{Code: mongoDBDuplicatedKeyErrorCode, Message: message}}}
+	}
+
+	dataTable := []struct {
+		name          string
+		err           error
+		expectedError error
+	}{
+		{
+			name:          "duplicated slug",
+			err:           duplicated(`E11000 duplicate key error collection: ecommerce.products index: slugs dup key: { slugs: "shoes" }`),
+			expectedError: internalErrors.ErrSlugAlreadyExist,
+		},
+		{
+			name:          "duplicated sku",
+			err:           duplicated(`E11000 duplicate key error collection: ecommerce.products index: sku_unique dup key: { skus: "shoes" }`),
+			expectedError: internalErrors.ErrProductSKUAlreadyExist,
+		},
+		{
+			name: "other write error",
+			err:  mongo.WriteException{WriteErrors: mongo.WriteErrors{{Code: 2, Message: "bad value"}}},
+		},
+		{
+			name: "not a write error",
+			err:  errors.New("connection refused"),
+		},
+	}
+
+	for _, dt := range dataTable {
+		t.Run(dt.name, func(t *testing.T) {
+			// When
+			err := getDuplicatedKeyError(dt.err)
+
+			// Then
+			assert.Equal(t, dt.expectedError, err)
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"container/list"
+	"time"
+)
+
+// Least recently used entries that expire after ttl, not safe for concurrent use
+type lru struct {
+	size    int
+	ttl     time.Duration
+	now     func() time.Time
+	entries map[string]*list.Element
+	order   *list.List
+}
+
+type lruEntry struct {
+	key       string
+	value     interface{}
+	expiresAt time.Time
+}
+
+func newLRU(size int, ttl time.Duration) *lru {
+	return &lru{
+		size:    size,
+		ttl:     ttl,
+		now:     time.Now,
+		entries: make(map[string]*list.Element),
+		order:   list.New(),
+	}
+}
+
+// Get the value stored on key, expired entries are removed and reported as missing
+func (c *lru) get(key string) (interface{}, bool) {
+	element, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+
+	entry := element.Value.(*lruEntry)
+
+	if !c.now().Before(entry.expiresAt) {
+		c.removeElement(element)
+
+		return nil, false
+	}
+
+	c.order.MoveToFront(element)
+
+	return entry.value, true
+}
+
+// Store value on key, the least recently used entry is evicted when the size is exceeded
+func (c *lru) put(key string, value interface{}) {
+	expiresAt := c.now().Add(c.ttl)
+
+	if element, ok := c.entries[key]; ok {
+		entry := element.Value.(*lruEntry)
+
+		entry.value, entry.expiresAt = value, expiresAt
+
+		c.order.MoveToFront(element)
+
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(&lruEntry{
+		key:       key,
+		value:     value,
+		expiresAt: expiresAt,
+	})
+
+	if c.order.Len() > c.size {
+		c.removeElement(c.order.Back())
+	}
+}
+
+func (c *lru) remove(key string) {
+	if element, ok := c.entries[key]; ok {
+		c.removeElement(element)
+	}
+}
+
+// Remove every entry whose value matches
+func (c *lru) removeIf(match func(value interface{}) bool) {
+	for element := c.order.Front(); element != nil; {
+		next := element.Next()
+
+		if match(element.Value.(*lruEntry).value) {
+			c.removeElement(element)
+		}
+
+		element = next
+	}
+}
+
+func (c *lru) clear() {
+	c.entries = make(map[string]*list.Element)
+
+	c.order.Init()
+}
+
+func (c *lru) len() int {
+	return c.order.Len()
+}
+
+func (c *lru) removeElement(element *list.Element) {
+	c.order.Remove(element)
+
+	delete(c.entries, element.Value.(*lruEntry).key)
+}
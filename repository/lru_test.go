@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Test eviction of least recently used and expired entries
+func TestRepository_LRU(t *testing.T) {
+	t.Run("evict least recently used entry when size is exceeded", func(t *testing.T) {
+		// Given
+		cache := newLRU(2, time.Minute)
+
+		cache.put("a", 1)
+
+		cache.put("b", 2)
+
+		cache.get("a")
+
+		// When
+		cache.put("c", 3)
+
+		// Then
+		_, ok := cache.get("b")
+
+		assert.False(t, ok)
+
+		value, ok := cache.get("a")
+
+		assert.True(t, ok)
+
+		assert.Equal(t, 1, value)
+
+		assert.Equal(t, 2, cache.len())
+	})
+
+	t.Run("expire entries after ttl", func(t *testing.T) {
+		// Given
+		now := time.Now()
+
+		cache := newLRU(2, time.Minute)
+
+		cache.now = func() time.Time { return now }
+
+		cache.put("a", 1)
+
+		// When
+		now = now.Add(time.Minute)
+
+		// Then
+		_, ok := cache.get("a")
+
+		assert.False(t, ok)
+
+		assert.Equal(t, 0, cache.len())
+	})
+
+	t.Run("refresh ttl when entry is stored again", func(t *testing.T) {
+		// Given
+		now := time.Now()
+
+		cache := newLRU(2, time.Minute)
+
+		cache.now = func() time.Time { return now }
+
+		cache.put("a", 1)
+
+		now = now.Add(30 * time.Second)
+
+		cache.put("a", 2)
+
+		// When
+		now = now.Add(45 * time.Second)
+
+		// Then
+		value, ok := cache.get("a")
+
+		assert.True(t, ok)
+
+		assert.Equal(t, 2, value)
+	})
+}
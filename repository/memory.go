@@ -0,0 +1,516 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	internalError "github.com/srodrmendz/api-product-catalog/errors"
+	"github.com/srodrmendz/api-product-catalog/model"
+)
+
+// Create new in memory product repository
+func NewMemory() *Memory {
+	return &Memory{
+		products:   make(map[string]model.Product),
+		skus:       make(map[string]string),
+		slugs:      make(map[string]string),
+		categories: make(map[string]model.Category),
+		brands:     make(map[string]model.Brand),
+	}
+}
+
+// Create a new product
+func (r *Memory) Create(ctx context.Context, product model.Product) (*model.Product, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, sku := range product.SKUs() {
+		if _, ok := r.skus[sku]; ok {
+			return nil, internalError.ErrProductSKUAlreadyExist
+		}
+	}
+
+	if err := checkSlugs("", product.Slugs(), r.slugHolder); err != nil {
+		return nil, err
+	}
+
+	product.ID = uuid.NewString()
+
+	now := time.Now()
+
+	product.CreatedAt = now
+
+	product.UpdatedAt = now
+
+	product.InStock = product.Qty > 0
+
+	product.Version = 1
+
+	setEntry(r, r.products, product.ID, copyProduct(product))
+
+	for _, sku := range product.SKUs() {
+		setEntry(r, r.skus, sku, product.ID)
+	}
+
+	for _, slug := range product.Slugs() {
+		setEntry(r, r.slugs, slug, product.ID)
+	}
+
+	return &product, nil
+}
+
+// Get a product by id
+func (r *Memory) GetByID(ctx context.Context, id string) (*model.Product, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	product, ok := r.products[id]
+	if !ok || product.DeletedAt != nil {
+		return nil, internalError.ErrProductNotFound
+	}
+
+	product = copyProduct(product)
+
+	return &product, nil
+}
+
+// Get a product by sku
+func (r *Memory) GetBySKU(ctx context.Context, sku string) (*model.Product, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	id, ok := r.skus[sku]
+	if !ok || r.products[id].DeletedAt != nil {
+		return nil, internalError.ErrProductNotFound
+	}
+
+	product := copyProduct(r.products[id])
+
+	return &product, nil
+}
+
+// Get the product holding a slug, deleted products included
+func (r *Memory) GetBySlug(ctx context.Context, slug string) (*model.Product, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	id, ok := r.slugs[slug]
+	if !ok {
+		return nil, internalError.ErrProductNotFound
+	}
+
+	product := copyProduct(r.products[id])
+
+	return &product, nil
+}
+
+// Id of the product holding a slug, empty when no product holds it
+func (r *Memory) slugHolder(slug string) string {
+	return r.slugs[slug]
+}
+
+// Delete product, it is kept until purged so it can be restored
+func (r *Memory) Delete(ctx context.Context, id string, version int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	product, ok := r.products[id]
+	if !ok || product.DeletedAt != nil {
+		return internalError.ErrProductNotFound
+	}
+
+	if err := checkVersion(product, version); err != nil {
+		return err
+	}
+
+	markDeleted(&product)
+
+	setEntry(r, r.products, id, product)
+
+	// Relations are copied before they change, so products read before keep theirs
+	for relatedID, related := range r.products {
+		related = copyProduct(related)
+
+		if markUnlinked(&related, id) {
+			setEntry(r, r.products, relatedID, related)
+		}
+	}
+
+	return nil
+}
+
+// Restore a deleted product, restoring a product that is not deleted returns it unchanged
+func (r *Memory) Restore(ctx context.Context, id string) (*model.Product, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	product, ok := r.products[id]
+	if !ok {
+		return nil, internalError.ErrProductNotFound
+	}
+
+	if product.DeletedAt != nil {
+		markRestored(&product)
+
+		setEntry(r, r.products, id, product)
+	}
+
+	product = copyProduct(product)
+
+	return &product, nil
+}
+
+// Permanently remove the products deleted before the given time
+func (r *Memory) Purge(ctx context.Context, before time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var purged int64
+
+	for id, product := range r.products {
+		if product.DeletedAt == nil || !product.DeletedAt.Before(before) {
+			continue
+		}
+
+		for _, sku := range product.SKUs() {
+			deleteEntry(r, r.skus, sku)
+		}
+
+		for _, slug := range product.Slugs() {
+			deleteEntry(r, r.slugs, slug)
+		}
+
+		deleteEntry(r, r.products, id)
+
+		purged++
+	}
+
+	return purged, nil
+}
+
+// List the products with a scheduled price starting or ending at the given time or before
+func (r *Memory) ListDuePrices(ctx context.Context, at time.Time) ([]model.Product, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var products []model.Product
+
+	for _, product := range r.products {
+		if product.DeletedAt == nil && isPriceDue(product, at) {
+			products = append(products, copyProduct(product))
+		}
+	}
+
+	return products, nil
+}
+
+// Update a product
+func (r *Memory) Update(ctx context.Context, update model.Update) (*model.Product, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	product, ok := r.products[update.ID]
+	if !ok || product.DeletedAt != nil {
+		return nil, internalError.ErrProductNotFound
+	}
+
+	if err := checkUpdate(product, update); err != nil {
+		return nil, err
+	}
+
+	// Variants are shared with the stored product, so they are copied before being updated
+	product = copyProduct(product)
+
+	applyUpdate(&product, update)
+
+	setEntry(r, r.products, update.ID, product)
+
+	product = copyProduct(product)
+
+	return &product, nil
+}
+
+// Replace a product
+func (r *Memory) Replace(ctx context.Context, product model.Product) (*model.Product, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.products[product.ID]
+	if !ok || stored.DeletedAt != nil {
+		return nil, internalError.ErrProductNotFound
+	}
+
+	if err := checkReplace(stored, product); err != nil {
+		return nil, err
+	}
+
+	if err := checkSlugs(product.ID, product.Slugs(), r.slugHolder); err != nil {
+		return nil, err
+	}
+
+	prepareReplace(&product)
+
+	setEntry(r, r.products, product.ID, copyProduct(product))
+
+	for _, slug := range stored.Slugs() {
+		deleteEntry(r, r.slugs, slug)
+	}
+
+	for _, slug := range product.Slugs() {
+		setEntry(r, r.slugs, slug, product.ID)
+	}
+
+	return &product, nil
+}
+
+// Search products
+func (r *Memory) Search(ctx context.Context, request model.SearchRequest) (*model.Page, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var products []model.Product
+
+	for _, product := range r.products {
+		// Selecting the currency changes the product prices, so it is done on a copy
+		if request.Currency.Selected() {
+			product = copyProduct(product)
+
+			if !product.SelectCurrency(request.Currency) {
+				continue
+			}
+		}
+
+		if matchSearch(product, request) {
+			products = append(products, product)
+		}
+	}
+
+	sortByPrice(products, request.Sort)
+
+	pager := newPager(request)
+
+	for _, product := range products {
+		pager.add(product)
+	}
+
+	page := pager.page()
+
+	for i, product := range page.Products {
+		page.Products[i] = copyProduct(product)
+	}
+
+	return page, nil
+}
+
+// Run fn on the stored data, its writes are undone when fn returns an error
+// Every other call waits until fn returns, so transactions are serialized
+func (r *Memory) WithTransaction(ctx context.Context, fn func(txRepo Repository) error) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// The transaction shares the stored maps and journals how to undo each write, so it costs what it writes
+	tx := &Memory{
+		products:    r.products,
+		skus:        r.skus,
+		slugs:       r.slugs,
+		categories:  r.categories,
+		brands:      r.brands,
+		movements:   r.movements,
+		transaction: true,
+	}
+
+	if err := fn(tx); err != nil {
+		for i := len(tx.undo) - 1; i >= 0; i-- {
+			tx.undo[i]()
+		}
+
+		return err
+	}
+
+	// Movements are only appended, the ones of a failed transaction are left past the stored length
+	r.movements = tx.movements
+
+	// A committed nested transaction is undone with the one it runs on
+	if r.transaction {
+		r.undo = append(r.undo, tx.undo...)
+	}
+
+	return nil
+}
+
+// Set key on m to value, journaling how to undo it when r is a transaction
+func setEntry[K comparable, V any](r *Memory, m map[K]V, key K, value V) {
+	journalEntry(r, m, key)
+
+	m[key] = value
+}
+
+// Delete key from m, journaling how to undo it when r is a transaction
+func deleteEntry[K comparable, V any](r *Memory, m map[K]V, key K) {
+	journalEntry(r, m, key)
+
+	delete(m, key)
+}
+
+// Journal how to restore the current entry of key on m when r is a transaction
+func journalEntry[K comparable, V any](r *Memory, m map[K]V, key K) {
+	if !r.transaction {
+		return
+	}
+
+	previous, ok := m[key]
+
+	r.undo = append(r.undo, func() {
+		if ok {
+			m[key] = previous
+		} else {
+			delete(m, key)
+		}
+	})
+}
+
+// Copy images with their alternative texts
+func copyImages(images []model.Image) []model.Image {
+	if images == nil {
+		return nil
+	}
+
+	copied := make([]model.Image, len(images))
+
+	for i, image := range images {
+		if image.Alt != nil {
+			alt := make(map[string]string, len(image.Alt))
+
+			for locale, text := range image.Alt {
+				alt[locale] = text
+			}
+
+			image.Alt = alt
+		}
+
+		copied[i] = image
+	}
+
+	return copied
+}
+
+// Copy product so callers can not modify stored data
+func copyProduct(product model.Product) model.Product {
+	if product.Description != nil {
+		description := *product.Description
+
+		product.Description = &description
+	}
+
+	product.Images = copyImages(product.Images)
+
+	if product.Translations != nil {
+		translations := make([]model.Translation, len(product.Translations))
+
+		for i, translation := range product.Translations {
+			if translation.Description != nil {
+				description := *translation.Description
+
+				translation.Description = &description
+			}
+
+			translations[i] = translation
+		}
+
+		product.Translations = translations
+	}
+
+	if product.CategoryIDs != nil {
+		product.CategoryIDs = append([]string(nil), product.CategoryIDs...)
+	}
+
+	if product.Tags != nil {
+		product.Tags = append([]string(nil), product.Tags...)
+	}
+
+	product.Attributes = copyAttributes(product.Attributes)
+
+	product = copyShipping(product)
+
+	product.Bundle = copyBundle(product.Bundle)
+
+	if product.Relations != nil {
+		product.Relations = append([]model.Relation(nil), product.Relations...)
+	}
+
+	if product.SlugHistory != nil {
+		product.SlugHistory = append([]model.SlugChange(nil), product.SlugHistory...)
+	}
+
+	if product.Prices != nil {
+		product.Prices = append([]model.Money(nil), product.Prices...)
+	}
+
+	// Scheduled prices and price changes are never modified in place, so their times can be shared
+	if product.PriceSchedule != nil {
+		product.PriceSchedule = append([]model.ScheduledPrice(nil), product.PriceSchedule...)
+	}
+
+	if product.PriceHistory != nil {
+		product.PriceHistory = append([]model.PriceChange(nil), product.PriceHistory...)
+	}
+
+	if product.Options != nil {
+		options := make([]model.Option, len(product.Options))
+
+		for i, option := range product.Options {
+			option.Values = append([]string(nil), option.Values...)
+
+			options[i] = option
+		}
+
+		product.Options = options
+	}
+
+	if product.Variants != nil {
+		variants := make([]model.Variant, len(product.Variants))
+
+		for i, variant := range product.Variants {
+			selected := make(map[string]string, len(variant.Options))
+
+			for name, value := range variant.Options {
+				selected[name] = value
+			}
+
+			variant.Options = selected
+
+			variant.Images = copyImages(variant.Images)
+
+			variants[i] = variant
+		}
+
+		product.Variants = variants
+	}
+
+	if product.PriceRange != nil {
+		priceRange := *product.PriceRange
+
+		product.PriceRange = &priceRange
+	}
+
+	if product.DeletedAt != nil {
+		deletedAt := *product.DeletedAt
+
+		product.DeletedAt = &deletedAt
+	}
+
+	if product.PublishAt != nil {
+		publishAt := *product.PublishAt
+
+		product.PublishAt = &publishAt
+	}
+
+	if product.UnpublishAt != nil {
+		unpublishAt := *product.UnpublishAt
+
+		product.UnpublishAt = &unpublishAt
+	}
+
+	return product
+}
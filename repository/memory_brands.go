@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	internalError "github.com/srodrmendz/api-product-catalog/errors"
+	"github.com/srodrmendz/api-product-catalog/model"
+)
+
+// Count how many of the products matching the search filters have each tag
+func (r *Memory) CountTags(ctx context.Context, request model.SearchRequest) ([]model.TagCount, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var products []model.Product
+
+	for _, product := range r.products {
+		// Selecting the currency changes the product prices, so it is done on a copy
+		if request.Currency.Selected() {
+			product = copyProduct(product)
+
+			if !product.SelectCurrency(request.Currency) {
+				continue
+			}
+		}
+
+		if matchSearch(product, request) {
+			products = append(products, product)
+		}
+	}
+
+	return countTags(products), nil
+}
+
+// Create a new brand
+func (r *Memory) CreateBrand(ctx context.Context, brand model.Brand) (*model.Brand, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	brand.ID = uuid.NewString()
+
+	now := time.Now()
+
+	brand.CreatedAt = now
+
+	brand.UpdatedAt = now
+
+	setEntry(r, r.brands, brand.ID, copyBrand(brand))
+
+	brand = copyBrand(brand)
+
+	return &brand, nil
+}
+
+// Get a brand by id
+func (r *Memory) GetBrand(ctx context.Context, id string) (*model.Brand, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	brand, ok := r.brands[id]
+	if !ok {
+		return nil, internalError.ErrBrandNotFound
+	}
+
+	brand = copyBrand(brand)
+
+	return &brand, nil
+}
+
+// List every brand sorted by name
+func (r *Memory) ListBrands(ctx context.Context) ([]model.Brand, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	brands := make([]model.Brand, 0, len(r.brands))
+
+	for _, brand := range r.brands {
+		brands = append(brands, copyBrand(brand))
+	}
+
+	sortBrands(brands)
+
+	return brands, nil
+}
+
+// Update a brand name and description
+func (r *Memory) UpdateBrand(ctx context.Context, brand model.Brand) (*model.Brand, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.brands[brand.ID]
+	if !ok {
+		return nil, internalError.ErrBrandNotFound
+	}
+
+	stored.Name = brand.Name
+
+	stored.Description = brand.Description
+
+	stored.UpdatedAt = time.Now()
+
+	setEntry(r, r.brands, stored.ID, copyBrand(stored))
+
+	stored = copyBrand(stored)
+
+	return &stored, nil
+}
+
+// Delete a brand
+func (r *Memory) DeleteBrand(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.brands[id]; !ok {
+		return internalError.ErrBrandNotFound
+	}
+
+	deleteEntry(r, r.brands, id)
+
+	return nil
+}
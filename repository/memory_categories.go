@@ -0,0 +1,161 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	internalError "github.com/srodrmendz/api-product-catalog/errors"
+	"github.com/srodrmendz/api-product-catalog/model"
+)
+
+// Create a new category
+func (r *Memory) CreateCategory(ctx context.Context, category model.Category) (*model.Category, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	category.ID = uuid.NewString()
+
+	now := time.Now()
+
+	category.CreatedAt = now
+
+	category.UpdatedAt = now
+
+	category = copyCategory(category)
+
+	setEntry(r, r.categories, category.ID, category)
+
+	category = copyCategory(category)
+
+	return &category, nil
+}
+
+// Get a category by id
+func (r *Memory) GetCategory(ctx context.Context, id string) (*model.Category, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	category, ok := r.categories[id]
+	if !ok {
+		return nil, internalError.ErrCategoryNotFound
+	}
+
+	category = copyCategory(category)
+
+	return &category, nil
+}
+
+// List every category sorted by name
+func (r *Memory) ListCategories(ctx context.Context) ([]model.Category, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	categories := make([]model.Category, 0, len(r.categories))
+
+	for _, category := range r.categories {
+		categories = append(categories, copyCategory(category))
+	}
+
+	sortCategories(categories)
+
+	return categories, nil
+}
+
+// Get a category and all its descendants
+func (r *Memory) GetCategorySubtree(ctx context.Context, id string) ([]model.Category, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if _, ok := r.categories[id]; !ok {
+		return nil, internalError.ErrCategoryNotFound
+	}
+
+	var categories []model.Category
+
+	for _, category := range r.categories {
+		if inSubtree(category, id) {
+			categories = append(categories, copyCategory(category))
+		}
+	}
+
+	sortCategories(categories)
+
+	return categories, nil
+}
+
+// Update a category, the paths of its descendants are updated to follow it
+func (r *Memory) UpdateCategory(ctx context.Context, category model.Category) (*model.Category, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.categories[category.ID]
+	if !ok {
+		return nil, internalError.ErrCategoryNotFound
+	}
+
+	stored.Name = category.Name
+
+	stored.ParentID = category.ParentID
+
+	stored.Path = append([]string{}, category.Path...)
+
+	stored.Attributes = copyCategory(category).Attributes
+
+	stored.UpdatedAt = time.Now()
+
+	setEntry(r, r.categories, stored.ID, stored)
+
+	for id, descendant := range r.categories {
+		if id != stored.ID && contains(descendant.Path, stored.ID) {
+			descendant.Path = movedPath(descendant.Path, stored)
+
+			setEntry(r, r.categories, id, descendant)
+		}
+	}
+
+	stored = copyCategory(stored)
+
+	return &stored, nil
+}
+
+// Delete a category
+func (r *Memory) DeleteCategory(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.categories[id]; !ok {
+		return internalError.ErrCategoryNotFound
+	}
+
+	deleteEntry(r, r.categories, id)
+
+	return nil
+}
+
+// Replace category from by to on every product, an empty to removes it
+func (r *Memory) ReplaceProductCategory(ctx context.Context, from string, to string) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var replaced int64
+
+	for id, product := range r.products {
+		categoryIDs, ok := replaceCategory(product.CategoryIDs, from, to)
+		if !ok {
+			continue
+		}
+
+		product.CategoryIDs = categoryIDs
+
+		product.UpdatedAt = time.Now()
+
+		product.Version++
+
+		setEntry(r, r.products, id, product)
+
+		replaced++
+	}
+
+	return replaced, nil
+}
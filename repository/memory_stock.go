@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	internalError "github.com/srodrmendz/api-product-catalog/errors"
+	"github.com/srodrmendz/api-product-catalog/model"
+)
+
+// Adjust the stock of a product, checked and applied under the repository lock
+func (r *Memory) AdjustStock(ctx context.Context, adjustment model.StockAdjustment) (*model.Product, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	product, ok := r.products[adjustment.ID]
+	if !ok || product.DeletedAt != nil {
+		return nil, internalError.ErrProductNotFound
+	}
+
+	if err := checkAdjustment(product, adjustment); err != nil {
+		return nil, err
+	}
+
+	// Variants are shared with the stored product, so they are copied before being adjusted
+	product = copyProduct(product)
+
+	applyAdjustment(&product, adjustment)
+
+	setEntry(r, r.products, adjustment.ID, product)
+
+	product = copyProduct(product)
+
+	return &product, nil
+}
+
+// Record a stock movement
+func (r *Memory) CreateStockMovement(ctx context.Context, movement model.StockMovement) (*model.StockMovement, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	movement.ID = uuid.NewString()
+
+	movement.CreatedAt = time.Now()
+
+	r.movements = append(r.movements, movement)
+
+	return &movement, nil
+}
+
+// List the stock movements of a product, newest first
+func (r *Memory) ListStockMovements(ctx context.Context, request model.MovementSearchRequest) (*model.MovementPage, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return pageMovements(r.movements, request), nil
+}
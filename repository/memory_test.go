@@ -0,0 +1,15 @@
+package repository_test
+
+import (
+	"testing"
+
+	"github.com/srodrmendz/api-product-catalog/repository"
+	"github.com/srodrmendz/api-product-catalog/repository/repotest"
+)
+
+// Test Memory Repository against the repository contract
+func TestMemory(t *testing.T) {
+	repotest.Run(t, func(t *testing.T) repository.Repository {
+		return repository.NewMemory()
+	})
+}
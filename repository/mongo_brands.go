@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	internalError "github.com/srodrmendz/api-product-catalog/errors"
+	"github.com/srodrmendz/api-product-catalog/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Count how many of the products matching the search filters have each tag, grouped on an aggregation
+func (r *ProductsCatalogRepository) CountTags(ctx context.Context, request model.SearchRequest) ([]model.TagCount, error) {
+	filter := r.getSearchFilter(request)
+
+	pipeline := mongo.Pipeline{{{Key: "$match", Value: filter}}}
+
+	// Products without a price on the selected currency are not counted
+	if request.Currency.Selected() {
+		pipeline = r.getCurrencyPipeline(filter, request.Currency)
+	}
+
+	pipeline = append(
+		pipeline,
+		bson.D{{Key: "$unwind", Value: "$tags"}},
+		bson.D{{Key: "$group", Value: bson.M{"_id": "$tags", "count": bson.M{"$sum": 1}}}},
+		bson.D{{Key: "$sort", Value: bson.D{{Key: "count", Value: -1}, {Key: "_id", Value: 1}}}},
+	)
+
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("counting product tags on repository %w", err)
+	}
+
+	tags := []model.TagCount{}
+
+	if err := cursor.All(ctx, &tags); err != nil {
+		return nil, fmt.Errorf("decoding product tags from repository %w", err)
+	}
+
+	return tags, nil
+}
+
+// Create a new brand
+func (r *ProductsCatalogRepository) CreateBrand(ctx context.Context, brand model.Brand) (*model.Brand, error) {
+	brand.ID = uuid.NewString()
+
+	now := time.Now()
+
+	brand.CreatedAt = now
+
+	brand.UpdatedAt = now
+
+	brand = copyBrand(brand)
+
+	if _, err := r.brands.InsertOne(ctx, brand); err != nil {
+		return nil, fmt.Errorf("creating brand %s on repository %w", brand.Name, err)
+	}
+
+	return &brand, nil
+}
+
+// Get a brand by id
+func (r *ProductsCatalogRepository) GetBrand(ctx context.Context, id string) (*model.Brand, error) {
+	var brand model.Brand
+
+	if err := r.brands.FindOne(ctx, bson.M{"_id": id}).Decode(&brand); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, internalError.ErrBrandNotFound
+		}
+
+		return nil, fmt.Errorf("decoding brand from repository %w", err)
+	}
+
+	return &brand, nil
+}
+
+// List every brand sorted by name
+func (r *ProductsCatalogRepository) ListBrands(ctx context.Context) ([]model.Brand, error) {
+	opt := options.Find().SetSort(bson.D{{Key: "name", Value: 1}, {Key: "_id", Value: 1}})
+
+	cursor, err := r.brands.Find(ctx, bson.M{}, opt)
+	if err != nil {
+		return nil, fmt.Errorf("finding brands on repository %w", err)
+	}
+
+	brands := []model.Brand{}
+
+	if err := cursor.All(ctx, &brands); err != nil {
+		return nil, fmt.Errorf("decoding brands from repository %w", err)
+	}
+
+	return brands, nil
+}
+
+// Update a brand name and description
+func (r *ProductsCatalogRepository) UpdateBrand(ctx context.Context, brand model.Brand) (*model.Brand, error) {
+	update := bson.M{
+		"$set": bson.M{
+			"name":       brand.Name,
+			"updated_at": time.Now(),
+		},
+	}
+
+	if brand.Description == nil {
+		update["$unset"] = bson.M{"description": ""}
+	} else {
+		update["$set"].(bson.M)["description"] = *brand.Description
+	}
+
+	var stored model.Brand
+
+	err := r.
+		brands.
+		FindOneAndUpdate(
+			ctx,
+			bson.M{"_id": brand.ID},
+			update,
+			options.FindOneAndUpdate().SetReturnDocument(options.After)).
+		Decode(&stored)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, internalError.ErrBrandNotFound
+		}
+
+		return nil, fmt.Errorf("updating brand on repository %w", err)
+	}
+
+	return &stored, nil
+}
+
+// Delete a brand
+func (r *ProductsCatalogRepository) DeleteBrand(ctx context.Context, id string) error {
+	resp, err := r.brands.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return fmt.Errorf("deleting brand from repository %w", err)
+	}
+
+	if resp.DeletedCount == 0 {
+		return internalError.ErrBrandNotFound
+	}
+
+	return nil
+}
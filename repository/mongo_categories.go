@@ -0,0 +1,204 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	internalError "github.com/srodrmendz/api-product-catalog/errors"
+	"github.com/srodrmendz/api-product-catalog/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Create a new category
+func (r *ProductsCatalogRepository) CreateCategory(ctx context.Context, category model.Category) (*model.Category, error) {
+	category.ID = uuid.NewString()
+
+	now := time.Now()
+
+	category.CreatedAt = now
+
+	category.UpdatedAt = now
+
+	category = copyCategory(category)
+
+	if _, err := r.categories.InsertOne(ctx, category); err != nil {
+		return nil, fmt.Errorf("creating category %s on repository %w", category.Name, err)
+	}
+
+	return &category, nil
+}
+
+// Get a category by id
+func (r *ProductsCatalogRepository) GetCategory(ctx context.Context, id string) (*model.Category, error) {
+	var category model.Category
+
+	if err := r.categories.FindOne(ctx, bson.M{"_id": id}).Decode(&category); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, internalError.ErrCategoryNotFound
+		}
+
+		return nil, fmt.Errorf("decoding category from repository %w", err)
+	}
+
+	category = copyCategory(category)
+
+	return &category, nil
+}
+
+// List every category sorted by name
+func (r *ProductsCatalogRepository) ListCategories(ctx context.Context) ([]model.Category, error) {
+	return r.findCategories(ctx, bson.M{})
+}
+
+// Get a category and all its descendants
+func (r *ProductsCatalogRepository) GetCategorySubtree(ctx context.Context, id string) ([]model.Category, error) {
+	categories, err := r.findCategories(ctx, bson.M{"$or": []bson.M{{"_id": id}, {"path": id}}})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(categories) == 0 {
+		return nil, internalError.ErrCategoryNotFound
+	}
+
+	return categories, nil
+}
+
+// Update a category, the paths of its descendants are updated to follow it
+func (r *ProductsCatalogRepository) UpdateCategory(ctx context.Context, category model.Category) (*model.Category, error) {
+	update := bson.M{
+		"$set": bson.M{
+			"name":       category.Name,
+			"path":       copyCategory(category).Path,
+			"updated_at": time.Now(),
+		},
+	}
+
+	unset := bson.M{}
+
+	if category.ParentID == "" {
+		unset["parent_id"] = ""
+	} else {
+		update["$set"].(bson.M)["parent_id"] = category.ParentID
+	}
+
+	if len(category.Attributes) == 0 {
+		unset["attributes"] = ""
+	} else {
+		update["$set"].(bson.M)["attributes"] = category.Attributes
+	}
+
+	if len(unset) > 0 {
+		update["$unset"] = unset
+	}
+
+	var stored model.Category
+
+	err := r.
+		categories.
+		FindOneAndUpdate(
+			ctx,
+			bson.M{"_id": category.ID},
+			update,
+			options.FindOneAndUpdate().SetReturnDocument(options.After)).
+		Decode(&stored)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, internalError.ErrCategoryNotFound
+		}
+
+		return nil, fmt.Errorf("updating category on repository %w", err)
+	}
+
+	stored = copyCategory(stored)
+
+	descendants, err := r.findCategories(ctx, bson.M{"path": stored.ID})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, descendant := range descendants {
+		path := bson.M{"$set": bson.M{"path": movedPath(descendant.Path, stored)}}
+
+		if _, err := r.categories.UpdateOne(ctx, bson.M{"_id": descendant.ID}, path); err != nil {
+			return nil, fmt.Errorf("updating category %s path on repository %w", descendant.ID, err)
+		}
+	}
+
+	return &stored, nil
+}
+
+// Delete a category
+func (r *ProductsCatalogRepository) DeleteCategory(ctx context.Context, id string) error {
+	resp, err := r.categories.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return fmt.Errorf("deleting category from repository %w", err)
+	}
+
+	if resp.DeletedCount == 0 {
+		return internalError.ErrCategoryNotFound
+	}
+
+	return nil
+}
+
+// Replace category from by to on every product, an empty to removes it
+func (r *ProductsCatalogRepository) ReplaceProductCategory(ctx context.Context, from string, to string) (int64, error) {
+	var replaced int64
+
+	// Products that are not on to get it in place of from, the rest only lose from below
+	if to != "" {
+		filter := bson.M{"$and": []bson.M{{"category_ids": from}, {"category_ids": bson.M{"$ne": to}}}}
+
+		update := bson.M{
+			"$set": bson.M{"category_ids.$": to, "updated_at": time.Now()},
+			"$inc": bson.M{"version": 1},
+		}
+
+		resp, err := r.collection.UpdateMany(ctx, filter, update)
+		if err != nil {
+			return 0, fmt.Errorf("replacing product category on repository %w", err)
+		}
+
+		replaced = resp.ModifiedCount
+	}
+
+	update := bson.M{
+		"$pull": bson.M{"category_ids": from},
+		"$set":  bson.M{"updated_at": time.Now()},
+		"$inc":  bson.M{"version": 1},
+	}
+
+	resp, err := r.collection.UpdateMany(ctx, bson.M{"category_ids": from}, update)
+	if err != nil {
+		return 0, fmt.Errorf("replacing product category on repository %w", err)
+	}
+
+	return replaced + resp.ModifiedCount, nil
+}
+
+func (r *ProductsCatalogRepository) findCategories(ctx context.Context, filter bson.M) ([]model.Category, error) {
+	opt := options.Find().SetSort(bson.D{{Key: "name", Value: 1}, {Key: "_id", Value: 1}})
+
+	cursor, err := r.categories.Find(ctx, filter, opt)
+	if err != nil {
+		return nil, fmt.Errorf("finding categories on repository %w", err)
+	}
+
+	categories := []model.Category{}
+
+	if err := cursor.All(ctx, &categories); err != nil {
+		return nil, fmt.Errorf("decoding categories from repository %w", err)
+	}
+
+	for i := range categories {
+		categories[i] = copyCategory(categories[i])
+	}
+
+	return categories, nil
+}
@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/srodrmendz/api-product-catalog/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Field the search pipeline adds with the product price on the searched currency
+const selectedPriceField = "selected_price"
+
+// Search products priced on the selected currency, the price is resolved on an aggregation so the sort follows it
+func (r *ProductsCatalogRepository) searchInCurrency(ctx context.Context, filter bson.M, request model.SearchRequest) ([]model.Product, bool, error) {
+	pipeline := r.getCurrencyPipeline(filter, request.Currency)
+
+	sortValue := 1
+
+	if request.Sort == "desc" {
+		sortValue = -1
+	}
+
+	cursor := request.Cursor
+
+	var page mongo.Pipeline
+
+	if cursor == nil {
+		page = append(page, bson.D{{Key: "$skip", Value: int64(request.Offset)}})
+
+		if request.Limit > 0 {
+			page = append(page, bson.D{{Key: "$limit", Value: int64(request.Limit)}})
+		}
+	} else {
+		pipeline = append(pipeline, bson.D{{Key: "$match", Value: r.getCursorFilter(bson.M{}, selectedPriceField, cursor, sortValue)}})
+
+		// Walk the sort order backwards to get the products placed before the cursor
+		if cursor.Backward {
+			sortValue = -sortValue
+		}
+
+		// Fetch an extra product to know whether there is another page
+		if request.Limit > 0 {
+			page = append(page, bson.D{{Key: "$limit", Value: int64(request.Limit + 1)}})
+		}
+	}
+
+	// Sort by id too so products with the same price keep a stable order between pages
+	pipeline = append(pipeline, bson.D{{Key: "$sort", Value: bson.D{{Key: selectedPriceField, Value: sortValue}, {Key: "_id", Value: sortValue}}}})
+
+	resp, err := r.collection.Aggregate(ctx, append(pipeline, page...))
+	if err != nil {
+		return nil, false, err
+	}
+
+	var products []model.Product
+
+	for resp.Next(ctx) {
+		var product model.Product
+
+		if err := resp.Decode(&product); err != nil {
+			return nil, false, fmt.Errorf("decoding product from repository %w", err)
+		}
+
+		// Resolves the same price the pipeline sorted by
+		product.SelectCurrency(request.Currency)
+
+		products = append(products, product)
+	}
+
+	if cursor == nil {
+		return products, false, nil
+	}
+
+	products, more := keysetPage(products, request)
+
+	return products, more, nil
+}
+
+func (r *ProductsCatalogRepository) getTotalInCurrency(ctx context.Context, filter bson.M, selection model.CurrencySelection) (int64, error) {
+	pipeline := append(r.getCurrencyPipeline(filter, selection), bson.D{{Key: "$count", Value: "total"}})
+
+	resp, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return 0, err
+	}
+
+	var result struct {
+		Total int64 `bson:"total"`
+	}
+
+	if resp.Next(ctx) {
+		if err := resp.Decode(&result); err != nil {
+			return 0, fmt.Errorf("decoding products total from repository %w", err)
+		}
+	}
+
+	return result.Total, resp.Err()
+}
+
+// Stages matching the filter and the products with a price on the selected currency, which is added on selectedPriceField
+func (r *ProductsCatalogRepository) getCurrencyPipeline(filter bson.M, selection model.CurrencySelection) mongo.Pipeline {
+	return mongo.Pipeline{
+		{{Key: "$match", Value: filter}},
+		{{Key: "$addFields", Value: bson.M{selectedPriceField: getSelectedPrice(selection)}}},
+		{{Key: "$match", Value: bson.M{selectedPriceField: bson.M{"$ne": nil}}}},
+	}
+}
+
+// Expression resolving the product price on the selected currency the same way Product.SelectCurrency does, null when it has none
+func getSelectedPrice(selection model.CurrencySelection) bson.M {
+	branches := bson.A{
+		bson.M{"case": bson.M{"$eq": bson.A{"$$currency", selection.Code}}, "then": "$price"},
+		bson.M{"case": bson.M{"$gt": bson.A{bson.M{"$size": "$$listed"}, 0}}, "then": bson.M{"$arrayElemAt": bson.A{"$$listed.amount", 0}}},
+	}
+
+	currencies := make([]string, 0, len(selection.Factors))
+
+	for currency := range selection.Factors {
+		currencies = append(currencies, currency)
+	}
+
+	sort.Strings(currencies)
+
+	// Converted prices are rounded half up, like model.Convert
+	for _, currency := range currencies {
+		branches = append(branches, bson.M{
+			"case": bson.M{"$eq": bson.A{"$$currency", currency}},
+			"then": bson.M{"$floor": bson.M{"$add": bson.A{bson.M{"$multiply": bson.A{"$price", selection.Factors[currency]}}, 0.5}}},
+		})
+	}
+
+	return bson.M{
+		"$let": bson.M{
+			"vars": bson.M{
+				"currency": bson.M{"$ifNull": bson.A{"$currency", selection.Default}},
+				"listed": bson.M{
+					"$filter": bson.M{
+						"input": bson.M{"$ifNull": bson.A{"$prices", bson.A{}}},
+						"cond":  bson.M{"$eq": bson.A{"$$this.currency", selection.Code}},
+					},
+				},
+			},
+			"in": bson.M{"$switch": bson.M{"branches": branches, "default": nil}},
+		},
+	}
+}
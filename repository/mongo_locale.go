@@ -0,0 +1,53 @@
+package repository
+
+import "github.com/srodrmendz/api-product-catalog/model"
+
+// Languages supported by MongoDB text search by locale language, the rest are searched without stemming nor stop words
+var textLanguages = map[string]string{
+	"da": "danish",
+	"de": "german",
+	"en": "english",
+	"es": "spanish",
+	"fi": "finnish",
+	"fr": "french",
+	"hu": "hungarian",
+	"it": "italian",
+	"nb": "norwegian",
+	"nl": "dutch",
+	"pt": "portuguese",
+	"ro": "romanian",
+	"ru": "russian",
+	"sv": "swedish",
+	"tr": "turkish",
+}
+
+// Text search language of a locale
+func textLanguage(locale string) string {
+	if language, ok := textLanguages[model.LocaleLanguage(locale)]; ok {
+		return language
+	}
+
+	return "none"
+}
+
+// Create the document stored for product, each translation is indexed with the language of its locale
+// Products without locale are indexed with the text index default language
+func newMongoProduct(product model.Product) mongoProduct {
+	document := mongoProduct{Product: product, SKUs: product.SKUs(), Slugs: product.Slugs()}
+
+	if product.Locale != "" {
+		document.Language = textLanguage(product.Locale)
+	}
+
+	if product.Translations != nil {
+		document.Translations = make([]model.Translation, len(product.Translations))
+
+		for i, translation := range product.Translations {
+			translation.Language = textLanguage(translation.Locale)
+
+			document.Translations[i] = translation
+		}
+	}
+
+	return document
+}
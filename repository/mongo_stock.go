@@ -0,0 +1,177 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	internalError "github.com/srodrmendz/api-product-catalog/errors"
+	"github.com/srodrmendz/api-product-catalog/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Adjust the stock of a product on a single conditional update, the filter only matches it while the qty covers the delta
+func (r *ProductsCatalogRepository) AdjustStock(ctx context.Context, adjustment model.StockAdjustment) (*model.Product, error) {
+	filter := r.getVersionFilter(adjustment.ID, 0)
+
+	// Decrements only match the product, or its variant, while its qty covers them
+	qty := bson.M{"$gte": -adjustment.Delta}
+
+	// Increments only match while the product qty, the sum of its variants included, stays within MaxQty
+	if adjustment.Delta > 0 {
+		filter["qty"] = bson.M{"$lte": model.MaxQty - adjustment.Delta}
+	}
+
+	var update mongo.Pipeline
+
+	if adjustment.VariantSKU == "" {
+		// Products with variants take their qty from them
+		filter["variants.0"] = bson.M{"$exists": false}
+
+		if adjustment.Delta < 0 {
+			filter["qty"] = qty
+		}
+
+		update = mongo.Pipeline{
+			{{Key: "$set", Value: bson.M{
+				"qty":        bson.M{"$add": bson.A{"$qty", adjustment.Delta}},
+				"updated_at": time.Now(),
+				"version":    bson.M{"$add": bson.A{"$version", 1}},
+			}}},
+			{{Key: "$set", Value: bson.M{"in_stock": bson.M{"$gt": bson.A{"$qty", 0}}}}},
+		}
+	} else {
+		variant := bson.M{"sku": adjustment.VariantSKU}
+
+		if adjustment.Delta < 0 {
+			variant["qty"] = qty
+		}
+
+		filter["variants"] = bson.M{"$elemMatch": variant}
+
+		update = getVariantAdjustment(adjustment)
+	}
+
+	var product model.Product
+
+	err := r.
+		collection.
+		FindOneAndUpdate(
+			ctx,
+			filter,
+			update,
+			options.FindOneAndUpdate().SetReturnDocument(options.After)).
+		Decode(&product)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, r.getAdjustmentError(ctx, adjustment)
+		}
+
+		return nil, fmt.Errorf("adjusting product stock on repository %w", err)
+	}
+
+	return &product, nil
+}
+
+// Pipeline that adds the delta to the qty of the adjusted variant and derives the product qty and in_stock from every variant
+func getVariantAdjustment(adjustment model.StockAdjustment) mongo.Pipeline {
+	qty := bson.M{"$add": bson.A{"$$variant.qty", adjustment.Delta}}
+
+	variant := bson.M{
+		"$cond": bson.A{
+			bson.M{"$eq": bson.A{"$$variant.sku", bson.M{"$literal": adjustment.VariantSKU}}},
+			bson.M{"$mergeObjects": bson.A{"$$variant", bson.M{"qty": qty, "in_stock": bson.M{"$gt": bson.A{qty, 0}}}}},
+			"$$variant",
+		},
+	}
+
+	set := bson.M{
+		"qty":        bson.M{"$sum": "$variants.qty"},
+		"updated_at": time.Now(),
+		"version":    bson.M{"$add": bson.A{"$version", 1}},
+	}
+
+	return mongo.Pipeline{
+		{{Key: "$set", Value: bson.M{"variants": bson.M{"$map": bson.M{"input": "$variants", "as": "variant", "in": variant}}}}},
+		{{Key: "$set", Value: set}},
+		{{Key: "$set", Value: bson.M{"in_stock": bson.M{"$gt": bson.A{"$qty", 0}}}}},
+	}
+}
+
+// Called when an adjustment matched no product, to tell why from the stored product
+func (r *ProductsCatalogRepository) getAdjustmentError(ctx context.Context, adjustment model.StockAdjustment) error {
+	product, err := r.GetByID(ctx, adjustment.ID)
+	if err != nil {
+		return err
+	}
+
+	if err := checkAdjustment(*product, adjustment); err != nil {
+		return err
+	}
+
+	// The stock changed after the adjustment was filtered, it is only reached when another write added to it
+	return &internalError.VersionConflictError{Current: product.Version}
+}
+
+// Record a stock movement
+func (r *ProductsCatalogRepository) CreateStockMovement(ctx context.Context, movement model.StockMovement) (*model.StockMovement, error) {
+	movement.ID = uuid.NewString()
+
+	movement.CreatedAt = time.Now()
+
+	if _, err := r.movements.InsertOne(ctx, movement); err != nil {
+		return nil, fmt.Errorf("recording stock movement on repository %w", err)
+	}
+
+	return &movement, nil
+}
+
+// List the stock movements of a product, newest first
+func (r *ProductsCatalogRepository) ListStockMovements(ctx context.Context, request model.MovementSearchRequest) (*model.MovementPage, error) {
+	filter := bson.M{"product_id": request.ProductID}
+
+	if len(request.Types) > 0 {
+		filter["type"] = bson.M{"$in": request.Types}
+	}
+
+	createdAt := bson.M{}
+
+	if request.From != nil {
+		createdAt["$gte"] = *request.From
+	}
+
+	if request.To != nil {
+		createdAt["$lt"] = *request.To
+	}
+
+	if len(createdAt) > 0 {
+		filter["created_at"] = createdAt
+	}
+
+	total, err := r.movements.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("counting stock movements on repository %w", err)
+	}
+
+	opt := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}).
+		SetSkip(int64(request.Offset)).
+		SetLimit(int64(request.Limit))
+
+	cursor, err := r.movements.Find(ctx, filter, opt)
+	if err != nil {
+		return nil, fmt.Errorf("finding stock movements on repository %w", err)
+	}
+
+	movements := []model.StockMovement{}
+
+	if err := cursor.All(ctx, &movements); err != nil {
+		return nil, fmt.Errorf("decoding stock movements from repository %w", err)
+	}
+
+	return &model.MovementPage{Movements: movements, Total: total}, nil
+}
@@ -0,0 +1,214 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	internalError "github.com/srodrmendz/api-product-catalog/errors"
+	"github.com/srodrmendz/api-product-catalog/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Run fn on a MongoDB transaction, it is committed only when fn returns no error
+// Transactions need MongoDB to run as a replica set or sharded cluster, standalone servers fail with ErrTransactionsUnsupported
+func (r *ProductsCatalogRepository) WithTransaction(ctx context.Context, fn func(txRepo Repository) error) error {
+	supported, err := r.SupportsTransactions(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Writes applied together are never run one by one, they could be left half applied
+	if !supported {
+		return internalError.ErrTransactionsUnsupported
+	}
+
+	session, err := r.collection.Database().Client().StartSession()
+	if err != nil {
+		return fmt.Errorf("starting repository session %w", err)
+	}
+
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(sessionCtx mongo.SessionContext) (interface{}, error) {
+		return nil, fn(&mongoTransaction{
+			repository: r,
+			session:    sessionCtx,
+		})
+	})
+
+	return err
+}
+
+// Reports whether the MongoDB server runs transactions, only replica set members and mongos routers do
+// The server is asked once, on the first successful check
+func (r *ProductsCatalogRepository) SupportsTransactions(ctx context.Context) (bool, error) {
+	r.topologyMu.Lock()
+	defer r.topologyMu.Unlock()
+
+	if r.transactions != nil {
+		return *r.transactions, nil
+	}
+
+	var hello struct {
+		SetName string `bson:"setName"`
+		Msg     string `bson:"msg"`
+	}
+
+	err := r.collection.Database().RunCommand(ctx, bson.D{{Key: "isMaster", Value: 1}}).Decode(&hello)
+	if err != nil {
+		return false, fmt.Errorf("checking repository topology %w", err)
+	}
+
+	supported := hello.SetName != "" || hello.Msg == mongosMsg
+
+	r.transactions = &supported
+
+	return supported, nil
+}
+
+// Create a new product
+func (t *mongoTransaction) Create(ctx context.Context, product model.Product) (*model.Product, error) {
+	return t.repository.Create(t.context(ctx), product)
+}
+
+// Get a product by id
+func (t *mongoTransaction) GetByID(ctx context.Context, id string) (*model.Product, error) {
+	return t.repository.GetByID(t.context(ctx), id)
+}
+
+// Get a product by sku
+func (t *mongoTransaction) GetBySKU(ctx context.Context, sku string) (*model.Product, error) {
+	return t.repository.GetBySKU(t.context(ctx), sku)
+}
+
+// Get the product holding a slug
+func (t *mongoTransaction) GetBySlug(ctx context.Context, slug string) (*model.Product, error) {
+	return t.repository.GetBySlug(t.context(ctx), slug)
+}
+
+// Delete product, it is kept until purged so it can be restored
+func (t *mongoTransaction) Delete(ctx context.Context, id string, version int64) error {
+	return t.repository.delete(t.context(ctx), id, version)
+}
+
+// Restore a deleted product, restoring a product that is not deleted returns it unchanged
+func (t *mongoTransaction) Restore(ctx context.Context, id string) (*model.Product, error) {
+	return t.repository.Restore(t.context(ctx), id)
+}
+
+// Replace a product
+func (t *mongoTransaction) Replace(ctx context.Context, product model.Product) (*model.Product, error) {
+	return t.repository.Replace(t.context(ctx), product)
+}
+
+// Adjust the stock of a product on a single conditional write
+func (t *mongoTransaction) AdjustStock(ctx context.Context, adjustment model.StockAdjustment) (*model.Product, error) {
+	return t.repository.AdjustStock(t.context(ctx), adjustment)
+}
+
+// Permanently remove the products deleted before the given time
+func (t *mongoTransaction) Purge(ctx context.Context, before time.Time) (int64, error) {
+	return t.repository.Purge(t.context(ctx), before)
+}
+
+// List the products with a scheduled price starting or ending at the given time or before
+func (t *mongoTransaction) ListDuePrices(ctx context.Context, at time.Time) ([]model.Product, error) {
+	return t.repository.ListDuePrices(t.context(ctx), at)
+}
+
+// Update a product
+func (t *mongoTransaction) Update(ctx context.Context, update model.Update) (*model.Product, error) {
+	return t.repository.Update(t.context(ctx), update)
+}
+
+// Search products
+func (t *mongoTransaction) Search(ctx context.Context, request model.SearchRequest) (*model.Page, error) {
+	return t.repository.Search(t.context(ctx), request)
+}
+
+// Already running on a transaction, fn joins it
+func (t *mongoTransaction) WithTransaction(ctx context.Context, fn func(txRepo Repository) error) error {
+	return fn(t)
+}
+
+// Carry the session on ctx so the collection calls run on its transaction
+func (t *mongoTransaction) context(ctx context.Context) context.Context {
+	return mongo.NewSessionContext(ctx, t.session)
+}
+
+// Create a new category
+func (t *mongoTransaction) CreateCategory(ctx context.Context, category model.Category) (*model.Category, error) {
+	return t.repository.CreateCategory(t.context(ctx), category)
+}
+
+// Get a category by id
+func (t *mongoTransaction) GetCategory(ctx context.Context, id string) (*model.Category, error) {
+	return t.repository.GetCategory(t.context(ctx), id)
+}
+
+// List every category sorted by name
+func (t *mongoTransaction) ListCategories(ctx context.Context) ([]model.Category, error) {
+	return t.repository.ListCategories(t.context(ctx))
+}
+
+// Get a category and all its descendants
+func (t *mongoTransaction) GetCategorySubtree(ctx context.Context, id string) ([]model.Category, error) {
+	return t.repository.GetCategorySubtree(t.context(ctx), id)
+}
+
+// Update a category, the paths of its descendants are updated to follow it
+func (t *mongoTransaction) UpdateCategory(ctx context.Context, category model.Category) (*model.Category, error) {
+	return t.repository.UpdateCategory(t.context(ctx), category)
+}
+
+// Delete a category
+func (t *mongoTransaction) DeleteCategory(ctx context.Context, id string) error {
+	return t.repository.DeleteCategory(t.context(ctx), id)
+}
+
+// Replace category from by to on every product, an empty to removes it
+func (t *mongoTransaction) ReplaceProductCategory(ctx context.Context, from string, to string) (int64, error) {
+	return t.repository.ReplaceProductCategory(t.context(ctx), from, to)
+}
+
+// Count how many of the products matching the search filters have each tag
+func (t *mongoTransaction) CountTags(ctx context.Context, request model.SearchRequest) ([]model.TagCount, error) {
+	return t.repository.CountTags(t.context(ctx), request)
+}
+
+// Create a new brand
+func (t *mongoTransaction) CreateBrand(ctx context.Context, brand model.Brand) (*model.Brand, error) {
+	return t.repository.CreateBrand(t.context(ctx), brand)
+}
+
+// Get a brand by id
+func (t *mongoTransaction) GetBrand(ctx context.Context, id string) (*model.Brand, error) {
+	return t.repository.GetBrand(t.context(ctx), id)
+}
+
+// List every brand sorted by name
+func (t *mongoTransaction) ListBrands(ctx context.Context) ([]model.Brand, error) {
+	return t.repository.ListBrands(t.context(ctx))
+}
+
+// Update a brand name and description
+func (t *mongoTransaction) UpdateBrand(ctx context.Context, brand model.Brand) (*model.Brand, error) {
+	return t.repository.UpdateBrand(t.context(ctx), brand)
+}
+
+// Delete a brand
+func (t *mongoTransaction) DeleteBrand(ctx context.Context, id string) error {
+	return t.repository.DeleteBrand(t.context(ctx), id)
+}
+
+// Record a stock movement
+func (t *mongoTransaction) CreateStockMovement(ctx context.Context, movement model.StockMovement) (*model.StockMovement, error) {
+	return t.repository.CreateStockMovement(t.context(ctx), movement)
+}
+
+// List the stock movements of a product, newest first
+func (t *mongoTransaction) ListStockMovements(ctx context.Context, request model.MovementSearchRequest) (*model.MovementPage, error) {
+	return t.repository.ListStockMovements(t.context(ctx), request)
+}
@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/srodrmendz/api-product-catalog/model"
+)
+
+// Remove the relations of a stored product to the deleted one the same way the MongoDB delete does, reports whether it had any
+func markUnlinked(product *model.Product, id string) bool {
+	if !product.RemoveRelationsTo(id) {
+		return false
+	}
+
+	product.UpdatedAt = time.Now()
+
+	product.Version++
+
+	return true
+}
@@ -2,7 +2,9 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -14,10 +16,14 @@ import (
 	"golang.org/x/sync/errgroup"
 )
 
-// Create new product repository
+// Create new product repository, categories and brands are stored on the collections named after the products one
+// with a _categories and _brands suffix
 func New(client *mongo.Client, database string, collection string) *ProductsCatalogRepository {
 	return &ProductsCatalogRepository{
 		collection: client.Database(database).Collection(collection),
+		categories: client.Database(database).Collection(collection + "_categories"),
+		brands:     client.Database(database).Collection(collection + "_brands"),
+		movements:  client.Database(database).Collection(collection + "_stock_movements"),
 	}
 }
 
@@ -33,17 +39,12 @@ func (r *ProductsCatalogRepository) Create(ctx context.Context, product model.Pr
 
 	product.InStock = product.Qty > 0
 
-	// Create user on repository
-	if _, err := r.collection.InsertOne(ctx, product); err != nil {
-		writeError, ok := err.(mongo.WriteException)
-		if !ok {
-			return nil, fmt.Errorf("creating product %s on repository %w", product.Name, err)
-		}
+	product.Version = 1
 
-		for _, wErr := range writeError.WriteErrors {
-			if wErr.Code == mongoDBDuplicatedKeyErrorCode {
-				return nil, internalError.ErrProductSKUAlreadyExist
-			}
+	// Create user on repository
+	if _, err := r.collection.InsertOne(ctx, newMongoProduct(product)); err != nil {
+		if duplicated := getDuplicatedKeyError(err); duplicated != nil {
+			return nil, duplicated
 		}
 
 		return nil, fmt.Errorf("creating product %s on repository %w", product.Name, err)
@@ -54,7 +55,7 @@ func (r *ProductsCatalogRepository) Create(ctx context.Context, product model.Pr
 
 // Get a product by id
 func (r *ProductsCatalogRepository) GetByID(ctx context.Context, id string) (*model.Product, error) {
-	resp := r.collection.FindOne(ctx, bson.M{"_id": id})
+	resp := r.collection.FindOne(ctx, bson.M{"_id": id, "deleted_at": nil})
 
 	if resp.Err() != nil {
 		return nil, internalError.ErrProductNotFound
@@ -71,7 +72,24 @@ func (r *ProductsCatalogRepository) GetByID(ctx context.Context, id string) (*mo
 
 // Get a product by sku
 func (r *ProductsCatalogRepository) GetBySKU(ctx context.Context, sku string) (*model.Product, error) {
-	resp := r.collection.FindOne(ctx, bson.M{"sku": sku})
+	resp := r.collection.FindOne(ctx, bson.M{"skus": sku, "deleted_at": nil})
+
+	if resp.Err() != nil {
+		return nil, internalError.ErrProductNotFound
+	}
+
+	var product model.Product
+
+	if err := resp.Decode(&product); err != nil {
+		return nil, fmt.Errorf("decoding product from repository %w", err)
+	}
+
+	return &product, nil
+}
+
+// Get the product holding a slug, deleted products included
+func (r *ProductsCatalogRepository) GetBySlug(ctx context.Context, slug string) (*model.Product, error) {
+	resp := r.collection.FindOne(ctx, bson.M{"slugs": slug})
 
 	if resp.Err() != nil {
 		return nil, internalError.ErrProductNotFound
@@ -86,24 +104,67 @@ func (r *ProductsCatalogRepository) GetBySKU(ctx context.Context, sku string) (*
 	return &product, nil
 }
 
-// Delete product
-func (r *ProductsCatalogRepository) Delete(ctx context.Context, id string) error {
-	if _, err := r.collection.DeleteOne(ctx, bson.M{"_id": id}); err != nil {
+// Delete product, it is kept until purged so it can be restored
+// It is deleted and unlinked from the products related to it on a transaction, so neither write is applied alone
+func (r *ProductsCatalogRepository) Delete(ctx context.Context, id string, version int64) error {
+	return r.WithTransaction(ctx, func(txRepo Repository) error {
+		return r.delete(txRepo.(*mongoTransaction).context(ctx), id, version)
+	})
+}
+
+// Delete product and unlink it from the products related to it, ctx carries the transaction session
+func (r *ProductsCatalogRepository) delete(ctx context.Context, id string, version int64) error {
+	now := time.Now()
+
+	update := bson.M{
+		"$set": bson.M{
+			"deleted_at": now,
+			"updated_at": now,
+		},
+		"$inc": bson.M{
+			"version": 1,
+		},
+	}
+
+	resp, err := r.collection.UpdateOne(ctx, r.getVersionFilter(id, version), update)
+	if err != nil {
 		return fmt.Errorf("deleting product from repository %w", err)
 	}
 
+	if resp.MatchedCount == 0 {
+		if version != 0 {
+			return r.getVersionConflict(ctx, id)
+		}
+
+		return internalError.ErrProductNotFound
+	}
+
+	unlink := bson.M{
+		"$pull": bson.M{"relations": bson.M{"product_id": id}},
+		"$set":  bson.M{"updated_at": now},
+		"$inc":  bson.M{"version": 1},
+	}
+
+	if _, err := r.collection.UpdateMany(ctx, bson.M{"relations.product_id": id}, unlink); err != nil {
+		return fmt.Errorf("unlinking deleted product on repository %w", err)
+	}
+
 	return nil
 }
 
-// Update a product
-func (r *ProductsCatalogRepository) Update(ctx context.Context, id string, qty uint64) (*model.Product, error) {
-	filter := bson.M{"_id": id}
+// Restore a deleted product, restoring a product that is not deleted returns it unchanged
+func (r *ProductsCatalogRepository) Restore(ctx context.Context, id string) (*model.Product, error) {
+	filter := bson.M{"_id": id, "deleted_at": bson.M{"$ne": nil}}
 
 	update := bson.M{
+		"$unset": bson.M{
+			"deleted_at": "",
+		},
 		"$set": bson.M{
-			"qty":        qty,
 			"updated_at": time.Now(),
-			"in_stock":   qty > 0,
+		},
+		"$inc": bson.M{
+			"version": 1,
 		},
 	}
 
@@ -118,37 +179,309 @@ func (r *ProductsCatalogRepository) Update(ctx context.Context, id string, qty u
 			options.FindOneAndUpdate().SetReturnDocument(options.After)).
 		Decode(&product)
 	if err != nil {
-		return nil, internalError.ErrProductNotFound
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return r.GetByID(ctx, id)
+		}
+
+		return nil, fmt.Errorf("restoring product on repository %w", err)
 	}
 
 	return &product, nil
 }
 
+// Permanently remove the products deleted before the given time
+func (r *ProductsCatalogRepository) Purge(ctx context.Context, before time.Time) (int64, error) {
+	resp, err := r.collection.DeleteMany(ctx, bson.M{"deleted_at": bson.M{"$lt": before}})
+	if err != nil {
+		return 0, fmt.Errorf("purging products from repository %w", err)
+	}
+
+	return resp.DeletedCount, nil
+}
+
+// List the products with a scheduled price starting or ending at the given time or before
+func (r *ProductsCatalogRepository) ListDuePrices(ctx context.Context, at time.Time) ([]model.Product, error) {
+	resp, err := r.collection.Find(ctx, bson.M{"price_changes_at": bson.M{"$lte": at}, "deleted_at": nil})
+	if err != nil {
+		return nil, fmt.Errorf("listing due prices on repository %w", err)
+	}
+
+	var products []model.Product
+
+	if err := resp.All(ctx, &products); err != nil {
+		return nil, fmt.Errorf("decoding product from repository %w", err)
+	}
+
+	return products, nil
+}
+
+// Update a product
+func (r *ProductsCatalogRepository) Update(ctx context.Context, request model.Update) (*model.Product, error) {
+	filter := r.getVersionFilter(request.ID, request.Version)
+
+	var update interface{}
+
+	if request.VariantSKU == "" {
+		// Products with variants take their qty and price from them
+		if request.Qty != nil || request.Pricing != nil {
+			filter["variants.0"] = bson.M{"$exists": false}
+		}
+
+		set := bson.M{
+			"updated_at": time.Now(),
+		}
+
+		// The stock is left as it is unless it is requested
+		if request.Qty != nil {
+			set["qty"], set["in_stock"] = *request.Qty, *request.Qty > 0
+		}
+
+		if request.Attributes != nil {
+			set["attributes"] = request.Attributes
+		}
+
+		if request.Pricing != nil {
+			set["price"] = request.Pricing.Price
+
+			for field, value := range getPricingFields(request.Pricing) {
+				set[field] = value
+			}
+		}
+
+		if request.Tags != nil {
+			set["tags"] = request.Tags
+		}
+
+		fields := bson.M{
+			"$set": set,
+			"$inc": bson.M{
+				"version": 1,
+			},
+		}
+
+		// An empty brand id removes the product brand
+		if request.BrandID != nil && *request.BrandID == "" {
+			fields["$unset"] = bson.M{"brand_id": ""}
+		} else if request.BrandID != nil {
+			set["brand_id"] = *request.BrandID
+		}
+
+		update = fields
+	} else {
+		filter["variants.sku"] = request.VariantSKU
+
+		update = getVariantUpdate(request)
+	}
+
+	var product model.Product
+
+	err := r.
+		collection.
+		FindOneAndUpdate(
+			ctx,
+			filter,
+			update,
+			options.FindOneAndUpdate().SetReturnDocument(options.After)).
+		Decode(&product)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, r.getUpdateError(ctx, request)
+		}
+
+		return nil, fmt.Errorf("updating product on repository %w", err)
+	}
+
+	return &product, nil
+}
+
+// Replace a product, the stored document is only replaced when it is on the product version
+func (r *ProductsCatalogRepository) Replace(ctx context.Context, product model.Product) (*model.Product, error) {
+	filter := bson.M{"_id": product.ID, "version": product.Version, "deleted_at": nil}
+
+	prepareReplace(&product)
+
+	resp, err := r.collection.ReplaceOne(ctx, filter, newMongoProduct(product))
+	if err != nil {
+		if duplicated := getDuplicatedKeyError(err); duplicated != nil {
+			return nil, duplicated
+		}
+
+		return nil, fmt.Errorf("replacing product on repository %w", err)
+	}
+
+	if resp.MatchedCount == 0 {
+		return nil, r.getVersionConflict(ctx, product.ID)
+	}
+
+	return &product, nil
+}
+
+// Pipeline that sets the qty of the updated variant and derives the product qty and in_stock from every variant
+func getVariantUpdate(request model.Update) mongo.Pipeline {
+	// Literals are used so values starting with $ are not read as field paths
+	fields := bson.M{}
+
+	if request.Qty != nil {
+		fields["qty"], fields["in_stock"] = bson.M{"$literal": *request.Qty}, *request.Qty > 0
+	}
+
+	if request.Pricing != nil {
+		fields["price"] = bson.M{"$literal": request.Pricing.Price}
+	}
+
+	variant := bson.M{
+		"$cond": bson.A{
+			bson.M{"$eq": bson.A{"$$variant.sku", bson.M{"$literal": request.VariantSKU}}},
+			bson.M{"$mergeObjects": bson.A{"$$variant", fields}},
+			"$$variant",
+		},
+	}
+
+	set := bson.M{
+		"qty":        bson.M{"$sum": "$variants.qty"},
+		"updated_at": time.Now(),
+		"version":    bson.M{"$add": bson.A{"$version", 1}},
+	}
+
+	if request.Attributes != nil {
+		set["attributes"] = bson.M{"$literal": request.Attributes}
+	}
+
+	if request.Tags != nil {
+		set["tags"] = bson.M{"$literal": request.Tags}
+	}
+
+	// An empty brand id removes the product brand
+	if request.BrandID != nil && *request.BrandID == "" {
+		set["brand_id"] = "$$REMOVE"
+	} else if request.BrandID != nil {
+		set["brand_id"] = bson.M{"$literal": *request.BrandID}
+	}
+
+	// Products with variants take their price and price range from them
+	if request.Pricing != nil {
+		set["price"] = bson.M{"$min": "$variants.price"}
+
+		set["price_range"] = bson.M{"min": bson.M{"$min": "$variants.price"}, "max": bson.M{"$max": "$variants.price"}}
+
+		for field, value := range getPricingFields(request.Pricing) {
+			set[field] = bson.M{"$literal": value}
+		}
+	}
+
+	return mongo.Pipeline{
+		{{Key: "$set", Value: bson.M{"variants": bson.M{"$map": bson.M{"input": "$variants", "as": "variant", "in": variant}}}}},
+		{{Key: "$set", Value: set}},
+		{{Key: "$set", Value: bson.M{"in_stock": bson.M{"$gt": bson.A{"$qty", 0}}}}},
+	}
+}
+
+// Tell which unique index a write error violated, nil when it is not a duplicated key error
+// The slugs index rejects slugs held by another product, the sku index the rest
+func getDuplicatedKeyError(err error) error {
+	var writeError mongo.WriteException
+
+	if !errors.As(err, &writeError) {
+		return nil
+	}
+
+	for _, wErr := range writeError.WriteErrors {
+		if wErr.Code != mongoDBDuplicatedKeyErrorCode {
+			continue
+		}
+
+		if strings.Contains(wErr.Message, "index: "+slugsIndexName+" ") {
+			return internalError.ErrSlugAlreadyExist
+		}
+
+		return internalError.ErrProductSKUAlreadyExist
+	}
+
+	return nil
+}
+
+// Called when an update matched no product, to tell why from the stored product
+func (r *ProductsCatalogRepository) getUpdateError(ctx context.Context, request model.Update) error {
+	product, err := r.GetByID(ctx, request.ID)
+	if err != nil {
+		return err
+	}
+
+	if err := checkUpdate(*product, request); err != nil {
+		return err
+	}
+
+	// The product changed after the update was filtered
+	return &internalError.VersionConflictError{Current: product.Version}
+}
+
+// Filter a not deleted product by id, and by version when it is not 0
+func (r *ProductsCatalogRepository) getVersionFilter(id string, version int64) bson.M {
+	filter := bson.M{"_id": id, "deleted_at": nil}
+
+	if version != 0 {
+		filter["version"] = version
+	}
+
+	return filter
+}
+
+// Called when a versioned write matched no product, to tell a missing product from a version conflict
+func (r *ProductsCatalogRepository) getVersionConflict(ctx context.Context, id string) error {
+	product, err := r.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return &internalError.VersionConflictError{Current: product.Version}
+}
+
 // Search products
-func (r *ProductsCatalogRepository) Search(ctx context.Context, name string, sort string, inStock bool, limit int, offset int) ([]model.Product, *int64, error) {
+func (r *ProductsCatalogRepository) Search(ctx context.Context, request model.SearchRequest) (*model.Page, error) {
 	eg, _ := errgroup.WithContext(ctx)
 
-	filter := r.getSearchFilter(name, inStock)
+	// Sessions can not be used concurrently, so searches on a transaction run one call at a time
+	if mongo.SessionFromContext(ctx) != nil {
+		eg.SetLimit(1)
+	}
+
+	filter := r.getSearchFilter(request)
 
 	var products []model.Product
 
+	var more bool
+
 	var total int64
 
 	// Call concurrenlty search method
 	eg.Go(func() error {
-		prds, err := r.search(ctx, filter, sort, limit, offset)
+		search := r.search
+
+		if request.Currency.Selected() {
+			search = r.searchInCurrency
+		}
+
+		prds, mr, err := search(ctx, filter, request)
 		if err != nil {
 			return err
 		}
 
-		products = prds
+		products, more = prds, mr
 
 		return nil
 	})
 
 	// Call concurrenlty get total items on db
 	eg.Go(func() error {
-		tl, err := r.getTotal(ctx, filter)
+		getTotal := r.getTotal
+
+		if request.Currency.Selected() {
+			getTotal = func(ctx context.Context, filter bson.M) (int64, error) {
+				return r.getTotalInCurrency(ctx, filter, request.Currency)
+			}
+		}
+
+		tl, err := getTotal(ctx, filter)
 		if err != nil {
 			return err
 		}
@@ -159,59 +492,220 @@ func (r *ProductsCatalogRepository) Search(ctx context.Context, name string, sor
 	})
 
 	if err := eg.Wait(); err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 
-	return products, &total, nil
+	return newPage(products, total, request, more), nil
 }
 
-func (r *ProductsCatalogRepository) search(ctx context.Context, filter bson.M, sort string, limit int, offset int) ([]model.Product, error) {
+func (r *ProductsCatalogRepository) search(ctx context.Context, filter bson.M, request model.SearchRequest) ([]model.Product, bool, error) {
 	opt := options.Find()
 
-	opt.SetLimit(int64(limit))
+	sortValue := 1
 
-	opt.SetSkip(int64(offset))
+	if request.Sort == "desc" {
+		sortValue = -1
+	}
+
+	cursor := request.Cursor
 
-	sortValue := -1
+	if cursor == nil {
+		opt.SetLimit(int64(request.Limit))
 
-	if sort == "desc" {
-		sortValue = 1
+		opt.SetSkip(int64(request.Offset))
+	} else {
+		filter = r.getCursorFilter(filter, "price", cursor, sortValue)
+
+		// Walk the sort order backwards to get the products placed before the cursor
+		if cursor.Backward {
+			sortValue = -sortValue
+		}
+
+		// Fetch an extra product to know whether there is another page
+		if request.Limit > 0 {
+			opt.SetLimit(int64(request.Limit + 1))
+		}
 	}
 
-	opt.SetSort(bson.M{"price": sortValue})
+	// Sort by id too so products with the same price keep a stable order between pages
+	opt.SetSort(bson.D{{Key: "price", Value: sortValue}, {Key: "_id", Value: sortValue}})
 
-	cursor, err := r.collection.Find(ctx, filter, opt)
+	resp, err := r.collection.Find(ctx, filter, opt)
 	if err != nil {
-		return nil, err
+		return nil, false, err
 	}
 
 	var products []model.Product
 
-	for cursor.Next(ctx) {
+	for resp.Next(ctx) {
 		var product model.Product
 
-		if err := cursor.Decode(&product); err != nil {
-			return nil, fmt.Errorf("decoding product from repository %w", err)
+		if err := resp.Decode(&product); err != nil {
+			return nil, false, fmt.Errorf("decoding product from repository %w", err)
 		}
 
 		products = append(products, product)
 	}
 
-	return products, nil
+	if cursor == nil {
+		return products, false, nil
+	}
+
+	products, more := keysetPage(products, request)
+
+	return products, more, nil
+}
+
+// Add to filter the condition that keeps the products placed after the cursor, or before it when it is backward
+// Products are sorted by the price on priceField
+func (r *ProductsCatalogRepository) getCursorFilter(filter bson.M, priceField string, cursor *model.Cursor, sortValue int) bson.M {
+	operator := "$gt"
+
+	if (sortValue < 0) != cursor.Backward {
+		operator = "$lt"
+	}
+
+	keyset := bson.M{}
+
+	for key, value := range filter {
+		keyset[key] = value
+	}
+
+	keyset["$or"] = []bson.M{
+		{priceField: bson.M{operator: cursor.Price}},
+		{priceField: cursor.Price, "_id": bson.M{operator: cursor.ID}},
+	}
+
+	return keyset
 }
 
 func (r *ProductsCatalogRepository) getTotal(ctx context.Context, filter bson.M) (int64, error) {
 	return r.collection.CountDocuments(ctx, filter)
 }
 
-func (r *ProductsCatalogRepository) getSearchFilter(name string, inStock bool) bson.M {
+func (r *ProductsCatalogRepository) getSearchFilter(request model.SearchRequest) bson.M {
 	filter := bson.M{
-		"in_stock": inStock,
+		"in_stock": request.InStock,
+	}
+
+	if len(request.CategoryIDs) > 0 {
+		filter["category_ids"] = bson.M{"$in": request.CategoryIDs}
+	}
+
+	if request.BrandID != "" {
+		filter["brand_id"] = request.BrandID
+	}
+
+	if request.ComponentID != "" {
+		filter["bundle.components.product_id"] = request.ComponentID
+	}
+
+	if len(request.Tags) > 0 {
+		operator := "$in"
+
+		if request.TagMatch == model.TagMatchAll {
+			operator = "$all"
+		}
+
+		filter["tags"] = bson.M{operator: request.Tags}
+	}
+
+	if request.Name != "" {
+		search := bson.M{"$search": request.Name}
+
+		// Terms are stemmed and stop words removed with the language of the requested locale
+		if request.Locale != "" {
+			search["$language"] = textLanguage(request.Locale)
+		}
+
+		filter["$text"] = search
+	}
+
+	conditions := append(r.getAttributeFilter(request.Attributes), r.getStatusFilter(request)...)
+
+	for _, measure := range request.Measures {
+		conditions = append(conditions, bson.M{measureFields[measure.Measure]: bson.M{"$" + measure.Operator: measure.Value}})
+	}
+
+	if len(conditions) > 0 {
+		filter["$and"] = conditions
 	}
 
-	if name != "" {
-		filter["$text"] = bson.M{"$search": name}
+	if !request.IncludeDeleted {
+		filter["deleted_at"] = nil
 	}
 
 	return filter
 }
+
+// Conditions matching every attribute filter, equality filters match the value written as string, number or bool
+func (r *ProductsCatalogRepository) getAttributeFilter(filters []model.AttributeFilter) []bson.M {
+	conditions := make([]bson.M, 0, len(filters))
+
+	for _, filter := range filters {
+		key := "attributes." + filter.Name
+
+		switch filter.Operator {
+		case model.AttributeGreaterOrEqual:
+			conditions = append(conditions, bson.M{key: bson.M{"$gte": filter.Number()}})
+		case model.AttributeLessOrEqual:
+			conditions = append(conditions, bson.M{key: bson.M{"$lte": filter.Number()}})
+		default:
+			conditions = append(conditions, bson.M{key: bson.M{"$in": attributeValues(filter)}})
+		}
+	}
+
+	return conditions
+}
+
+// Conditions matching the requested statuses and the products visible at the requested time
+// Products stored without status are published, so published matches a missing status too
+func (r *ProductsCatalogRepository) getStatusFilter(request model.SearchRequest) []bson.M {
+	var conditions []bson.M
+
+	if len(request.Statuses) > 0 {
+		conditions = append(conditions, bson.M{"status": bson.M{"$in": statusValues(request.Statuses)}})
+	}
+
+	if at := request.VisibleAt; at != nil {
+		conditions = append(
+			conditions,
+			bson.M{"status": bson.M{"$in": statusValues([]model.Status{model.StatusPublished})}},
+			bson.M{"publish_at": bson.M{"$not": bson.M{"$gt": *at}}},
+			bson.M{"unpublish_at": bson.M{"$not": bson.M{"$lte": *at}}},
+		)
+	}
+
+	return conditions
+}
+
+func statusValues(statuses []model.Status) bson.A {
+	values := bson.A{}
+
+	for _, status := range statuses {
+		values = append(values, status)
+
+		if status == model.StatusPublished {
+			values = append(values, nil)
+		}
+	}
+
+	return values
+}
+
+// Pricing fields replaced by an update, unset ones are removed like the omitted fields of a created product
+func getPricingFields(pricing *model.Pricing) bson.M {
+	fields := bson.M{
+		"regular_price":  pricing.RegularPrice,
+		"price_history":  pricing.PriceHistory,
+		"price_schedule": pricing.PriceSchedule,
+	}
+
+	if pricing.PriceChangesAt != nil {
+		fields["price_changes_at"] = *pricing.PriceChangesAt
+	} else {
+		fields["price_changes_at"] = nil
+	}
+
+	return fields
+}
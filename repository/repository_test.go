@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"testing"
+	"time"
 
 	internalError "github.com/srodrmendz/api-product-catalog/errors"
 	"github.com/srodrmendz/api-product-catalog/model"
@@ -30,7 +31,7 @@ func TestRepository_Integration_Create(t *testing.T) {
 			Qty:    10,
 			Name:   "product 1",
 			Sku:    "sku1",
-			Images: []string{"https://google.com/image1.png"},
+			Images: []model.Image{{ID: "image1", URL: "https://google.com/image1.png", Primary: true}},
 		})
 
 		// Then
@@ -50,9 +51,7 @@ func TestRepository_Integration_Create(t *testing.T) {
 
 		assert.Equal(t, false, product.UpdatedAt.IsZero())
 
-		err = repo.Delete(ctx, response.ID)
-
-		require.NoError(t, err)
+		deleteProduct(t, ctx, repo, response.ID)
 	})
 
 	t.Run("failed to create product, sku already founded", func(t *testing.T) {
@@ -70,7 +69,7 @@ func TestRepository_Integration_Create(t *testing.T) {
 			Qty:    10,
 			Name:   "product 1",
 			Sku:    "sku1",
-			Images: []string{"https://google.com/image1.png"},
+			Images: []model.Image{{ID: "image1", URL: "https://google.com/image1.png", Primary: true}},
 		})
 
 		// Then
@@ -84,9 +83,7 @@ func TestRepository_Integration_Create(t *testing.T) {
 
 		assert.EqualError(t, internalError.ErrProductSKUAlreadyExist, err.Error())
 
-		err = repo.Delete(ctx, product.ID)
-
-		require.NoError(t, err)
+		deleteProduct(t, ctx, repo, product.ID)
 	})
 }
 
@@ -106,7 +103,7 @@ func TestRepository_Integration_GetByID(t *testing.T) {
 			Qty:    10,
 			Name:   "product 1",
 			Sku:    "sku1",
-			Images: []string{"https://google.com/image1.png"},
+			Images: []model.Image{{ID: "image1", URL: "https://google.com/image1.png", Primary: true}},
 		})
 
 		require.NoError(t, err)
@@ -126,9 +123,7 @@ func TestRepository_Integration_GetByID(t *testing.T) {
 
 		assert.Equal(t, false, product.UpdatedAt.IsZero())
 
-		err = repo.Delete(ctx, response.ID)
-
-		require.NoError(t, err)
+		deleteProduct(t, ctx, repo, response.ID)
 	})
 
 	t.Run("failed to get by id, product not found", func(t *testing.T) {
@@ -167,7 +162,7 @@ func TestRepository_Integration_GetBySKU(t *testing.T) {
 			Qty:    10,
 			Name:   "product 1",
 			Sku:    "sku1",
-			Images: []string{"https://google.com/image1.png"},
+			Images: []model.Image{{ID: "image1", URL: "https://google.com/image1.png", Primary: true}},
 		})
 
 		require.NoError(t, err)
@@ -187,9 +182,7 @@ func TestRepository_Integration_GetBySKU(t *testing.T) {
 
 		assert.Equal(t, false, product.UpdatedAt.IsZero())
 
-		err = repo.Delete(ctx, response.ID)
-
-		require.NoError(t, err)
+		deleteProduct(t, ctx, repo, response.ID)
 	})
 
 	t.Run("failed to get by sku, product not found", func(t *testing.T) {
@@ -228,15 +221,13 @@ func TestRepository_Integration_Delete(t *testing.T) {
 			Qty:    10,
 			Name:   "product 1",
 			Sku:    "sku1",
-			Images: []string{"https://google.com/image1.png"},
+			Images: []model.Image{{ID: "image1", URL: "https://google.com/image1.png", Primary: true}},
 		})
 
 		// Then
 		require.NoError(t, err)
 
-		err = repo.Delete(ctx, product.ID)
-
-		require.NoError(t, err)
+		deleteProduct(t, ctx, repo, product.ID)
 	})
 }
 
@@ -255,12 +246,14 @@ func TestRepository_Integration_Update(t *testing.T) {
 		product, err := repo.Create(ctx, model.Product{
 			Name:   "product 1",
 			Sku:    "sku1",
-			Images: []string{"https://google.com/image1.png"},
+			Images: []model.Image{{ID: "image1", URL: "https://google.com/image1.png", Primary: true}},
 		})
 
 		require.NoError(t, err)
 
-		resp, err := repo.Update(ctx, product.ID, 1000)
+		qty := uint64(1000)
+
+		resp, err := repo.Update(ctx, model.Update{ID: product.ID, UpdateRequest: model.UpdateRequest{Qty: &qty}})
 
 		// Then
 		assert.NoError(t, err)
@@ -271,9 +264,7 @@ func TestRepository_Integration_Update(t *testing.T) {
 
 		assert.Equal(t, uint64(1000), resp.Qty)
 
-		err = repo.Delete(ctx, product.ID)
-
-		require.NoError(t, err)
+		deleteProduct(t, ctx, repo, product.ID)
 	})
 
 	t.Run("failed update product, product not found", func(t *testing.T) {
@@ -287,7 +278,9 @@ func TestRepository_Integration_Update(t *testing.T) {
 		repo := New(client, "ecommerce", "products")
 
 		// When
-		product, err := repo.Update(ctx, "fake", 1000)
+		qty := uint64(1000)
+
+		product, err := repo.Update(ctx, model.Update{ID: "fake", UpdateRequest: model.UpdateRequest{Qty: &qty}})
 
 		// Then
 		require.Error(t, err)
@@ -317,7 +310,7 @@ func TestRepository_Integration_Search(t *testing.T) {
 				Qty:    10,
 				Name:   "product 1",
 				Sku:    fmt.Sprintf("sku%d", i),
-				Images: []string{"https://google.com/image1.png"},
+				Images: []model.Image{{ID: "image1", URL: "https://google.com/image1.png", Primary: true}},
 			})
 
 			require.NoError(t, err)
@@ -325,25 +318,37 @@ func TestRepository_Integration_Search(t *testing.T) {
 			productsIDS = append(productsIDS, product.ID)
 		}
 
-		resp, total, err := repo.Search(ctx, "", "asc", true, 0, 0)
+		resp, err := repo.Search(ctx, model.SearchRequest{
+			Sort:    "asc",
+			InStock: true,
+		})
 
 		// Then
 		require.NoError(t, err)
 
 		require.NoError(t, err)
 
-		assert.Less(t, 0, len(resp))
+		assert.Less(t, 0, len(resp.Products))
 
-		assert.Less(t, 0, int(*total))
+		assert.Less(t, 0, int(resp.Total))
 
 		for _, id := range productsIDS {
-			err = repo.Delete(ctx, id)
-
-			require.NoError(t, err)
+			deleteProduct(t, ctx, repo, id)
 		}
 	})
 }
 
+// Delete product and purge it so its sku can be used again by the next run
+func deleteProduct(t *testing.T, ctx context.Context, repo *ProductsCatalogRepository, id string) {
+	err := repo.Delete(ctx, id, 0)
+
+	require.NoError(t, err)
+
+	_, err = repo.Purge(ctx, time.Now().Add(time.Second))
+
+	require.NoError(t, err)
+}
+
 func createMongoClient(t *testing.T, ctx context.Context) *mongo.Client {
 	clientOptions := options.Client().ApplyURI("mongodb://localhost:27017")
 
@@ -0,0 +1,225 @@
+package repotest
+
+import (
+	"context"
+	"testing"
+
+	internalError "github.com/srodrmendz/api-product-catalog/errors"
+	"github.com/srodrmendz/api-product-catalog/model"
+	"github.com/srodrmendz/api-product-catalog/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testBrands(t *testing.T, factory Factory) {
+	t.Run("successfully create, update and delete brand", func(t *testing.T) {
+		// Given
+		ctx := context.TODO()
+
+		repo := factory(t)
+
+		description := "running shoes"
+
+		// When
+		brand, err := repo.CreateBrand(ctx, model.Brand{Name: "Acme", Description: &description})
+
+		// Then
+		require.NoError(t, err)
+
+		assert.NotEmpty(t, brand.ID)
+
+		assert.Equal(t, false, brand.CreatedAt.IsZero())
+
+		stored, err := repo.GetBrand(ctx, brand.ID)
+
+		require.NoError(t, err)
+
+		assert.Equal(t, "Acme", stored.Name)
+
+		assert.Equal(t, &description, stored.Description)
+
+		updated, err := repo.UpdateBrand(ctx, model.Brand{ID: brand.ID, Name: "Acme Sports"})
+
+		require.NoError(t, err)
+
+		assert.Equal(t, "Acme Sports", updated.Name)
+
+		assert.Nil(t, updated.Description)
+
+		require.NoError(t, repo.DeleteBrand(ctx, brand.ID))
+
+		_, err = repo.GetBrand(ctx, brand.ID)
+
+		assert.ErrorIs(t, err, internalError.ErrBrandNotFound)
+	})
+
+	t.Run("failed to update and delete brand, brand not found", func(t *testing.T) {
+		// Given
+		ctx := context.TODO()
+
+		repo := factory(t)
+
+		// When
+		_, err := repo.UpdateBrand(ctx, model.Brand{ID: "fake", Name: "Acme"})
+
+		// Then
+		assert.ErrorIs(t, err, internalError.ErrBrandNotFound)
+
+		assert.ErrorIs(t, repo.DeleteBrand(ctx, "fake"), internalError.ErrBrandNotFound)
+	})
+
+	t.Run("successfully list brands sorted by name", func(t *testing.T) {
+		// Given
+		repo := factory(t)
+
+		for _, name := range []string{"Zeta", "Acme", "Mondo"} {
+			_, err := repo.CreateBrand(context.TODO(), model.Brand{Name: name})
+
+			require.NoError(t, err)
+		}
+
+		// When
+		brands, err := repo.ListBrands(context.TODO())
+
+		// Then
+		require.NoError(t, err)
+
+		var names []string
+
+		for _, brand := range brands {
+			names = append(names, brand.Name)
+		}
+
+		assert.Equal(t, []string{"Acme", "Mondo", "Zeta"}, names)
+	})
+
+	t.Run("successfully search products by brand and tags", func(t *testing.T) {
+		// Given
+		repo := factory(t)
+
+		shoes := createProduct(t, repo, model.Product{Name: "shoes", Sku: "shoes", Qty: 1, Price: 100, BrandID: "acme", Tags: []string{"sale", "running"}})
+
+		shirt := createProduct(t, repo, model.Product{Name: "shirt", Sku: "shirt", Qty: 1, Price: 200, BrandID: "acme", Tags: []string{"sale"}})
+
+		hat := createProduct(t, repo, model.Product{Name: "hat", Sku: "hat", Qty: 1, Price: 300, BrandID: "mondo", Tags: []string{"running"}})
+
+		createProduct(t, repo, model.Product{Name: "coat", Sku: "coat", Qty: 1, Price: 400})
+
+		tests := []struct {
+			name     string
+			request  model.SearchRequest
+			expected []string
+		}{
+			{
+				name:     "brand",
+				request:  model.SearchRequest{BrandID: "acme"},
+				expected: []string{shoes.ID, shirt.ID},
+			},
+			{
+				name:     "any tag",
+				request:  model.SearchRequest{Tags: []string{"sale", "running"}, TagMatch: model.TagMatchAny},
+				expected: []string{shoes.ID, shirt.ID, hat.ID},
+			},
+			{
+				name:     "all tags",
+				request:  model.SearchRequest{Tags: []string{"sale", "running"}, TagMatch: model.TagMatchAll},
+				expected: []string{shoes.ID},
+			},
+			{
+				name:     "brand and tag",
+				request:  model.SearchRequest{BrandID: "mondo", Tags: []string{"running"}},
+				expected: []string{hat.ID},
+			},
+		}
+
+		for _, test := range tests {
+			test.request.InStock, test.request.Sort = true, "asc"
+
+			// When
+			page := search(t, repo, test.request)
+
+			// Then
+			assert.Equal(t, test.expected, ids(page.Products), test.name)
+
+			assert.Equal(t, int64(len(test.expected)), page.Total, test.name)
+		}
+	})
+
+	t.Run("successfully update product brand and tags", func(t *testing.T) {
+		// Given
+		ctx := context.TODO()
+
+		repo := factory(t)
+
+		product := createProduct(t, repo, model.Product{Name: "shoes", Sku: "shoes", Qty: 1, Price: 100, BrandID: "acme", Tags: []string{"sale"}})
+
+		mondo := "mondo"
+
+		// When
+		updated, err := repo.Update(ctx, model.Update{
+			ID:            product.ID,
+			UpdateRequest: model.UpdateRequest{BrandID: &mondo, Tags: []string{"new", "running"}},
+		})
+
+		// Then
+		require.NoError(t, err)
+
+		assert.Equal(t, "mondo", updated.BrandID)
+
+		assert.Equal(t, []string{"new", "running"}, updated.Tags)
+
+		// Brand and tags updates leave the stock as it is
+		assert.Equal(t, uint64(1), updated.Qty)
+
+		none := ""
+
+		updated, err = repo.Update(ctx, model.Update{ID: product.ID, UpdateRequest: model.UpdateRequest{BrandID: &none}})
+
+		require.NoError(t, err)
+
+		assert.Equal(t, "", updated.BrandID)
+
+		assert.Equal(t, []string{"new", "running"}, updated.Tags)
+
+		assert.Equal(t, uint64(1), updated.Qty)
+	})
+
+	t.Run("successfully count tags of the products matching the filters", func(t *testing.T) {
+		// Given
+		repo := factory(t)
+
+		createProduct(t, repo, model.Product{Name: "shoes", Sku: "shoes", Qty: 1, Price: 100, BrandID: "acme", Tags: []string{"sale", "running"}})
+
+		createProduct(t, repo, model.Product{Name: "shirt", Sku: "shirt", Qty: 1, Price: 200, BrandID: "acme", Tags: []string{"sale", "new"}})
+
+		createProduct(t, repo, model.Product{Name: "hat", Sku: "hat", Qty: 1, Price: 300, BrandID: "mondo", Tags: []string{"running"}})
+
+		createProduct(t, repo, model.Product{Name: "coat", Sku: "coat", Qty: 0, Price: 400, BrandID: "acme", Tags: []string{"sale"}})
+
+		// When
+		all := countTags(t, repo, model.SearchRequest{InStock: true})
+
+		acme := countTags(t, repo, model.SearchRequest{InStock: true, BrandID: "acme"})
+
+		running := countTags(t, repo, model.SearchRequest{InStock: true, Tags: []string{"running"}})
+
+		// Then
+		assert.Equal(t, []model.TagCount{{Tag: "running", Count: 2}, {Tag: "sale", Count: 2}, {Tag: "new", Count: 1}}, all)
+
+		assert.Equal(t, []model.TagCount{{Tag: "sale", Count: 2}, {Tag: "new", Count: 1}, {Tag: "running", Count: 1}}, acme)
+
+		assert.Equal(t, []model.TagCount{{Tag: "running", Count: 2}, {Tag: "sale", Count: 1}}, running)
+
+		assert.Equal(t, []model.TagCount{}, countTags(t, repo, model.SearchRequest{InStock: true, BrandID: "fake"}))
+	})
+}
+
+func countTags(t *testing.T, repo repository.Repository, request model.SearchRequest) []model.TagCount {
+	t.Helper()
+
+	tags, err := repo.CountTags(context.TODO(), request)
+
+	require.NoError(t, err)
+
+	return tags
+}
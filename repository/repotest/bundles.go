@@ -0,0 +1,70 @@
+package repotest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/srodrmendz/api-product-catalog/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testBundles(t *testing.T, factory Factory) {
+	t.Run("successfully store product bundle", func(t *testing.T) {
+		// Given
+		repo := factory(t)
+
+		bundle := &model.Bundle{
+			Components: []model.BundleComponent{{ProductID: "shoes", Qty: 1}, {ProductID: "socks", Qty: 2}},
+			Pricing:    model.BundlePricingComponents,
+			Discount:   50,
+		}
+
+		product := createProduct(t, repo, model.Product{Name: "kit", Sku: "kit", Qty: 1, Price: 250, Bundle: bundle})
+
+		// When
+		stored, err := repo.GetByID(context.TODO(), product.ID)
+
+		// Then
+		require.NoError(t, err)
+
+		assert.Equal(t, bundle, stored.Bundle)
+
+		updated, err := repo.Update(context.TODO(), model.Update{ID: product.ID, UpdateRequest: model.UpdateRequest{Qty: qtyOf(3)}})
+
+		require.NoError(t, err)
+
+		assert.Equal(t, bundle, updated.Bundle)
+	})
+
+	t.Run("successfully search bundles holding a product", func(t *testing.T) {
+		// Given
+		repo := factory(t)
+
+		running := createProduct(t, repo, model.Product{Name: "running kit", Sku: "running-kit", Qty: 1, Price: 100, Bundle: &model.Bundle{
+			Components: []model.BundleComponent{{ProductID: "shoes", Qty: 1}, {ProductID: "socks", Qty: 2}},
+			Pricing:    model.BundlePricingFixed,
+		}})
+
+		hiking := createProduct(t, repo, model.Product{Name: "hiking kit", Sku: "hiking-kit", Qty: 1, Price: 200, Bundle: &model.Bundle{
+			Components: []model.BundleComponent{{ProductID: "boots", Qty: 1}, {ProductID: "socks", Qty: 1}},
+			Pricing:    model.BundlePricingFixed,
+		}})
+
+		createProduct(t, repo, model.Product{Name: "socks", Sku: "socks", Qty: 1, Price: 10})
+
+		// When
+		socks := search(t, repo, model.SearchRequest{InStock: true, Sort: "asc", ComponentID: "socks"})
+
+		shoes := search(t, repo, model.SearchRequest{InStock: true, Sort: "asc", ComponentID: "shoes"})
+
+		hat := search(t, repo, model.SearchRequest{InStock: true, Sort: "asc", ComponentID: "hat"})
+
+		// Then
+		assert.Equal(t, []string{running.ID, hiking.ID}, ids(socks.Products))
+
+		assert.Equal(t, []string{running.ID}, ids(shoes.Products))
+
+		assert.Empty(t, hat.Products)
+	})
+}
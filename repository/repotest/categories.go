@@ -0,0 +1,401 @@
+package repotest
+
+import (
+	"context"
+	"testing"
+
+	internalError "github.com/srodrmendz/api-product-catalog/errors"
+	"github.com/srodrmendz/api-product-catalog/model"
+	"github.com/srodrmendz/api-product-catalog/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testCategories(t *testing.T, factory Factory) {
+	t.Run("successfully create and get category", func(t *testing.T) {
+		// Given
+		ctx := context.TODO()
+
+		repo := factory(t)
+
+		root := createCategory(t, repo, model.Category{Name: "clothing"})
+
+		// When
+		category, err := repo.CreateCategory(ctx, model.Category{Name: "shoes", ParentID: root.ID, Path: []string{root.ID}})
+
+		// Then
+		require.NoError(t, err)
+
+		assert.NotEmpty(t, category.ID)
+
+		assert.Equal(t, false, category.CreatedAt.IsZero())
+
+		stored, err := repo.GetCategory(ctx, category.ID)
+
+		require.NoError(t, err)
+
+		assert.Equal(t, "shoes", stored.Name)
+
+		assert.Equal(t, root.ID, stored.ParentID)
+
+		assert.Equal(t, []string{root.ID}, stored.Path)
+
+		stored, err = repo.GetCategory(ctx, root.ID)
+
+		require.NoError(t, err)
+
+		assert.Equal(t, "", stored.ParentID)
+
+		assert.Equal(t, []string{}, stored.Path)
+	})
+
+	t.Run("failed to get category, category not found", func(t *testing.T) {
+		// Given
+		repo := factory(t)
+
+		// When
+		category, err := repo.GetCategory(context.TODO(), "fake")
+
+		// Then
+		assert.ErrorIs(t, err, internalError.ErrCategoryNotFound)
+
+		assert.Nil(t, category)
+	})
+
+	t.Run("successfully list categories sorted by name", func(t *testing.T) {
+		// Given
+		repo := factory(t)
+
+		for _, name := range []string{"shoes", "hats", "coats"} {
+			createCategory(t, repo, model.Category{Name: name})
+		}
+
+		// When
+		categories, err := repo.ListCategories(context.TODO())
+
+		// Then
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"coats", "hats", "shoes"}, categoryNames(categories))
+	})
+
+	t.Run("successfully get category subtree", func(t *testing.T) {
+		// Given
+		repo := factory(t)
+
+		clothing := createCategory(t, repo, model.Category{Name: "clothing"})
+
+		shoes := createCategory(t, repo, model.Category{Name: "shoes", ParentID: clothing.ID, Path: []string{clothing.ID}})
+
+		createCategory(t, repo, model.Category{Name: "running", ParentID: shoes.ID, Path: []string{clothing.ID, shoes.ID}})
+
+		createCategory(t, repo, model.Category{Name: "hats", ParentID: clothing.ID, Path: []string{clothing.ID}})
+
+		// When
+		categories, err := repo.GetCategorySubtree(context.TODO(), shoes.ID)
+
+		// Then
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"running", "shoes"}, categoryNames(categories))
+
+		_, err = repo.GetCategorySubtree(context.TODO(), "fake")
+
+		assert.ErrorIs(t, err, internalError.ErrCategoryNotFound)
+	})
+
+	t.Run("successfully update category moves its descendants", func(t *testing.T) {
+		// Given
+		ctx := context.TODO()
+
+		repo := factory(t)
+
+		clothing := createCategory(t, repo, model.Category{Name: "clothing"})
+
+		sports := createCategory(t, repo, model.Category{Name: "sports"})
+
+		shoes := createCategory(t, repo, model.Category{Name: "shoes", ParentID: clothing.ID, Path: []string{clothing.ID}})
+
+		running := createCategory(t, repo, model.Category{Name: "running", ParentID: shoes.ID, Path: []string{clothing.ID, shoes.ID}})
+
+		// When
+		updated, err := repo.UpdateCategory(ctx, model.Category{ID: shoes.ID, Name: "sneakers", ParentID: sports.ID, Path: []string{sports.ID}})
+
+		// Then
+		require.NoError(t, err)
+
+		assert.Equal(t, "sneakers", updated.Name)
+
+		assert.Equal(t, sports.ID, updated.ParentID)
+
+		stored, err := repo.GetCategory(ctx, running.ID)
+
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{sports.ID, shoes.ID}, stored.Path)
+
+		// Moved back to the root
+		updated, err = repo.UpdateCategory(ctx, model.Category{ID: shoes.ID, Name: "sneakers"})
+
+		require.NoError(t, err)
+
+		assert.Equal(t, "", updated.ParentID)
+
+		stored, err = repo.GetCategory(ctx, running.ID)
+
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{shoes.ID}, stored.Path)
+
+		_, err = repo.UpdateCategory(ctx, model.Category{ID: "fake", Name: "fake"})
+
+		assert.ErrorIs(t, err, internalError.ErrCategoryNotFound)
+	})
+
+	t.Run("successfully delete category", func(t *testing.T) {
+		// Given
+		ctx := context.TODO()
+
+		repo := factory(t)
+
+		category := createCategory(t, repo, model.Category{Name: "shoes"})
+
+		// When
+		err := repo.DeleteCategory(ctx, category.ID)
+
+		// Then
+		require.NoError(t, err)
+
+		_, err = repo.GetCategory(ctx, category.ID)
+
+		assert.ErrorIs(t, err, internalError.ErrCategoryNotFound)
+
+		assert.ErrorIs(t, repo.DeleteCategory(ctx, category.ID), internalError.ErrCategoryNotFound)
+	})
+
+	t.Run("successfully search products on categories", func(t *testing.T) {
+		// Given
+		repo := factory(t)
+
+		createProduct(t, repo, model.Product{Name: "boots", Sku: "sku1", Qty: 1, Price: 100, CategoryIDs: []string{"shoes"}})
+
+		createProduct(t, repo, model.Product{Name: "cap", Sku: "sku2", Qty: 1, Price: 200, CategoryIDs: []string{"hats", "sale"}})
+
+		createProduct(t, repo, model.Product{Name: "scarf", Sku: "sku3", Qty: 1, Price: 300})
+
+		// When
+		page := search(t, repo, model.SearchRequest{InStock: true, Sort: "asc", CategoryIDs: []string{"shoes", "sale"}})
+
+		// Then
+		assert.Equal(t, []int64{100, 200}, prices(page.Products))
+
+		assert.Equal(t, int64(2), page.Total)
+
+		assert.Equal(t, []string{"hats", "sale"}, page.Products[1].CategoryIDs)
+	})
+
+	t.Run("successfully replace product category", func(t *testing.T) {
+		// Given
+		ctx := context.TODO()
+
+		repo := factory(t)
+
+		boots := createProduct(t, repo, model.Product{Name: "boots", Sku: "sku1", Qty: 1, CategoryIDs: []string{"running", "sale"}})
+
+		sneakers := createProduct(t, repo, model.Product{Name: "sneakers", Sku: "sku2", Qty: 1, CategoryIDs: []string{"running", "shoes"}})
+
+		scarf := createProduct(t, repo, model.Product{Name: "scarf", Sku: "sku3", Qty: 1, CategoryIDs: []string{"sale"}})
+
+		// When
+		replaced, err := repo.ReplaceProductCategory(ctx, "running", "shoes")
+
+		// Then
+		require.NoError(t, err)
+
+		assert.Equal(t, int64(2), replaced)
+
+		stored, err := repo.GetByID(ctx, boots.ID)
+
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"shoes", "sale"}, stored.CategoryIDs)
+
+		assert.Equal(t, boots.Version+1, stored.Version)
+
+		stored, err = repo.GetByID(ctx, sneakers.ID)
+
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"shoes"}, stored.CategoryIDs)
+
+		// Removed when there is no category to replace it
+		replaced, err = repo.ReplaceProductCategory(ctx, "sale", "")
+
+		require.NoError(t, err)
+
+		assert.Equal(t, int64(2), replaced)
+
+		stored, err = repo.GetByID(ctx, scarf.ID)
+
+		require.NoError(t, err)
+
+		assert.Empty(t, stored.CategoryIDs)
+	})
+}
+
+func createCategory(t *testing.T, repo repository.Repository, category model.Category) *model.Category {
+	created, err := repo.CreateCategory(context.TODO(), category)
+
+	require.NoError(t, err)
+
+	return created
+}
+
+func categoryNames(categories []model.Category) []string {
+	names := make([]string, 0, len(categories))
+
+	for _, category := range categories {
+		names = append(names, category.Name)
+	}
+
+	return names
+}
+
+func testAttributes(t *testing.T, factory Factory) {
+	t.Run("successfully store category attribute definitions", func(t *testing.T) {
+		// Given
+		ctx := context.TODO()
+
+		repo := factory(t)
+
+		definitions := []model.AttributeDefinition{
+			{Name: "material", Type: model.AttributeString, Required: true},
+			{Name: "size", Type: model.AttributeEnum, Values: []string{"s", "m"}},
+		}
+
+		category := createCategory(t, repo, model.Category{Name: "clothing", Attributes: definitions})
+
+		// When
+		stored, err := repo.GetCategory(ctx, category.ID)
+
+		// Then
+		require.NoError(t, err)
+
+		assert.Equal(t, definitions, stored.Attributes)
+
+		// When
+		updated, err := repo.UpdateCategory(ctx, model.Category{ID: category.ID, Name: "clothing", Path: []string{}})
+
+		// Then
+		require.NoError(t, err)
+
+		assert.Empty(t, updated.Attributes)
+	})
+
+	t.Run("successfully search products by attributes", func(t *testing.T) {
+		// Given
+		repo := factory(t)
+
+		cotton := createProduct(t, repo, model.Product{
+			Name:       "cotton",
+			Sku:        "cotton",
+			Qty:        1,
+			Price:      100,
+			Attributes: map[string]interface{}{"material": "cotton", "screen_size": 13.0, "waterproof": false},
+		})
+
+		wool := createProduct(t, repo, model.Product{
+			Name:       "wool",
+			Sku:        "wool",
+			Qty:        1,
+			Price:      200,
+			Attributes: map[string]interface{}{"material": "wool", "screen_size": 15.6, "waterproof": true},
+		})
+
+		numeric := createProduct(t, repo, model.Product{
+			Name:       "numeric",
+			Sku:        "numeric",
+			Qty:        1,
+			Price:      300,
+			Attributes: map[string]interface{}{"material": "1", "screen_size": 1.0},
+		})
+
+		createProduct(t, repo, model.Product{Name: "plain", Sku: "plain", Qty: 1, Price: 400})
+
+		for _, test := range []struct {
+			filters  []model.AttributeFilter
+			expected []string
+		}{
+			{
+				filters:  []model.AttributeFilter{{Name: "material", Operator: model.AttributeEqual, Value: "cotton"}},
+				expected: []string{cotton.ID},
+			},
+			{
+				filters:  []model.AttributeFilter{{Name: "screen_size", Operator: model.AttributeGreaterOrEqual, Value: "13"}},
+				expected: []string{cotton.ID, wool.ID},
+			},
+			{
+				filters: []model.AttributeFilter{
+					{Name: "screen_size", Operator: model.AttributeGreaterOrEqual, Value: "13"},
+					{Name: "screen_size", Operator: model.AttributeLessOrEqual, Value: "14"},
+				},
+				expected: []string{cotton.ID},
+			},
+			{
+				filters:  []model.AttributeFilter{{Name: "screen_size", Operator: model.AttributeEqual, Value: "15.6"}},
+				expected: []string{wool.ID},
+			},
+			{
+				filters:  []model.AttributeFilter{{Name: "waterproof", Operator: model.AttributeEqual, Value: "true"}},
+				expected: []string{wool.ID},
+			},
+			{
+				// Strings are not matched by range filters, and equality matches a string and a number written the same
+				filters:  []model.AttributeFilter{{Name: "material", Operator: model.AttributeLessOrEqual, Value: "1"}},
+				expected: nil,
+			},
+			{
+				filters:  []model.AttributeFilter{{Name: "screen_size", Operator: model.AttributeEqual, Value: "1"}},
+				expected: []string{numeric.ID},
+			},
+		} {
+			// When
+			page := search(t, repo, model.SearchRequest{InStock: true, Attributes: test.filters})
+
+			// Then
+			assert.Equal(t, test.expected, ids(page.Products), "%+v", test.filters)
+
+			assert.Equal(t, int64(len(test.expected)), page.Total, "%+v", test.filters)
+		}
+	})
+
+	t.Run("successfully update product attributes", func(t *testing.T) {
+		// Given
+		ctx := context.TODO()
+
+		repo := factory(t)
+
+		product := createProduct(t, repo, model.Product{
+			Name:       "product 1",
+			Sku:        "sku1",
+			Attributes: map[string]interface{}{"material": "cotton"},
+		})
+
+		// When
+		response, err := repo.Update(ctx, model.Update{
+			ID:            product.ID,
+			UpdateRequest: model.UpdateRequest{Qty: qtyOf(1), Attributes: map[string]interface{}{"material": "wool", "weight": 2.5}},
+		})
+
+		// Then
+		require.NoError(t, err)
+
+		assert.Equal(t, map[string]interface{}{"material": "wool", "weight": 2.5}, response.Attributes)
+
+		stored, err := repo.GetByID(ctx, product.ID)
+
+		require.NoError(t, err)
+
+		assert.Equal(t, map[string]interface{}{"material": "wool", "weight": 2.5}, stored.Attributes)
+	})
+}
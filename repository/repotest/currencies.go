@@ -0,0 +1,107 @@
+package repotest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/srodrmendz/api-product-catalog/model"
+	"github.com/srodrmendz/api-product-catalog/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Euro selection converting from dollars, products stored without currency are on dollars
+var euroSelection = model.CurrencySelection{
+	Code:    "EUR",
+	Default: "USD",
+	Factors: map[string]float64{"USD": 0.9},
+}
+
+func testCurrencies(t *testing.T, factory Factory) {
+	t.Run("successfully store product currency and price list", func(t *testing.T) {
+		// Given
+		ctx := context.TODO()
+
+		repo := factory(t)
+
+		product := createProduct(t, repo, model.Product{
+			Name:     "product",
+			Sku:      "sku",
+			Qty:      1,
+			Price:    1000,
+			Currency: "USD",
+			Prices:   []model.Money{{Amount: 950, Currency: "EUR"}},
+		})
+
+		// When
+		response, err := repo.GetByID(ctx, product.ID)
+
+		// Then
+		require.NoError(t, err)
+
+		assert.Equal(t, "USD", response.Currency)
+
+		assert.Equal(t, []model.Money{{Amount: 950, Currency: "EUR"}}, response.Prices)
+	})
+
+	t.Run("successfully search products priced on the selected currency", func(t *testing.T) {
+		// Given
+		repo := factory(t)
+
+		products := createCurrencyProducts(t, repo)
+
+		// When
+		page := search(t, repo, model.SearchRequest{Sort: "asc", InStock: true, Currency: euroSelection})
+
+		// Then
+		assert.Equal(t, int64(3), page.Total)
+
+		assert.Equal(t, []string{products["euro"].ID, products["listed"].ID, products["legacy"].ID}, ids(page.Products))
+
+		assert.Equal(t, []int64{900, 950, 1801}, prices(page.Products))
+
+		for _, product := range page.Products {
+			assert.Equal(t, "EUR", product.Currency)
+		}
+	})
+
+	t.Run("successfully page products priced on the selected currency", func(t *testing.T) {
+		// Given
+		repo := factory(t)
+
+		products := createCurrencyProducts(t, repo)
+
+		first := search(t, repo, model.SearchRequest{Sort: "desc", InStock: true, Limit: 2, Currency: euroSelection})
+
+		require.NotNil(t, first.Next)
+
+		assert.Equal(t, "EUR", first.Next.Currency)
+
+		// When
+		next := search(t, repo, model.SearchRequest{Sort: "desc", InStock: true, Limit: 2, Currency: euroSelection, Cursor: first.Next})
+
+		prev := search(t, repo, model.SearchRequest{Sort: "desc", InStock: true, Limit: 2, Currency: euroSelection, Cursor: next.Prev})
+
+		// Then
+		assert.Equal(t, []string{products["legacy"].ID, products["listed"].ID}, ids(first.Products))
+
+		assert.Equal(t, []string{products["euro"].ID}, ids(next.Products))
+
+		assert.Nil(t, next.Next)
+
+		assert.Equal(t, ids(first.Products), ids(prev.Products))
+	})
+}
+
+// Create products priced on euros, on dollars with a euro price, on dollars without currency and on pounds,
+// which have no exchange rate
+func createCurrencyProducts(t *testing.T, repo repository.Repository) map[string]*model.Product {
+	t.Helper()
+
+	return map[string]*model.Product{
+		"euro":   createProduct(t, repo, model.Product{Name: "euro", Sku: "euro", Qty: 1, Price: 900, Currency: "EUR"}),
+		"listed": createProduct(t, repo, model.Product{Name: "listed", Sku: "listed", Qty: 1, Price: 100, Currency: "USD", Prices: []model.Money{{Amount: 950, Currency: "EUR"}}}),
+		"legacy": createProduct(t, repo, model.Product{Name: "legacy", Sku: "legacy", Qty: 1, Price: 2001}),
+		"pound":  createProduct(t, repo, model.Product{Name: "pound", Sku: "pound", Qty: 1, Price: 500, Currency: "GBP"}),
+	}
+}
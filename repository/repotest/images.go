@@ -0,0 +1,55 @@
+package repotest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/srodrmendz/api-product-catalog/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testImages(t *testing.T, factory Factory) {
+	t.Run("successfully store product images with their metadata", func(t *testing.T) {
+		// Given
+		ctx := context.TODO()
+
+		repo := factory(t)
+
+		front := model.Image{
+			ID:       "front",
+			URL:      "https://cdn.example.com/shirt-front.png",
+			Alt:      map[string]string{"en": "shirt front", "es": "frente de la camisa"},
+			Width:    800,
+			Height:   600,
+			MimeType: "image/png",
+			Primary:  true,
+		}
+
+		product := createProduct(t, repo, model.Product{
+			Name:   "shirt",
+			Sku:    "shirt",
+			Qty:    1,
+			Price:  100,
+			Images: []model.Image{front},
+		})
+
+		back := model.Image{ID: "back", URL: "https://cdn.example.com/shirt-back.jpg", MimeType: "image/jpeg"}
+
+		product.AddImage(back)
+
+		// When
+		_, err := repo.Replace(ctx, *product)
+
+		// Then
+		require.NoError(t, err)
+
+		stored, err := repo.GetByID(ctx, product.ID)
+
+		require.NoError(t, err)
+
+		back.Position = 1
+
+		assert.Equal(t, []model.Image{front, back}, stored.Images)
+	})
+}
@@ -0,0 +1,166 @@
+package repotest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/srodrmendz/api-product-catalog/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testPrices(t *testing.T, factory Factory) {
+	t.Run("successfully update product pricing", func(t *testing.T) {
+		// Given
+		ctx := context.TODO()
+
+		repo := factory(t)
+
+		product := createProduct(t, repo, model.Product{Name: "product", Sku: "sku", Qty: 1, Price: 1000})
+
+		now := time.Now().UTC().Truncate(time.Second)
+
+		end := now.Add(time.Hour)
+
+		pricing := &model.Pricing{
+			Price:          800,
+			RegularPrice:   1000,
+			PriceSchedule:  []model.ScheduledPrice{{ID: "sale", Price: 800, StartsAt: now, EndsAt: &end, Actor: "merchandising"}},
+			PriceHistory:   []model.PriceChange{{Price: 800, PreviousPrice: 1000, ChangedAt: now, ScheduleID: "sale"}},
+			PriceChangesAt: &end,
+		}
+
+		// When
+		response, err := repo.Update(ctx, model.Update{
+			ID:      product.ID,
+			Version: product.Version,
+			Pricing: pricing,
+		})
+
+		// Then
+		require.NoError(t, err)
+
+		assert.Equal(t, int64(800), response.Price)
+
+		// Pricing updates leave the stock as it is
+		assert.Equal(t, uint64(1), response.Qty)
+
+		assert.Equal(t, true, response.InStock)
+
+		stored, err := repo.GetByID(ctx, product.ID)
+
+		require.NoError(t, err)
+
+		assert.Equal(t, int64(800), stored.Price)
+
+		assert.Equal(t, int64(1000), stored.RegularPrice)
+
+		require.Len(t, stored.PriceSchedule, 1)
+
+		assert.Equal(t, "merchandising", stored.PriceSchedule[0].Actor)
+
+		assert.True(t, end.Equal(*stored.PriceSchedule[0].EndsAt))
+
+		require.Len(t, stored.PriceHistory, 1)
+
+		assert.True(t, now.Equal(stored.PriceHistory[0].ChangedAt))
+
+		require.NotNil(t, stored.PriceChangesAt)
+
+		assert.True(t, end.Equal(*stored.PriceChangesAt))
+	})
+
+	t.Run("successfully search products by their updated price", func(t *testing.T) {
+		// Given
+		ctx := context.TODO()
+
+		repo := factory(t)
+
+		cheap := createProduct(t, repo, model.Product{Name: "cheap", Sku: "cheap", Qty: 1, Price: 100})
+
+		expensive := createProduct(t, repo, model.Product{Name: "expensive", Sku: "expensive", Qty: 1, Price: 200})
+
+		// When
+		_, err := repo.Update(ctx, model.Update{
+			ID:            cheap.ID,
+			UpdateRequest: model.UpdateRequest{Qty: qtyOf(1)},
+			Pricing:       &model.Pricing{Price: 300, RegularPrice: 300},
+		})
+
+		require.NoError(t, err)
+
+		page := search(t, repo, model.SearchRequest{Sort: "asc", InStock: true})
+
+		// Then
+		assert.Equal(t, []string{expensive.ID, cheap.ID}, ids(page.Products))
+
+		assert.Equal(t, []int64{200, 300}, prices(page.Products))
+	})
+
+	t.Run("successfully update variant price", func(t *testing.T) {
+		// Given
+		ctx := context.TODO()
+
+		repo := factory(t)
+
+		product := createVariantProduct(t, repo, "shirt")
+
+		// When
+		response, err := repo.Update(ctx, model.Update{
+			ID:            product.ID,
+			UpdateRequest: model.UpdateRequest{Qty: qtyOf(10), VariantSKU: "shirt-m"},
+			Pricing:       &model.Pricing{Price: 1500, PriceHistory: []model.PriceChange{{Price: 1500, Sku: "shirt-m"}}},
+		})
+
+		// Then
+		require.NoError(t, err)
+
+		assert.Equal(t, int64(1200), response.Price)
+
+		assert.Equal(t, &model.PriceRange{Min: 1200, Max: 1500}, response.PriceRange)
+
+		stored, err := repo.GetBySKU(ctx, "shirt-m")
+
+		require.NoError(t, err)
+
+		assert.Equal(t, int64(1500), stored.Variant("shirt-m").Price)
+
+		assert.Len(t, stored.PriceHistory, 1)
+	})
+
+	t.Run("successfully list products with due prices", func(t *testing.T) {
+		// Given
+		ctx := context.TODO()
+
+		repo := factory(t)
+
+		now := time.Now()
+
+		due := createProduct(t, repo, model.Product{Name: "due", Sku: "due", Qty: 1, Price: 100})
+
+		later := createProduct(t, repo, model.Product{Name: "later", Sku: "later", Qty: 1, Price: 100})
+
+		createProduct(t, repo, model.Product{Name: "unscheduled", Sku: "unscheduled", Qty: 1, Price: 100})
+
+		for product, changesAt := range map[string]time.Time{due.ID: now.Add(-time.Minute), later.ID: now.Add(time.Hour)} {
+			changesAt := changesAt
+
+			_, err := repo.Update(ctx, model.Update{
+				ID:            product,
+				UpdateRequest: model.UpdateRequest{Qty: qtyOf(1)},
+				Pricing:       &model.Pricing{Price: 100, RegularPrice: 100, PriceChangesAt: &changesAt},
+			})
+
+			require.NoError(t, err)
+		}
+
+		// When
+		products, err := repo.ListDuePrices(ctx, now)
+
+		// Then
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{due.ID}, ids(products))
+	})
+}
@@ -0,0 +1,86 @@
+package repotest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/srodrmendz/api-product-catalog/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testRelations(t *testing.T, factory Factory) {
+	t.Run("successfully store product relations", func(t *testing.T) {
+		// Given
+		ctx := context.TODO()
+
+		repo := factory(t)
+
+		product := createProduct(t, repo, model.Product{Name: "shoes", Sku: "shoes", Qty: 1, Price: 100})
+
+		product.Relations = []model.Relation{
+			{ProductID: "socks", Type: model.RelationAccessory, Position: 0},
+			{ProductID: "laces", Type: model.RelationAccessory, Position: 1},
+			{ProductID: "boots", Type: model.RelationUpsell, Position: 0, Bidirectional: true},
+		}
+
+		// When
+		_, err := repo.Replace(ctx, *product)
+
+		// Then
+		require.NoError(t, err)
+
+		stored, err := repo.GetByID(ctx, product.ID)
+
+		require.NoError(t, err)
+
+		assert.Equal(t, product.Relations, stored.Relations)
+	})
+
+	t.Run("successfully remove the relations to a deleted product", func(t *testing.T) {
+		// Given
+		ctx := context.TODO()
+
+		repo := factory(t)
+
+		socks := createProduct(t, repo, model.Product{Name: "socks", Sku: "socks", Qty: 1, Price: 10})
+
+		shoes := createProduct(t, repo, model.Product{Name: "shoes", Sku: "shoes", Qty: 1, Price: 100})
+
+		shoes.Relations = []model.Relation{
+			{ProductID: "laces", Type: model.RelationAccessory, Position: 0},
+			{ProductID: socks.ID, Type: model.RelationAccessory, Position: 1},
+			{ProductID: socks.ID, Type: model.RelationCrossSell, Position: 0},
+		}
+
+		shoes, err := repo.Replace(ctx, *shoes)
+
+		require.NoError(t, err)
+
+		hat := createProduct(t, repo, model.Product{Name: "hat", Sku: "hat", Qty: 1, Price: 50, Relations: []model.Relation{
+			{ProductID: "scarf", Type: model.RelationCrossSell, Position: 0},
+		}})
+
+		// When
+		err = repo.Delete(ctx, socks.ID, 0)
+
+		// Then
+		require.NoError(t, err)
+
+		stored, err := repo.GetByID(ctx, shoes.ID)
+
+		require.NoError(t, err)
+
+		assert.Equal(t, []model.Relation{{ProductID: "laces", Type: model.RelationAccessory, Position: 0}}, stored.Relations)
+
+		assert.Equal(t, shoes.Version+1, stored.Version)
+
+		unrelated, err := repo.GetByID(ctx, hat.ID)
+
+		require.NoError(t, err)
+
+		assert.Equal(t, hat.Relations, unrelated.Relations)
+
+		assert.Equal(t, hat.Version, unrelated.Version)
+	})
+}
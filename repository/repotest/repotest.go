@@ -0,0 +1,1186 @@
+// Package repotest holds the conformance suite every repository.Repository
+// implementation has to pass, so all backends behave like the MongoDB one.
+package repotest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	internalError "github.com/srodrmendz/api-product-catalog/errors"
+	"github.com/srodrmendz/api-product-catalog/model"
+	"github.com/srodrmendz/api-product-catalog/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Factory returns an empty repository, it is called once per test
+type Factory func(t *testing.T) repository.Repository
+
+// Run the conformance suite against the repositories returned by factory
+func Run(t *testing.T, factory Factory) {
+	t.Run("Create", func(t *testing.T) { testCreate(t, factory) })
+
+	t.Run("GetByID", func(t *testing.T) { testGetByID(t, factory) })
+
+	t.Run("GetBySKU", func(t *testing.T) { testGetBySKU(t, factory) })
+
+	t.Run("Delete", func(t *testing.T) { testDelete(t, factory) })
+
+	t.Run("Restore", func(t *testing.T) { testRestore(t, factory) })
+
+	t.Run("Purge", func(t *testing.T) { testPurge(t, factory) })
+
+	t.Run("Update", func(t *testing.T) { testUpdate(t, factory) })
+
+	t.Run("Search", func(t *testing.T) { testSearch(t, factory) })
+
+	t.Run("WithTransaction", func(t *testing.T) { testWithTransaction(t, factory) })
+
+	t.Run("SearchCursor", func(t *testing.T) { testSearchCursor(t, factory) })
+
+	t.Run("Categories", func(t *testing.T) { testCategories(t, factory) })
+
+	t.Run("Variants", func(t *testing.T) { testVariants(t, factory) })
+
+	t.Run("Attributes", func(t *testing.T) { testAttributes(t, factory) })
+
+	t.Run("Currencies", func(t *testing.T) { testCurrencies(t, factory) })
+
+	t.Run("Prices", func(t *testing.T) { testPrices(t, factory) })
+
+	t.Run("Statuses", func(t *testing.T) { testStatuses(t, factory) })
+
+	t.Run("Translations", func(t *testing.T) { testTranslations(t, factory) })
+
+	t.Run("Images", func(t *testing.T) { testImages(t, factory) })
+
+	t.Run("Brands", func(t *testing.T) { testBrands(t, factory) })
+
+	t.Run("Shipping", func(t *testing.T) { testShipping(t, factory) })
+
+	t.Run("Bundles", func(t *testing.T) { testBundles(t, factory) })
+
+	t.Run("Relations", func(t *testing.T) { testRelations(t, factory) })
+
+	t.Run("Slugs", func(t *testing.T) { testSlugs(t, factory) })
+
+	t.Run("StockMovements", func(t *testing.T) { testStockMovements(t, factory) })
+
+	t.Run("AdjustStock", func(t *testing.T) { testAdjustStock(t, factory) })
+}
+
+func testCreate(t *testing.T, factory Factory) {
+	t.Run("successfully create product", func(t *testing.T) {
+		// Given
+		ctx := context.TODO()
+
+		repo := factory(t)
+
+		// When
+		product, err := repo.Create(ctx, model.Product{
+			Qty:    10,
+			Name:   "product 1",
+			Sku:    "sku1",
+			Price:  100,
+			Images: []model.Image{{ID: "image1", URL: "https://google.com/image1.png", Primary: true}},
+		})
+
+		// Then
+		require.NoError(t, err)
+
+		assert.NotEmpty(t, product.ID)
+
+		assert.Equal(t, true, product.InStock)
+
+		assert.Equal(t, int64(1), product.Version)
+
+		assert.Equal(t, false, product.CreatedAt.IsZero())
+
+		assert.Equal(t, false, product.UpdatedAt.IsZero())
+	})
+
+	t.Run("successfully create product without stock", func(t *testing.T) {
+		// Given
+		ctx := context.TODO()
+
+		repo := factory(t)
+
+		// When
+		product, err := repo.Create(ctx, model.Product{
+			Name: "product 1",
+			Sku:  "sku1",
+		})
+
+		// Then
+		require.NoError(t, err)
+
+		assert.Equal(t, false, product.InStock)
+	})
+
+	t.Run("failed to create product, sku already exist", func(t *testing.T) {
+		// Given
+		ctx := context.TODO()
+
+		repo := factory(t)
+
+		product := createProduct(t, repo, model.Product{
+			Qty:  10,
+			Name: "product 1",
+			Sku:  "sku1",
+		})
+
+		// When
+		response, err := repo.Create(ctx, model.Product{
+			Qty:  100,
+			Name: "product 2",
+			Sku:  product.Sku,
+		})
+
+		// Then
+		assert.ErrorIs(t, err, internalError.ErrProductSKUAlreadyExist)
+
+		assert.Nil(t, response)
+	})
+}
+
+func testGetByID(t *testing.T, factory Factory) {
+	t.Run("successfully get product by id", func(t *testing.T) {
+		// Given
+		repo := factory(t)
+
+		product := createProduct(t, repo, model.Product{
+			Qty:    10,
+			Name:   "product 1",
+			Sku:    "sku1",
+			Price:  100,
+			Images: []model.Image{{ID: "image1", URL: "https://google.com/image1.png", Primary: true}},
+		})
+
+		// When
+		response, err := repo.GetByID(context.TODO(), product.ID)
+
+		// Then
+		require.NoError(t, err)
+
+		assertSameProduct(t, *product, *response)
+	})
+
+	t.Run("failed to get by id, product not found", func(t *testing.T) {
+		// Given
+		repo := factory(t)
+
+		// When
+		response, err := repo.GetByID(context.TODO(), "fake")
+
+		// Then
+		assert.ErrorIs(t, err, internalError.ErrProductNotFound)
+
+		assert.Nil(t, response)
+	})
+}
+
+func testGetBySKU(t *testing.T, factory Factory) {
+	t.Run("successfully get product by sku", func(t *testing.T) {
+		// Given
+		repo := factory(t)
+
+		product := createProduct(t, repo, model.Product{
+			Qty:   10,
+			Name:  "product 1",
+			Sku:   "sku1",
+			Price: 100,
+		})
+
+		createProduct(t, repo, model.Product{
+			Name: "product 2",
+			Sku:  "sku2",
+		})
+
+		// When
+		response, err := repo.GetBySKU(context.TODO(), "sku1")
+
+		// Then
+		require.NoError(t, err)
+
+		assertSameProduct(t, *product, *response)
+	})
+
+	t.Run("failed to get by sku, product not found", func(t *testing.T) {
+		// Given
+		repo := factory(t)
+
+		// When
+		response, err := repo.GetBySKU(context.TODO(), "fake")
+
+		// Then
+		assert.ErrorIs(t, err, internalError.ErrProductNotFound)
+
+		assert.Nil(t, response)
+	})
+}
+
+func testDelete(t *testing.T, factory Factory) {
+	t.Run("successfully delete product", func(t *testing.T) {
+		// Given
+		ctx := context.TODO()
+
+		repo := factory(t)
+
+		product := createProduct(t, repo, model.Product{
+			Name: "product 1",
+			Sku:  "sku1",
+		})
+
+		// When
+		err := repo.Delete(ctx, product.ID, 0)
+
+		// Then
+		require.NoError(t, err)
+
+		_, err = repo.GetByID(ctx, product.ID)
+
+		assert.ErrorIs(t, err, internalError.ErrProductNotFound)
+
+		_, err = repo.GetBySKU(ctx, product.Sku)
+
+		assert.ErrorIs(t, err, internalError.ErrProductNotFound)
+	})
+
+	t.Run("deleted product keeps its sku reserved and is hidden from search", func(t *testing.T) {
+		// Given
+		ctx := context.TODO()
+
+		repo := factory(t)
+
+		product := createProduct(t, repo, model.Product{
+			Qty:  10,
+			Name: "product 1",
+			Sku:  "sku1",
+		})
+
+		// When
+		err := repo.Delete(ctx, product.ID, 0)
+
+		// Then
+		require.NoError(t, err)
+
+		_, err = repo.Create(ctx, model.Product{Name: "product 2", Sku: product.Sku})
+
+		assert.ErrorIs(t, err, internalError.ErrProductSKUAlreadyExist)
+
+		page := search(t, repo, model.SearchRequest{InStock: true})
+
+		assert.Empty(t, page.Products)
+
+		assert.Equal(t, int64(0), page.Total)
+
+		page = search(t, repo, model.SearchRequest{InStock: true, IncludeDeleted: true})
+
+		require.Len(t, page.Products, 1)
+
+		assert.Equal(t, product.ID, page.Products[0].ID)
+
+		assert.NotNil(t, page.Products[0].DeletedAt)
+	})
+
+	t.Run("failed delete product, product not found", func(t *testing.T) {
+		// Given
+		repo := factory(t)
+
+		// When
+		err := repo.Delete(context.TODO(), "fake", 0)
+
+		// Then
+		assert.ErrorIs(t, err, internalError.ErrProductNotFound)
+	})
+
+	t.Run("failed delete product, product already deleted", func(t *testing.T) {
+		// Given
+		ctx := context.TODO()
+
+		repo := factory(t)
+
+		product := createProduct(t, repo, model.Product{
+			Name: "product 1",
+			Sku:  "sku1",
+		})
+
+		require.NoError(t, repo.Delete(ctx, product.ID, 0))
+
+		// When
+		err := repo.Delete(ctx, product.ID, 0)
+
+		// Then
+		assert.ErrorIs(t, err, internalError.ErrProductNotFound)
+
+		_, err = repo.Update(ctx, model.Update{ID: product.ID, UpdateRequest: model.UpdateRequest{Qty: qtyOf(10)}})
+
+		assert.ErrorIs(t, err, internalError.ErrProductNotFound)
+	})
+
+	t.Run("successfully delete product on its current version", func(t *testing.T) {
+		// Given
+		ctx := context.TODO()
+
+		repo := factory(t)
+
+		product := createProduct(t, repo, model.Product{
+			Name: "product 1",
+			Sku:  "sku1",
+		})
+
+		// When
+		err := repo.Delete(ctx, product.ID, product.Version)
+
+		// Then
+		require.NoError(t, err)
+
+		_, err = repo.GetByID(ctx, product.ID)
+
+		assert.ErrorIs(t, err, internalError.ErrProductNotFound)
+	})
+
+	t.Run("failed delete product, version conflict", func(t *testing.T) {
+		// Given
+		ctx := context.TODO()
+
+		repo := factory(t)
+
+		product := createProduct(t, repo, model.Product{
+			Name: "product 1",
+			Sku:  "sku1",
+		})
+
+		_, err := repo.Update(ctx, model.Update{ID: product.ID, UpdateRequest: model.UpdateRequest{Qty: qtyOf(10)}})
+
+		require.NoError(t, err)
+
+		// When
+		err = repo.Delete(ctx, product.ID, product.Version)
+
+		// Then
+		assertVersionConflict(t, err, product.Version+1)
+
+		_, err = repo.GetByID(ctx, product.ID)
+
+		assert.NoError(t, err)
+	})
+}
+
+func testRestore(t *testing.T, factory Factory) {
+	t.Run("successfully restore deleted product", func(t *testing.T) {
+		// Given
+		ctx := context.TODO()
+
+		repo := factory(t)
+
+		product := createProduct(t, repo, model.Product{
+			Qty:  10,
+			Name: "product 1",
+			Sku:  "sku1",
+		})
+
+		require.NoError(t, repo.Delete(ctx, product.ID, 0))
+
+		// When
+		response, err := repo.Restore(ctx, product.ID)
+
+		// Then
+		require.NoError(t, err)
+
+		assert.Nil(t, response.DeletedAt)
+
+		assert.Equal(t, product.Version+2, response.Version)
+
+		stored, err := repo.GetBySKU(ctx, product.Sku)
+
+		require.NoError(t, err)
+
+		assert.Equal(t, product.ID, stored.ID)
+
+		assert.Equal(t, int64(1), search(t, repo, model.SearchRequest{InStock: true}).Total)
+	})
+
+	t.Run("successfully restore product that is not deleted", func(t *testing.T) {
+		// Given
+		repo := factory(t)
+
+		product := createProduct(t, repo, model.Product{
+			Name: "product 1",
+			Sku:  "sku1",
+		})
+
+		// When
+		response, err := repo.Restore(context.TODO(), product.ID)
+
+		// Then
+		require.NoError(t, err)
+
+		assert.Equal(t, product.Version, response.Version)
+	})
+
+	t.Run("failed restore product, product not found", func(t *testing.T) {
+		// Given
+		repo := factory(t)
+
+		// When
+		response, err := repo.Restore(context.TODO(), "fake")
+
+		// Then
+		assert.ErrorIs(t, err, internalError.ErrProductNotFound)
+
+		assert.Nil(t, response)
+	})
+}
+
+func testPurge(t *testing.T, factory Factory) {
+	t.Run("successfully purge products deleted before the given time", func(t *testing.T) {
+		// Given
+		ctx := context.TODO()
+
+		repo := factory(t)
+
+		deleted := createProduct(t, repo, model.Product{
+			Name: "product 1",
+			Sku:  "sku1",
+		})
+
+		active := createProduct(t, repo, model.Product{
+			Name: "product 2",
+			Sku:  "sku2",
+		})
+
+		beforeDelete := time.Now().Add(-time.Second)
+
+		require.NoError(t, repo.Delete(ctx, deleted.ID, 0))
+
+		// When
+		notYet, err := repo.Purge(ctx, beforeDelete)
+
+		require.NoError(t, err)
+
+		purged, err := repo.Purge(ctx, time.Now().Add(time.Second))
+
+		// Then
+		require.NoError(t, err)
+
+		assert.Equal(t, int64(0), notYet)
+
+		assert.Equal(t, int64(1), purged)
+
+		_, err = repo.Restore(ctx, deleted.ID)
+
+		assert.ErrorIs(t, err, internalError.ErrProductNotFound)
+
+		_, err = repo.GetByID(ctx, active.ID)
+
+		assert.NoError(t, err)
+
+		_, err = repo.Create(ctx, model.Product{Name: "product 3", Sku: deleted.Sku})
+
+		assert.NoError(t, err)
+	})
+}
+
+func testUpdate(t *testing.T, factory Factory) {
+	t.Run("successfully update product", func(t *testing.T) {
+		// Given
+		ctx := context.TODO()
+
+		repo := factory(t)
+
+		product := createProduct(t, repo, model.Product{
+			Name: "product 1",
+			Sku:  "sku1",
+		})
+
+		// When
+		response, err := repo.Update(ctx, model.Update{ID: product.ID, UpdateRequest: model.UpdateRequest{Qty: qtyOf(1000)}})
+
+		// Then
+		require.NoError(t, err)
+
+		assert.Equal(t, uint64(1000), response.Qty)
+
+		assert.Equal(t, true, response.InStock)
+
+		assert.Equal(t, product.Version+1, response.Version)
+
+		stored, err := repo.GetByID(ctx, product.ID)
+
+		require.NoError(t, err)
+
+		assert.Equal(t, uint64(1000), stored.Qty)
+
+		assert.Equal(t, true, stored.InStock)
+
+		assert.Equal(t, product.Version+1, stored.Version)
+	})
+
+	t.Run("successfully update product on its current version", func(t *testing.T) {
+		// Given
+		ctx := context.TODO()
+
+		repo := factory(t)
+
+		product := createProduct(t, repo, model.Product{
+			Name: "product 1",
+			Sku:  "sku1",
+		})
+
+		// When
+		first, err := repo.Update(ctx, model.Update{ID: product.ID, Version: product.Version, UpdateRequest: model.UpdateRequest{Qty: qtyOf(10)}})
+
+		require.NoError(t, err)
+
+		second, err := repo.Update(ctx, model.Update{ID: product.ID, Version: first.Version, UpdateRequest: model.UpdateRequest{Qty: qtyOf(20)}})
+
+		// Then
+		require.NoError(t, err)
+
+		assert.Equal(t, uint64(20), second.Qty)
+
+		assert.Equal(t, product.Version+2, second.Version)
+	})
+
+	t.Run("failed update product, version conflict", func(t *testing.T) {
+		// Given
+		ctx := context.TODO()
+
+		repo := factory(t)
+
+		product := createProduct(t, repo, model.Product{
+			Name: "product 1",
+			Sku:  "sku1",
+		})
+
+		_, err := repo.Update(ctx, model.Update{ID: product.ID, Version: product.Version, UpdateRequest: model.UpdateRequest{Qty: qtyOf(10)}})
+
+		require.NoError(t, err)
+
+		// When
+		response, err := repo.Update(ctx, model.Update{ID: product.ID, Version: product.Version, UpdateRequest: model.UpdateRequest{Qty: qtyOf(20)}})
+
+		// Then
+		assertVersionConflict(t, err, product.Version+1)
+
+		assert.Nil(t, response)
+
+		stored, err := repo.GetByID(ctx, product.ID)
+
+		require.NoError(t, err)
+
+		assert.Equal(t, uint64(10), stored.Qty)
+	})
+
+	t.Run("failed versioned update product, product not found", func(t *testing.T) {
+		// Given
+		repo := factory(t)
+
+		// When
+		response, err := repo.Update(context.TODO(), model.Update{ID: "fake", Version: 1})
+
+		// Then
+		assert.ErrorIs(t, err, internalError.ErrProductNotFound)
+
+		assert.Nil(t, response)
+	})
+
+	t.Run("successfully update product out of stock", func(t *testing.T) {
+		// Given
+		repo := factory(t)
+
+		product := createProduct(t, repo, model.Product{
+			Qty:  10,
+			Name: "product 1",
+			Sku:  "sku1",
+		})
+
+		// When
+		response, err := repo.Update(context.TODO(), model.Update{ID: product.ID, UpdateRequest: model.UpdateRequest{Qty: qtyOf(0)}})
+
+		// Then
+		require.NoError(t, err)
+
+		assert.Equal(t, uint64(0), response.Qty)
+
+		assert.Equal(t, false, response.InStock)
+	})
+
+	t.Run("successfully update product without qty keeps its stock", func(t *testing.T) {
+		// Given
+		repo := factory(t)
+
+		product := createProduct(t, repo, model.Product{
+			Qty:     10,
+			Name:    "product 1",
+			Sku:     "sku1",
+			InStock: true,
+			Variants: []model.Variant{
+				{Sku: "shirt-m", Qty: 4, Price: 100},
+				{Sku: "shirt-l", Qty: 6, Price: 100},
+			},
+		})
+
+		// When
+		response, err := repo.Update(context.TODO(), model.Update{
+			ID:            product.ID,
+			UpdateRequest: model.UpdateRequest{Tags: []string{"sale"}},
+		})
+
+		// Then
+		require.NoError(t, err)
+
+		assert.Equal(t, uint64(10), response.Qty)
+
+		assert.Equal(t, true, response.InStock)
+
+		assert.Equal(t, []string{"sale"}, response.Tags)
+	})
+
+	t.Run("failed update product, product not found", func(t *testing.T) {
+		// Given
+		repo := factory(t)
+
+		// When
+		response, err := repo.Update(context.TODO(), model.Update{ID: "fake", UpdateRequest: model.UpdateRequest{Qty: qtyOf(1000)}})
+
+		// Then
+		assert.ErrorIs(t, err, internalError.ErrProductNotFound)
+
+		assert.Nil(t, response)
+	})
+}
+
+func testWithTransaction(t *testing.T, factory Factory) {
+	t.Run("successfully commit every write of the transaction", func(t *testing.T) {
+		// Given
+		ctx := context.TODO()
+
+		repo := factory(t)
+
+		existing := createProduct(t, repo, model.Product{
+			Qty:  10,
+			Name: "product 1",
+			Sku:  "sku1",
+		})
+
+		var created *model.Product
+
+		// When
+		err := repo.WithTransaction(ctx, func(txRepo repository.Repository) error {
+			var err error
+
+			created, err = txRepo.Create(ctx, model.Product{Qty: 5, Name: "product 2", Sku: "sku2"})
+			if err != nil {
+				return err
+			}
+
+			// Writes are visible to the reads of the same transaction
+			if _, err := txRepo.GetBySKU(ctx, "sku2"); err != nil {
+				return err
+			}
+
+			if _, err := txRepo.Update(ctx, model.Update{ID: existing.ID, UpdateRequest: model.UpdateRequest{Qty: qtyOf(0)}}); err != nil {
+				return err
+			}
+
+			page, err := txRepo.Search(ctx, model.SearchRequest{InStock: true})
+			if err != nil {
+				return err
+			}
+
+			if page.Total != 1 {
+				return fmt.Errorf("expected 1 product in stock, found %d", page.Total)
+			}
+
+			return nil
+		})
+
+		// Then
+		require.NoError(t, err)
+
+		_, err = repo.GetByID(ctx, created.ID)
+
+		assert.NoError(t, err)
+
+		stored, err := repo.GetByID(ctx, existing.ID)
+
+		require.NoError(t, err)
+
+		assert.Equal(t, false, stored.InStock)
+	})
+
+	t.Run("failed transaction discards every write", func(t *testing.T) {
+		// Given
+		ctx := context.TODO()
+
+		repo := factory(t)
+
+		existing := createProduct(t, repo, model.Product{
+			Qty:  10,
+			Name: "product 1",
+			Sku:  "sku1",
+		})
+
+		expectedErr := errors.New("stock transfer failed")
+
+		// When
+		err := repo.WithTransaction(ctx, func(txRepo repository.Repository) error {
+			if _, err := txRepo.Create(ctx, model.Product{Qty: 5, Name: "product 2", Sku: "sku2", Slug: "product-2"}); err != nil {
+				return err
+			}
+
+			if _, err := txRepo.Update(ctx, model.Update{ID: existing.ID, UpdateRequest: model.UpdateRequest{Qty: qtyOf(0)}}); err != nil {
+				return err
+			}
+
+			if err := txRepo.Delete(ctx, existing.ID, 0); err != nil {
+				return err
+			}
+
+			return expectedErr
+		})
+
+		// Then
+		assert.ErrorIs(t, err, expectedErr)
+
+		_, err = repo.GetBySKU(ctx, "sku2")
+
+		assert.ErrorIs(t, err, internalError.ErrProductNotFound)
+
+		_, err = repo.GetBySlug(ctx, "product-2")
+
+		assert.ErrorIs(t, err, internalError.ErrProductNotFound)
+
+		stored, err := repo.GetByID(ctx, existing.ID)
+
+		require.NoError(t, err)
+
+		assert.Equal(t, uint64(10), stored.Qty)
+
+		assert.Nil(t, stored.DeletedAt)
+
+		_, err = repo.Create(ctx, model.Product{Name: "product 2", Sku: "sku2"})
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("nested transaction joins the running one", func(t *testing.T) {
+		// Given
+		ctx := context.TODO()
+
+		repo := factory(t)
+
+		expectedErr := errors.New("import failed")
+
+		// When
+		err := repo.WithTransaction(ctx, func(txRepo repository.Repository) error {
+			err := txRepo.WithTransaction(ctx, func(nested repository.Repository) error {
+				_, err := nested.Create(ctx, model.Product{Name: "product 1", Sku: "sku1"})
+
+				return err
+			})
+			if err != nil {
+				return err
+			}
+
+			return expectedErr
+		})
+
+		// Then
+		assert.ErrorIs(t, err, expectedErr)
+
+		_, err = repo.GetBySKU(ctx, "sku1")
+
+		assert.ErrorIs(t, err, internalError.ErrProductNotFound)
+	})
+}
+
+func testSearch(t *testing.T, factory Factory) {
+	repo := factory(t)
+
+	names := []string{"red shirt", "blue shirt", "green pants", "black hat", "white socks"}
+
+	description := "cotton ankle socks"
+
+	for i, name := range names {
+		product := model.Product{
+			Qty:   10,
+			Name:  name,
+			Sku:   fmt.Sprintf("sku%d", i),
+			Price: int64(100 * (i + 1)),
+		}
+
+		if name == "white socks" {
+			product.Description = &description
+		}
+
+		createProduct(t, repo, product)
+	}
+
+	createProduct(t, repo, model.Product{
+		Name:  "yellow shirt",
+		Sku:   "sku-out-of-stock-1",
+		Price: 50,
+	})
+
+	createProduct(t, repo, model.Product{
+		Name:  "purple pants",
+		Sku:   "sku-out-of-stock-2",
+		Price: 5000,
+	})
+
+	dataTable := []struct {
+		name           string
+		search         string
+		sort           string
+		inStock        bool
+		limit          int
+		offset         int
+		expectedTotal  int64
+		expectedPrices []int64
+	}{
+		{
+			name:           "sort ascending by price",
+			sort:           "asc",
+			inStock:        true,
+			expectedTotal:  5,
+			expectedPrices: []int64{100, 200, 300, 400, 500},
+		},
+		{
+			name:           "sort descending by price",
+			sort:           "desc",
+			inStock:        true,
+			expectedTotal:  5,
+			expectedPrices: []int64{500, 400, 300, 200, 100},
+		},
+		{
+			name:           "filter out of stock products",
+			sort:           "asc",
+			expectedTotal:  2,
+			expectedPrices: []int64{50, 5000},
+		},
+		{
+			name:           "first page keeps the total",
+			sort:           "asc",
+			inStock:        true,
+			limit:          2,
+			expectedTotal:  5,
+			expectedPrices: []int64{100, 200},
+		},
+		{
+			name:           "middle page keeps the total",
+			sort:           "asc",
+			inStock:        true,
+			limit:          2,
+			offset:         2,
+			expectedTotal:  5,
+			expectedPrices: []int64{300, 400},
+		},
+		{
+			name:           "last page keeps the total",
+			sort:           "desc",
+			inStock:        true,
+			limit:          2,
+			offset:         4,
+			expectedTotal:  5,
+			expectedPrices: []int64{100},
+		},
+		{
+			name:          "page after the last one is empty",
+			sort:          "asc",
+			inStock:       true,
+			limit:         2,
+			offset:        10,
+			expectedTotal: 5,
+		},
+		{
+			name:           "filter by name",
+			search:         "shirt",
+			sort:           "asc",
+			inStock:        true,
+			expectedTotal:  2,
+			expectedPrices: []int64{100, 200},
+		},
+		{
+			name:           "filter by name ignoring case",
+			search:         "PANTS",
+			sort:           "asc",
+			inStock:        true,
+			expectedTotal:  1,
+			expectedPrices: []int64{300},
+		},
+		{
+			name:           "filter by any of the name terms",
+			search:         "hat socks",
+			sort:           "desc",
+			inStock:        true,
+			expectedTotal:  2,
+			expectedPrices: []int64{500, 400},
+		},
+		{
+			name:           "filter by description",
+			search:         "cotton",
+			sort:           "asc",
+			inStock:        true,
+			expectedTotal:  1,
+			expectedPrices: []int64{500},
+		},
+		{
+			name:           "filter by name and stock",
+			search:         "shirt",
+			sort:           "asc",
+			expectedTotal:  1,
+			expectedPrices: []int64{50},
+		},
+		{
+			name:          "filter by name without matches",
+			search:        "jacket",
+			sort:          "asc",
+			inStock:       true,
+			expectedTotal: 0,
+		},
+	}
+
+	for _, dt := range dataTable {
+		t.Run(dt.name, func(t *testing.T) {
+			// When
+			page, err := repo.Search(context.TODO(), model.SearchRequest{
+				Name:    dt.search,
+				Sort:    dt.sort,
+				InStock: dt.inStock,
+				Limit:   dt.limit,
+				Offset:  dt.offset,
+			})
+
+			// Then
+			require.NoError(t, err)
+
+			assert.Equal(t, dt.expectedTotal, page.Total)
+
+			assert.Equal(t, dt.expectedPrices, prices(page.Products))
+		})
+	}
+}
+
+func testSearchCursor(t *testing.T, factory Factory) {
+	repo := factory(t)
+
+	// Repeated prices check the id tie breaker keeps pages stable
+	for i, price := range []int64{100, 200, 200, 200, 300, 400, 500} {
+		createProduct(t, repo, model.Product{
+			Qty:   10,
+			Name:  "product",
+			Sku:   fmt.Sprintf("sku%d", i),
+			Price: price,
+		})
+	}
+
+	createProduct(t, repo, model.Product{
+		Name:  "product",
+		Sku:   "sku-out-of-stock",
+		Price: 250,
+	})
+
+	t.Run("offset pages return cursors to their surrounding pages", func(t *testing.T) {
+		// When
+		first := search(t, repo, model.SearchRequest{Sort: "asc", InStock: true, Limit: 3})
+
+		middle := search(t, repo, model.SearchRequest{Sort: "asc", InStock: true, Limit: 3, Offset: 3})
+
+		last := search(t, repo, model.SearchRequest{Sort: "asc", InStock: true, Limit: 3, Offset: 6})
+
+		// Then
+		assert.NotNil(t, first.Next)
+
+		assert.Nil(t, first.Prev)
+
+		assert.NotNil(t, middle.Next)
+
+		assert.NotNil(t, middle.Prev)
+
+		assert.Nil(t, last.Next)
+
+		assert.NotNil(t, last.Prev)
+
+		next := search(t, repo, model.SearchRequest{Sort: "asc", InStock: true, Limit: 3, Cursor: first.Next})
+
+		assert.Equal(t, ids(middle.Products), ids(next.Products))
+
+		prev := search(t, repo, model.SearchRequest{Sort: "asc", InStock: true, Limit: 3, Cursor: last.Prev})
+
+		assert.Equal(t, ids(middle.Products), ids(prev.Products))
+	})
+
+	for _, sort := range []string{"asc", "desc"} {
+		t.Run(fmt.Sprintf("walk every page forward and backward sorted %s", sort), func(t *testing.T) {
+			// Given
+			all := search(t, repo, model.SearchRequest{Sort: sort, InStock: true})
+
+			require.Len(t, all.Products, 7)
+
+			// When
+			var forward []string
+
+			var pages []*model.Page
+
+			request := model.SearchRequest{Sort: sort, InStock: true, Limit: 2}
+
+			for {
+				page := search(t, repo, request)
+
+				assert.Equal(t, int64(7), page.Total)
+
+				pages = append(pages, page)
+
+				forward = append(forward, ids(page.Products)...)
+
+				if page.Next == nil {
+					break
+				}
+
+				request.Cursor = page.Next
+			}
+
+			var backward []string
+
+			request.Cursor = pages[len(pages)-1].Prev
+
+			for request.Cursor != nil {
+				page := search(t, repo, request)
+
+				backward = append(ids(page.Products), backward...)
+
+				request.Cursor = page.Prev
+			}
+
+			// Then
+			assert.Len(t, pages, 4)
+
+			assert.Equal(t, ids(all.Products), forward)
+
+			assert.Equal(t, ids(all.Products[:6]), backward)
+		})
+	}
+
+	t.Run("pages after a cursor are not shifted by new products placed before it", func(t *testing.T) {
+		// Given
+		first := search(t, repo, model.SearchRequest{Sort: "asc", InStock: true, Limit: 2})
+
+		expected := search(t, repo, model.SearchRequest{Sort: "asc", InStock: true, Limit: 2, Cursor: first.Next})
+
+		createProduct(t, repo, model.Product{
+			Qty:   10,
+			Name:  "product",
+			Sku:   "sku-cheapest",
+			Price: 10,
+		})
+
+		// When
+		page := search(t, repo, model.SearchRequest{Sort: "asc", InStock: true, Limit: 2, Cursor: first.Next})
+
+		// Then
+		assert.Equal(t, ids(expected.Products), ids(page.Products))
+
+		assert.Equal(t, int64(8), page.Total)
+	})
+
+	t.Run("cursor after the last product returns an empty page", func(t *testing.T) {
+		// Given
+		all := search(t, repo, model.SearchRequest{Sort: "desc", InStock: true})
+
+		cursor := model.NewCursor(all.Products[len(all.Products)-1], "desc", false)
+
+		// When
+		page := search(t, repo, model.SearchRequest{Sort: "desc", InStock: true, Limit: 2, Cursor: cursor})
+
+		// Then
+		assert.Empty(t, page.Products)
+
+		assert.Nil(t, page.Next)
+
+		assert.Nil(t, page.Prev)
+	})
+}
+
+func assertVersionConflict(t *testing.T, err error, current int64) {
+	t.Helper()
+
+	require.ErrorIs(t, err, internalError.ErrVersionConflict)
+
+	var conflict *internalError.VersionConflictError
+
+	require.ErrorAs(t, err, &conflict)
+
+	assert.Equal(t, current, conflict.Current)
+}
+
+func search(t *testing.T, repo repository.Repository, request model.SearchRequest) *model.Page {
+	t.Helper()
+
+	page, err := repo.Search(context.TODO(), request)
+
+	require.NoError(t, err)
+
+	return page
+}
+
+func prices(products []model.Product) []int64 {
+	var prices []int64
+
+	for _, product := range products {
+		prices = append(prices, product.Price)
+	}
+
+	return prices
+}
+
+func ids(products []model.Product) []string {
+	var ids []string
+
+	for _, product := range products {
+		ids = append(ids, product.ID)
+	}
+
+	return ids
+}
+
+func createProduct(t *testing.T, repo repository.Repository, product model.Product) *model.Product {
+	t.Helper()
+
+	response, err := repo.Create(context.TODO(), product)
+
+	require.NoError(t, err)
+
+	return response
+}
+
+func assertSameProduct(t *testing.T, expected model.Product, actual model.Product) {
+	t.Helper()
+
+	assert.Equal(t, expected.ID, actual.ID)
+
+	assert.Equal(t, expected.Name, actual.Name)
+
+	assert.Equal(t, expected.Sku, actual.Sku)
+
+	assert.Equal(t, expected.Qty, actual.Qty)
+
+	assert.Equal(t, expected.Price, actual.Price)
+
+	assert.Equal(t, expected.InStock, actual.InStock)
+
+	assert.Equal(t, expected.Images, actual.Images)
+
+	assert.Equal(t, false, actual.CreatedAt.IsZero())
+
+	assert.Equal(t, false, actual.UpdatedAt.IsZero())
+}
+
+func qtyOf(qty uint64) *uint64 {
+	return &qty
+}
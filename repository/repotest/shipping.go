@@ -0,0 +1,112 @@
+package repotest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/srodrmendz/api-product-catalog/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testShipping(t *testing.T, factory Factory) {
+	t.Run("successfully store product weight, dimensions and shipping class", func(t *testing.T) {
+		// Given
+		repo := factory(t)
+
+		weight := &model.Weight{Value: 1.25, Unit: model.Kilogram}
+
+		dimensions := &model.Dimensions{Length: 30, Width: 20, Height: 10.5, Unit: model.Centimeter}
+
+		product := createProduct(t, repo, model.Product{
+			Name:          "shoes",
+			Sku:           "shoes",
+			Qty:           1,
+			Price:         100,
+			Weight:        weight,
+			Dimensions:    dimensions,
+			ShippingClass: model.ShippingFragile,
+		})
+
+		// When
+		stored, err := repo.GetByID(context.TODO(), product.ID)
+
+		// Then
+		require.NoError(t, err)
+
+		assert.Equal(t, weight, stored.Weight)
+
+		assert.Equal(t, dimensions, stored.Dimensions)
+
+		assert.Equal(t, model.ShippingFragile, stored.ShippingClass)
+	})
+
+	t.Run("successfully search products by weight and dimension ranges", func(t *testing.T) {
+		// Given
+		repo := factory(t)
+
+		light := createProduct(t, repo, model.Product{
+			Name:       "shirt",
+			Sku:        "shirt",
+			Qty:        1,
+			Price:      100,
+			Weight:     &model.Weight{Value: 0.2, Unit: model.Kilogram},
+			Dimensions: &model.Dimensions{Length: 30, Width: 20, Height: 2, Unit: model.Centimeter},
+		})
+
+		heavy := createProduct(t, repo, model.Product{
+			Name:       "boots",
+			Sku:        "boots",
+			Qty:        1,
+			Price:      200,
+			Weight:     &model.Weight{Value: 2, Unit: model.Kilogram},
+			Dimensions: &model.Dimensions{Length: 35, Width: 25, Height: 15, Unit: model.Centimeter},
+		})
+
+		// Products without weight or dimensions never match a range
+		createProduct(t, repo, model.Product{Name: "gift card", Sku: "gift", Qty: 1, Price: 300})
+
+		tests := []struct {
+			name     string
+			measures []model.MeasureFilter
+			expected []string
+		}{
+			{
+				name:     "minimum weight",
+				measures: []model.MeasureFilter{{Measure: model.MeasureWeight, Operator: model.AttributeGreaterOrEqual, Value: 1}},
+				expected: []string{heavy.ID},
+			},
+			{
+				name:     "maximum weight, inclusive",
+				measures: []model.MeasureFilter{{Measure: model.MeasureWeight, Operator: model.AttributeLessOrEqual, Value: 2}},
+				expected: []string{light.ID, heavy.ID},
+			},
+			{
+				name: "height range",
+				measures: []model.MeasureFilter{
+					{Measure: model.MeasureHeight, Operator: model.AttributeGreaterOrEqual, Value: 1},
+					{Measure: model.MeasureHeight, Operator: model.AttributeLessOrEqual, Value: 5},
+				},
+				expected: []string{light.ID},
+			},
+			{
+				name: "length and width",
+				measures: []model.MeasureFilter{
+					{Measure: model.MeasureLength, Operator: model.AttributeGreaterOrEqual, Value: 35},
+					{Measure: model.MeasureWidth, Operator: model.AttributeLessOrEqual, Value: 20},
+				},
+				expected: nil,
+			},
+		}
+
+		for _, test := range tests {
+			// When
+			page := search(t, repo, model.SearchRequest{InStock: true, Sort: "asc", Measures: test.measures})
+
+			// Then
+			assert.Equal(t, test.expected, ids(page.Products), test.name)
+
+			assert.Equal(t, int64(len(test.expected)), page.Total, test.name)
+		}
+	})
+}
@@ -0,0 +1,130 @@
+package repotest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	internalErrors "github.com/srodrmendz/api-product-catalog/errors"
+	"github.com/srodrmendz/api-product-catalog/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testSlugs(t *testing.T, factory Factory) {
+	t.Run("successfully get products by their current and previous slugs", func(t *testing.T) {
+		// Given
+		ctx := context.TODO()
+
+		repo := factory(t)
+
+		createProduct(t, repo, model.Product{Name: "hat", Sku: "hat", Qty: 1, Price: 50, Slug: "hat"})
+
+		product := createProduct(t, repo, model.Product{
+			Name:         "running shoes",
+			Sku:          "shoes",
+			Qty:          1,
+			Price:        100,
+			Locale:       "en",
+			Slug:         "running-shoes",
+			Translations: []model.Translation{{Locale: "es", Name: "zapatillas", Slug: "zapatillas"}},
+		})
+
+		product.ChangeSlug("trail-shoes", "", time.Now().UTC().Truncate(time.Millisecond))
+
+		product, err := repo.Replace(ctx, *product)
+
+		require.NoError(t, err)
+
+		// When
+		for _, slug := range []string{"trail-shoes", "zapatillas", "running-shoes"} {
+			found, err := repo.GetBySlug(ctx, slug)
+
+			// Then
+			require.NoError(t, err)
+
+			assert.Equal(t, product.ID, found.ID)
+		}
+
+		found, err := repo.GetBySlug(ctx, "running-shoes")
+
+		require.NoError(t, err)
+
+		assert.Equal(t, product.SlugHistory, found.SlugHistory)
+
+		_, err = repo.GetBySlug(ctx, "running")
+
+		assert.ErrorIs(t, err, internalErrors.ErrProductNotFound)
+	})
+
+	t.Run("failed to hold a slug another product holds", func(t *testing.T) {
+		// Given
+		ctx := context.TODO()
+
+		repo := factory(t)
+
+		shoes := createProduct(t, repo, model.Product{Name: "shoes", Sku: "shoes", Qty: 1, Price: 100, Slug: "shoes"})
+
+		hat := createProduct(t, repo, model.Product{Name: "hat", Sku: "hat", Qty: 1, Price: 50, Slug: "hat"})
+
+		// When
+		_, err := repo.Create(ctx, model.Product{Name: "boots", Sku: "boots", Qty: 1, Price: 100, Slug: "shoes"})
+
+		// Then
+		assert.ErrorIs(t, err, internalErrors.ErrSlugAlreadyExist)
+
+		hat.Translations = []model.Translation{{Locale: "es", Name: "zapatos", Slug: "shoes"}}
+
+		_, err = repo.Replace(ctx, *hat)
+
+		assert.ErrorIs(t, err, internalErrors.ErrSlugAlreadyExist)
+
+		// The product holding the slug can keep replacing itself with it
+		shoes.Name = "running shoes"
+
+		_, err = repo.Replace(ctx, *shoes)
+
+		require.NoError(t, err)
+
+		found, err := repo.GetBySlug(ctx, "shoes")
+
+		require.NoError(t, err)
+
+		assert.Equal(t, shoes.ID, found.ID)
+
+		found, err = repo.GetBySlug(ctx, "hat")
+
+		require.NoError(t, err)
+
+		assert.Equal(t, hat.ID, found.ID)
+	})
+
+	t.Run("successfully get deleted products by slug until purged", func(t *testing.T) {
+		// Given
+		ctx := context.TODO()
+
+		repo := factory(t)
+
+		product := createProduct(t, repo, model.Product{Name: "shoes", Sku: "shoes", Qty: 1, Price: 100, Slug: "shoes"})
+
+		require.NoError(t, repo.Delete(ctx, product.ID, 0))
+
+		// When
+		found, err := repo.GetBySlug(ctx, "shoes")
+
+		// Then
+		require.NoError(t, err)
+
+		assert.Equal(t, product.ID, found.ID)
+
+		assert.NotNil(t, found.DeletedAt)
+
+		_, err = repo.Purge(ctx, time.Now().Add(time.Minute))
+
+		require.NoError(t, err)
+
+		_, err = repo.GetBySlug(ctx, "shoes")
+
+		assert.ErrorIs(t, err, internalErrors.ErrProductNotFound)
+	})
+}
@@ -0,0 +1,155 @@
+package repotest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	internalError "github.com/srodrmendz/api-product-catalog/errors"
+	"github.com/srodrmendz/api-product-catalog/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testStatuses(t *testing.T, factory Factory) {
+	t.Run("successfully replace product", func(t *testing.T) {
+		// Given
+		ctx := context.TODO()
+
+		repo := factory(t)
+
+		product := createProduct(t, repo, model.Product{Name: "product", Sku: "sku", Qty: 1, Price: 100, Status: model.StatusDraft})
+
+		publishAt := time.Now().UTC().Truncate(time.Second)
+
+		product.Status, product.PublishAt = model.StatusPublished, &publishAt
+
+		product.Qty = 0
+
+		// When
+		replaced, err := repo.Replace(ctx, *product)
+
+		// Then
+		require.NoError(t, err)
+
+		assert.Equal(t, product.Version+1, replaced.Version)
+
+		assert.False(t, replaced.InStock)
+
+		stored, err := repo.GetByID(ctx, product.ID)
+
+		require.NoError(t, err)
+
+		assert.Equal(t, model.StatusPublished, stored.Status)
+
+		require.NotNil(t, stored.PublishAt)
+
+		assert.True(t, publishAt.Equal(*stored.PublishAt))
+
+		assert.Nil(t, stored.UnpublishAt)
+
+		assert.Equal(t, replaced.Version, stored.Version)
+
+		assert.False(t, stored.InStock)
+	})
+
+	t.Run("failed to replace product, version conflict", func(t *testing.T) {
+		// Given
+		ctx := context.TODO()
+
+		repo := factory(t)
+
+		product := createProduct(t, repo, model.Product{Name: "product", Sku: "sku", Qty: 1, Price: 100})
+
+		_, err := repo.Replace(ctx, *product)
+
+		require.NoError(t, err)
+
+		// When
+		_, err = repo.Replace(ctx, *product)
+
+		// Then
+		assertVersionConflict(t, err, product.Version+1)
+	})
+
+	t.Run("failed to replace product, product not found", func(t *testing.T) {
+		// Given
+		ctx := context.TODO()
+
+		repo := factory(t)
+
+		product := createProduct(t, repo, model.Product{Name: "product", Sku: "sku", Qty: 1, Price: 100})
+
+		require.NoError(t, repo.Delete(ctx, product.ID, 0))
+
+		// When
+		_, err := repo.Replace(ctx, *product)
+
+		// Then
+		assert.ErrorIs(t, err, internalError.ErrProductNotFound)
+	})
+
+	t.Run("successfully search products by status", func(t *testing.T) {
+		// Given
+		repo := factory(t)
+
+		legacy := createProduct(t, repo, model.Product{Name: "legacy", Sku: "legacy", Qty: 1, Price: 100})
+
+		draft := createProduct(t, repo, model.Product{Name: "draft", Sku: "draft", Qty: 1, Price: 200, Status: model.StatusDraft})
+
+		published := createProduct(t, repo, model.Product{Name: "published", Sku: "published", Qty: 1, Price: 300, Status: model.StatusPublished})
+
+		archived := createProduct(t, repo, model.Product{Name: "archived", Sku: "archived", Qty: 1, Price: 400, Status: model.StatusArchived})
+
+		// When
+		all := search(t, repo, model.SearchRequest{InStock: true})
+
+		publishedPage := search(t, repo, model.SearchRequest{InStock: true, Statuses: []model.Status{model.StatusPublished}})
+
+		others := search(t, repo, model.SearchRequest{InStock: true, Statuses: []model.Status{model.StatusDraft, model.StatusArchived}})
+
+		// Then
+		assert.Equal(t, []string{legacy.ID, draft.ID, published.ID, archived.ID}, ids(all.Products))
+
+		assert.Equal(t, []string{legacy.ID, published.ID}, ids(publishedPage.Products))
+
+		assert.Equal(t, int64(2), publishedPage.Total)
+
+		assert.Equal(t, []string{draft.ID, archived.ID}, ids(others.Products))
+	})
+
+	t.Run("successfully search products visible at a time", func(t *testing.T) {
+		// Given
+		repo := factory(t)
+
+		now := time.Now().UTC().Truncate(time.Second)
+
+		past, future := now.Add(-time.Hour), now.Add(time.Hour)
+
+		legacy := createProduct(t, repo, model.Product{Name: "legacy", Sku: "legacy", Qty: 1, Price: 100})
+
+		createProduct(t, repo, model.Product{Name: "draft", Sku: "draft", Qty: 1, Price: 200, Status: model.StatusDraft})
+
+		started := createProduct(t, repo, model.Product{
+			Name:        "started",
+			Sku:         "started",
+			Qty:         1,
+			Price:       300,
+			Status:      model.StatusPublished,
+			PublishAt:   &past,
+			UnpublishAt: &future,
+		})
+
+		createProduct(t, repo, model.Product{Name: "scheduled", Sku: "scheduled", Qty: 1, Price: 400, Status: model.StatusPublished, PublishAt: &future})
+
+		createProduct(t, repo, model.Product{Name: "ended", Sku: "ended", Qty: 1, Price: 500, Status: model.StatusPublished, UnpublishAt: &now})
+
+		// When
+		page := search(t, repo, model.SearchRequest{InStock: true, VisibleAt: &now})
+
+		// Then
+		assert.Equal(t, []string{legacy.ID, started.ID}, ids(page.Products))
+
+		assert.Equal(t, int64(2), page.Total)
+	})
+}
@@ -0,0 +1,405 @@
+package repotest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	internalError "github.com/srodrmendz/api-product-catalog/errors"
+	"github.com/srodrmendz/api-product-catalog/model"
+	"github.com/srodrmendz/api-product-catalog/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testStockMovements(t *testing.T, factory Factory) {
+	// Records the movements apart in time, so every backend sorts them the same way
+	record := func(t *testing.T, repo repository.Repository, movements ...model.StockMovement) []model.StockMovement {
+		var recorded []model.StockMovement
+
+		for _, movement := range movements {
+			time.Sleep(2 * time.Millisecond)
+
+			created, err := repo.CreateStockMovement(context.TODO(), movement)
+
+			require.NoError(t, err)
+
+			recorded = append(recorded, *created)
+		}
+
+		return recorded
+	}
+
+	ids := func(movements []model.StockMovement) []string {
+		var ids []string
+
+		for _, movement := range movements {
+			ids = append(ids, movement.ID)
+		}
+
+		return ids
+	}
+
+	t.Run("successfully record and list stock movements newest first", func(t *testing.T) {
+		// Given
+		ctx := context.TODO()
+
+		repo := factory(t)
+
+		// When
+		recorded := record(
+			t,
+			repo,
+			model.StockMovement{ProductID: "1", Type: model.MovementReceipt, Delta: 10, Qty: 10, Reference: "PO-1", Actor: "warehouse"},
+			model.StockMovement{ProductID: "2", Type: model.MovementReceipt, Delta: 5, Qty: 5},
+			model.StockMovement{ProductID: "1", Type: model.MovementSale, Delta: -3, Qty: 7, Reason: "order", Reference: "SO-1"},
+			model.StockMovement{ProductID: "1", VariantSKU: "shoes-42", Type: model.MovementDamage, Delta: -1, Qty: 6},
+		)
+
+		// Then
+		assert.NotEmpty(t, recorded[0].ID)
+
+		assert.Equal(t, false, recorded[0].CreatedAt.IsZero())
+
+		page, err := repo.ListStockMovements(ctx, model.MovementSearchRequest{ProductID: "1"})
+
+		require.NoError(t, err)
+
+		assert.Equal(t, int64(3), page.Total)
+
+		assert.Equal(t, []string{recorded[3].ID, recorded[2].ID, recorded[0].ID}, ids(page.Movements))
+
+		stored := page.Movements[2]
+
+		assert.Equal(t, model.MovementReceipt, stored.Type)
+
+		assert.Equal(t, int64(10), stored.Delta)
+
+		assert.Equal(t, uint64(10), stored.Qty)
+
+		assert.Equal(t, "PO-1", stored.Reference)
+
+		assert.Equal(t, "warehouse", stored.Actor)
+
+		assert.Equal(t, "shoes-42", page.Movements[0].VariantSKU)
+
+		page, err = repo.ListStockMovements(ctx, model.MovementSearchRequest{ProductID: "1", Limit: 1, Offset: 1})
+
+		require.NoError(t, err)
+
+		assert.Equal(t, int64(3), page.Total)
+
+		assert.Equal(t, []string{recorded[2].ID}, ids(page.Movements))
+
+		page, err = repo.ListStockMovements(ctx, model.MovementSearchRequest{ProductID: "fake"})
+
+		require.NoError(t, err)
+
+		assert.Equal(t, int64(0), page.Total)
+
+		assert.Empty(t, page.Movements)
+	})
+
+	t.Run("successfully filter stock movements by type and time", func(t *testing.T) {
+		// Given
+		ctx := context.TODO()
+
+		repo := factory(t)
+
+		first := record(t, repo, model.StockMovement{ProductID: "1", Type: model.MovementReceipt, Delta: 10, Qty: 10})
+
+		time.Sleep(2 * time.Millisecond)
+
+		from := time.Now()
+
+		second := record(
+			t,
+			repo,
+			model.StockMovement{ProductID: "1", Type: model.MovementSale, Delta: -2, Qty: 8},
+			model.StockMovement{ProductID: "1", Type: model.MovementReturn, Delta: 1, Qty: 9},
+		)
+
+		time.Sleep(2 * time.Millisecond)
+
+		to := time.Now()
+
+		third := record(t, repo, model.StockMovement{ProductID: "1", Type: model.MovementSale, Delta: -4, Qty: 5})
+
+		// When
+		sales, err := repo.ListStockMovements(ctx, model.MovementSearchRequest{ProductID: "1", Types: []model.MovementType{model.MovementSale}})
+
+		require.NoError(t, err)
+
+		between, err := repo.ListStockMovements(ctx, model.MovementSearchRequest{ProductID: "1", From: &from, To: &to})
+
+		require.NoError(t, err)
+
+		before, err := repo.ListStockMovements(ctx, model.MovementSearchRequest{
+			ProductID: "1",
+			Types:     []model.MovementType{model.MovementReceipt, model.MovementReturn},
+			To:        &to,
+		})
+
+		require.NoError(t, err)
+
+		// Then
+		assert.Equal(t, []string{third[0].ID, second[0].ID}, ids(sales.Movements))
+
+		assert.Equal(t, []string{second[1].ID, second[0].ID}, ids(between.Movements))
+
+		assert.Equal(t, int64(2), between.Total)
+
+		assert.Equal(t, []string{second[1].ID, first[0].ID}, ids(before.Movements))
+	})
+
+	t.Run("stock movements recorded on a failed transaction are discarded", func(t *testing.T) {
+		// Given
+		ctx := context.TODO()
+
+		repo := factory(t)
+
+		failed := errors.New("failed")
+
+		// When
+		err := repo.WithTransaction(ctx, func(txRepo repository.Repository) error {
+			if _, err := txRepo.CreateStockMovement(ctx, model.StockMovement{ProductID: "1", Type: model.MovementReceipt, Delta: 1, Qty: 1}); err != nil {
+				return err
+			}
+
+			return failed
+		})
+
+		// Then
+		assert.ErrorIs(t, err, failed)
+
+		page, err := repo.ListStockMovements(ctx, model.MovementSearchRequest{ProductID: "1"})
+
+		require.NoError(t, err)
+
+		assert.Equal(t, int64(0), page.Total)
+	})
+}
+
+func testAdjustStock(t *testing.T, factory Factory) {
+	t.Run("successfully adjust product stock down to zero", func(t *testing.T) {
+		// Given
+		ctx := context.TODO()
+
+		repo := factory(t)
+
+		product := createProduct(t, repo, model.Product{Name: "product 1", Sku: "sku1", Qty: 5, InStock: true})
+
+		// When
+		adjusted, err := repo.AdjustStock(ctx, model.StockAdjustment{ID: product.ID, Delta: -5})
+
+		// Then
+		require.NoError(t, err)
+
+		assert.Equal(t, uint64(0), adjusted.Qty)
+
+		assert.Equal(t, false, adjusted.InStock)
+
+		assert.Equal(t, product.Version+1, adjusted.Version)
+
+		stored, err := repo.GetByID(ctx, product.ID)
+
+		require.NoError(t, err)
+
+		assert.Equal(t, uint64(0), stored.Qty)
+
+		assert.Equal(t, false, stored.InStock)
+
+		assert.Equal(t, adjusted.Version, stored.Version)
+	})
+
+	t.Run("successfully adjust product stock back in stock", func(t *testing.T) {
+		// Given
+		ctx := context.TODO()
+
+		repo := factory(t)
+
+		product := createProduct(t, repo, model.Product{Name: "product 1", Sku: "sku1"})
+
+		// When
+		adjusted, err := repo.AdjustStock(ctx, model.StockAdjustment{ID: product.ID, Delta: 3})
+
+		// Then
+		require.NoError(t, err)
+
+		assert.Equal(t, uint64(3), adjusted.Qty)
+
+		assert.Equal(t, true, adjusted.InStock)
+	})
+
+	t.Run("failed to adjust product stock, insufficient stock", func(t *testing.T) {
+		// Given
+		ctx := context.TODO()
+
+		repo := factory(t)
+
+		product := createProduct(t, repo, model.Product{Name: "product 1", Sku: "sku1", Qty: 2, InStock: true})
+
+		// When
+		adjusted, err := repo.AdjustStock(ctx, model.StockAdjustment{ID: product.ID, Delta: -3})
+
+		// Then
+		assert.ErrorIs(t, err, internalError.ErrInsufficientStock)
+
+		assert.Nil(t, adjusted)
+
+		stored, err := repo.GetByID(ctx, product.ID)
+
+		require.NoError(t, err)
+
+		assert.Equal(t, uint64(2), stored.Qty)
+
+		assert.Equal(t, product.Version, stored.Version)
+	})
+
+	t.Run("failed to adjust product stock, qty over the maximum", func(t *testing.T) {
+		// Given
+		ctx := context.TODO()
+
+		repo := factory(t)
+
+		product := createProduct(t, repo, model.Product{Name: "product 1", Sku: "sku1", Qty: 10, InStock: true})
+
+		// When
+		_, err := repo.AdjustStock(ctx, model.StockAdjustment{ID: product.ID, Delta: model.MaxQty - 9})
+
+		// Then
+		assert.ErrorIs(t, err, internalError.ErrStockLimit)
+
+		adjusted, err := repo.AdjustStock(ctx, model.StockAdjustment{ID: product.ID, Delta: model.MaxQty - 10})
+
+		require.NoError(t, err)
+
+		assert.Equal(t, uint64(model.MaxQty), adjusted.Qty)
+
+		_, err = repo.AdjustStock(ctx, model.StockAdjustment{ID: product.ID, Delta: 1})
+
+		assert.ErrorIs(t, err, internalError.ErrStockLimit)
+
+		stored, err := repo.GetByID(ctx, product.ID)
+
+		require.NoError(t, err)
+
+		assert.Equal(t, uint64(model.MaxQty), stored.Qty)
+	})
+
+	t.Run("failed to adjust variant stock, product qty over the maximum", func(t *testing.T) {
+		// Given
+		ctx := context.TODO()
+
+		repo := factory(t)
+
+		product := createVariantProduct(t, repo, "shirt")
+
+		// When
+		_, err := repo.AdjustStock(ctx, model.StockAdjustment{ID: product.ID, VariantSKU: "shirt-l", Delta: model.MaxQty - 10})
+
+		// Then
+		assert.ErrorIs(t, err, internalError.ErrStockLimit)
+
+		stored, err := repo.GetByID(ctx, product.ID)
+
+		require.NoError(t, err)
+
+		assert.Equal(t, uint64(5), stored.Variant("shirt-l").Qty)
+	})
+
+	t.Run("failed to adjust product stock, product not found", func(t *testing.T) {
+		// Given
+		ctx := context.TODO()
+
+		repo := factory(t)
+
+		// When
+		_, err := repo.AdjustStock(ctx, model.StockAdjustment{ID: "fake", Delta: 1})
+
+		// Then
+		assert.ErrorIs(t, err, internalError.ErrProductNotFound)
+	})
+
+	t.Run("successfully adjust variant stock", func(t *testing.T) {
+		// Given
+		ctx := context.TODO()
+
+		repo := factory(t)
+
+		product := createVariantProduct(t, repo, "shirt")
+
+		// When
+		adjusted, err := repo.AdjustStock(ctx, model.StockAdjustment{ID: product.ID, VariantSKU: "shirt-l", Delta: -5})
+
+		// Then
+		require.NoError(t, err)
+
+		assert.Equal(t, uint64(0), adjusted.Variant("shirt-l").Qty)
+
+		assert.Equal(t, false, adjusted.Variant("shirt-l").InStock)
+
+		assert.Equal(t, uint64(10), adjusted.Variant("shirt-m").Qty)
+
+		assert.Equal(t, true, adjusted.Variant("shirt-m").InStock)
+
+		assert.Equal(t, uint64(10), adjusted.Qty)
+
+		assert.Equal(t, true, adjusted.InStock)
+
+		stored, err := repo.GetByID(ctx, product.ID)
+
+		require.NoError(t, err)
+
+		assert.Equal(t, uint64(0), stored.Variant("shirt-l").Qty)
+
+		assert.Equal(t, false, stored.Variant("shirt-l").InStock)
+
+		assert.Equal(t, []string{"shirt-m", "shirt-l"}, []string{stored.Variants[0].Sku, stored.Variants[1].Sku})
+
+		assert.Equal(t, uint64(10), stored.Qty)
+	})
+
+	t.Run("failed to adjust variant stock, insufficient stock", func(t *testing.T) {
+		// Given
+		ctx := context.TODO()
+
+		repo := factory(t)
+
+		product := createVariantProduct(t, repo, "shirt")
+
+		// When
+		_, err := repo.AdjustStock(ctx, model.StockAdjustment{ID: product.ID, VariantSKU: "shirt-l", Delta: -6})
+
+		// Then
+		assert.ErrorIs(t, err, internalError.ErrInsufficientStock)
+
+		stored, err := repo.GetByID(ctx, product.ID)
+
+		require.NoError(t, err)
+
+		assert.Equal(t, uint64(5), stored.Variant("shirt-l").Qty)
+
+		assert.Equal(t, uint64(15), stored.Qty)
+	})
+
+	t.Run("failed to adjust variant stock, variant required or not found", func(t *testing.T) {
+		// Given
+		ctx := context.TODO()
+
+		repo := factory(t)
+
+		product := createVariantProduct(t, repo, "shirt")
+
+		// When
+		_, required := repo.AdjustStock(ctx, model.StockAdjustment{ID: product.ID, Delta: 1})
+
+		_, notFound := repo.AdjustStock(ctx, model.StockAdjustment{ID: product.ID, VariantSKU: "shirt-xl", Delta: 1})
+
+		// Then
+		assert.ErrorIs(t, required, internalError.ErrVariantRequired)
+
+		assert.ErrorIs(t, notFound, internalError.ErrVariantNotFound)
+	})
+}
@@ -0,0 +1,94 @@
+package repotest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/srodrmendz/api-product-catalog/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testTranslations(t *testing.T, factory Factory) {
+	t.Run("successfully store product translations", func(t *testing.T) {
+		// Given
+		ctx := context.TODO()
+
+		repo := factory(t)
+
+		description := "camisa de algodón"
+
+		product := createProduct(t, repo, model.Product{
+			Name:   "cotton shirt",
+			Sku:    "shirt",
+			Slug:   "cotton-shirt",
+			Locale: "en",
+			Qty:    1,
+			Price:  100,
+			Translations: []model.Translation{
+				{Locale: "es", Name: "camisa", Description: &description, Slug: "camisa"},
+			},
+		})
+
+		product.SetTranslation(model.Translation{Locale: "pt-BR", Name: "camisa de algodão"})
+
+		// When
+		_, err := repo.Replace(ctx, *product)
+
+		// Then
+		require.NoError(t, err)
+
+		stored, err := repo.GetByID(ctx, product.ID)
+
+		require.NoError(t, err)
+
+		assert.Equal(t, "en", stored.Locale)
+
+		assert.Equal(t, "cotton-shirt", stored.Slug)
+
+		require.Len(t, stored.Translations, 2)
+
+		assert.Equal(t, "es", stored.Translations[0].Locale)
+
+		assert.Equal(t, "camisa", stored.Translations[0].Slug)
+
+		assert.Equal(t, description, *stored.Translations[0].Description)
+
+		assert.Equal(t, "camisa de algodão", stored.Translations[1].Name)
+	})
+
+	t.Run("successfully search products by their translated names", func(t *testing.T) {
+		// Given
+		repo := factory(t)
+
+		description := "zapatilla para correr"
+
+		shoe := createProduct(t, repo, model.Product{
+			Name:         "running shoe",
+			Sku:          "shoe",
+			Locale:       "en",
+			Qty:          1,
+			Price:        100,
+			Translations: []model.Translation{{Locale: "es", Name: "zapatilla", Description: &description}},
+		})
+
+		createProduct(t, repo, model.Product{
+			Name:         "shirt",
+			Sku:          "shirt",
+			Locale:       "en",
+			Qty:          1,
+			Price:        200,
+			Translations: []model.Translation{{Locale: "es", Name: "camisa"}},
+		})
+
+		// When
+		byName := search(t, repo, model.SearchRequest{InStock: true, Name: "zapatilla", Locale: "es"})
+
+		byDescription := search(t, repo, model.SearchRequest{InStock: true, Name: "correr", Locale: "es"})
+
+		// Then
+		assert.Equal(t, []string{shoe.ID}, ids(byName.Products))
+
+		assert.Equal(t, []string{shoe.ID}, ids(byDescription.Products))
+	})
+}
@@ -0,0 +1,193 @@
+package repotest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	internalError "github.com/srodrmendz/api-product-catalog/errors"
+	"github.com/srodrmendz/api-product-catalog/model"
+	"github.com/srodrmendz/api-product-catalog/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testVariants(t *testing.T, factory Factory) {
+	t.Run("successfully create and get product by variant sku", func(t *testing.T) {
+		// Given
+		ctx := context.TODO()
+
+		repo := factory(t)
+
+		product := createVariantProduct(t, repo, "shirt")
+
+		// When
+		response, err := repo.GetBySKU(ctx, "shirt-l")
+
+		// Then
+		require.NoError(t, err)
+
+		assertSameProduct(t, *product, *response)
+
+		require.Len(t, response.Variants, 2)
+
+		assert.Equal(t, map[string]string{"size": "l"}, response.Variant("shirt-l").Options)
+
+		assert.Equal(t, int64(1200), response.Variant("shirt-l").Price)
+
+		assert.Equal(t, &model.PriceRange{Min: 1000, Max: 1200}, response.PriceRange)
+	})
+
+	t.Run("failed to create product, variant sku already exist", func(t *testing.T) {
+		// Given
+		ctx := context.TODO()
+
+		repo := factory(t)
+
+		createVariantProduct(t, repo, "shirt")
+
+		// When
+		response, err := repo.Create(ctx, model.Product{
+			Name:  "product 2",
+			Sku:   "shirt-m",
+			Price: 100,
+		})
+
+		// Then
+		assert.ErrorIs(t, err, internalError.ErrProductSKUAlreadyExist)
+
+		assert.Nil(t, response)
+
+		_, err = repo.GetBySKU(ctx, "shirt-m")
+
+		require.NoError(t, err)
+	})
+
+	t.Run("successfully update variant qty", func(t *testing.T) {
+		// Given
+		ctx := context.TODO()
+
+		repo := factory(t)
+
+		product := createVariantProduct(t, repo, "shirt")
+
+		// When
+		response, err := repo.Update(ctx, model.Update{
+			ID:            product.ID,
+			Version:       product.Version,
+			UpdateRequest: model.UpdateRequest{Qty: qtyOf(0), VariantSKU: "shirt-m"},
+		})
+
+		// Then
+		require.NoError(t, err)
+
+		assert.Equal(t, uint64(0), response.Variant("shirt-m").Qty)
+
+		assert.Equal(t, false, response.Variant("shirt-m").InStock)
+
+		assert.Equal(t, uint64(5), response.Qty)
+
+		assert.Equal(t, true, response.InStock)
+
+		assert.Equal(t, product.Version+1, response.Version)
+
+		// When
+		response, err = repo.Update(ctx, model.Update{
+			ID:            product.ID,
+			UpdateRequest: model.UpdateRequest{Qty: qtyOf(0), VariantSKU: "shirt-l"},
+		})
+
+		// Then
+		require.NoError(t, err)
+
+		stored, err := repo.GetByID(ctx, product.ID)
+
+		require.NoError(t, err)
+
+		assert.Equal(t, uint64(0), stored.Qty)
+
+		assert.Equal(t, false, stored.InStock)
+
+		assert.Equal(t, response.Version, stored.Version)
+	})
+
+	t.Run("failed update product with variants, variant sku required", func(t *testing.T) {
+		// Given
+		repo := factory(t)
+
+		product := createVariantProduct(t, repo, "shirt")
+
+		// When
+		response, err := repo.Update(context.TODO(), model.Update{ID: product.ID, UpdateRequest: model.UpdateRequest{Qty: qtyOf(10)}})
+
+		// Then
+		assert.ErrorIs(t, err, internalError.ErrVariantRequired)
+
+		assert.Nil(t, response)
+	})
+
+	t.Run("failed update product, variant not found", func(t *testing.T) {
+		// Given
+		repo := factory(t)
+
+		product := createVariantProduct(t, repo, "shirt")
+
+		// When
+		response, err := repo.Update(context.TODO(), model.Update{
+			ID:            product.ID,
+			UpdateRequest: model.UpdateRequest{Qty: qtyOf(10), VariantSKU: "shirt-xl"},
+		})
+
+		// Then
+		assert.ErrorIs(t, err, internalError.ErrVariantNotFound)
+
+		assert.Nil(t, response)
+	})
+
+	t.Run("purge releases variant skus", func(t *testing.T) {
+		// Given
+		ctx := context.TODO()
+
+		repo := factory(t)
+
+		product := createVariantProduct(t, repo, "shirt")
+
+		require.NoError(t, repo.Delete(ctx, product.ID, 0))
+
+		_, err := repo.Purge(ctx, time.Now().Add(time.Minute))
+
+		require.NoError(t, err)
+
+		// When
+		response, err := repo.Create(ctx, model.Product{
+			Name:  "product 2",
+			Sku:   "shirt-l",
+			Price: 100,
+		})
+
+		// Then
+		require.NoError(t, err)
+
+		assert.Equal(t, "shirt-l", response.Sku)
+	})
+}
+
+// Create a product with a medium and a large variant, their skus are prefixed by sku
+func createVariantProduct(t *testing.T, repo repository.Repository, sku string) *model.Product {
+	t.Helper()
+
+	product := model.Product{
+		Name:    "shirt",
+		Sku:     sku,
+		Options: []model.Option{{Name: "size", Values: []string{"m", "l"}}},
+		Variants: []model.Variant{
+			{Sku: sku + "-m", Options: map[string]string{"size": "m"}, Qty: 10, Price: 1000},
+			{Sku: sku + "-l", Options: map[string]string{"size": "l"}, Qty: 5, Price: 1200},
+		},
+	}
+
+	// Products are created from the builder, which derives the stock and prices from the variants
+	product.ApplyVariants()
+
+	return createProduct(t, repo, product)
+}
@@ -0,0 +1,240 @@
+package repository
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/srodrmendz/api-product-catalog/model"
+)
+
+// Reports whether product matches the search filters, the same way getSearchFilter does on MongoDB
+// The name is searched on every locale, words are matched as written since there is no language analyzer
+func matchSearch(product model.Product, request model.SearchRequest) bool {
+	if product.InStock != request.InStock {
+		return false
+	}
+
+	if product.DeletedAt != nil && !request.IncludeDeleted {
+		return false
+	}
+
+	if !matchCategories(product, request.CategoryIDs) {
+		return false
+	}
+
+	if !matchAttributes(product, request.Attributes) {
+		return false
+	}
+
+	if request.BrandID != "" && product.BrandID != request.BrandID {
+		return false
+	}
+
+	if !matchTags(product, request.Tags, request.TagMatch) {
+		return false
+	}
+
+	if !matchMeasures(product, request.Measures) {
+		return false
+	}
+
+	if !matchComponent(product, request.ComponentID) {
+		return false
+	}
+
+	if !matchStatus(product, request) {
+		return false
+	}
+
+	if request.Name == "" || matchContent(product.Name, product.Description, request.Name) {
+		return true
+	}
+
+	for _, translation := range product.Translations {
+		if matchContent(translation.Name, translation.Description, request.Name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Reports whether any of the search terms is a word of the name or description
+func matchContent(name string, description *string, search string) bool {
+	return matchText(name, search) || description != nil && matchText(*description, search)
+}
+
+// Reports whether the product has one of the requested statuses and is visible at the requested time, when they are set
+func matchStatus(product model.Product, request model.SearchRequest) bool {
+	if request.VisibleAt != nil && !product.IsVisible(*request.VisibleAt) {
+		return false
+	}
+
+	if len(request.Statuses) == 0 {
+		return true
+	}
+
+	for _, status := range request.Statuses {
+		if product.PublicationStatus() == status {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Reports whether any of the search terms is a word of text, ignoring case
+func matchText(text string, search string) bool {
+	words := make(map[string]struct{})
+
+	for _, word := range splitWords(text) {
+		words[word] = struct{}{}
+	}
+
+	for _, term := range splitWords(search) {
+		if _, ok := words[term]; ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+func splitWords(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
+
+// Sort products by price, ascending unless sort is desc. Ties are broken by id
+// so pages are stable between calls
+func sortByPrice(products []model.Product, direction string) {
+	sort.Slice(products, func(i, j int) bool {
+		a, b := products[i], products[j]
+
+		if direction == "desc" {
+			a, b = b, a
+		}
+
+		if a.Price == b.Price {
+			return a.ID < b.ID
+		}
+
+		return a.Price < b.Price
+	})
+}
+
+// Collects the page requested by a search from the matching products, fed in sort order
+type pager struct {
+	request  model.SearchRequest
+	total    int64
+	products []model.Product
+	more     bool
+}
+
+func newPager(request model.SearchRequest) *pager {
+	return &pager{
+		request: request,
+	}
+}
+
+func (p *pager) add(product model.Product) {
+	p.total++
+
+	limit := p.request.Limit
+
+	cursor := p.request.Cursor
+
+	switch {
+	case cursor == nil:
+		if p.total > int64(p.request.Offset) && (limit == 0 || len(p.products) < limit) {
+			p.products = append(p.products, product)
+		}
+	case cursor.Backward:
+		// Keep the last products placed before the cursor
+		if !cursor.Follows(product) {
+			return
+		}
+
+		p.products = append(p.products, product)
+
+		if limit > 0 && len(p.products) > limit {
+			p.products = p.products[1:]
+
+			p.more = true
+		}
+	default:
+		if !cursor.Precedes(product) {
+			return
+		}
+
+		if limit == 0 || len(p.products) < limit {
+			p.products = append(p.products, product)
+		} else {
+			p.more = true
+		}
+	}
+}
+
+func (p *pager) page() *model.Page {
+	return newPage(p.products, p.total, p.request, p.more)
+}
+
+// Trim products fetched from a cursor position with one extra product, used to know whether
+// there are more products, and restore the sort order of the ones fetched walking it backwards
+func keysetPage(products []model.Product, request model.SearchRequest) ([]model.Product, bool) {
+	more := request.Limit > 0 && len(products) > request.Limit
+
+	if more {
+		products = products[:request.Limit]
+	}
+
+	if request.Cursor.Backward {
+		for i, j := 0, len(products)-1; i < j; i, j = i+1, j-1 {
+			products[i], products[j] = products[j], products[i]
+		}
+	}
+
+	return products, more
+}
+
+// Create the page of products with the cursors to the surrounding pages, more reports
+// whether there are products after the page on the cursor direction
+func newPage(products []model.Product, total int64, request model.SearchRequest, more bool) *model.Page {
+	page := &model.Page{
+		Products: products,
+		Total:    total,
+	}
+
+	if len(products) == 0 {
+		return page
+	}
+
+	var hasNext, hasPrev bool
+
+	switch cursor := request.Cursor; {
+	case cursor == nil:
+		hasNext = request.Limit > 0 && int64(request.Offset+len(products)) < total
+
+		hasPrev = request.Offset > 0
+	case cursor.Backward:
+		hasNext, hasPrev = true, more
+	default:
+		hasNext, hasPrev = more, true
+	}
+
+	if hasNext {
+		page.Next = model.NewCursor(products[len(products)-1], request.Sort, false)
+
+		page.Next.Currency = request.Currency.Code
+	}
+
+	if hasPrev {
+		page.Prev = model.NewCursor(products[0], request.Sort, true)
+
+		page.Prev.Currency = request.Currency.Code
+	}
+
+	return page
+}
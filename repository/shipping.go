@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"github.com/srodrmendz/api-product-catalog/model"
+)
+
+// Stored field of each measure on MongoDB, the SQL columns are named after the measures
+var measureFields = map[model.Measure]string{
+	model.MeasureWeight: "weight.value",
+	model.MeasureLength: "dimensions.length",
+	model.MeasureWidth:  "dimensions.width",
+	model.MeasureHeight: "dimensions.height",
+}
+
+// Reports whether the product measures pass every filter, products without a filtered measure do not
+func matchMeasures(product model.Product, filters []model.MeasureFilter) bool {
+	for _, filter := range filters {
+		value, ok := product.Measure(filter.Measure)
+		if !ok || !filter.Match(value) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func copyShipping(product model.Product) model.Product {
+	if product.Weight != nil {
+		weight := *product.Weight
+
+		product.Weight = &weight
+	}
+
+	if product.Dimensions != nil {
+		dimensions := *product.Dimensions
+
+		product.Dimensions = &dimensions
+	}
+
+	return product
+}
@@ -0,0 +1,17 @@
+package repository
+
+import (
+	internalError "github.com/srodrmendz/api-product-catalog/errors"
+)
+
+// Check no product other than the one with id holds any of the slugs, the same check the MongoDB unique slugs index does
+// holder returns the id of the product holding a slug, empty when none does
+func checkSlugs(id string, slugs []string, holder func(slug string) string) error {
+	for _, slug := range slugs {
+		if holderID := holder(slug); holderID != "" && holderID != id {
+			return internalError.ErrSlugAlreadyExist
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,982 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	internalError "github.com/srodrmendz/api-product-catalog/errors"
+	"github.com/srodrmendz/api-product-catalog/model"
+	"golang.org/x/sync/errgroup"
+)
+
+const productColumns = "id, name, description, sku, qty, images, created_at, updated_at, price, in_stock, version, deleted_at, category_ids, options, variants, attributes, currency, prices, " +
+	"regular_price, price_schedule, price_history, price_changes_at, status, publish_at, unpublish_at, slug, locale, translations, brand_id, tags, weight, length, width, height, shipping_class, bundle, component_ids, relations, related_ids, slug_history, slugs"
+
+// Create new SQL product repository, the database schema is migrated to the latest version
+func NewSQL(ctx context.Context, db *sql.DB, dialect Dialect) (*SQL, error) {
+	if err := migrate(ctx, db, dialect); err != nil {
+		return nil, err
+	}
+
+	return &SQL{
+		db:      db,
+		dialect: dialect,
+	}, nil
+}
+
+// Create a new product
+func (r *SQL) Create(ctx context.Context, product model.Product) (*model.Product, error) {
+	product.ID = uuid.NewString()
+
+	now := time.Now()
+
+	product.CreatedAt = now
+
+	product.UpdatedAt = now
+
+	product.InStock = product.Qty > 0
+
+	product.Version = 1
+
+	values, err := productValues(product)
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.inTransaction(ctx, func(tx *SQL) error {
+		_, err := tx.conn().ExecContext(
+			ctx,
+			tx.dialect.Rebind("INSERT INTO products ("+productColumns+") VALUES (?"+strings.Repeat(", ?", len(values)-1)+")"),
+			values...,
+		)
+		if err != nil {
+			return err
+		}
+
+		for _, sku := range product.SKUs() {
+			_, err := tx.conn().ExecContext(ctx, tx.dialect.Rebind("INSERT INTO product_skus (sku, product_id) VALUES (?, ?)"), sku, product.ID)
+			if err != nil {
+				return err
+			}
+		}
+
+		return tx.insertSlugs(ctx, product)
+	})
+	if err != nil {
+		if errors.Is(err, internalError.ErrSlugAlreadyExist) {
+			return nil, err
+		}
+
+		if r.dialect.IsUniqueViolation(err) {
+			return nil, internalError.ErrProductSKUAlreadyExist
+		}
+
+		return nil, fmt.Errorf("creating product %s on repository %w", product.Name, err)
+	}
+
+	return &product, nil
+}
+
+// Values of the product columns, on the productColumns order
+func productValues(product model.Product) ([]interface{}, error) {
+	var err error
+
+	// Encode a field as JSON, once a field fails the rest are skipped
+	encode := func(field string, value interface{}) string {
+		if err != nil {
+			return ""
+		}
+
+		var data []byte
+
+		if data, err = json.Marshal(value); err != nil {
+			err = fmt.Errorf("encoding product %s %s %w", product.Name, field, err)
+		}
+
+		return string(data)
+	}
+
+	values := []interface{}{
+		product.ID,
+		product.Name,
+		product.Description,
+		product.Sku,
+		int64(product.Qty),
+		encode("images", product.Images),
+		product.CreatedAt,
+		product.UpdatedAt,
+		product.Price,
+		product.InStock,
+		product.Version,
+		product.DeletedAt,
+		encode("category ids", product.CategoryIDs),
+		encode("options", product.Options),
+		encode("variants", product.Variants),
+		encode("attributes", product.Attributes),
+		product.Currency,
+		encode("prices", product.Prices),
+		product.RegularPrice,
+		encode("price schedule", product.PriceSchedule),
+		encode("price history", product.PriceHistory),
+		utcTime(product.PriceChangesAt),
+		// Products without status are stored as NULL, the status of the products stored before statuses existed
+		sql.NullString{String: string(product.Status), Valid: product.Status != ""},
+		utcTime(product.PublishAt),
+		utcTime(product.UnpublishAt),
+		product.Slug,
+		product.Locale,
+		encode("translations", product.Translations),
+		nullString(product.BrandID),
+		encode("tags", product.Tags),
+		// Weights and dimensions are stored on kilograms and centimeters, so only their values are
+		nullMeasure(product.Measure(model.MeasureWeight)),
+		nullMeasure(product.Measure(model.MeasureLength)),
+		nullMeasure(product.Measure(model.MeasureWidth)),
+		nullMeasure(product.Measure(model.MeasureHeight)),
+		nullString(string(product.ShippingClass)),
+		encode("bundle", product.Bundle),
+		// Component ids are only stored to find the bundles holding a product
+		encode("component ids", product.ComponentIDs()),
+		encode("relations", product.Relations),
+		// Related ids are only stored to find the products related to a deleted one
+		encode("related ids", product.RelatedIDs()),
+		encode("slug history", product.SlugHistory),
+		// Slugs are indexed on product_slugs, they are stored too so the index can be built from them
+		encode("slugs", product.Slugs()),
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return values, nil
+}
+
+// Write every column of a stored product
+func (r *SQL) writeProduct(ctx context.Context, product model.Product) error {
+	values, err := productValues(product)
+	if err != nil {
+		return err
+	}
+
+	columns := strings.Split(productColumns, ", ")
+
+	assignments := make([]string, 0, len(columns)-1)
+
+	for _, column := range columns[1:] {
+		assignments = append(assignments, column+" = ?")
+	}
+
+	// The id is the first column, it is moved to the end to filter the row
+	_, err = r.conn().ExecContext(
+		ctx,
+		r.dialect.Rebind("UPDATE products SET "+strings.Join(assignments, ", ")+" WHERE id = ?"),
+		append(values[1:], values[0])...,
+	)
+
+	return err
+}
+
+// Get a product by id
+func (r *SQL) GetByID(ctx context.Context, id string) (*model.Product, error) {
+	return r.get(ctx, "id = ?", id)
+}
+
+// Get a product by its sku or the sku of one of its variants
+func (r *SQL) GetBySKU(ctx context.Context, sku string) (*model.Product, error) {
+	return r.get(ctx, "id = (SELECT product_id FROM product_skus WHERE sku = ?)", sku)
+}
+
+// Get the product holding a slug, deleted products included
+func (r *SQL) GetBySlug(ctx context.Context, slug string) (*model.Product, error) {
+	query := r.dialect.Rebind(fmt.Sprintf("SELECT %s FROM products WHERE id = (SELECT product_id FROM product_slugs WHERE slug = ?)", productColumns))
+
+	product, err := scanProduct(r.conn().QueryRowContext(ctx, query, slug))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, internalError.ErrProductNotFound
+	}
+
+	return product, err
+}
+
+// Delete product, it is kept until purged so it can be restored
+func (r *SQL) Delete(ctx context.Context, id string, version int64) error {
+	return r.inTransaction(ctx, func(tx *SQL) error {
+		where, args := tx.getVersionFilter(id, version)
+
+		// Deleted times are stored on UTC so purge can compare them as text
+		now := time.Now().UTC()
+
+		result, err := tx.conn().ExecContext(
+			ctx,
+			tx.dialect.Rebind("UPDATE products SET deleted_at = ?, updated_at = ?, version = version + 1 WHERE "+where),
+			append([]interface{}{now, now}, args...)...,
+		)
+		if err != nil {
+			return fmt.Errorf("deleting product from repository %w", err)
+		}
+
+		if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+			if version != 0 {
+				return tx.getVersionConflict(ctx, id)
+			}
+
+			return internalError.ErrProductNotFound
+		}
+
+		return tx.unlinkProduct(ctx, id)
+	})
+}
+
+// Remove the relations of other products to the deleted one
+func (r *SQL) unlinkProduct(ctx context.Context, id string) error {
+	condition, args := r.dialect.JSONContainsAny("products.related_ids", []string{id})
+
+	rows, err := r.conn().QueryContext(ctx, r.dialect.Rebind("SELECT "+productColumns+" FROM products WHERE "+condition), args...)
+	if err != nil {
+		return fmt.Errorf("unlinking deleted product on repository %w", err)
+	}
+
+	var products []model.Product
+
+	for rows.Next() {
+		product, err := scanProduct(rows)
+		if err != nil {
+			rows.Close()
+
+			return err
+		}
+
+		products = append(products, *product)
+	}
+
+	rows.Close()
+
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	// Rows are read before writing, transactions run on a single connection
+	for _, product := range products {
+		if !product.RemoveRelationsTo(id) {
+			continue
+		}
+
+		relations, err := json.Marshal(product.Relations)
+		if err != nil {
+			return fmt.Errorf("encoding product %s relations %w", product.Name, err)
+		}
+
+		relatedIDs, err := json.Marshal(product.RelatedIDs())
+		if err != nil {
+			return fmt.Errorf("encoding product %s related ids %w", product.Name, err)
+		}
+
+		_, err = r.conn().ExecContext(
+			ctx,
+			r.dialect.Rebind("UPDATE products SET relations = ?, related_ids = ?, updated_at = ?, version = version + 1 WHERE id = ?"),
+			string(relations),
+			string(relatedIDs),
+			time.Now(),
+			product.ID,
+		)
+		if err != nil {
+			return fmt.Errorf("unlinking deleted product on repository %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Restore a deleted product, restoring a product that is not deleted returns it unchanged
+func (r *SQL) Restore(ctx context.Context, id string) (*model.Product, error) {
+	_, err := r.conn().ExecContext(
+		ctx,
+		r.dialect.Rebind("UPDATE products SET deleted_at = NULL, updated_at = ?, version = version + 1 WHERE id = ? AND deleted_at IS NOT NULL"),
+		time.Now(),
+		id,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("restoring product on repository %w", err)
+	}
+
+	return r.GetByID(ctx, id)
+}
+
+// Permanently remove the products deleted before the given time
+func (r *SQL) Purge(ctx context.Context, before time.Time) (int64, error) {
+	var purged int64
+
+	err := r.inTransaction(ctx, func(tx *SQL) error {
+		_, err := tx.conn().ExecContext(
+			ctx,
+			tx.dialect.Rebind("DELETE FROM product_skus WHERE product_id IN (SELECT id FROM products WHERE deleted_at < ?)"),
+			before.UTC(),
+		)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.conn().ExecContext(
+			ctx,
+			tx.dialect.Rebind("DELETE FROM product_slugs WHERE product_id IN (SELECT id FROM products WHERE deleted_at < ?)"),
+			before.UTC(),
+		)
+		if err != nil {
+			return err
+		}
+
+		result, err := tx.conn().ExecContext(ctx, tx.dialect.Rebind("DELETE FROM products WHERE deleted_at < ?"), before.UTC())
+		if err != nil {
+			return err
+		}
+
+		purged, err = result.RowsAffected()
+
+		return err
+	})
+	if err != nil {
+		return 0, fmt.Errorf("purging products from repository %w", err)
+	}
+
+	return purged, nil
+}
+
+// List the products with a scheduled price starting or ending at the given time or before
+func (r *SQL) ListDuePrices(ctx context.Context, at time.Time) ([]model.Product, error) {
+	query := r.dialect.Rebind(
+		fmt.Sprintf("SELECT %s FROM products WHERE price_changes_at <= ? AND deleted_at IS NULL", productColumns),
+	)
+
+	rows, err := r.conn().QueryContext(ctx, query, at.UTC())
+	if err != nil {
+		return nil, fmt.Errorf("listing due prices on repository %w", err)
+	}
+
+	defer rows.Close()
+
+	var products []model.Product
+
+	for rows.Next() {
+		product, err := scanProduct(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		products = append(products, *product)
+	}
+
+	return products, rows.Err()
+}
+
+// Update a product, it is read and written on a transaction since variants are stored together with it
+func (r *SQL) Update(ctx context.Context, update model.Update) (*model.Product, error) {
+	var product *model.Product
+
+	err := r.inTransaction(ctx, func(tx *SQL) error {
+		var err error
+
+		product, err = tx.GetByID(ctx, update.ID)
+		if err != nil {
+			return err
+		}
+
+		if err := checkUpdate(*product, update); err != nil {
+			return err
+		}
+
+		applyUpdate(product, update)
+
+		if err := tx.writeProduct(ctx, *product); err != nil {
+			return fmt.Errorf("updating product on repository %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return product, nil
+}
+
+// Replace a product, it is read and written on a transaction to check its version
+func (r *SQL) Replace(ctx context.Context, product model.Product) (*model.Product, error) {
+	err := r.inTransaction(ctx, func(tx *SQL) error {
+		stored, err := tx.GetByID(ctx, product.ID)
+		if err != nil {
+			return err
+		}
+
+		if err := checkReplace(*stored, product); err != nil {
+			return err
+		}
+
+		prepareReplace(&product)
+
+		if err := tx.writeProduct(ctx, product); err != nil {
+			return fmt.Errorf("replacing product on repository %w", err)
+		}
+
+		_, err = tx.conn().ExecContext(ctx, tx.dialect.Rebind("DELETE FROM product_slugs WHERE product_id = ?"), product.ID)
+		if err != nil {
+			return fmt.Errorf("replacing product on repository %w", err)
+		}
+
+		return tx.insertSlugs(ctx, product)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &product, nil
+}
+
+// Index the slugs the product holds, the unique slug index rejects the ones held by another product
+func (r *SQL) insertSlugs(ctx context.Context, product model.Product) error {
+	for _, slug := range product.Slugs() {
+		_, err := r.conn().ExecContext(ctx, r.dialect.Rebind("INSERT INTO product_slugs (slug, product_id) VALUES (?, ?)"), slug, product.ID)
+		if r.dialect.IsUniqueViolation(err) {
+			return internalError.ErrSlugAlreadyExist
+		}
+
+		if err != nil {
+			return fmt.Errorf("indexing product slugs on repository %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Run fn on a database transaction, it is committed only when fn returns no error
+func (r *SQL) WithTransaction(ctx context.Context, fn func(txRepo Repository) error) error {
+	// Already running on a transaction, fn joins it
+	if r.tx != nil {
+		return fn(r)
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("starting repository transaction %w", err)
+	}
+
+	// Rolls back when fn fails or panics, it is a no-op once committed
+	defer tx.Rollback()
+
+	if err := fn(&SQL{db: r.db, tx: tx, dialect: r.dialect}); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("committing repository transaction %w", err)
+	}
+
+	return nil
+}
+
+// Run fn on the current transaction, or on a new one when the repository is not bound to any
+func (r *SQL) inTransaction(ctx context.Context, fn func(tx *SQL) error) error {
+	return r.WithTransaction(ctx, func(txRepo Repository) error {
+		return fn(txRepo.(*SQL))
+	})
+}
+
+// Connection used by the queries, the transaction when the repository is bound to one
+func (r *SQL) conn() sqlConn {
+	if r.tx != nil {
+		return r.tx
+	}
+
+	return r.db
+}
+
+// Called when a versioned write matched no row, to tell a missing product from a version conflict
+func (r *SQL) getVersionConflict(ctx context.Context, id string) error {
+	product, err := r.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return &internalError.VersionConflictError{Current: product.Version}
+}
+
+// Filter a not deleted product by id, and by version when it is not 0
+func (r *SQL) getVersionFilter(id string, version int64) (string, []interface{}) {
+	if version == 0 {
+		return "id = ? AND deleted_at IS NULL", []interface{}{id}
+	}
+
+	return "id = ? AND version = ? AND deleted_at IS NULL", []interface{}{id, version}
+}
+
+// Search products
+func (r *SQL) Search(ctx context.Context, request model.SearchRequest) (*model.Page, error) {
+	eg, _ := errgroup.WithContext(ctx)
+
+	// Transactions run on a single connection, so searches on a transaction run one query at a time
+	if r.tx != nil {
+		eg.SetLimit(1)
+	}
+
+	where, args := r.getSearchFilter(request)
+
+	var products []model.Product
+
+	var more bool
+
+	var total int64
+
+	// Call concurrenlty search method
+	eg.Go(func() error {
+		prds, mr, err := r.search(ctx, where, args, request)
+		if err != nil {
+			return err
+		}
+
+		products, more = prds, mr
+
+		return nil
+	})
+
+	// Call concurrenlty get total items on db
+	eg.Go(func() error {
+		query := "SELECT COUNT(*) FROM products WHERE " + where
+
+		countArgs := args
+
+		// Products without a price on the selected currency are not counted
+		if request.Currency.Selected() {
+			price, priceArgs := r.dialect.SelectedPrice(request.Currency)
+
+			query = fmt.Sprintf("SELECT COUNT(*) FROM (SELECT %s AS selected_price FROM products WHERE %s) WHERE selected_price IS NOT NULL", price, where)
+
+			countArgs = append(append([]interface{}{}, priceArgs...), args...)
+		}
+
+		return r.conn().QueryRowContext(ctx, r.dialect.Rebind(query), countArgs...).Scan(&total)
+	})
+
+	if err := eg.Wait(); err != nil {
+		return nil, err
+	}
+
+	return newPage(products, total, request, more), nil
+}
+
+func (r *SQL) search(ctx context.Context, where string, args []interface{}, request model.SearchRequest) ([]model.Product, bool, error) {
+	descending := request.Sort == "desc"
+
+	limit, offset := request.Limit, request.Offset
+
+	cursor := request.Cursor
+
+	from, priceColumn := "products", "price"
+
+	// Products are sorted by their price on the selected currency, resolved on a subquery
+	if request.Currency.Selected() {
+		price, priceArgs := r.dialect.SelectedPrice(request.Currency)
+
+		from = fmt.Sprintf("(SELECT %s, %s AS selected_price FROM products WHERE %s)", productColumns, price, where)
+
+		priceColumn = "selected_price"
+
+		where = "selected_price IS NOT NULL"
+
+		args = append(append([]interface{}{}, priceArgs...), args...)
+	}
+
+	if cursor != nil {
+		operator := ">"
+
+		if descending != cursor.Backward {
+			operator = "<"
+		}
+
+		where = fmt.Sprintf("%s AND (%s %s ? OR (%s = ? AND id %s ?))", where, priceColumn, operator, priceColumn, operator)
+
+		args = append(append([]interface{}{}, args...), cursor.Price, cursor.Price, cursor.ID)
+
+		// Walk the sort order backwards to get the products placed before the cursor
+		if cursor.Backward {
+			descending = !descending
+		}
+
+		// Fetch an extra product to know whether there is another page
+		if limit > 0 {
+			limit++
+		}
+
+		offset = 0
+	}
+
+	direction := "ASC"
+
+	if descending {
+		direction = "DESC"
+	}
+
+	query := fmt.Sprintf(
+		"SELECT %s FROM %s WHERE %s ORDER BY %s %s, id %s %s",
+		productColumns,
+		from,
+		where,
+		priceColumn,
+		direction,
+		direction,
+		r.dialect.LimitOffset(limit, offset),
+	)
+
+	rows, err := r.conn().QueryContext(ctx, r.dialect.Rebind(query), args...)
+	if err != nil {
+		return nil, false, fmt.Errorf("searching products on repository %w", err)
+	}
+
+	defer rows.Close()
+
+	var products []model.Product
+
+	for rows.Next() {
+		product, err := scanProduct(rows)
+		if err != nil {
+			return nil, false, err
+		}
+
+		// Resolves the same price the query sorted by
+		product.SelectCurrency(request.Currency)
+
+		products = append(products, *product)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, false, err
+	}
+
+	if cursor == nil {
+		return products, false, nil
+	}
+
+	products, more := keysetPage(products, request)
+
+	return products, more, nil
+}
+
+func (r *SQL) getSearchFilter(request model.SearchRequest) (string, []interface{}) {
+	conditions := []string{"in_stock = ?"}
+
+	args := []interface{}{request.InStock}
+
+	if !request.IncludeDeleted {
+		conditions = append(conditions, "deleted_at IS NULL")
+	}
+
+	if len(request.CategoryIDs) > 0 {
+		condition, categoryArgs := r.dialect.JSONContainsAny("products.category_ids", request.CategoryIDs)
+
+		conditions = append(conditions, condition)
+
+		args = append(args, categoryArgs...)
+	}
+
+	for _, filter := range request.Attributes {
+		condition, attributeArgs := r.dialect.AttributeCondition("products.attributes", filter)
+
+		conditions = append(conditions, condition)
+
+		args = append(args, attributeArgs...)
+	}
+
+	if request.BrandID != "" {
+		conditions = append(conditions, "brand_id = ?")
+
+		args = append(args, request.BrandID)
+	}
+
+	if request.ComponentID != "" {
+		condition, componentArgs := r.dialect.JSONContainsAny("products.component_ids", []string{request.ComponentID})
+
+		conditions = append(conditions, condition)
+
+		args = append(args, componentArgs...)
+	}
+
+	for _, measure := range request.Measures {
+		operator := ">="
+
+		if measure.Operator == model.AttributeLessOrEqual {
+			operator = "<="
+		}
+
+		conditions = append(conditions, fmt.Sprintf("%s %s ?", measure.Measure, operator))
+
+		args = append(args, measure.Value)
+	}
+
+	// Matching every tag takes a condition for each of them
+	if len(request.Tags) > 0 && request.TagMatch == model.TagMatchAll {
+		for _, tag := range request.Tags {
+			condition, tagArgs := r.dialect.JSONContainsAny("products.tags", []string{tag})
+
+			conditions = append(conditions, condition)
+
+			args = append(args, tagArgs...)
+		}
+	} else if len(request.Tags) > 0 {
+		condition, tagArgs := r.dialect.JSONContainsAny("products.tags", request.Tags)
+
+		conditions = append(conditions, condition)
+
+		args = append(args, tagArgs...)
+	}
+
+	// Products stored without status are published
+	if len(request.Statuses) > 0 {
+		conditions = append(conditions, "COALESCE(status, ?) IN (?"+strings.Repeat(", ?", len(request.Statuses)-1)+")")
+
+		args = append(args, model.StatusPublished)
+
+		for _, status := range request.Statuses {
+			args = append(args, status)
+		}
+	}
+
+	if at := request.VisibleAt; at != nil {
+		conditions = append(
+			conditions,
+			"COALESCE(status, ?) = ?",
+			"(publish_at IS NULL OR publish_at <= ?)",
+			"(unpublish_at IS NULL OR unpublish_at > ?)",
+		)
+
+		args = append(args, model.StatusPublished, model.StatusPublished, at.UTC(), at.UTC())
+	}
+
+	if terms := splitWords(request.Name); len(terms) > 0 {
+		condition, textArgs := r.dialect.TextSearch(terms)
+
+		conditions = append(conditions, condition)
+
+		args = append(args, textArgs...)
+	}
+
+	return strings.Join(conditions, " AND "), args
+}
+
+func (r *SQL) get(ctx context.Context, condition string, value string) (*model.Product, error) {
+	query := r.dialect.Rebind(fmt.Sprintf("SELECT %s FROM products WHERE %s AND deleted_at IS NULL", productColumns, condition))
+
+	product, err := scanProduct(r.conn().QueryRowContext(ctx, query, value))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, internalError.ErrProductNotFound
+	}
+
+	return product, err
+}
+
+// Row or Rows
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanProduct(row scanner) (*model.Product, error) {
+	var (
+		product       model.Product
+		qty           int64
+		images        sql.NullString
+		categoryIDs   sql.NullString
+		options       sql.NullString
+		variants      sql.NullString
+		attributes    sql.NullString
+		currency      sql.NullString
+		prices        sql.NullString
+		schedule      sql.NullString
+		history       sql.NullString
+		status        sql.NullString
+		slug          sql.NullString
+		locale        sql.NullString
+		translations  sql.NullString
+		brandID       sql.NullString
+		tags          sql.NullString
+		weight        sql.NullFloat64
+		length        sql.NullFloat64
+		width         sql.NullFloat64
+		height        sql.NullFloat64
+		shippingClass sql.NullString
+		bundle        sql.NullString
+		componentIDs  sql.NullString
+		relations     sql.NullString
+		relatedIDs    sql.NullString
+		slugHistory   sql.NullString
+		slugs         sql.NullString
+	)
+
+	err := row.Scan(
+		&product.ID,
+		&product.Name,
+		&product.Description,
+		&product.Sku,
+		&qty,
+		&images,
+		&product.CreatedAt,
+		&product.UpdatedAt,
+		&product.Price,
+		&product.InStock,
+		&product.Version,
+		&product.DeletedAt,
+		&categoryIDs,
+		&options,
+		&variants,
+		&attributes,
+		&currency,
+		&prices,
+		&product.RegularPrice,
+		&schedule,
+		&history,
+		&product.PriceChangesAt,
+		&status,
+		&product.PublishAt,
+		&product.UnpublishAt,
+		&slug,
+		&locale,
+		&translations,
+		&brandID,
+		&tags,
+		&weight,
+		&length,
+		&width,
+		&height,
+		&shippingClass,
+		&bundle,
+		&componentIDs,
+		&relations,
+		&relatedIDs,
+		&slugHistory,
+		&slugs,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+
+		return nil, fmt.Errorf("decoding product from repository %w", err)
+	}
+
+	product.Qty = uint64(qty)
+
+	if images.Valid {
+		if err := json.Unmarshal([]byte(images.String), &product.Images); err != nil {
+			return nil, fmt.Errorf("decoding product images from repository %w", err)
+		}
+	}
+
+	if categoryIDs.Valid {
+		if err := json.Unmarshal([]byte(categoryIDs.String), &product.CategoryIDs); err != nil {
+			return nil, fmt.Errorf("decoding product category ids from repository %w", err)
+		}
+	}
+
+	if options.Valid {
+		if err := json.Unmarshal([]byte(options.String), &product.Options); err != nil {
+			return nil, fmt.Errorf("decoding product options from repository %w", err)
+		}
+	}
+
+	if variants.Valid {
+		if err := json.Unmarshal([]byte(variants.String), &product.Variants); err != nil {
+			return nil, fmt.Errorf("decoding product variants from repository %w", err)
+		}
+	}
+
+	if attributes.Valid {
+		if err := json.Unmarshal([]byte(attributes.String), &product.Attributes); err != nil {
+			return nil, fmt.Errorf("decoding product attributes from repository %w", err)
+		}
+	}
+
+	product.Currency = currency.String
+
+	product.Status = model.Status(status.String)
+
+	product.Slug, product.Locale = slug.String, locale.String
+
+	if translations.Valid {
+		if err := json.Unmarshal([]byte(translations.String), &product.Translations); err != nil {
+			return nil, fmt.Errorf("decoding product translations from repository %w", err)
+		}
+	}
+
+	product.BrandID = brandID.String
+
+	if tags.Valid {
+		if err := json.Unmarshal([]byte(tags.String), &product.Tags); err != nil {
+			return nil, fmt.Errorf("decoding product tags from repository %w", err)
+		}
+	}
+
+	if weight.Valid {
+		product.Weight = &model.Weight{Value: weight.Float64, Unit: model.Kilogram}
+	}
+
+	if length.Valid {
+		product.Dimensions = &model.Dimensions{Length: length.Float64, Width: width.Float64, Height: height.Float64, Unit: model.Centimeter}
+	}
+
+	product.ShippingClass = model.ShippingClass(shippingClass.String)
+
+	if bundle.Valid {
+		if err := json.Unmarshal([]byte(bundle.String), &product.Bundle); err != nil {
+			return nil, fmt.Errorf("decoding product bundle from repository %w", err)
+		}
+	}
+
+	if relations.Valid {
+		if err := json.Unmarshal([]byte(relations.String), &product.Relations); err != nil {
+			return nil, fmt.Errorf("decoding product relations from repository %w", err)
+		}
+	}
+
+	if slugHistory.Valid {
+		if err := json.Unmarshal([]byte(slugHistory.String), &product.SlugHistory); err != nil {
+			return nil, fmt.Errorf("decoding product slug history from repository %w", err)
+		}
+	}
+
+	if prices.Valid {
+		if err := json.Unmarshal([]byte(prices.String), &product.Prices); err != nil {
+			return nil, fmt.Errorf("decoding product prices from repository %w", err)
+		}
+	}
+
+	if schedule.Valid {
+		if err := json.Unmarshal([]byte(schedule.String), &product.PriceSchedule); err != nil {
+			return nil, fmt.Errorf("decoding product price schedule from repository %w", err)
+		}
+	}
+
+	if history.Valid {
+		if err := json.Unmarshal([]byte(history.String), &product.PriceHistory); err != nil {
+			return nil, fmt.Errorf("decoding product price history from repository %w", err)
+		}
+	}
+
+	// The price range is not stored, it is derived again from the variants
+	product.ApplyVariants()
+
+	return &product, nil
+}
+
+// Times are compared as stored, so they are always written on UTC
+func utcTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+
+	utc := t.UTC()
+
+	return &utc
+}
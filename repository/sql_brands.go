@@ -0,0 +1,185 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	internalError "github.com/srodrmendz/api-product-catalog/errors"
+	"github.com/srodrmendz/api-product-catalog/model"
+)
+
+const brandColumns = "id, name, description, created_at, updated_at"
+
+// Count how many of the products matching the search filters have each tag
+func (r *SQL) CountTags(ctx context.Context, request model.SearchRequest) ([]model.TagCount, error) {
+	where, args := r.getSearchFilter(request)
+
+	query := "SELECT tags FROM products WHERE " + where
+
+	// Products without a price on the selected currency are not counted
+	if request.Currency.Selected() {
+		price, priceArgs := r.dialect.SelectedPrice(request.Currency)
+
+		query = fmt.Sprintf("SELECT tags FROM (SELECT tags, %s AS selected_price FROM products WHERE %s) WHERE selected_price IS NOT NULL", price, where)
+
+		args = append(append([]interface{}{}, priceArgs...), args...)
+	}
+
+	rows, err := r.conn().QueryContext(ctx, r.dialect.Rebind(query), args...)
+	if err != nil {
+		return nil, fmt.Errorf("counting product tags on repository %w", err)
+	}
+
+	defer rows.Close()
+
+	var products []model.Product
+
+	for rows.Next() {
+		var tags sql.NullString
+
+		if err := rows.Scan(&tags); err != nil {
+			return nil, fmt.Errorf("decoding product tags from repository %w", err)
+		}
+
+		var product model.Product
+
+		if tags.Valid {
+			if err := json.Unmarshal([]byte(tags.String), &product.Tags); err != nil {
+				return nil, fmt.Errorf("decoding product tags from repository %w", err)
+			}
+		}
+
+		products = append(products, product)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return countTags(products), nil
+}
+
+// Create a new brand
+func (r *SQL) CreateBrand(ctx context.Context, brand model.Brand) (*model.Brand, error) {
+	brand.ID = uuid.NewString()
+
+	now := time.Now()
+
+	brand.CreatedAt = now
+
+	brand.UpdatedAt = now
+
+	brand = copyBrand(brand)
+
+	_, err := r.conn().ExecContext(
+		ctx,
+		r.dialect.Rebind("INSERT INTO brands ("+brandColumns+") VALUES (?, ?, ?, ?, ?)"),
+		brand.ID,
+		brand.Name,
+		brand.Description,
+		brand.CreatedAt,
+		brand.UpdatedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("creating brand %s on repository %w", brand.Name, err)
+	}
+
+	return &brand, nil
+}
+
+// Get a brand by id
+func (r *SQL) GetBrand(ctx context.Context, id string) (*model.Brand, error) {
+	query := r.dialect.Rebind("SELECT " + brandColumns + " FROM brands WHERE id = ?")
+
+	brand, err := scanBrand(r.conn().QueryRowContext(ctx, query, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, internalError.ErrBrandNotFound
+	}
+
+	return brand, err
+}
+
+// List every brand sorted by name
+func (r *SQL) ListBrands(ctx context.Context) ([]model.Brand, error) {
+	rows, err := r.conn().QueryContext(ctx, "SELECT "+brandColumns+" FROM brands ORDER BY name, id")
+	if err != nil {
+		return nil, fmt.Errorf("listing brands on repository %w", err)
+	}
+
+	defer rows.Close()
+
+	brands := []model.Brand{}
+
+	for rows.Next() {
+		brand, err := scanBrand(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		brands = append(brands, *brand)
+	}
+
+	return brands, rows.Err()
+}
+
+// Update a brand name and description
+func (r *SQL) UpdateBrand(ctx context.Context, brand model.Brand) (*model.Brand, error) {
+	result, err := r.conn().ExecContext(
+		ctx,
+		r.dialect.Rebind("UPDATE brands SET name = ?, description = ?, updated_at = ? WHERE id = ?"),
+		brand.Name,
+		brand.Description,
+		time.Now(),
+		brand.ID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("updating brand on repository %w", err)
+	}
+
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return nil, internalError.ErrBrandNotFound
+	}
+
+	return r.GetBrand(ctx, brand.ID)
+}
+
+// Delete a brand
+func (r *SQL) DeleteBrand(ctx context.Context, id string) error {
+	result, err := r.conn().ExecContext(ctx, r.dialect.Rebind("DELETE FROM brands WHERE id = ?"), id)
+	if err != nil {
+		return fmt.Errorf("deleting brand from repository %w", err)
+	}
+
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return internalError.ErrBrandNotFound
+	}
+
+	return nil
+}
+
+func scanBrand(row scanner) (*model.Brand, error) {
+	var (
+		brand       model.Brand
+		description sql.NullString
+	)
+
+	err := row.Scan(&brand.ID, &brand.Name, &description, &brand.CreatedAt, &brand.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+
+		return nil, fmt.Errorf("decoding brand from repository %w", err)
+	}
+
+	if description.Valid {
+		brand.Description = &description.String
+	}
+
+	return &brand, nil
+}
@@ -0,0 +1,284 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	internalError "github.com/srodrmendz/api-product-catalog/errors"
+	"github.com/srodrmendz/api-product-catalog/model"
+)
+
+const categoryColumns = "id, name, parent_id, path, created_at, updated_at, attributes"
+
+// Create a new category
+func (r *SQL) CreateCategory(ctx context.Context, category model.Category) (*model.Category, error) {
+	category.ID = uuid.NewString()
+
+	now := time.Now()
+
+	category.CreatedAt = now
+
+	category.UpdatedAt = now
+
+	category = copyCategory(category)
+
+	path, err := json.Marshal(category.Path)
+	if err != nil {
+		return nil, fmt.Errorf("encoding category %s path %w", category.Name, err)
+	}
+
+	attributes, err := json.Marshal(category.Attributes)
+	if err != nil {
+		return nil, fmt.Errorf("encoding category %s attributes %w", category.Name, err)
+	}
+
+	_, err = r.conn().ExecContext(
+		ctx,
+		r.dialect.Rebind("INSERT INTO categories ("+categoryColumns+") VALUES (?, ?, ?, ?, ?, ?, ?)"),
+		category.ID,
+		category.Name,
+		nullString(category.ParentID),
+		string(path),
+		category.CreatedAt,
+		category.UpdatedAt,
+		string(attributes),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("creating category %s on repository %w", category.Name, err)
+	}
+
+	return &category, nil
+}
+
+// Get a category by id
+func (r *SQL) GetCategory(ctx context.Context, id string) (*model.Category, error) {
+	query := r.dialect.Rebind("SELECT " + categoryColumns + " FROM categories WHERE id = ?")
+
+	category, err := scanCategory(r.conn().QueryRowContext(ctx, query, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, internalError.ErrCategoryNotFound
+	}
+
+	return category, err
+}
+
+// List every category sorted by name
+func (r *SQL) ListCategories(ctx context.Context) ([]model.Category, error) {
+	return r.findCategories(ctx, "1 = 1", nil)
+}
+
+// Get a category and all its descendants
+func (r *SQL) GetCategorySubtree(ctx context.Context, id string) ([]model.Category, error) {
+	condition, args := r.dialect.JSONContainsAny("categories.path", []string{id})
+
+	categories, err := r.findCategories(ctx, "id = ? OR "+condition, append([]interface{}{id}, args...))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(categories) == 0 {
+		return nil, internalError.ErrCategoryNotFound
+	}
+
+	return categories, nil
+}
+
+// Update a category, the paths of its descendants are updated to follow it
+func (r *SQL) UpdateCategory(ctx context.Context, category model.Category) (*model.Category, error) {
+	category = copyCategory(category)
+
+	path, err := json.Marshal(category.Path)
+	if err != nil {
+		return nil, fmt.Errorf("encoding category %s path %w", category.Name, err)
+	}
+
+	attributes, err := json.Marshal(category.Attributes)
+	if err != nil {
+		return nil, fmt.Errorf("encoding category %s attributes %w", category.Name, err)
+	}
+
+	result, err := r.conn().ExecContext(
+		ctx,
+		r.dialect.Rebind("UPDATE categories SET name = ?, parent_id = ?, path = ?, attributes = ?, updated_at = ? WHERE id = ?"),
+		category.Name,
+		nullString(category.ParentID),
+		string(path),
+		string(attributes),
+		time.Now(),
+		category.ID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("updating category on repository %w", err)
+	}
+
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return nil, internalError.ErrCategoryNotFound
+	}
+
+	stored, err := r.GetCategory(ctx, category.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	condition, args := r.dialect.JSONContainsAny("categories.path", []string{stored.ID})
+
+	descendants, err := r.findCategories(ctx, condition, args)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, descendant := range descendants {
+		path, err := json.Marshal(movedPath(descendant.Path, *stored))
+		if err != nil {
+			return nil, fmt.Errorf("encoding category %s path %w", descendant.Name, err)
+		}
+
+		_, err = r.conn().ExecContext(ctx, r.dialect.Rebind("UPDATE categories SET path = ? WHERE id = ?"), string(path), descendant.ID)
+		if err != nil {
+			return nil, fmt.Errorf("updating category %s path on repository %w", descendant.ID, err)
+		}
+	}
+
+	return stored, nil
+}
+
+// Delete a category
+func (r *SQL) DeleteCategory(ctx context.Context, id string) error {
+	result, err := r.conn().ExecContext(ctx, r.dialect.Rebind("DELETE FROM categories WHERE id = ?"), id)
+	if err != nil {
+		return fmt.Errorf("deleting category from repository %w", err)
+	}
+
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return internalError.ErrCategoryNotFound
+	}
+
+	return nil
+}
+
+// Replace category from by to on every product, an empty to removes it
+func (r *SQL) ReplaceProductCategory(ctx context.Context, from string, to string) (int64, error) {
+	condition, args := r.dialect.JSONContainsAny("products.category_ids", []string{from})
+
+	query := r.dialect.Rebind("SELECT " + productColumns + " FROM products WHERE " + condition)
+
+	rows, err := r.conn().QueryContext(ctx, query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("replacing product category on repository %w", err)
+	}
+
+	var products []model.Product
+
+	for rows.Next() {
+		product, err := scanProduct(rows)
+		if err != nil {
+			rows.Close()
+
+			return 0, err
+		}
+
+		products = append(products, *product)
+	}
+
+	rows.Close()
+
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	// Rows are read before writing, transactions run on a single connection
+	for _, product := range products {
+		categoryIDs, _ := replaceCategory(product.CategoryIDs, from, to)
+
+		data, err := json.Marshal(categoryIDs)
+		if err != nil {
+			return 0, fmt.Errorf("encoding product %s category ids %w", product.Name, err)
+		}
+
+		_, err = r.conn().ExecContext(
+			ctx,
+			r.dialect.Rebind("UPDATE products SET category_ids = ?, updated_at = ?, version = version + 1 WHERE id = ?"),
+			string(data),
+			time.Now(),
+			product.ID,
+		)
+		if err != nil {
+			return 0, fmt.Errorf("replacing product category on repository %w", err)
+		}
+	}
+
+	return int64(len(products)), nil
+}
+
+func (r *SQL) findCategories(ctx context.Context, where string, args []interface{}) ([]model.Category, error) {
+	query := r.dialect.Rebind("SELECT " + categoryColumns + " FROM categories WHERE " + where + " ORDER BY name, id")
+
+	rows, err := r.conn().QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("finding categories on repository %w", err)
+	}
+
+	defer rows.Close()
+
+	categories := []model.Category{}
+
+	for rows.Next() {
+		category, err := scanCategory(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		categories = append(categories, *category)
+	}
+
+	return categories, rows.Err()
+}
+
+func scanCategory(row scanner) (*model.Category, error) {
+	var (
+		category   model.Category
+		parentID   sql.NullString
+		path       string
+		attributes sql.NullString
+	)
+
+	err := row.Scan(&category.ID, &category.Name, &parentID, &path, &category.CreatedAt, &category.UpdatedAt, &attributes)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+
+		return nil, fmt.Errorf("decoding category from repository %w", err)
+	}
+
+	category.ParentID = parentID.String
+
+	if err := json.Unmarshal([]byte(path), &category.Path); err != nil {
+		return nil, fmt.Errorf("decoding category path from repository %w", err)
+	}
+
+	if attributes.Valid {
+		if err := json.Unmarshal([]byte(attributes.String), &category.Attributes); err != nil {
+			return nil, fmt.Errorf("decoding category attributes from repository %w", err)
+		}
+	}
+
+	category = copyCategory(category)
+
+	return &category, nil
+}
+
+// Store empty strings as NULL
+func nullString(value string) sql.NullString {
+	return sql.NullString{String: value, Valid: value != ""}
+}
+
+// Store missing measures as NULL
+func nullMeasure(value float64, ok bool) sql.NullFloat64 {
+	return sql.NullFloat64{Float64: value, Valid: ok}
+}
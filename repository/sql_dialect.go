@@ -0,0 +1,508 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/srodrmendz/api-product-catalog/model"
+	// Registers the pure Go sqlite driver, so the service builds without cgo
+	_ "modernc.org/sqlite"
+)
+
+// Dialect hides the differences between the SQL databases supported by the SQL repository
+type Dialect interface {
+	// Name of the database/sql driver
+	Driver() string
+
+	// Rewrite a query written with ? placeholders to the database placeholder syntax
+	Rebind(query string) string
+
+	// Reports whether err is a unique constraint violation
+	IsUniqueViolation(err error) bool
+
+	// Condition that matches products whose name or description contains any of terms, with its arguments
+	TextSearch(terms []string) (string, []interface{})
+
+	// Condition that matches rows whose JSON array column holds any of values, with its arguments
+	JSONContainsAny(column string, values []string) (string, []interface{})
+
+	// Condition that matches rows whose JSON array column is null or holds no elements
+	JSONEmpty(column string) string
+
+	// Condition that matches rows whose JSON array column of variants holds the variant sku with a qty covering delta, with its arguments
+	VariantStock(column string, sku string, delta int64) (string, []interface{})
+
+	// Expression resolving the JSON array column of variants with delta added to the qty of the variant sku
+	// and its in_stock derived from it, with its arguments
+	AdjustVariant(column string, sku string, delta int64) (string, []interface{})
+
+	// Condition that matches rows whose JSON object column holds an attribute matching filter, with its arguments
+	// Equality filters match the value written as string, number or bool, range filters only match numbers
+	AttributeCondition(column string, filter model.AttributeFilter) (string, []interface{})
+
+	// Expression resolving the product price on the selected currency the same way Product.SelectCurrency does,
+	// null when it has none, with its arguments
+	SelectedPrice(selection model.CurrencySelection) (string, []interface{})
+
+	// Limit and offset clause, a zero limit returns every remaining row
+	LimitOffset(limit int, offset int) string
+
+	// Schema migrations ordered by version
+	Migrations() []Migration
+}
+
+// SQLite dialect, uses an fts5 table to search product names and descriptions
+// The fts5 rows are keyed on products_fts_keys, products rowids are not stable since its primary key is not an INTEGER one
+var SQLite Dialect = sqliteDialect{}
+
+// Open a SQLite data file ready to be used by the SQL repository
+func OpenSQLite(path string) (*sql.DB, error) {
+	db, err := sql.Open(SQLite.Driver(), fmt.Sprintf("file:%s?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)", path))
+	if err != nil {
+		return nil, fmt.Errorf("opening sqlite data file %s %w", path, err)
+	}
+
+	// SQLite allows a single writer, sharing one connection avoids busy errors under concurrent writes
+	db.SetMaxOpenConns(1)
+
+	return db, nil
+}
+
+type sqliteDialect struct{}
+
+// Names and descriptions of the translations on column, as indexed text
+func sqliteTranslationsText(column string) string {
+	return `SELECT group_concat(COALESCE(json_extract(value, '$.name'), '') || ' ' || COALESCE(json_extract(value, '$.description'), ''), ' ')
+	FROM json_each(` + column + `)`
+}
+
+func (sqliteDialect) Driver() string {
+	return "sqlite"
+}
+
+func (sqliteDialect) Rebind(query string) string {
+	return query
+}
+
+// Every SQLite driver reports the message of the database engine, so it is checked instead of driver error types
+func (sqliteDialect) IsUniqueViolation(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "UNIQUE constraint failed")
+}
+
+func (sqliteDialect) TextSearch(terms []string) (string, []interface{}) {
+	quoted := make([]string, 0, len(terms))
+
+	for _, term := range terms {
+		quoted = append(quoted, fmt.Sprintf(`"%s"`, term))
+	}
+
+	return "products.id IN (SELECT product_id FROM products_fts_keys WHERE seq IN (SELECT rowid FROM products_fts WHERE products_fts MATCH ?))",
+		[]interface{}{strings.Join(quoted, " OR ")}
+}
+
+func (sqliteDialect) JSONContainsAny(column string, values []string) (string, []interface{}) {
+	args := make([]interface{}, 0, len(values))
+
+	for _, value := range values {
+		args = append(args, value)
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(values)), ", ")
+
+	return fmt.Sprintf("EXISTS (SELECT 1 FROM json_each(%s) WHERE json_each.value IN (%s))", column, placeholders), args
+}
+
+// json_array_length reports 0 for the null the nil slices are encoded to
+func (sqliteDialect) JSONEmpty(column string) string {
+	return fmt.Sprintf("COALESCE(json_array_length(%s), 0) = 0", column)
+}
+
+// Stored qtys are at most MaxQty, so adding a negative delta to them never overflows
+func (sqliteDialect) VariantStock(column string, sku string, delta int64) (string, []interface{}) {
+	return fmt.Sprintf(
+			"EXISTS (SELECT 1 FROM json_each(%s) WHERE json_extract(json_each.value, '$.sku') = ? AND json_extract(json_each.value, '$.qty') + ? >= 0)",
+			column),
+		[]interface{}{sku, delta}
+}
+
+// json_group_array keeps the order json_each reads the variants in
+func (sqliteDialect) AdjustVariant(column string, sku string, delta int64) (string, []interface{}) {
+	qty := "json_extract(json_each.value, '$.qty') + ?"
+
+	adjusted := fmt.Sprintf(
+		"json_set(json_each.value, '$.qty', %[1]s, '$.in_stock', json(CASE WHEN %[1]s > 0 THEN 'true' ELSE 'false' END))",
+		qty)
+
+	return fmt.Sprintf(
+			"(SELECT json_group_array(CASE WHEN json_extract(json_each.value, '$.sku') = ? THEN %s ELSE json(json_each.value) END) FROM json_each(%s))",
+			adjusted,
+			column),
+		[]interface{}{sku, delta, delta}
+}
+
+// Attribute names are validated by the model, the JSON path is passed as an argument anyway
+func (sqliteDialect) AttributeCondition(column string, filter model.AttributeFilter) (string, []interface{}) {
+	path := "$." + filter.Name
+
+	number := fmt.Sprintf("(json_type(%[1]s, ?) IN ('integer', 'real') AND json_extract(%[1]s, ?) %%s ?)", column)
+
+	switch filter.Operator {
+	case model.AttributeGreaterOrEqual:
+		return fmt.Sprintf(number, ">="), []interface{}{path, path, filter.Number()}
+	case model.AttributeLessOrEqual:
+		return fmt.Sprintf(number, "<="), []interface{}{path, path, filter.Number()}
+	}
+
+	// SQLite reads JSON booleans as integers, so values are matched by their JSON type
+	conditions := []string{fmt.Sprintf("(json_type(%[1]s, ?) = 'text' AND json_extract(%[1]s, ?) = ?)", column)}
+
+	args := []interface{}{path, path, filter.Value}
+
+	for _, value := range attributeValues(filter)[1:] {
+		switch value := value.(type) {
+		case float64:
+			conditions = append(conditions, fmt.Sprintf(number, "="))
+
+			args = append(args, path, path, value)
+		case bool:
+			conditions = append(conditions, fmt.Sprintf("json_type(%s, ?) = ?", column))
+
+			args = append(args, path, strconv.FormatBool(value))
+		}
+	}
+
+	return "(" + strings.Join(conditions, " OR ") + ")", args
+}
+
+// Converted prices are rounded half up like model.Convert, prices are positive so the cast truncation rounds down
+func (sqliteDialect) SelectedPrice(selection model.CurrencySelection) (string, []interface{}) {
+	currency := "COALESCE(NULLIF(products.currency, ''), ?)"
+
+	listed := "(SELECT json_extract(json_each.value, '$.amount') FROM json_each(products.prices) " +
+		"WHERE json_type(json_each.value) = 'object' AND json_extract(json_each.value, '$.currency') = ?)"
+
+	args := []interface{}{selection.Default, selection.Code, selection.Code}
+
+	converted := "NULL"
+
+	if len(selection.Factors) > 0 {
+		currencies := make([]string, 0, len(selection.Factors))
+
+		for from := range selection.Factors {
+			currencies = append(currencies, from)
+		}
+
+		sort.Strings(currencies)
+
+		branches := make([]string, 0, len(currencies))
+
+		args = append(args, selection.Default)
+
+		for _, from := range currencies {
+			branches = append(branches, "WHEN ? THEN CAST(products.price * ? + 0.5 AS INTEGER)")
+
+			args = append(args, from, selection.Factors[from])
+		}
+
+		converted = fmt.Sprintf("CASE %s %s END", currency, strings.Join(branches, " "))
+	}
+
+	return fmt.Sprintf("CASE WHEN %s = ? THEN products.price ELSE COALESCE(%s, %s) END", currency, listed, converted), args
+}
+
+func (sqliteDialect) LimitOffset(limit int, offset int) string {
+	if limit == 0 {
+		limit = -1
+	}
+
+	return fmt.Sprintf("LIMIT %d OFFSET %d", limit, offset)
+}
+
+func (sqliteDialect) Migrations() []Migration {
+	return []Migration{
+		{
+			Version:     1,
+			Description: "create products table",
+			Statements: []string{
+				`CREATE TABLE products (
+					id TEXT PRIMARY KEY,
+					name TEXT NOT NULL,
+					description TEXT,
+					sku TEXT NOT NULL,
+					qty INTEGER NOT NULL,
+					images TEXT,
+					created_at TIMESTAMP NOT NULL,
+					updated_at TIMESTAMP NOT NULL,
+					price INTEGER NOT NULL,
+					in_stock BOOLEAN NOT NULL
+				)`,
+				`CREATE UNIQUE INDEX products_sku ON products (sku)`,
+				`CREATE INDEX products_in_stock_price ON products (in_stock, price, id)`,
+			},
+		},
+		{
+			Version:     2,
+			Description: "create products name full text index",
+			Statements: []string{
+				`CREATE VIRTUAL TABLE products_fts USING fts5 (name, tokenize='unicode61')`,
+				`CREATE TRIGGER products_fts_insert AFTER INSERT ON products BEGIN
+					INSERT INTO products_fts (rowid, name) VALUES (new.rowid, new.name);
+				END`,
+				`CREATE TRIGGER products_fts_update AFTER UPDATE OF name ON products BEGIN
+					UPDATE products_fts SET name = new.name WHERE rowid = old.rowid;
+				END`,
+				`CREATE TRIGGER products_fts_delete AFTER DELETE ON products BEGIN
+					DELETE FROM products_fts WHERE rowid = old.rowid;
+				END`,
+			},
+		},
+		{
+			Version:     3,
+			Description: "add description to products full text index",
+			Statements: []string{
+				`DROP TRIGGER products_fts_insert`,
+				`DROP TRIGGER products_fts_update`,
+				`DROP TRIGGER products_fts_delete`,
+				`DROP TABLE products_fts`,
+				`CREATE VIRTUAL TABLE products_fts USING fts5 (name, description, tokenize='unicode61')`,
+				`INSERT INTO products_fts (rowid, name, description) SELECT rowid, name, description FROM products`,
+				`CREATE TRIGGER products_fts_insert AFTER INSERT ON products BEGIN
+					INSERT INTO products_fts (rowid, name, description) VALUES (new.rowid, new.name, new.description);
+				END`,
+				`CREATE TRIGGER products_fts_update AFTER UPDATE OF name, description ON products BEGIN
+					UPDATE products_fts SET name = new.name, description = new.description WHERE rowid = old.rowid;
+				END`,
+				`CREATE TRIGGER products_fts_delete AFTER DELETE ON products BEGIN
+					DELETE FROM products_fts WHERE rowid = old.rowid;
+				END`,
+			},
+		},
+		{
+			Version:     4,
+			Description: "add products version",
+			Statements: []string{
+				`ALTER TABLE products ADD COLUMN version INTEGER NOT NULL DEFAULT 0`,
+			},
+		},
+		{
+			Version:     5,
+			Description: "add products soft delete",
+			Statements: []string{
+				`ALTER TABLE products ADD COLUMN deleted_at TIMESTAMP`,
+				`CREATE INDEX products_deleted_at ON products (deleted_at)`,
+			},
+		},
+		{
+			Version:     6,
+			Description: "create categories table and add products categories",
+			Statements: []string{
+				`CREATE TABLE categories (
+					id TEXT PRIMARY KEY,
+					name TEXT NOT NULL,
+					parent_id TEXT,
+					path TEXT NOT NULL,
+					created_at TIMESTAMP NOT NULL,
+					updated_at TIMESTAMP NOT NULL
+				)`,
+				`CREATE INDEX categories_parent_id ON categories (parent_id)`,
+				`ALTER TABLE products ADD COLUMN category_ids TEXT`,
+			},
+		},
+		{
+			Version:     7,
+			Description: "add products variants and index every product and variant sku",
+			Statements: []string{
+				`ALTER TABLE products ADD COLUMN options TEXT`,
+				`ALTER TABLE products ADD COLUMN variants TEXT`,
+				`CREATE TABLE product_skus (
+					sku TEXT PRIMARY KEY,
+					product_id TEXT NOT NULL
+				)`,
+				`CREATE INDEX product_skus_product_id ON product_skus (product_id)`,
+				`INSERT INTO product_skus (sku, product_id) SELECT sku, id FROM products`,
+			},
+		},
+		{
+			Version:     8,
+			Description: "add products attributes and categories attribute definitions",
+			Statements: []string{
+				`ALTER TABLE products ADD COLUMN attributes TEXT`,
+				`ALTER TABLE categories ADD COLUMN attributes TEXT`,
+			},
+		},
+		{
+			Version:     9,
+			Description: "add products currency and price list",
+			Statements: []string{
+				`ALTER TABLE products ADD COLUMN currency TEXT`,
+				`ALTER TABLE products ADD COLUMN prices TEXT`,
+			},
+		},
+		{
+			Version:     10,
+			Description: "add products price schedule and history",
+			Statements: []string{
+				`ALTER TABLE products ADD COLUMN regular_price INTEGER NOT NULL DEFAULT 0`,
+				`ALTER TABLE products ADD COLUMN price_schedule TEXT`,
+				`ALTER TABLE products ADD COLUMN price_history TEXT`,
+				`ALTER TABLE products ADD COLUMN price_changes_at TIMESTAMP`,
+				`CREATE INDEX products_price_changes_at ON products (price_changes_at)`,
+			},
+		},
+		{
+			Version:     11,
+			Description: "add products publication status",
+			Statements: []string{
+				`ALTER TABLE products ADD COLUMN status TEXT`,
+				`ALTER TABLE products ADD COLUMN publish_at TIMESTAMP`,
+				`ALTER TABLE products ADD COLUMN unpublish_at TIMESTAMP`,
+			},
+		},
+		{
+			Version:     12,
+			Description: "add products localized content",
+			Statements: []string{
+				`ALTER TABLE products ADD COLUMN slug TEXT`,
+				`ALTER TABLE products ADD COLUMN locale TEXT`,
+				`ALTER TABLE products ADD COLUMN translations TEXT`,
+				`DROP TRIGGER products_fts_insert`,
+				`DROP TRIGGER products_fts_update`,
+				`DROP TRIGGER products_fts_delete`,
+				`DROP TABLE products_fts`,
+				`CREATE VIRTUAL TABLE products_fts USING fts5 (name, description, translations, tokenize='unicode61')`,
+				`INSERT INTO products_fts (rowid, name, description) SELECT rowid, name, description FROM products`,
+				// Translations are indexed by their names and descriptions, so the JSON keys are not matched
+				`CREATE TRIGGER products_fts_insert AFTER INSERT ON products BEGIN
+					INSERT INTO products_fts (rowid, name, description, translations) VALUES (new.rowid, new.name, new.description, (` + sqliteTranslationsText("new.translations") + `));
+				END`,
+				`CREATE TRIGGER products_fts_update AFTER UPDATE OF name, description, translations ON products BEGIN
+					UPDATE products_fts SET name = new.name, description = new.description, translations = (` + sqliteTranslationsText("new.translations") + `) WHERE rowid = old.rowid;
+				END`,
+				`CREATE TRIGGER products_fts_delete AFTER DELETE ON products BEGIN
+					DELETE FROM products_fts WHERE rowid = old.rowid;
+				END`,
+			},
+		},
+		{
+			Version:     13,
+			Description: "add brands and products brand and tags",
+			Statements: []string{
+				`CREATE TABLE brands (
+					id TEXT PRIMARY KEY,
+					name TEXT NOT NULL,
+					description TEXT,
+					created_at TIMESTAMP NOT NULL,
+					updated_at TIMESTAMP NOT NULL
+				)`,
+				`ALTER TABLE products ADD COLUMN brand_id TEXT`,
+				`ALTER TABLE products ADD COLUMN tags TEXT`,
+				`CREATE INDEX products_brand_id ON products (brand_id)`,
+			},
+		},
+		{
+			Version:     14,
+			Description: "add products weight, dimensions and shipping class",
+			Statements: []string{
+				`ALTER TABLE products ADD COLUMN weight REAL`,
+				`ALTER TABLE products ADD COLUMN length REAL`,
+				`ALTER TABLE products ADD COLUMN width REAL`,
+				`ALTER TABLE products ADD COLUMN height REAL`,
+				`ALTER TABLE products ADD COLUMN shipping_class TEXT`,
+			},
+		},
+		{
+			Version:     15,
+			Description: "add products bundle and its component ids",
+			Statements: []string{
+				`ALTER TABLE products ADD COLUMN bundle TEXT`,
+				`ALTER TABLE products ADD COLUMN component_ids TEXT`,
+			},
+		},
+		{
+			Version:     16,
+			Description: "add products relations and their related ids",
+			Statements: []string{
+				`ALTER TABLE products ADD COLUMN relations TEXT`,
+				`ALTER TABLE products ADD COLUMN related_ids TEXT`,
+			},
+		},
+		{
+			Version:     17,
+			Description: "add products slug history and every slug they hold",
+			Statements: []string{
+				`ALTER TABLE products ADD COLUMN slug_history TEXT`,
+				`ALTER TABLE products ADD COLUMN slugs TEXT`,
+			},
+		},
+		{
+			Version:     18,
+			Description: "create stock movements table",
+			Statements: []string{
+				`CREATE TABLE stock_movements (
+					seq INTEGER PRIMARY KEY AUTOINCREMENT,
+					id TEXT NOT NULL UNIQUE,
+					product_id TEXT NOT NULL,
+					variant_sku TEXT NOT NULL,
+					type TEXT NOT NULL,
+					delta INTEGER NOT NULL,
+					qty INTEGER NOT NULL,
+					reason TEXT NOT NULL,
+					reference TEXT NOT NULL,
+					actor TEXT NOT NULL,
+					created_at TIMESTAMP NOT NULL
+				)`,
+				`CREATE INDEX stock_movements_product_id ON stock_movements (product_id, created_at)`,
+			},
+		},
+		{
+			Version:     19,
+			Description: "index every slug products hold as unique",
+			Statements: []string{
+				`CREATE TABLE product_slugs (
+					slug TEXT PRIMARY KEY,
+					product_id TEXT NOT NULL
+				)`,
+				`CREATE INDEX product_slugs_product_id ON product_slugs (product_id)`,
+				// Slugs assigned twice before the index existed stay with the first product found holding them
+				`INSERT OR IGNORE INTO product_slugs (slug, product_id)
+					SELECT json_each.value, products.id FROM products, json_each(products.slugs)
+					WHERE json_type(products.slugs) = 'array'`,
+			},
+		},
+		{
+			Version:     20,
+			Description: "key products full text index on a stable key",
+			Statements: []string{
+				`CREATE TABLE products_fts_keys (
+					seq INTEGER PRIMARY KEY,
+					product_id TEXT NOT NULL UNIQUE
+				)`,
+				`INSERT INTO products_fts_keys (product_id) SELECT id FROM products`,
+				`DROP TRIGGER products_fts_insert`,
+				`DROP TRIGGER products_fts_update`,
+				`DROP TRIGGER products_fts_delete`,
+				`DROP TABLE products_fts`,
+				`CREATE VIRTUAL TABLE products_fts USING fts5 (name, description, translations, tokenize='unicode61')`,
+				`INSERT INTO products_fts (rowid, name, description, translations)
+					SELECT products_fts_keys.seq, products.name, products.description, (` + sqliteTranslationsText("products.translations") + `)
+					FROM products JOIN products_fts_keys ON products_fts_keys.product_id = products.id`,
+				`CREATE TRIGGER products_fts_insert AFTER INSERT ON products BEGIN
+					INSERT INTO products_fts_keys (product_id) VALUES (new.id);
+					INSERT INTO products_fts (rowid, name, description, translations)
+						VALUES ((SELECT seq FROM products_fts_keys WHERE product_id = new.id), new.name, new.description, (` + sqliteTranslationsText("new.translations") + `));
+				END`,
+				`CREATE TRIGGER products_fts_update AFTER UPDATE OF name, description, translations ON products BEGIN
+					UPDATE products_fts SET name = new.name, description = new.description, translations = (` + sqliteTranslationsText("new.translations") + `)
+						WHERE rowid = (SELECT seq FROM products_fts_keys WHERE product_id = old.id);
+				END`,
+				`CREATE TRIGGER products_fts_delete AFTER DELETE ON products BEGIN
+					DELETE FROM products_fts WHERE rowid = (SELECT seq FROM products_fts_keys WHERE product_id = old.id);
+					DELETE FROM products_fts_keys WHERE product_id = old.id;
+				END`,
+			},
+		},
+	}
+}
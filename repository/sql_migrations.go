@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Migration is a versioned schema change, applied once and in a single transaction
+type Migration struct {
+	Version     int
+	Description string
+	Statements  []string
+}
+
+// Apply the dialect migrations newer than the schema version recorded on the database
+func migrate(ctx context.Context, db *sql.DB, dialect Dialect) error {
+	_, err := db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version INTEGER PRIMARY KEY,
+		description TEXT NOT NULL,
+		applied_at TIMESTAMP NOT NULL
+	)`)
+	if err != nil {
+		return fmt.Errorf("creating schema migrations table %w", err)
+	}
+
+	var current int
+
+	if err := db.QueryRowContext(ctx, "SELECT COALESCE(MAX(version), 0) FROM schema_migrations").Scan(&current); err != nil {
+		return fmt.Errorf("getting schema version %w", err)
+	}
+
+	for _, migration := range dialect.Migrations() {
+		if migration.Version <= current {
+			continue
+		}
+
+		if err := applyMigration(ctx, db, dialect, migration); err != nil {
+			return fmt.Errorf("applying migration %d %s %w", migration.Version, migration.Description, err)
+		}
+	}
+
+	return nil
+}
+
+func applyMigration(ctx context.Context, db *sql.DB, dialect Dialect, migration Migration) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	for _, statement := range migration.Statements {
+		if _, err := tx.ExecContext(ctx, statement); err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.ExecContext(
+		ctx,
+		dialect.Rebind("INSERT INTO schema_migrations (version, description, applied_at) VALUES (?, ?, ?)"),
+		migration.Version,
+		migration.Description,
+		time.Now(),
+	)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
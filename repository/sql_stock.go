@@ -0,0 +1,187 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	internalError "github.com/srodrmendz/api-product-catalog/errors"
+	"github.com/srodrmendz/api-product-catalog/model"
+)
+
+const movementColumns = "id, product_id, variant_sku, type, delta, qty, reason, reference, actor, created_at"
+
+// Adjust the stock of a product on a single conditional update, the condition only matches it while the qty covers the delta
+func (r *SQL) AdjustStock(ctx context.Context, adjustment model.StockAdjustment) (*model.Product, error) {
+	set := []string{"qty = qty + ?", "in_stock = qty + ? > 0", "version = version + 1", "updated_at = ?"}
+
+	setArgs := []interface{}{adjustment.Delta, adjustment.Delta, time.Now()}
+
+	where, whereArgs := r.getVersionFilter(adjustment.ID, 0)
+
+	// Increments only match while the product qty, the sum of its variants included, stays within MaxQty
+	if adjustment.Delta > 0 {
+		where += " AND qty <= ?"
+
+		whereArgs = append(whereArgs, model.MaxQty-adjustment.Delta)
+	}
+
+	if adjustment.VariantSKU == "" {
+		// Products with variants take their qty from them
+		where += " AND " + r.dialect.JSONEmpty("products.variants") + " AND qty + ? >= 0"
+
+		whereArgs = append(whereArgs, adjustment.Delta)
+	} else {
+		variants, args := r.dialect.AdjustVariant("products.variants", adjustment.VariantSKU, adjustment.Delta)
+
+		set = append(set, "variants = "+variants)
+
+		setArgs = append(setArgs, args...)
+
+		condition, args := r.dialect.VariantStock("products.variants", adjustment.VariantSKU, adjustment.Delta)
+
+		where += " AND " + condition
+
+		whereArgs = append(whereArgs, args...)
+	}
+
+	var product *model.Product
+
+	err := r.inTransaction(ctx, func(tx *SQL) error {
+		result, err := tx.conn().ExecContext(
+			ctx,
+			tx.dialect.Rebind(fmt.Sprintf("UPDATE products SET %s WHERE %s", strings.Join(set, ", "), where)),
+			append(setArgs, whereArgs...)...,
+		)
+		if err != nil {
+			return fmt.Errorf("adjusting product stock on repository %w", err)
+		}
+
+		if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+			return tx.getAdjustmentError(ctx, adjustment)
+		}
+
+		product, err = tx.GetByID(ctx, adjustment.ID)
+
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return product, nil
+}
+
+// Called when an adjustment matched no product, to tell why from the stored product
+func (r *SQL) getAdjustmentError(ctx context.Context, adjustment model.StockAdjustment) error {
+	product, err := r.GetByID(ctx, adjustment.ID)
+	if err != nil {
+		return err
+	}
+
+	if err := checkAdjustment(*product, adjustment); err != nil {
+		return err
+	}
+
+	// The update and the read run on the same transaction, it is only reached when the stored qty is not the sum of its variants
+	return &internalError.VersionConflictError{Current: product.Version}
+}
+
+// Record a stock movement
+func (r *SQL) CreateStockMovement(ctx context.Context, movement model.StockMovement) (*model.StockMovement, error) {
+	movement.ID = uuid.NewString()
+
+	movement.CreatedAt = time.Now()
+
+	_, err := r.conn().ExecContext(
+		ctx,
+		r.dialect.Rebind("INSERT INTO stock_movements ("+movementColumns+") VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)"),
+		movement.ID,
+		movement.ProductID,
+		movement.VariantSKU,
+		movement.Type,
+		movement.Delta,
+		movement.Qty,
+		movement.Reason,
+		movement.Reference,
+		movement.Actor,
+		// Created times are filtered as text, so they are stored on UTC
+		movement.CreatedAt.UTC(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("recording stock movement on repository %w", err)
+	}
+
+	return &movement, nil
+}
+
+// List the stock movements of a product, newest first and in the order they were recorded when created at the same time
+func (r *SQL) ListStockMovements(ctx context.Context, request model.MovementSearchRequest) (*model.MovementPage, error) {
+	where, args := "product_id = ?", []interface{}{request.ProductID}
+
+	if len(request.Types) > 0 {
+		where += " AND type IN (?" + strings.Repeat(", ?", len(request.Types)-1) + ")"
+
+		for _, movementType := range request.Types {
+			args = append(args, movementType)
+		}
+	}
+
+	if request.From != nil {
+		where += " AND created_at >= ?"
+
+		args = append(args, request.From.UTC())
+	}
+
+	if request.To != nil {
+		where += " AND created_at < ?"
+
+		args = append(args, request.To.UTC())
+	}
+
+	page := &model.MovementPage{Movements: []model.StockMovement{}}
+
+	if err := r.conn().QueryRowContext(ctx, r.dialect.Rebind("SELECT COUNT(*) FROM stock_movements WHERE "+where), args...).Scan(&page.Total); err != nil {
+		return nil, fmt.Errorf("counting stock movements on repository %w", err)
+	}
+
+	query := fmt.Sprintf(
+		"SELECT %s FROM stock_movements WHERE %s ORDER BY created_at DESC, seq DESC %s",
+		movementColumns,
+		where,
+		r.dialect.LimitOffset(request.Limit, request.Offset),
+	)
+
+	rows, err := r.conn().QueryContext(ctx, r.dialect.Rebind(query), args...)
+	if err != nil {
+		return nil, fmt.Errorf("listing stock movements on repository %w", err)
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var movement model.StockMovement
+
+		err := rows.Scan(
+			&movement.ID,
+			&movement.ProductID,
+			&movement.VariantSKU,
+			&movement.Type,
+			&movement.Delta,
+			&movement.Qty,
+			&movement.Reason,
+			&movement.Reference,
+			&movement.Actor,
+			&movement.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("decoding stock movement from repository %w", err)
+		}
+
+		page.Movements = append(page.Movements, movement)
+	}
+
+	return page, rows.Err()
+}
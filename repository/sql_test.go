@@ -0,0 +1,160 @@
+package repository_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/srodrmendz/api-product-catalog/model"
+	"github.com/srodrmendz/api-product-catalog/repository"
+	"github.com/srodrmendz/api-product-catalog/repository/repotest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test SQL Repository on SQLite against the repository contract
+func TestSQL(t *testing.T) {
+	repotest.Run(t, func(t *testing.T) repository.Repository {
+		return openSQLite(t, filepath.Join(t.TempDir(), "catalog.db"))
+	})
+}
+
+func TestSQL_Migrations(t *testing.T) {
+	t.Run("successfully reopen an already migrated database", func(t *testing.T) {
+		// Given
+		ctx := context.TODO()
+
+		path := filepath.Join(t.TempDir(), "catalog.db")
+
+		openSQLite(t, path)
+
+		db, err := repository.OpenSQLite(path)
+
+		require.NoError(t, err)
+
+		defer db.Close()
+
+		// When
+		_, err = repository.NewSQL(ctx, db, repository.SQLite)
+
+		// Then
+		require.NoError(t, err)
+
+		var version, applied int
+
+		err = db.QueryRowContext(ctx, "SELECT MAX(version), COUNT(*) FROM schema_migrations").Scan(&version, &applied)
+
+		require.NoError(t, err)
+
+		migrations := repository.SQLite.Migrations()
+
+		assert.Equal(t, migrations[len(migrations)-1].Version, version)
+
+		assert.Equal(t, len(migrations), applied)
+	})
+}
+
+func openSQLite(t *testing.T, path string) *repository.SQL {
+	t.Helper()
+
+	db, err := repository.OpenSQLite(path)
+
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		db.Close()
+	})
+
+	repo, err := repository.NewSQL(context.TODO(), db, repository.SQLite)
+
+	require.NoError(t, err)
+
+	return repo
+}
+
+func TestSQL_Images(t *testing.T) {
+	t.Run("successfully read images stored as their url", func(t *testing.T) {
+		// Given
+		ctx := context.TODO()
+
+		path := filepath.Join(t.TempDir(), "catalog.db")
+
+		repo := openSQLite(t, path)
+
+		product, err := repo.Create(ctx, model.Product{Name: "shirt", Sku: "shirt", Qty: 1, Price: 100})
+
+		require.NoError(t, err)
+
+		db, err := repository.OpenSQLite(path)
+
+		require.NoError(t, err)
+
+		defer db.Close()
+
+		_, err = db.ExecContext(ctx, `UPDATE products SET images = '["https://cdn.example.com/shirt.png"]' WHERE id = ?`, product.ID)
+
+		require.NoError(t, err)
+
+		// When
+		stored, err := repo.GetByID(ctx, product.ID)
+
+		// Then
+		require.NoError(t, err)
+
+		require.Len(t, stored.Images, 1)
+
+		assert.Equal(t, "https://cdn.example.com/shirt.png", stored.Images[0].URL)
+
+		assert.NotEmpty(t, stored.Images[0].ID)
+
+		again, err := repo.GetByID(ctx, product.ID)
+
+		require.NoError(t, err)
+
+		assert.Equal(t, stored.Images[0].ID, again.Images[0].ID)
+	})
+}
+
+func TestSQL_TextSearch(t *testing.T) {
+	t.Run("successfully search names after the products rows are renumbered", func(t *testing.T) {
+		// Given
+		ctx := context.TODO()
+
+		path := filepath.Join(t.TempDir(), "catalog.db")
+
+		repo := openSQLite(t, path)
+
+		var ids []string
+
+		for _, name := range []string{"linen shirt", "wool hat"} {
+			product, err := repo.Create(ctx, model.Product{Name: name, Sku: name, Qty: 1, InStock: true, Price: 100})
+
+			require.NoError(t, err)
+
+			ids = append(ids, product.ID)
+		}
+
+		db, err := repository.OpenSQLite(path)
+
+		require.NoError(t, err)
+
+		defer db.Close()
+
+		// Rows of tables without an INTEGER PRIMARY KEY can be given other rowids, VACUUM is allowed to renumber them
+		for _, renumber := range []string{"UPDATE products SET rowid = -rowid", "UPDATE products SET rowid = 3 + rowid"} {
+			_, err = db.ExecContext(ctx, renumber)
+
+			require.NoError(t, err)
+		}
+
+		// When
+		page, err := repo.Search(ctx, model.SearchRequest{Name: "hat", InStock: true, Admin: true, Limit: 10})
+
+		// Then
+		require.NoError(t, err)
+
+		require.Len(t, page.Products, 1)
+
+		assert.Equal(t, ids[1], page.Products[0].ID)
+	})
+}
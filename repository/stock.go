@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"sort"
+
+	"github.com/srodrmendz/api-product-catalog/model"
+)
+
+// Page the movements matching the request, newest first the same way ListStockMovements does on MongoDB
+// movements must be in the order they were recorded, so the last recorded goes first when created at the same time
+func pageMovements(movements []model.StockMovement, request model.MovementSearchRequest) *model.MovementPage {
+	matched := []model.StockMovement{}
+
+	for i := len(movements) - 1; i >= 0; i-- {
+		if request.Match(movements[i]) {
+			matched = append(matched, movements[i])
+		}
+	}
+
+	sort.SliceStable(matched, func(i, j int) bool {
+		return matched[i].CreatedAt.After(matched[j].CreatedAt)
+	})
+
+	page := &model.MovementPage{Total: int64(len(matched))}
+
+	if request.Offset >= len(matched) {
+		page.Movements = []model.StockMovement{}
+
+		return page
+	}
+
+	matched = matched[request.Offset:]
+
+	if request.Limit > 0 && request.Limit < len(matched) {
+		matched = matched[:request.Limit]
+	}
+
+	page.Movements = matched
+
+	return page
+}
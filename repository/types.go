@@ -2,17 +2,46 @@ package repository
 
 import (
 	"context"
+	"database/sql"
+	"sync"
+	"time"
 
 	"github.com/srodrmendz/api-product-catalog/model"
+	bolt "go.etcd.io/bbolt"
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
 // MongoDB error code when sku index entry is duplicated
 const mongoDBDuplicatedKeyErrorCode = 11000
 
+// isMaster msg of mongos routers, which run transactions as replica set members do
+const mongosMsg = "isdbgrid"
+
+// Name of the unique index on every slug products hold, duplicated key errors on it are slug conflicts
+const slugsIndexName = "slugs"
+
 // Check on build time that ProductsCatalogRepository implement Repository interface
 var _ Repository = (*ProductsCatalogRepository)(nil)
 
+// Check on build time that Memory implement Repository interface
+var _ Repository = (*Memory)(nil)
+
+// Check on build time that Bolt implement Repository interface
+var _ Repository = (*Bolt)(nil)
+
+// Check on build time that SQL implement Repository interface
+var _ Repository = (*SQL)(nil)
+
+// Check on build time that Cache implement Repository interface
+var _ Repository = (*Cache)(nil)
+
+// Check on build time that transaction bound repositories implement Repository interface
+var (
+	_ Repository = (*mongoTransaction)(nil)
+	_ Repository = (*boltTransaction)(nil)
+)
+
 // Repository defines the methods that should be implemented by a user repository.
 type Repository interface {
 	// Create a new product
@@ -27,20 +56,218 @@ type Repository interface {
 	// Returns error if there is an error in the system
 	GetBySKU(ctx context.Context, sku string) (*model.Product, error)
 
-	// Delete a product
+	// Get the product holding a slug as its own, as the one of a translation or as a previous one
+	// Deleted products keep their slugs until purged, so they are found too
+	// Returns error if product not found or there is an error in the system
+	GetBySlug(ctx context.Context, slug string) (*model.Product, error)
+
+	// Delete a product, it is excluded from reads until restored and keeps its sku until purged
+	// The relations of other products to it are removed
+	// A version other than 0 must match the stored product version
+	// Returns error if product not found, the version does not match or there is an error in the system
+	Delete(ctx context.Context, id string, version int64) error
+
+	// Restore a deleted product
+	// Returns error if product not found or there is an error in the system
+	Restore(ctx context.Context, id string) (*model.Product, error)
+
+	// Permanently remove the products deleted before the given time, returns how many were removed
+	// Returns error if there is an error in the system
+	Purge(ctx context.Context, before time.Time) (int64, error)
+
+	// Update a product and increment its version, an update version other than 0 must match the stored product version
+	// Returns error if product not found, the version does not match or there is an error in the system
+	Update(ctx context.Context, update model.Update) (*model.Product, error)
+
+	// Replace a stored product with the given one, its version must match the stored product version and its skus can not change
+	// The version is incremented and the updated time and stock flag are set by the repository
+	// Returns error if product not found, the version does not match or there is an error in the system
+	Replace(ctx context.Context, product model.Product) (*model.Product, error)
+
+	// Add the adjustment delta to the qty of a product, or of its variant, on a single conditional write that only applies
+	// when the qty does not go below 0, the stock flags follow the new qty and the version is incremented
+	// Returns error if product or variant not found, the qty would go below 0 or there is an error in the system
+	AdjustStock(ctx context.Context, adjustment model.StockAdjustment) (*model.Product, error)
+
+	// List the products with a scheduled price starting or ending at the given time or before
+	// Returns error if there is an error in the system
+	ListDuePrices(ctx context.Context, at time.Time) ([]model.Product, error)
+
+	// Search products, paginated by offset or by the request cursor
+	// Returns error if there is an error in the system
+	Search(ctx context.Context, request model.SearchRequest) (*model.Page, error)
+
+	// Create a new category, its path must already hold the ids of its ancestors
 	// Returns error if there is an error in the system
-	Delete(ctx context.Context, id string) error
+	CreateCategory(ctx context.Context, category model.Category) (*model.Category, error)
+
+	// Get a category by id
+	// Returns error if category not found or there is an error in the system
+	GetCategory(ctx context.Context, id string) (*model.Category, error)
 
-	// Update a product
+	// List every category sorted by name
 	// Returns error if there is an error in the system
-	Update(ctx context.Context, id string, qty uint64) (*model.Product, error)
+	ListCategories(ctx context.Context) ([]model.Category, error)
+
+	// Get a category and all its descendants
+	// Returns error if category not found or there is an error in the system
+	GetCategorySubtree(ctx context.Context, id string) ([]model.Category, error)
+
+	// Update a category name, parent and path, the paths of its descendants are updated to follow it
+	// Returns error if category not found or there is an error in the system
+	UpdateCategory(ctx context.Context, category model.Category) (*model.Category, error)
+
+	// Delete a category, its products and subcategories are left untouched
+	// Returns error if category not found or there is an error in the system
+	DeleteCategory(ctx context.Context, id string) error
+
+	// Replace category from by to on every product, including deleted ones, an empty to removes it
+	// Returns how many products changed or error if there is an error in the system
+	ReplaceProductCategory(ctx context.Context, from string, to string) (int64, error)
+
+	// Count how many of the products matching the search filters have each tag, most used first
+	// Pagination and sort of the request are ignored
+	// Returns error if there is an error in the system
+	CountTags(ctx context.Context, request model.SearchRequest) ([]model.TagCount, error)
+
+	// Create a new brand
+	// Returns error if there is an error in the system
+	CreateBrand(ctx context.Context, brand model.Brand) (*model.Brand, error)
+
+	// Get a brand by id
+	// Returns error if brand not found or there is an error in the system
+	GetBrand(ctx context.Context, id string) (*model.Brand, error)
 
-	// Search products
+	// List every brand sorted by name
 	// Returns error if there is an error in the system
-	Search(ctx context.Context, name string, sort string, inStock bool, limit int, offset int) ([]model.Product, *int64, error)
+	ListBrands(ctx context.Context) ([]model.Brand, error)
+
+	// Update a brand name and description
+	// Returns error if brand not found or there is an error in the system
+	UpdateBrand(ctx context.Context, brand model.Brand) (*model.Brand, error)
+
+	// Delete a brand, its products are left untouched
+	// Returns error if brand not found or there is an error in the system
+	DeleteBrand(ctx context.Context, id string) error
+
+	// Record a stock movement, its id and creation time are set by the repository
+	// It does not change the product qty, callers update it on the same transaction
+	// Returns error if there is an error in the system
+	CreateStockMovement(ctx context.Context, movement model.StockMovement) (*model.StockMovement, error)
+
+	// List the stock movements of a product matching the request filters, newest first
+	// A zero limit returns every matching movement
+	// Returns error if there is an error in the system
+	ListStockMovements(ctx context.Context, request model.MovementSearchRequest) (*model.MovementPage, error)
+
+	// Run fn as a unit of work, the writes made through txRepo are applied together only when fn returns no error
+	// txRepo must be the only repository used inside fn and is not valid after fn returns, nested calls join the running transaction
+	// fn may be called more than once when the transaction is retried, so it should not have other side effects
+	// Returns the fn error or error if there is an error in the system
+	WithTransaction(ctx context.Context, fn func(txRepo Repository) error) error
 }
 
 // MongoDB Products Catalog Repository Implementation
 type ProductsCatalogRepository struct {
 	collection *mongo.Collection
+	categories *mongo.Collection
+	brands     *mongo.Collection
+	movements  *mongo.Collection
+	topologyMu sync.Mutex
+	// Whether the server runs transactions, nil until it is checked
+	transactions *bool
+}
+
+// Product document stored on MongoDB, SKUs holds every product and variant sku so a single unique index covers them
+// Slugs holds every product, translation and previous slug so a single index finds them
+// Language is the text search language of the product locale, translations hold the one of theirs
+type mongoProduct struct {
+	model.Product `bson:",inline"`
+	SKUs          []string `bson:"skus"`
+	Slugs         []string `bson:"slugs,omitempty"`
+	Language      string   `bson:"language,omitempty"`
+}
+
+// MongoDB repository bound to a session, every call runs on the session transaction
+type mongoTransaction struct {
+	repository *ProductsCatalogRepository
+	session    mongo.Session
+}
+
+// In memory Products Catalog Repository Implementation, data is lost when the process exits
+type Memory struct {
+	mu         sync.RWMutex
+	products   map[string]model.Product
+	skus       map[string]string
+	slugs      map[string]string
+	categories map[string]model.Category
+	brands     map[string]model.Brand
+	// Stock movements in the order they were recorded
+	movements []model.StockMovement
+	// Set on the repository WithTransaction passes to fn, undo restores every entry it wrote in reverse order
+	transaction bool
+	undo        []func()
+}
+
+// Embedded Products Catalog Repository Implementation, stores every product on a single bbolt data file
+type Bolt struct {
+	db *bolt.DB
+}
+
+// Embedded repository bound to a bbolt transaction
+type boltTransaction struct {
+	tx *bolt.Tx
+}
+
+// SQL Products Catalog Repository Implementation, database specifics are handled by its dialect
+// When tx is set the repository is bound to that transaction
+type SQL struct {
+	db      *sql.DB
+	tx      *sql.Tx
+	dialect Dialect
+}
+
+// Read-through cache decorator, wraps any Repository and caches products by id and sku, and optionally search pages
+// Entries written through the cache are invalidated, writes made directly on the wrapped repository are seen once their entries expire
+type Cache struct {
+	repository Repository
+	config     CacheConfig
+	mu         sync.Mutex
+	products   *lru
+	skus       *lru
+	searches   *lru
+	stats      CacheStats
+	// Incremented on every invalidation, reads started before it are not stored
+	generation uint64
+}
+
+// Repository bound to a transaction of the repository a Cache wraps, it records the writes made through it
+type cacheTransaction struct {
+	Repository
+	writes *cacheWrites
+}
+
+// Writes made on a cache transaction, the cache invalidates only their entries once it returns
+type cacheWrites struct {
+	ids []string
+	// Deleted products, the relations to them are removed from other products
+	deleted []string
+	// Set by writes that can change any product
+	all bool
+}
+
+// Query methods shared by *sql.DB and *sql.Tx
+type sqlConn interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// Index as returned by listIndexes
+type indexDocument struct {
+	Name    string `bson:"name"`
+	Key     bson.D `bson:"key"`
+	Unique  bool   `bson:"unique"`
+	Sparse  bool   `bson:"sparse"`
+	Weights bson.M `bson:"weights"`
 }
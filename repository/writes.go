@@ -0,0 +1,190 @@
+package repository
+
+import (
+	"time"
+
+	internalError "github.com/srodrmendz/api-product-catalog/errors"
+	"github.com/srodrmendz/api-product-catalog/model"
+)
+
+// Check the version expected by a write against the stored product, 0 skips the check
+func checkVersion(product model.Product, version int64) error {
+	if version != 0 && version != product.Version {
+		return &internalError.VersionConflictError{Current: product.Version}
+	}
+
+	return nil
+}
+
+// Check an update against the stored product, the same checks the MongoDB update filter does
+func checkUpdate(product model.Product, update model.Update) error {
+	if err := checkVersion(product, update.Version); err != nil {
+		return err
+	}
+
+	if update.VariantSKU == "" {
+		// Only the stock and price of products with variants are kept on them
+		if len(product.Variants) > 0 && (update.Qty != nil || update.Pricing != nil) {
+			return internalError.ErrVariantRequired
+		}
+
+		return nil
+	}
+
+	variant := product.Variant(update.VariantSKU)
+	if variant == nil {
+		return internalError.ErrVariantNotFound
+	}
+
+	// The product qty is the sum of its variants, so the others leave less room to the updated one
+	if update.Qty != nil && *update.Qty > model.MaxQty-(product.Qty-variant.Qty) {
+		return internalError.ErrStockLimit
+	}
+
+	return nil
+}
+
+// Apply a checked update on a stored product the same way the MongoDB update does
+func applyUpdate(product *model.Product, update model.Update) {
+	if update.VariantSKU != "" {
+		variant := product.Variant(update.VariantSKU)
+
+		if update.Qty != nil {
+			variant.Qty = *update.Qty
+		}
+
+		if update.Pricing != nil {
+			variant.Price = update.Pricing.Price
+		}
+
+		product.ApplyVariants()
+	} else {
+		if update.Qty != nil {
+			product.Qty = *update.Qty
+		}
+
+		if update.Pricing != nil {
+			product.Price = update.Pricing.Price
+		}
+	}
+
+	if pricing := update.Pricing; pricing != nil {
+		product.RegularPrice = pricing.RegularPrice
+
+		product.PriceSchedule = append([]model.ScheduledPrice(nil), pricing.PriceSchedule...)
+
+		product.PriceHistory = append([]model.PriceChange(nil), pricing.PriceHistory...)
+
+		product.PriceChangesAt = pricing.PriceChangesAt
+	}
+
+	if update.Attributes != nil {
+		product.Attributes = copyAttributes(update.Attributes)
+	}
+
+	if update.BrandID != nil {
+		product.BrandID = *update.BrandID
+	}
+
+	if update.Tags != nil {
+		product.Tags = append([]string{}, update.Tags...)
+	}
+
+	product.UpdatedAt = time.Now()
+
+	product.InStock = product.Qty > 0
+
+	product.Version++
+}
+
+// Check a stock adjustment against the stored product, the same checks the MongoDB adjustment filter does
+func checkAdjustment(product model.Product, adjustment model.StockAdjustment) error {
+	if adjustment.VariantSKU == "" && len(product.Variants) > 0 {
+		return internalError.ErrVariantRequired
+	}
+
+	if adjustment.VariantSKU != "" && product.Variant(adjustment.VariantSKU) == nil {
+		return internalError.ErrVariantNotFound
+	}
+
+	qty := product.Qty
+
+	if adjustment.VariantSKU != "" {
+		qty = product.Variant(adjustment.VariantSKU).Qty
+	}
+
+	if adjustment.Delta < 0 && uint64(-adjustment.Delta) > qty {
+		return internalError.ErrInsufficientStock
+	}
+
+	// The product qty holds the sum of its variants, so it bounds variant increments too
+	if adjustment.Delta > 0 && (product.Qty > model.MaxQty || uint64(adjustment.Delta) > model.MaxQty-product.Qty) {
+		return internalError.ErrStockLimit
+	}
+
+	return nil
+}
+
+// Apply a checked stock adjustment on a stored product the same way the MongoDB adjustment does
+func applyAdjustment(product *model.Product, adjustment model.StockAdjustment) {
+	if adjustment.VariantSKU != "" {
+		variant := product.Variant(adjustment.VariantSKU)
+
+		variant.Qty = uint64(int64(variant.Qty) + adjustment.Delta)
+
+		product.ApplyVariants()
+	} else {
+		product.Qty = uint64(int64(product.Qty) + adjustment.Delta)
+	}
+
+	product.UpdatedAt = time.Now()
+
+	product.InStock = product.Qty > 0
+
+	product.Version++
+}
+
+// Check a replacement against the stored product, its version must match the stored product version
+func checkReplace(stored model.Product, product model.Product) error {
+	if product.Version != stored.Version {
+		return &internalError.VersionConflictError{Current: stored.Version}
+	}
+
+	return nil
+}
+
+// Set the fields a replacement does not take from the caller, the same way the MongoDB replace does
+func prepareReplace(product *model.Product) {
+	product.DeletedAt = nil
+
+	product.UpdatedAt = time.Now()
+
+	product.InStock = product.Qty > 0
+
+	product.Version++
+}
+
+// Reports whether a scheduled price of the product starts or ends at the given time or before
+func isPriceDue(product model.Product, at time.Time) bool {
+	return product.PriceChangesAt != nil && !product.PriceChangesAt.After(at)
+}
+
+// Mark a stored product as deleted the same way the MongoDB delete does
+func markDeleted(product *model.Product) {
+	now := time.Now()
+
+	product.DeletedAt = &now
+
+	product.UpdatedAt = now
+
+	product.Version++
+}
+
+// Clear the deleted mark of a stored product the same way the MongoDB restore does
+func markRestored(product *model.Product) {
+	product.DeletedAt = nil
+
+	product.UpdatedAt = time.Now()
+
+	product.Version++
+}
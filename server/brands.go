@@ -0,0 +1,197 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	internalErrors "github.com/srodrmendz/api-product-catalog/errors"
+	"github.com/srodrmendz/api-product-catalog/model"
+	"github.com/srodrmendz/api-product-catalog/utils"
+)
+
+// Create Brand godoc
+// @Tags brands
+// @Description Create brand, brand names are unique ignoring case
+// @Accept  json
+// @Produce  json
+// @Param request body model.BrandRequest true "Request body"
+// @Success 201 {object} model.Brand
+// @Failure 400
+// @Failure 409
+// @Failure 500
+// @Router /v1/brands [post]
+func (a *App) createBrand(w http.ResponseWriter, r *http.Request) {
+	builder := model.NewBrandBuilder(r)
+
+	request, err := builder.Build()
+	if err != nil {
+		utils.ErrJSON(w, http.StatusBadRequest, err)
+
+		return
+	}
+
+	brand, err := a.Services.ProductsService.CreateBrand(r.Context(), *request)
+	if err != nil {
+		a.brandError(w, err)
+
+		return
+	}
+
+	utils.DataJSON(w, http.StatusCreated, brand)
+}
+
+// List Brands godoc
+// @Tags brands
+// @Description List every brand sorted by name
+// @Accept  json
+// @Produce  json
+// @Success 200 {array} model.Brand
+// @Failure 500
+// @Router /v1/brands [get]
+func (a *App) listBrands(w http.ResponseWriter, r *http.Request) {
+	brands, err := a.Services.ProductsService.ListBrands(r.Context())
+	if err != nil {
+		utils.ErrJSON(w, http.StatusInternalServerError, err)
+
+		return
+	}
+
+	utils.DataJSON(w, http.StatusOK, brands)
+}
+
+// Get Brand godoc
+// @Tags brands
+// @Description Get brand by id
+// @Accept  json
+// @Produce  json
+// @Param id path string true "id"
+// @Success 200 {object} model.Brand
+// @Failure 404
+// @Failure 500
+// @Router /v1/brands/{id}/ [get]
+func (a *App) getBrand(w http.ResponseWriter, r *http.Request) {
+	brand, err := a.Services.ProductsService.GetBrand(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		a.brandError(w, err)
+
+		return
+	}
+
+	utils.DataJSON(w, http.StatusOK, brand)
+}
+
+// Update Brand godoc
+// @Tags brands
+// @Description Rename brand or change its description
+// @Accept  json
+// @Produce  json
+// @Param request body model.BrandRequest true "Request body"
+// @Param id path string true "id"
+// @Success 200 {object} model.Brand
+// @Failure 400
+// @Failure 404
+// @Failure 409
+// @Failure 500
+// @Router /v1/brands/{id}/ [put]
+func (a *App) updateBrand(w http.ResponseWriter, r *http.Request) {
+	builder := model.NewBrandBuilder(r)
+
+	request, err := builder.Build()
+	if err != nil {
+		utils.ErrJSON(w, http.StatusBadRequest, err)
+
+		return
+	}
+
+	brand, err := a.Services.ProductsService.UpdateBrand(r.Context(), *request)
+	if err != nil {
+		a.brandError(w, err)
+
+		return
+	}
+
+	utils.DataJSON(w, http.StatusOK, brand)
+}
+
+// Delete Brand godoc
+// @Tags brands
+// @Description Delete brand without products, deleted products not purged yet still hold it
+// @Accept  json
+// @Produce  json
+// @Param id path string true "id"
+// @Success 204
+// @Failure 404
+// @Failure 409
+// @Failure 500
+// @Router /v1/brands/{id}/ [delete]
+func (a *App) deleteBrand(w http.ResponseWriter, r *http.Request) {
+	if err := a.Services.ProductsService.DeleteBrand(r.Context(), mux.Vars(r)["id"]); err != nil {
+		a.brandError(w, err)
+
+		return
+	}
+
+	utils.DataJSON(w, http.StatusNoContent, nil)
+}
+
+// Tag Counts godoc
+// @Tags search
+// @Description Count how many of the products matching the search filters have each tag, sorted by count
+// @Accept  json
+// @Produce  json
+// @Param name query string false "name"
+// @Param in_stock query string false "in stock"
+// @Param include_deleted query bool false "include deleted products, only applied for admin callers"
+// @Param category query string false "category id, its subcategories are included"
+// @Param currency query string false "ISO 4217 currency, products without a price on it are not counted"
+// @Param brand query string false "brand id"
+// @Param tag query []string false "tag, repeated to match several" collectionFormat(multi)
+// @Param tag_match query string false "any or all of the tags, any by default"
+// @Param status query []string false "draft, published or archived, repeated to match any, only applied for admin callers" collectionFormat(multi)
+// @Param Authorization header string false "Bearer admin token, admin callers count products on every status, others only the ones visible to shoppers"
+// @Success 200 {object} model.TagCountsResponse
+// @Failure 400
+// @Failure 500
+// @Router /v1/tags [get]
+func (a *App) tagCounts(w http.ResponseWriter, r *http.Request) {
+	builder := model.NewTagCountBuilder(r)
+
+	request, err := builder.Build()
+	if err != nil {
+		utils.ErrJSON(w, http.StatusBadRequest, err)
+
+		return
+	}
+
+	tags, err := a.Services.ProductsService.TagCounts(r.Context(), *request)
+	if err != nil {
+		if errors.Is(err, internalErrors.ErrCategoryNotFound) {
+			utils.ErrJSON(w, http.StatusBadRequest, err)
+
+			return
+		}
+
+		utils.ErrJSON(w, http.StatusInternalServerError, err)
+
+		return
+	}
+
+	utils.DataJSON(w, http.StatusOK, tags)
+}
+
+func (a *App) brandError(w http.ResponseWriter, err error) {
+	if errors.Is(err, internalErrors.ErrBrandNotFound) {
+		utils.ErrJSON(w, http.StatusNotFound, err)
+
+		return
+	}
+
+	if errors.Is(err, internalErrors.ErrBrandAlreadyExist) || errors.Is(err, internalErrors.ErrBrandInUse) {
+		utils.ErrJSON(w, http.StatusConflict, err)
+
+		return
+	}
+
+	utils.ErrJSON(w, http.StatusInternalServerError, err)
+}
@@ -0,0 +1,181 @@
+package server
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	internalErrors "github.com/srodrmendz/api-product-catalog/errors"
+	"github.com/srodrmendz/api-product-catalog/model"
+	"github.com/srodrmendz/api-product-catalog/service"
+	"github.com/stretchr/testify/assert"
+)
+
+// Test brand and tag endpoints
+func TestServer_Brands(t *testing.T) {
+	dataTable := []struct {
+		name            string
+		method          string
+		endpoint        string
+		body            io.Reader
+		productsService service.Service
+		expectedCode    int
+	}{
+		{
+			name:            "failed to create brand, incorrect request body format",
+			method:          http.MethodPost,
+			endpoint:        "/v1/brands",
+			body:            mockRequest([]string{"acme"}),
+			productsService: &mockService{},
+			expectedCode:    http.StatusBadRequest,
+		},
+		{
+			name:            "failed to create brand, name cannot be empty",
+			method:          http.MethodPost,
+			endpoint:        "/v1/brands",
+			body:            mockRequest(model.BrandRequest{Name: "  "}),
+			productsService: &mockService{},
+			expectedCode:    http.StatusBadRequest,
+		},
+		{
+			name:     "failed to create brand, name already exist",
+			method:   http.MethodPost,
+			endpoint: "/v1/brands",
+			body:     mockRequest(model.BrandRequest{Name: "Acme"}),
+			productsService: &mockService{
+				err: internalErrors.ErrBrandAlreadyExist,
+			},
+			expectedCode: http.StatusConflict,
+		},
+		{
+			name:            "successfully create brand",
+			method:          http.MethodPost,
+			endpoint:        "/v1/brands",
+			body:            mockRequest(model.BrandRequest{Name: "Acme"}),
+			productsService: &mockService{},
+			expectedCode:    http.StatusCreated,
+		},
+		{
+			name:            "successfully list brands",
+			method:          http.MethodGet,
+			endpoint:        "/v1/brands",
+			productsService: &mockService{},
+			expectedCode:    http.StatusOK,
+		},
+		{
+			name:     "failed to get brand, brand not found",
+			method:   http.MethodGet,
+			endpoint: "/v1/brands/1/",
+			productsService: &mockService{
+				err: internalErrors.ErrBrandNotFound,
+			},
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:            "successfully get brand",
+			method:          http.MethodGet,
+			endpoint:        "/v1/brands/1/",
+			productsService: &mockService{},
+			expectedCode:    http.StatusOK,
+		},
+		{
+			name:     "failed to update brand, brand not found",
+			method:   http.MethodPut,
+			endpoint: "/v1/brands/1/",
+			body:     mockRequest(model.BrandRequest{Name: "Acme"}),
+			productsService: &mockService{
+				err: internalErrors.ErrBrandNotFound,
+			},
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:            "successfully update brand",
+			method:          http.MethodPut,
+			endpoint:        "/v1/brands/1/",
+			body:            mockRequest(model.BrandRequest{Name: "Acme"}),
+			productsService: &mockService{},
+			expectedCode:    http.StatusOK,
+		},
+		{
+			name:     "failed to delete brand, brand has products",
+			method:   http.MethodDelete,
+			endpoint: "/v1/brands/1/",
+			productsService: &mockService{
+				err: internalErrors.ErrBrandInUse,
+			},
+			expectedCode: http.StatusConflict,
+		},
+		{
+			name:     "failed to delete brand, error on service",
+			method:   http.MethodDelete,
+			endpoint: "/v1/brands/1/",
+			productsService: &mockService{
+				err: errors.New("error on service"),
+			},
+			expectedCode: http.StatusInternalServerError,
+		},
+		{
+			name:            "successfully delete brand",
+			method:          http.MethodDelete,
+			endpoint:        "/v1/brands/1/",
+			productsService: &mockService{},
+			expectedCode:    http.StatusNoContent,
+		},
+		{
+			name:            "failed to count tags, incorrect tag format",
+			method:          http.MethodGet,
+			endpoint:        "/v1/tags?tag=no%20spaces",
+			productsService: &mockService{},
+			expectedCode:    http.StatusBadRequest,
+		},
+		{
+			name:            "failed to count tags, incorrect tag match format",
+			method:          http.MethodGet,
+			endpoint:        "/v1/tags?tag=sale&tag_match=some",
+			productsService: &mockService{},
+			expectedCode:    http.StatusBadRequest,
+		},
+		{
+			name:     "failed to count tags, category not found",
+			method:   http.MethodGet,
+			endpoint: "/v1/tags?category=fake",
+			productsService: &mockService{
+				err: internalErrors.ErrCategoryNotFound,
+			},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:            "successfully count tags",
+			method:          http.MethodGet,
+			endpoint:        "/v1/tags?brand=1&tag=sale&tag=new&tag_match=all",
+			productsService: &mockService{},
+			expectedCode:    http.StatusOK,
+		},
+	}
+
+	for _, dt := range dataTable {
+		t.Run(dt.name, func(t *testing.T) {
+			// Given
+			app := New(
+				dt.productsService,
+				mux.NewRouter(),
+				"",
+				"",
+				"",
+				"")
+
+			w := httptest.NewRecorder()
+
+			req := httptest.NewRequest(dt.method, dt.endpoint, dt.body)
+
+			// When
+			app.serveHTTP(w, req)
+
+			// Then
+			assert.Equal(t, dt.expectedCode, w.Code)
+		})
+	}
+}
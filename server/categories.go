@@ -0,0 +1,170 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	internalErrors "github.com/srodrmendz/api-product-catalog/errors"
+	"github.com/srodrmendz/api-product-catalog/model"
+	"github.com/srodrmendz/api-product-catalog/utils"
+)
+
+// Create Category godoc
+// @Tags categories
+// @Description Create category, an empty parent creates a root category
+// @Accept  json
+// @Produce  json
+// @Param request body model.CategoryRequest true "Request body"
+// @Success 201 {object} model.Category
+// @Failure 400
+// @Failure 500
+// @Router /v1/categories [post]
+func (a *App) createCategory(w http.ResponseWriter, r *http.Request) {
+	builder := model.NewCategoryBuilder(r)
+
+	request, err := builder.Build()
+	if err != nil {
+		utils.ErrJSON(w, http.StatusBadRequest, err)
+
+		return
+	}
+
+	category, err := a.Services.ProductsService.CreateCategory(r.Context(), *request)
+	if err != nil {
+		if errors.Is(err, internalErrors.ErrCategoryParentNotFound) {
+			utils.ErrJSON(w, http.StatusBadRequest, err)
+
+			return
+		}
+
+		utils.ErrJSON(w, http.StatusInternalServerError, err)
+
+		return
+	}
+
+	utils.DataJSON(w, http.StatusCreated, category)
+}
+
+// List Categories godoc
+// @Tags categories
+// @Description List every category sorted by name
+// @Accept  json
+// @Produce  json
+// @Success 200 {array} model.Category
+// @Failure 500
+// @Router /v1/categories [get]
+func (a *App) listCategories(w http.ResponseWriter, r *http.Request) {
+	categories, err := a.Services.ProductsService.ListCategories(r.Context())
+	if err != nil {
+		utils.ErrJSON(w, http.StatusInternalServerError, err)
+
+		return
+	}
+
+	utils.DataJSON(w, http.StatusOK, categories)
+}
+
+// Get Category godoc
+// @Tags categories
+// @Description Get category by id
+// @Accept  json
+// @Produce  json
+// @Param id path string true "id"
+// @Success 200 {object} model.Category
+// @Failure 404
+// @Failure 500
+// @Router /v1/categories/{id}/ [get]
+func (a *App) getCategory(w http.ResponseWriter, r *http.Request) {
+	category, err := a.Services.ProductsService.GetCategory(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		if errors.Is(err, internalErrors.ErrCategoryNotFound) {
+			utils.ErrJSON(w, http.StatusNotFound, err)
+
+			return
+		}
+
+		utils.ErrJSON(w, http.StatusInternalServerError, err)
+
+		return
+	}
+
+	utils.DataJSON(w, http.StatusOK, category)
+}
+
+// Update Category godoc
+// @Tags categories
+// @Description Rename or move category, its subcategories and products move with it
+// @Accept  json
+// @Produce  json
+// @Param request body model.CategoryRequest true "Request body"
+// @Param id path string true "id"
+// @Success 200 {object} model.Category
+// @Failure 400
+// @Failure 404
+// @Failure 500
+// @Router /v1/categories/{id}/ [put]
+func (a *App) updateCategory(w http.ResponseWriter, r *http.Request) {
+	builder := model.NewCategoryBuilder(r)
+
+	request, err := builder.Build()
+	if err != nil {
+		utils.ErrJSON(w, http.StatusBadRequest, err)
+
+		return
+	}
+
+	category, err := a.Services.ProductsService.UpdateCategory(r.Context(), *request)
+	if err != nil {
+		if errors.Is(err, internalErrors.ErrCategoryNotFound) {
+			utils.ErrJSON(w, http.StatusNotFound, err)
+
+			return
+		}
+
+		if errors.Is(err, internalErrors.ErrCategoryParentNotFound) || errors.Is(err, internalErrors.ErrCategoryInvalidParent) {
+			utils.ErrJSON(w, http.StatusBadRequest, err)
+
+			return
+		}
+
+		utils.ErrJSON(w, http.StatusInternalServerError, err)
+
+		return
+	}
+
+	utils.DataJSON(w, http.StatusOK, category)
+}
+
+// Delete Category godoc
+// @Tags categories
+// @Description Delete category without subcategories, its products are moved to its parent
+// @Accept  json
+// @Produce  json
+// @Param id path string true "id"
+// @Success 204
+// @Failure 404
+// @Failure 409
+// @Failure 500
+// @Router /v1/categories/{id}/ [delete]
+func (a *App) deleteCategory(w http.ResponseWriter, r *http.Request) {
+	if err := a.Services.ProductsService.DeleteCategory(r.Context(), mux.Vars(r)["id"]); err != nil {
+		if errors.Is(err, internalErrors.ErrCategoryNotFound) {
+			utils.ErrJSON(w, http.StatusNotFound, err)
+
+			return
+		}
+
+		if errors.Is(err, internalErrors.ErrCategoryHasChildren) {
+			utils.ErrJSON(w, http.StatusConflict, err)
+
+			return
+		}
+
+		utils.ErrJSON(w, http.StatusInternalServerError, err)
+
+		return
+	}
+
+	utils.DataJSON(w, http.StatusNoContent, nil)
+}
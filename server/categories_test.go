@@ -0,0 +1,178 @@
+package server
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	internalErrors "github.com/srodrmendz/api-product-catalog/errors"
+	"github.com/srodrmendz/api-product-catalog/model"
+	"github.com/srodrmendz/api-product-catalog/service"
+	"github.com/stretchr/testify/assert"
+)
+
+// Test category endpoints
+func TestServer_Categories(t *testing.T) {
+	dataTable := []struct {
+		name            string
+		method          string
+		endpoint        string
+		body            io.Reader
+		productsService service.Service
+		expectedCode    int
+	}{
+		{
+			name:            "failed to create category, incorrect request body format",
+			method:          http.MethodPost,
+			endpoint:        "/v1/categories",
+			body:            mockRequest([]string{"shoes"}),
+			productsService: &mockService{},
+			expectedCode:    http.StatusBadRequest,
+		},
+		{
+			name:            "failed to create category, name cannot be empty",
+			method:          http.MethodPost,
+			endpoint:        "/v1/categories",
+			body:            mockRequest(model.CategoryRequest{}),
+			productsService: &mockService{},
+			expectedCode:    http.StatusBadRequest,
+		},
+		{
+			name:     "failed to create category, parent not found",
+			method:   http.MethodPost,
+			endpoint: "/v1/categories",
+			body:     mockRequest(model.CategoryRequest{Name: "shoes", ParentID: "fake"}),
+			productsService: &mockService{
+				err: internalErrors.ErrCategoryParentNotFound,
+			},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:            "successfully create category",
+			method:          http.MethodPost,
+			endpoint:        "/v1/categories",
+			body:            mockRequest(model.CategoryRequest{Name: "shoes"}),
+			productsService: &mockService{},
+			expectedCode:    http.StatusCreated,
+		},
+		{
+			name:            "successfully list categories",
+			method:          http.MethodGet,
+			endpoint:        "/v1/categories",
+			productsService: &mockService{},
+			expectedCode:    http.StatusOK,
+		},
+		{
+			name:     "failed to get category, category not found",
+			method:   http.MethodGet,
+			endpoint: "/v1/categories/1/",
+			productsService: &mockService{
+				err: internalErrors.ErrCategoryNotFound,
+			},
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:            "successfully get category",
+			method:          http.MethodGet,
+			endpoint:        "/v1/categories/1/",
+			productsService: &mockService{},
+			expectedCode:    http.StatusOK,
+		},
+		{
+			name:            "failed to update category, category cannot be its own parent",
+			method:          http.MethodPut,
+			endpoint:        "/v1/categories/1/",
+			body:            mockRequest(model.CategoryRequest{Name: "shoes", ParentID: "1"}),
+			productsService: &mockService{},
+			expectedCode:    http.StatusBadRequest,
+		},
+		{
+			name:     "failed to update category, parent on its subtree",
+			method:   http.MethodPut,
+			endpoint: "/v1/categories/1/",
+			body:     mockRequest(model.CategoryRequest{Name: "shoes", ParentID: "2"}),
+			productsService: &mockService{
+				err: internalErrors.ErrCategoryInvalidParent,
+			},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:     "failed to update category, category not found",
+			method:   http.MethodPut,
+			endpoint: "/v1/categories/1/",
+			body:     mockRequest(model.CategoryRequest{Name: "shoes"}),
+			productsService: &mockService{
+				err: internalErrors.ErrCategoryNotFound,
+			},
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:            "successfully update category",
+			method:          http.MethodPut,
+			endpoint:        "/v1/categories/1/",
+			body:            mockRequest(model.CategoryRequest{Name: "shoes"}),
+			productsService: &mockService{},
+			expectedCode:    http.StatusOK,
+		},
+		{
+			name:     "failed to delete category, it has subcategories",
+			method:   http.MethodDelete,
+			endpoint: "/v1/categories/1/",
+			productsService: &mockService{
+				err: internalErrors.ErrCategoryHasChildren,
+			},
+			expectedCode: http.StatusConflict,
+		},
+		{
+			name:     "failed to delete category, error on service",
+			method:   http.MethodDelete,
+			endpoint: "/v1/categories/1/",
+			productsService: &mockService{
+				err: errors.New("error on service"),
+			},
+			expectedCode: http.StatusInternalServerError,
+		},
+		{
+			name:            "successfully delete category",
+			method:          http.MethodDelete,
+			endpoint:        "/v1/categories/1/",
+			productsService: &mockService{},
+			expectedCode:    http.StatusNoContent,
+		},
+		{
+			name:     "failed to search products, category not found",
+			method:   http.MethodGet,
+			endpoint: "/v1?limit=10&offset=0&category=fake",
+			productsService: &mockService{
+				err: internalErrors.ErrCategoryNotFound,
+			},
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, dt := range dataTable {
+		t.Run(dt.name, func(t *testing.T) {
+			// Given
+			app := New(
+				dt.productsService,
+				mux.NewRouter(),
+				"",
+				"",
+				"",
+				"")
+
+			w := httptest.NewRecorder()
+
+			req := httptest.NewRequest(dt.method, dt.endpoint, dt.body)
+
+			// When
+			app.serveHTTP(w, req)
+
+			// Then
+			assert.Equal(t, dt.expectedCode, w.Code)
+		})
+	}
+}
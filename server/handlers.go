@@ -28,7 +28,9 @@ func (a *App) healthCheck(w http.ResponseWriter, _ *http.Request) {
 
 // Create godoc
 // @Tags create
-// @Description Create product
+// @Description Create product, it is a draft unless it is created published
+// @Description Bundles take their qty and in_stock from their components, and their price when they are priced on them
+// @Description The product and its translations get a slug from their names unless a slug no other product holds is requested
 // @Accept  json
 // @Produce  json
 // @Param request body model.Product true "Request body"
@@ -47,7 +49,14 @@ func (a *App) create(w http.ResponseWriter, r *http.Request) {
 
 	product, err := a.Services.ProductsService.Create(r.Context(), *request)
 	if err != nil {
-		if errors.Is(err, internalErrors.ErrProductSKUAlreadyExist) {
+		if errors.Is(err, internalErrors.ErrProductSKUAlreadyExist) ||
+			errors.Is(err, internalErrors.ErrCategoryNotFound) ||
+			errors.Is(err, internalErrors.ErrAttributeInvalid) ||
+			errors.Is(err, internalErrors.ErrImageLimit) ||
+			errors.Is(err, internalErrors.ErrBrandNotFound) ||
+			errors.Is(err, internalErrors.ErrComponentNotFound) ||
+			errors.Is(err, internalErrors.ErrComponentInvalid) ||
+			errors.Is(err, internalErrors.ErrSlugAlreadyExist) {
 			utils.ErrJSON(w, http.StatusBadRequest, err)
 
 			return
@@ -58,6 +67,8 @@ func (a *App) create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	w.Header().Set("ETag", model.ETag(product.Version))
+
 	utils.DataJSON(w, http.StatusCreated, product)
 }
 
@@ -67,6 +78,12 @@ func (a *App) create(w http.ResponseWriter, r *http.Request) {
 // @Accept  json
 // @Produce  json
 // @Param id path string true "id"
+// @Param currency query string false "ISO 4217 currency the price is returned on"
+// @Param units query string false "metric or imperial, unit system weight and dimensions are returned on, metric by default"
+// @Param locale query string false "locale the content is returned on, takes precedence over Accept-Language"
+// @Param Accept-Language header string false "locales the content is returned on by preference"
+// @Param expand query string false "relations inlines the related products on the product relations"
+// @Param Authorization header string false "Bearer admin token, admin callers find the product and expand related products on every status, others only the ones visible to shoppers"
 // @Success 200 {object} model.Product
 // @Failure 400
 // @Failure 404
@@ -81,7 +98,14 @@ func (a *App) getByID(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	product, err := a.Services.ProductsService.GetByID(r.Context(), id)
+	options, err := model.ParseReadOptions(r)
+	if err != nil {
+		utils.ErrJSON(w, http.StatusBadRequest, err)
+
+		return
+	}
+
+	product, err := a.Services.ProductsService.GetByID(r.Context(), id, options)
 	if err != nil {
 		if errors.Is(err, internalErrors.ErrProductNotFound) {
 			utils.ErrJSON(w, http.StatusNotFound, err)
@@ -89,11 +113,23 @@ func (a *App) getByID(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
+		if errors.Is(err, internalErrors.ErrPriceNotAvailable) {
+			utils.ErrJSON(w, http.StatusBadRequest, err)
+
+			return
+		}
+
 		utils.ErrJSON(w, http.StatusInternalServerError, err)
 
 		return
 	}
 
+	w.Header().Set("ETag", model.ETag(product.Version))
+
+	if product.Locale != "" {
+		w.Header().Set("Content-Language", product.Locale)
+	}
+
 	utils.DataJSON(w, http.StatusOK, product)
 }
 
@@ -102,8 +138,13 @@ func (a *App) getByID(w http.ResponseWriter, r *http.Request) {
 // @Description Get product by sku
 // @Accept  json
 // @Produce  json
-// @Param sku path string true "sku"
-// @Success 200 {object} model.Product
+// @Param sku path string true "product or variant sku"
+// @Param currency query string false "ISO 4217 currency the price is returned on"
+// @Param units query string false "metric or imperial, unit system weight and dimensions are returned on, metric by default"
+// @Param locale query string false "locale the content is returned on, takes precedence over Accept-Language"
+// @Param Accept-Language header string false "locales the content is returned on by preference"
+// @Param Authorization header string false "Bearer admin token, admin callers find products on every status, others only the ones visible to shoppers"
+// @Success 200 {object} model.SKUProduct
 // @Failure 400
 // @Failure 404
 // @Failure 500
@@ -117,7 +158,14 @@ func (a *App) getBySKU(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	product, err := a.Services.ProductsService.GetBySKU(r.Context(), sku)
+	options, err := model.ParseReadOptions(r)
+	if err != nil {
+		utils.ErrJSON(w, http.StatusBadRequest, err)
+
+		return
+	}
+
+	product, err := a.Services.ProductsService.GetBySKU(r.Context(), sku, options)
 	if err != nil {
 		if errors.Is(err, internalErrors.ErrProductNotFound) {
 			utils.ErrJSON(w, http.StatusNotFound, err)
@@ -125,22 +173,37 @@ func (a *App) getBySKU(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
+		if errors.Is(err, internalErrors.ErrPriceNotAvailable) {
+			utils.ErrJSON(w, http.StatusBadRequest, err)
+
+			return
+		}
+
 		utils.ErrJSON(w, http.StatusInternalServerError, err)
 
 		return
 	}
 
+	w.Header().Set("ETag", model.ETag(product.Version))
+
+	if product.Locale != "" {
+		w.Header().Set("Content-Language", product.Locale)
+	}
+
 	utils.DataJSON(w, http.StatusOK, product)
 }
 
 // Delete godoc
 // @Tags delete
-// @Description Delete product
+// @Description Delete product, components of bundles that are not archived or deleted can not be deleted
 // @Accept  json
 // @Produce  json
 // @Param id path string true "id"
+// @Param If-Match header string false "product version ETag"
 // @Success 204
 // @Failure 400
+// @Failure 404
+// @Failure 409 {object} server.conflictResponse
 // @Failure 500
 // @Router /v1/{id}/ [delete]
 func (a *App) delete(w http.ResponseWriter, r *http.Request) {
@@ -152,7 +215,32 @@ func (a *App) delete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := a.Services.ProductsService.Delete(r.Context(), id); err != nil {
+	version, err := model.ParseIfMatch(r.Header.Get("If-Match"))
+	if err != nil {
+		utils.ErrJSON(w, http.StatusBadRequest, err)
+
+		return
+	}
+
+	if err := a.Services.ProductsService.Delete(r.Context(), id, version); err != nil {
+		if errors.Is(err, internalErrors.ErrProductNotFound) {
+			utils.ErrJSON(w, http.StatusNotFound, err)
+
+			return
+		}
+
+		if errors.Is(err, internalErrors.ErrProductInBundle) {
+			utils.ErrJSON(w, http.StatusConflict, err)
+
+			return
+		}
+
+		if errors.Is(err, internalErrors.ErrVersionConflict) {
+			versionConflict(w, err)
+
+			return
+		}
+
 		utils.ErrJSON(w, http.StatusInternalServerError, err)
 
 		return
@@ -161,6 +249,44 @@ func (a *App) delete(w http.ResponseWriter, r *http.Request) {
 	utils.DataJSON(w, http.StatusNoContent, nil)
 }
 
+// Restore godoc
+// @Tags restore
+// @Description Restore deleted product
+// @Accept  json
+// @Produce  json
+// @Param id path string true "id"
+// @Success 200 {object} model.Product
+// @Failure 400
+// @Failure 404
+// @Failure 500
+// @Router /v1/{id}/restore [post]
+func (a *App) restore(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	if id == "" {
+		utils.ErrJSON(w, http.StatusBadRequest, errors.New("id must be provided"))
+
+		return
+	}
+
+	product, err := a.Services.ProductsService.Restore(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, internalErrors.ErrProductNotFound) {
+			utils.ErrJSON(w, http.StatusNotFound, err)
+
+			return
+		}
+
+		utils.ErrJSON(w, http.StatusInternalServerError, err)
+
+		return
+	}
+
+	w.Header().Set("ETag", model.ETag(product.Version))
+
+	utils.DataJSON(w, http.StatusOK, product)
+}
+
 // Update godoc
 // @Tags update
 // @Description Update product
@@ -168,8 +294,12 @@ func (a *App) delete(w http.ResponseWriter, r *http.Request) {
 // @Produce  json
 // @Param request body model.UpdateRequest true "Request body"
 // @Param id path string true "id"
+// @Param If-Match header string false "product version ETag"
+// @Param X-Actor header string false "who changes the price or qty, recorded on the price history and stock ledger"
 // @Success 201 {object} model.Product
+// @Failure 400
 // @Failure 404
+// @Failure 409 {object} server.conflictResponse
 // @Failure 500
 // @Router /v1/{id}/ [put]
 func (a *App) update(w http.ResponseWriter, r *http.Request) {
@@ -184,31 +314,80 @@ func (a *App) update(w http.ResponseWriter, r *http.Request) {
 
 	product, err := a.Services.ProductsService.Update(r.Context(), request)
 	if err != nil {
-		if errors.Is(err, internalErrors.ErrProductNotFound) {
+		if errors.Is(err, internalErrors.ErrProductNotFound) || errors.Is(err, internalErrors.ErrVariantNotFound) {
 			utils.ErrJSON(w, http.StatusNotFound, err)
 
 			return
 		}
 
+		if errors.Is(err, internalErrors.ErrVariantRequired) ||
+			errors.Is(err, internalErrors.ErrAttributeInvalid) ||
+			errors.Is(err, internalErrors.ErrBrandNotFound) ||
+			errors.Is(err, internalErrors.ErrBundlePricing) ||
+			errors.Is(err, internalErrors.ErrStockLimit) {
+			utils.ErrJSON(w, http.StatusBadRequest, err)
+
+			return
+		}
+
+		if errors.Is(err, internalErrors.ErrVersionConflict) {
+			versionConflict(w, err)
+
+			return
+		}
+
 		utils.ErrJSON(w, http.StatusInternalServerError, err)
 
 		return
 	}
 
+	w.Header().Set("ETag", model.ETag(product.Version))
+
 	utils.DataJSON(w, http.StatusCreated, product)
 }
 
+// Respond a version conflict with the current product version on the ETag header and body
+func versionConflict(w http.ResponseWriter, err error) {
+	response := conflictResponse{
+		Error: err.Error(),
+	}
+
+	var conflict *internalErrors.VersionConflictError
+
+	if errors.As(err, &conflict) {
+		response.Version = conflict.Current
+
+		w.Header().Set("ETag", model.ETag(conflict.Current))
+	}
+
+	utils.DataJSON(w, http.StatusConflict, response)
+}
+
 // Search godoc
 // @Tags search
-// @Description Search products
+// @Description Search products, attributes are filtered with attr.{name}={value}, attr.{name}_gte={number} and attr.{name}_lte={number}
+// @Description weight, length, width and height are filtered with {measure}_gte={number} and {measure}_lte={number}
 // @Accept  json
 // @Produce  json
 // @Param name query string false "name"
-// @Param sort query string false "sort"
+// @Param sort query string false "price order, asc (default) or desc"
 // @Param in_stock query string false "in stock"
 // @Param limit query int true "limit"
-// @Param offset query int true "offset"
+// @Param offset query int false "offset, required without cursor"
+// @Param cursor query string false "cursor from next_cursor or prev_cursor"
+// @Param include_deleted query bool false "include deleted products, only applied for admin callers"
+// @Param category query string false "category id, its subcategories are included"
+// @Param currency query string false "ISO 4217 currency products are priced and sorted on"
+// @Param units query string false "metric or imperial, unit system weight and dimensions are filtered and returned on, metric by default"
+// @Param locale query string false "locale products are localized to, name is searched with its language, takes precedence over Accept-Language"
+// @Param Accept-Language header string false "locales products are localized to by preference"
+// @Param status query []string false "draft, published or archived, repeated to match any, only applied for admin callers" collectionFormat(multi)
+// @Param brand query string false "brand id"
+// @Param tag query []string false "tag, repeated to match several" collectionFormat(multi)
+// @Param tag_match query string false "any or all of the tags, any by default"
+// @Param Authorization header string false "Bearer admin token, admin callers search products on every status, others only the ones visible to shoppers"
 // @Success 200 {object} model.SearchResponse
+// @Failure 400
 // @Failure 500
 // @Router /v1 [get]
 func (a *App) search(w http.ResponseWriter, r *http.Request) {
@@ -223,6 +402,12 @@ func (a *App) search(w http.ResponseWriter, r *http.Request) {
 
 	products, err := a.Services.ProductsService.Search(r.Context(), *request)
 	if err != nil {
+		if errors.Is(err, internalErrors.ErrCategoryNotFound) {
+			utils.ErrJSON(w, http.StatusBadRequest, err)
+
+			return
+		}
+
 		utils.ErrJSON(w, http.StatusInternalServerError, err)
 
 		return
@@ -0,0 +1,133 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+
+	internalErrors "github.com/srodrmendz/api-product-catalog/errors"
+	"github.com/srodrmendz/api-product-catalog/model"
+	"github.com/srodrmendz/api-product-catalog/utils"
+)
+
+// Add Image godoc
+// @Tags images
+// @Description Add an image after the product images, a primary image replaces the product primary one
+// @Accept  json
+// @Produce  json
+// @Param request body model.Image true "Request body"
+// @Param id path string true "id"
+// @Param If-Match header string false "product version the image is added to"
+// @Success 200 {object} model.Product
+// @Failure 400
+// @Failure 404
+// @Failure 409
+// @Failure 500
+// @Router /v1/{id}/images [post]
+func (a *App) addImage(w http.ResponseWriter, r *http.Request) {
+	builder := model.NewImageBuilder(r)
+
+	request, err := builder.Build()
+	if err != nil {
+		utils.ErrJSON(w, http.StatusBadRequest, err)
+
+		return
+	}
+
+	product, err := a.Services.ProductsService.AddImage(r.Context(), *request)
+
+	a.imageResponse(w, product, err)
+}
+
+// Remove Image godoc
+// @Tags images
+// @Description Remove a product image, the first image becomes the primary one when the primary one is removed
+// @Accept  json
+// @Produce  json
+// @Param id path string true "id"
+// @Param image_id path string true "image id"
+// @Param If-Match header string false "product version the image is removed from"
+// @Success 200 {object} model.Product
+// @Failure 400
+// @Failure 404
+// @Failure 409
+// @Failure 500
+// @Router /v1/{id}/images/{image_id} [delete]
+func (a *App) removeImage(w http.ResponseWriter, r *http.Request) {
+	builder := model.NewImageBuilder(r)
+
+	request, err := builder.Build()
+	if err != nil {
+		utils.ErrJSON(w, http.StatusBadRequest, err)
+
+		return
+	}
+
+	product, err := a.Services.ProductsService.RemoveImage(r.Context(), *request)
+
+	a.imageResponse(w, product, err)
+}
+
+// Reorder Images godoc
+// @Tags images
+// @Description Sort the product images on the requested ids order, primary_id changes the primary image when it is set
+// @Accept  json
+// @Produce  json
+// @Param request body model.ImageOrderRequest true "Request body"
+// @Param id path string true "id"
+// @Param If-Match header string false "product version the images are reordered on"
+// @Success 200 {object} model.Product
+// @Failure 400
+// @Failure 404
+// @Failure 409
+// @Failure 500
+// @Router /v1/{id}/images/order [put]
+func (a *App) reorderImages(w http.ResponseWriter, r *http.Request) {
+	builder := model.NewImageOrderBuilder(r)
+
+	request, err := builder.Build()
+	if err != nil {
+		utils.ErrJSON(w, http.StatusBadRequest, err)
+
+		return
+	}
+
+	product, err := a.Services.ProductsService.ReorderImages(r.Context(), *request)
+
+	a.imageResponse(w, product, err)
+}
+
+func (a *App) imageResponse(w http.ResponseWriter, product *model.Product, err error) {
+	if err != nil {
+		if errors.Is(err, internalErrors.ErrProductNotFound) || errors.Is(err, internalErrors.ErrImageNotFound) {
+			utils.ErrJSON(w, http.StatusNotFound, err)
+
+			return
+		}
+
+		if errors.Is(err, internalErrors.ErrImageLimit) || errors.Is(err, internalErrors.ErrImageOrder) {
+			utils.ErrJSON(w, http.StatusBadRequest, err)
+
+			return
+		}
+
+		if errors.Is(err, internalErrors.ErrVersionConflict) {
+			versionConflict(w, err)
+
+			return
+		}
+
+		if errors.Is(err, internalErrors.ErrImageAlreadyExist) {
+			utils.ErrJSON(w, http.StatusConflict, err)
+
+			return
+		}
+
+		utils.ErrJSON(w, http.StatusInternalServerError, err)
+
+		return
+	}
+
+	w.Header().Set("ETag", model.ETag(product.Version))
+
+	utils.DataJSON(w, http.StatusOK, product)
+}
@@ -0,0 +1,170 @@
+package server
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	internalErrors "github.com/srodrmendz/api-product-catalog/errors"
+	"github.com/srodrmendz/api-product-catalog/model"
+	"github.com/srodrmendz/api-product-catalog/service"
+	"github.com/stretchr/testify/assert"
+)
+
+// Test product image endpoints
+func TestServer_Images(t *testing.T) {
+	dataTable := []struct {
+		name            string
+		method          string
+		endpoint        string
+		body            io.Reader
+		productsService service.Service
+		expectedCode    int
+	}{
+		{
+			name:     "successfully add product image",
+			method:   http.MethodPost,
+			endpoint: "/v1/1/images",
+			body: mockRequest(model.Image{
+				URL:      "https://google.com/image1.png",
+				Alt:      map[string]string{"en": "front"},
+				Width:    800,
+				Height:   600,
+				MimeType: "image/png",
+			}),
+			productsService: &mockService{},
+			expectedCode:    http.StatusOK,
+		},
+		{
+			name:            "failed to add product image, url is not http",
+			method:          http.MethodPost,
+			endpoint:        "/v1/1/images",
+			body:            mockRequest(model.Image{URL: "ftp://google.com/image1.png"}),
+			productsService: &mockService{},
+			expectedCode:    http.StatusBadRequest,
+		},
+		{
+			name:            "failed to add product image, negative width",
+			method:          http.MethodPost,
+			endpoint:        "/v1/1/images",
+			body:            mockRequest(model.Image{URL: "https://google.com/image1.png", Width: -1}),
+			productsService: &mockService{},
+			expectedCode:    http.StatusBadRequest,
+		},
+		{
+			name:            "failed to add product image, incorrect alt locale",
+			method:          http.MethodPost,
+			endpoint:        "/v1/1/images",
+			body:            mockRequest(model.Image{URL: "https://google.com/image1.png", Alt: map[string]string{"english_": "front"}}),
+			productsService: &mockService{},
+			expectedCode:    http.StatusBadRequest,
+		},
+		{
+			name:     "failed to add product image, url already exist",
+			method:   http.MethodPost,
+			endpoint: "/v1/1/images",
+			body:     mockRequest(model.Image{URL: "https://google.com/image1.png"}),
+			productsService: &mockService{
+				err: internalErrors.ErrImageAlreadyExist,
+			},
+			expectedCode: http.StatusConflict,
+		},
+		{
+			name:     "failed to add product image, too many images",
+			method:   http.MethodPost,
+			endpoint: "/v1/1/images",
+			body:     mockRequest(model.Image{URL: "https://google.com/image1.png"}),
+			productsService: &mockService{
+				err: internalErrors.ErrImageLimit,
+			},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:            "successfully remove product image",
+			method:          http.MethodDelete,
+			endpoint:        "/v1/1/images/front",
+			productsService: &mockService{},
+			expectedCode:    http.StatusOK,
+		},
+		{
+			name:     "failed to remove product image, image not found",
+			method:   http.MethodDelete,
+			endpoint: "/v1/1/images/front",
+			productsService: &mockService{
+				err: internalErrors.ErrImageNotFound,
+			},
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:     "failed to remove product image, version conflict",
+			method:   http.MethodDelete,
+			endpoint: "/v1/1/images/front",
+			productsService: &mockService{
+				err: &internalErrors.VersionConflictError{Current: 2},
+			},
+			expectedCode: http.StatusConflict,
+		},
+		{
+			name:            "successfully reorder product images",
+			method:          http.MethodPut,
+			endpoint:        "/v1/1/images/order",
+			body:            mockRequest(model.ImageOrderRequest{ImageIDs: []string{"back", "front"}, PrimaryID: "back"}),
+			productsService: &mockService{},
+			expectedCode:    http.StatusOK,
+		},
+		{
+			name:            "failed to reorder product images, empty image ids",
+			method:          http.MethodPut,
+			endpoint:        "/v1/1/images/order",
+			body:            mockRequest(model.ImageOrderRequest{}),
+			productsService: &mockService{},
+			expectedCode:    http.StatusBadRequest,
+		},
+		{
+			name:     "failed to reorder product images, ids are not the product images",
+			method:   http.MethodPut,
+			endpoint: "/v1/1/images/order",
+			body:     mockRequest(model.ImageOrderRequest{ImageIDs: []string{"front"}}),
+			productsService: &mockService{
+				err: internalErrors.ErrImageOrder,
+			},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:     "failed to reorder product images, error on service",
+			method:   http.MethodPut,
+			endpoint: "/v1/1/images/order",
+			body:     mockRequest(model.ImageOrderRequest{ImageIDs: []string{"front"}}),
+			productsService: &mockService{
+				err: errors.New("error on service"),
+			},
+			expectedCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, dt := range dataTable {
+		t.Run(dt.name, func(t *testing.T) {
+			// Given
+			app := New(
+				dt.productsService,
+				mux.NewRouter(),
+				"",
+				"",
+				"",
+				"")
+
+			w := httptest.NewRecorder()
+
+			req := httptest.NewRequest(dt.method, dt.endpoint, dt.body)
+
+			// When
+			app.serveHTTP(w, req)
+
+			// Then
+			assert.Equal(t, dt.expectedCode, w.Code)
+		})
+	}
+}
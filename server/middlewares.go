@@ -1,9 +1,12 @@
 package server
 
 import (
+	"crypto/subtle"
 	"encoding/json"
 	"net/http"
+	"strings"
 
+	"github.com/srodrmendz/api-product-catalog/model"
 	"github.com/srodrmendz/api-product-catalog/utils"
 )
 
@@ -24,6 +27,19 @@ func (a *App) panicRecoveryMiddleware(next http.Handler) http.Handler {
 	})
 }
 
+// Middleware marking the requests with the admin token as admin requests
+func (a *App) adminMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+
+		if a.Config.AdminToken != "" && subtle.ConstantTimeCompare([]byte(token), []byte(a.Config.AdminToken)) == 1 {
+			r = r.WithContext(model.WithAdmin(r.Context()))
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
 func (a *App) serveHTTP(w http.ResponseWriter, req *http.Request) {
 	a.Router.ServeHTTP(w, req)
 }
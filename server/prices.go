@@ -0,0 +1,113 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	internalErrors "github.com/srodrmendz/api-product-catalog/errors"
+	"github.com/srodrmendz/api-product-catalog/model"
+	"github.com/srodrmendz/api-product-catalog/utils"
+)
+
+// Get Price Timeline godoc
+// @Tags prices
+// @Description Get product price timeline, its price changes and scheduled prices
+// @Accept  json
+// @Produce  json
+// @Param id path string true "id"
+// @Success 200 {object} model.PriceTimeline
+// @Failure 404
+// @Failure 500
+// @Router /v1/{id}/prices [get]
+func (a *App) getPriceTimeline(w http.ResponseWriter, r *http.Request) {
+	timeline, err := a.Services.ProductsService.GetPriceTimeline(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		if errors.Is(err, internalErrors.ErrProductNotFound) {
+			utils.ErrJSON(w, http.StatusNotFound, err)
+
+			return
+		}
+
+		utils.ErrJSON(w, http.StatusInternalServerError, err)
+
+		return
+	}
+
+	utils.DataJSON(w, http.StatusOK, timeline)
+}
+
+// Schedule Price godoc
+// @Tags prices
+// @Description Schedule product price from starts_at until ends_at, without ends_at it becomes the regular price once started
+// @Accept  json
+// @Produce  json
+// @Param request body model.ScheduledPrice true "Request body"
+// @Param id path string true "id"
+// @Param X-Actor header string false "who schedules the price"
+// @Success 201 {object} model.PriceTimeline
+// @Failure 400
+// @Failure 404
+// @Failure 500
+// @Router /v1/{id}/prices/schedule [post]
+func (a *App) schedulePrice(w http.ResponseWriter, r *http.Request) {
+	builder := model.NewSchedulePriceBuilder(r)
+
+	request, err := builder.Build()
+	if err != nil {
+		utils.ErrJSON(w, http.StatusBadRequest, err)
+
+		return
+	}
+
+	timeline, err := a.Services.ProductsService.SchedulePrice(r.Context(), *request)
+	if err != nil {
+		if errors.Is(err, internalErrors.ErrProductNotFound) {
+			utils.ErrJSON(w, http.StatusNotFound, err)
+
+			return
+		}
+
+		if errors.Is(err, internalErrors.ErrPriceScheduleVariants) || errors.Is(err, internalErrors.ErrBundlePricing) {
+			utils.ErrJSON(w, http.StatusBadRequest, err)
+
+			return
+		}
+
+		utils.ErrJSON(w, http.StatusInternalServerError, err)
+
+		return
+	}
+
+	utils.DataJSON(w, http.StatusCreated, timeline)
+}
+
+// Cancel Scheduled Price godoc
+// @Tags prices
+// @Description Cancel scheduled price that did not start yet
+// @Accept  json
+// @Produce  json
+// @Param id path string true "id"
+// @Param schedule_id path string true "scheduled price id"
+// @Success 200 {object} model.PriceTimeline
+// @Failure 404
+// @Failure 500
+// @Router /v1/{id}/prices/schedule/{schedule_id} [delete]
+func (a *App) cancelScheduledPrice(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	timeline, err := a.Services.ProductsService.CancelScheduledPrice(r.Context(), vars["id"], vars["schedule_id"])
+	if err != nil {
+		if errors.Is(err, internalErrors.ErrProductNotFound) || errors.Is(err, internalErrors.ErrScheduledPriceNotFound) {
+			utils.ErrJSON(w, http.StatusNotFound, err)
+
+			return
+		}
+
+		utils.ErrJSON(w, http.StatusInternalServerError, err)
+
+		return
+	}
+
+	utils.DataJSON(w, http.StatusOK, timeline)
+}
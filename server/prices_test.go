@@ -0,0 +1,147 @@
+package server
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	internalErrors "github.com/srodrmendz/api-product-catalog/errors"
+	"github.com/srodrmendz/api-product-catalog/model"
+	"github.com/srodrmendz/api-product-catalog/service"
+	"github.com/stretchr/testify/assert"
+)
+
+// Test price timeline and scheduled price endpoints
+func TestServer_Prices(t *testing.T) {
+	start := time.Now().Add(time.Hour)
+
+	end := start.Add(time.Hour)
+
+	dataTable := []struct {
+		name            string
+		method          string
+		endpoint        string
+		body            io.Reader
+		productsService service.Service
+		expectedCode    int
+	}{
+		{
+			name:     "failed to get price timeline, product not found",
+			method:   http.MethodGet,
+			endpoint: "/v1/1/prices",
+			productsService: &mockService{
+				err: internalErrors.ErrProductNotFound,
+			},
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:            "successfully get price timeline",
+			method:          http.MethodGet,
+			endpoint:        "/v1/1/prices",
+			productsService: &mockService{},
+			expectedCode:    http.StatusOK,
+		},
+		{
+			name:            "failed to schedule price, price invalid value",
+			method:          http.MethodPost,
+			endpoint:        "/v1/1/prices/schedule",
+			body:            mockRequest(model.ScheduledPrice{StartsAt: start}),
+			productsService: &mockService{},
+			expectedCode:    http.StatusBadRequest,
+		},
+		{
+			name:            "failed to schedule price, start in the past",
+			method:          http.MethodPost,
+			endpoint:        "/v1/1/prices/schedule",
+			body:            mockRequest(model.ScheduledPrice{Price: 100, StartsAt: time.Now().Add(-time.Hour)}),
+			productsService: &mockService{},
+			expectedCode:    http.StatusBadRequest,
+		},
+		{
+			name:            "failed to schedule price, end before start",
+			method:          http.MethodPost,
+			endpoint:        "/v1/1/prices/schedule",
+			body:            mockRequest(model.ScheduledPrice{Price: 100, StartsAt: end, EndsAt: &start}),
+			productsService: &mockService{},
+			expectedCode:    http.StatusBadRequest,
+		},
+		{
+			name:     "failed to schedule price, product has variants",
+			method:   http.MethodPost,
+			endpoint: "/v1/1/prices/schedule",
+			body:     mockRequest(model.ScheduledPrice{Price: 100, StartsAt: start}),
+			productsService: &mockService{
+				err: internalErrors.ErrPriceScheduleVariants,
+			},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:            "successfully schedule price",
+			method:          http.MethodPost,
+			endpoint:        "/v1/1/prices/schedule",
+			body:            mockRequest(model.ScheduledPrice{Price: 100, StartsAt: start, EndsAt: &end}),
+			productsService: &mockService{},
+			expectedCode:    http.StatusCreated,
+		},
+		{
+			name:     "failed to cancel scheduled price, scheduled price not found",
+			method:   http.MethodDelete,
+			endpoint: "/v1/1/prices/schedule/2",
+			productsService: &mockService{
+				err: internalErrors.ErrScheduledPriceNotFound,
+			},
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:     "failed to cancel scheduled price, error on service",
+			method:   http.MethodDelete,
+			endpoint: "/v1/1/prices/schedule/2",
+			productsService: &mockService{
+				err: errors.New("error on service"),
+			},
+			expectedCode: http.StatusInternalServerError,
+		},
+		{
+			name:            "successfully cancel scheduled price",
+			method:          http.MethodDelete,
+			endpoint:        "/v1/1/prices/schedule/2",
+			productsService: &mockService{},
+			expectedCode:    http.StatusOK,
+		},
+		{
+			name:            "failed to update product, price invalid value",
+			method:          http.MethodPut,
+			endpoint:        "/v1/1/",
+			body:            mockRequest(map[string]int{"qty": 1, "price": -1}),
+			productsService: &mockService{},
+			expectedCode:    http.StatusBadRequest,
+		},
+	}
+
+	for _, dt := range dataTable {
+		t.Run(dt.name, func(t *testing.T) {
+			// Given
+			app := New(
+				dt.productsService,
+				mux.NewRouter(),
+				"",
+				"",
+				"",
+				"")
+
+			w := httptest.NewRecorder()
+
+			req := httptest.NewRequest(dt.method, dt.endpoint, dt.body)
+
+			// When
+			app.serveHTTP(w, req)
+
+			// Then
+			assert.Equal(t, dt.expectedCode, w.Code)
+		})
+	}
+}
@@ -0,0 +1,172 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	internalErrors "github.com/srodrmendz/api-product-catalog/errors"
+	"github.com/srodrmendz/api-product-catalog/model"
+	"github.com/srodrmendz/api-product-catalog/utils"
+)
+
+// Get Relations godoc
+// @Tags relations
+// @Description Get the product relations sorted by type and position
+// @Accept  json
+// @Produce  json
+// @Param id path string true "id"
+// @Param type query string false "accessory, upsell, cross-sell or replacement-for, every type by default"
+// @Success 200 {array} model.Relation
+// @Failure 400
+// @Failure 404
+// @Failure 500
+// @Router /v1/{id}/relations [get]
+func (a *App) getRelations(w http.ResponseWriter, r *http.Request) {
+	relationType, err := model.ParseRelationType(r.URL.Query().Get("type"))
+	if err != nil {
+		utils.ErrJSON(w, http.StatusBadRequest, err)
+
+		return
+	}
+
+	relations, err := a.Services.ProductsService.GetRelations(r.Context(), mux.Vars(r)["id"], relationType)
+	if err != nil {
+		if errors.Is(err, internalErrors.ErrProductNotFound) {
+			utils.ErrJSON(w, http.StatusNotFound, err)
+
+			return
+		}
+
+		utils.ErrJSON(w, http.StatusInternalServerError, err)
+
+		return
+	}
+
+	utils.DataJSON(w, http.StatusOK, relations)
+}
+
+// Add Relation godoc
+// @Tags relations
+// @Description Link a related product after the product relations of its type, a bidirectional relation is linked on the related product too
+// @Accept  json
+// @Produce  json
+// @Param request body model.Relation true "Request body"
+// @Param id path string true "id"
+// @Param If-Match header string false "product version the relation is added to"
+// @Success 200 {object} model.Product
+// @Failure 400
+// @Failure 404
+// @Failure 409
+// @Failure 500
+// @Router /v1/{id}/relations [post]
+func (a *App) addRelation(w http.ResponseWriter, r *http.Request) {
+	builder := model.NewRelationBuilder(r)
+
+	request, err := builder.Build()
+	if err != nil {
+		utils.ErrJSON(w, http.StatusBadRequest, err)
+
+		return
+	}
+
+	product, err := a.Services.ProductsService.AddRelation(r.Context(), *request)
+
+	a.relationResponse(w, product, err)
+}
+
+// Remove Relation godoc
+// @Tags relations
+// @Description Unlink a related product, a bidirectional relation is unlinked from the related product too
+// @Accept  json
+// @Produce  json
+// @Param id path string true "id"
+// @Param type path string true "relation type"
+// @Param product_id path string true "related product id"
+// @Param If-Match header string false "product version the relation is removed from"
+// @Success 200 {object} model.Product
+// @Failure 400
+// @Failure 404
+// @Failure 409
+// @Failure 500
+// @Router /v1/{id}/relations/{type}/{product_id} [delete]
+func (a *App) removeRelation(w http.ResponseWriter, r *http.Request) {
+	builder := model.NewRelationBuilder(r)
+
+	request, err := builder.Build()
+	if err != nil {
+		utils.ErrJSON(w, http.StatusBadRequest, err)
+
+		return
+	}
+
+	product, err := a.Services.ProductsService.RemoveRelation(r.Context(), *request)
+
+	a.relationResponse(w, product, err)
+}
+
+// Reorder Relations godoc
+// @Tags relations
+// @Description Sort the product relations of a type on the requested related product ids order
+// @Accept  json
+// @Produce  json
+// @Param request body model.RelationOrderRequest true "Request body"
+// @Param id path string true "id"
+// @Param type path string true "relation type"
+// @Param If-Match header string false "product version the relations are reordered on"
+// @Success 200 {object} model.Product
+// @Failure 400
+// @Failure 404
+// @Failure 409
+// @Failure 500
+// @Router /v1/{id}/relations/{type}/order [put]
+func (a *App) reorderRelations(w http.ResponseWriter, r *http.Request) {
+	builder := model.NewRelationOrderBuilder(r)
+
+	request, err := builder.Build()
+	if err != nil {
+		utils.ErrJSON(w, http.StatusBadRequest, err)
+
+		return
+	}
+
+	product, err := a.Services.ProductsService.ReorderRelations(r.Context(), *request)
+
+	a.relationResponse(w, product, err)
+}
+
+func (a *App) relationResponse(w http.ResponseWriter, product *model.Product, err error) {
+	if err != nil {
+		if errors.Is(err, internalErrors.ErrProductNotFound) || errors.Is(err, internalErrors.ErrRelationNotFound) {
+			utils.ErrJSON(w, http.StatusNotFound, err)
+
+			return
+		}
+
+		if errors.Is(err, internalErrors.ErrRelatedNotFound) || errors.Is(err, internalErrors.ErrRelationOrder) {
+			utils.ErrJSON(w, http.StatusBadRequest, err)
+
+			return
+		}
+
+		if errors.Is(err, internalErrors.ErrVersionConflict) {
+			versionConflict(w, err)
+
+			return
+		}
+
+		if errors.Is(err, internalErrors.ErrRelationAlreadyExist) {
+			utils.ErrJSON(w, http.StatusConflict, err)
+
+			return
+		}
+
+		utils.ErrJSON(w, http.StatusInternalServerError, err)
+
+		return
+	}
+
+	w.Header().Set("ETag", model.ETag(product.Version))
+
+	utils.DataJSON(w, http.StatusOK, product)
+}
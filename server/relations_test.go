@@ -0,0 +1,186 @@
+package server
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	internalErrors "github.com/srodrmendz/api-product-catalog/errors"
+	"github.com/srodrmendz/api-product-catalog/model"
+	"github.com/srodrmendz/api-product-catalog/service"
+	"github.com/stretchr/testify/assert"
+)
+
+// Test product relation endpoints
+func TestServer_Relations(t *testing.T) {
+	dataTable := []struct {
+		name            string
+		method          string
+		endpoint        string
+		body            io.Reader
+		productsService service.Service
+		expectedCode    int
+	}{
+		{
+			name:            "successfully get product relations",
+			method:          http.MethodGet,
+			endpoint:        "/v1/1/relations?type=accessory",
+			productsService: &mockService{},
+			expectedCode:    http.StatusOK,
+		},
+		{
+			name:            "failed to get product relations, incorrect type",
+			method:          http.MethodGet,
+			endpoint:        "/v1/1/relations?type=fake",
+			productsService: &mockService{},
+			expectedCode:    http.StatusBadRequest,
+		},
+		{
+			name:     "failed to get product relations, product not found",
+			method:   http.MethodGet,
+			endpoint: "/v1/1/relations",
+			productsService: &mockService{
+				err: internalErrors.ErrProductNotFound,
+			},
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:            "successfully add product relation",
+			method:          http.MethodPost,
+			endpoint:        "/v1/1/relations",
+			body:            mockRequest(model.Relation{ProductID: "2", Type: model.RelationUpsell, Bidirectional: true}),
+			productsService: &mockService{},
+			expectedCode:    http.StatusOK,
+		},
+		{
+			name:            "failed to add product relation, missing type",
+			method:          http.MethodPost,
+			endpoint:        "/v1/1/relations",
+			body:            mockRequest(model.Relation{ProductID: "2"}),
+			productsService: &mockService{},
+			expectedCode:    http.StatusBadRequest,
+		},
+		{
+			name:            "failed to add product relation, related to itself",
+			method:          http.MethodPost,
+			endpoint:        "/v1/1/relations",
+			body:            mockRequest(model.Relation{ProductID: "1", Type: model.RelationAccessory}),
+			productsService: &mockService{},
+			expectedCode:    http.StatusBadRequest,
+		},
+		{
+			name:     "failed to add product relation, related product not found",
+			method:   http.MethodPost,
+			endpoint: "/v1/1/relations",
+			body:     mockRequest(model.Relation{ProductID: "2", Type: model.RelationAccessory}),
+			productsService: &mockService{
+				err: internalErrors.ErrRelatedNotFound,
+			},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:     "failed to add product relation, relation already exist",
+			method:   http.MethodPost,
+			endpoint: "/v1/1/relations",
+			body:     mockRequest(model.Relation{ProductID: "2", Type: model.RelationAccessory}),
+			productsService: &mockService{
+				err: internalErrors.ErrRelationAlreadyExist,
+			},
+			expectedCode: http.StatusConflict,
+		},
+		{
+			name:            "successfully remove product relation",
+			method:          http.MethodDelete,
+			endpoint:        "/v1/1/relations/cross-sell/2",
+			productsService: &mockService{},
+			expectedCode:    http.StatusOK,
+		},
+		{
+			name:            "failed to remove product relation, incorrect type",
+			method:          http.MethodDelete,
+			endpoint:        "/v1/1/relations/fake/2",
+			productsService: &mockService{},
+			expectedCode:    http.StatusBadRequest,
+		},
+		{
+			name:     "failed to remove product relation, relation not found",
+			method:   http.MethodDelete,
+			endpoint: "/v1/1/relations/cross-sell/2",
+			productsService: &mockService{
+				err: internalErrors.ErrRelationNotFound,
+			},
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:     "failed to remove product relation, version conflict",
+			method:   http.MethodDelete,
+			endpoint: "/v1/1/relations/cross-sell/2",
+			productsService: &mockService{
+				err: &internalErrors.VersionConflictError{Current: 2},
+			},
+			expectedCode: http.StatusConflict,
+		},
+		{
+			name:            "successfully reorder product relations",
+			method:          http.MethodPut,
+			endpoint:        "/v1/1/relations/accessory/order",
+			body:            mockRequest(model.RelationOrderRequest{ProductIDs: []string{"3", "2"}}),
+			productsService: &mockService{},
+			expectedCode:    http.StatusOK,
+		},
+		{
+			name:            "failed to reorder product relations, empty product ids",
+			method:          http.MethodPut,
+			endpoint:        "/v1/1/relations/accessory/order",
+			body:            mockRequest(model.RelationOrderRequest{}),
+			productsService: &mockService{},
+			expectedCode:    http.StatusBadRequest,
+		},
+		{
+			name:     "failed to reorder product relations, ids are not the related products",
+			method:   http.MethodPut,
+			endpoint: "/v1/1/relations/accessory/order",
+			body:     mockRequest(model.RelationOrderRequest{ProductIDs: []string{"2"}}),
+			productsService: &mockService{
+				err: internalErrors.ErrRelationOrder,
+			},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:     "failed to reorder product relations, error on service",
+			method:   http.MethodPut,
+			endpoint: "/v1/1/relations/accessory/order",
+			body:     mockRequest(model.RelationOrderRequest{ProductIDs: []string{"2"}}),
+			productsService: &mockService{
+				err: errors.New("error on service"),
+			},
+			expectedCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, dt := range dataTable {
+		t.Run(dt.name, func(t *testing.T) {
+			// Given
+			app := New(
+				dt.productsService,
+				mux.NewRouter(),
+				"",
+				"",
+				"",
+				"")
+
+			w := httptest.NewRecorder()
+
+			req := httptest.NewRequest(dt.method, dt.endpoint, dt.body)
+
+			// When
+			app.serveHTTP(w, req)
+
+			// Then
+			assert.Equal(t, dt.expectedCode, w.Code)
+		})
+	}
+}
@@ -10,15 +10,16 @@ import (
 	swagger "github.com/swaggo/http-swagger/v2"
 )
 
-// Initialize all dependencies
-func New(productsService service.Service, router *mux.Router, path string, version string, buildDate string) *App {
+// Initialize all dependencies, requests with the admin token as bearer token are admin requests
+func New(productsService service.Service, router *mux.Router, path string, version string, buildDate string, adminToken string) *App {
 	app := &App{
 		Services: services{
 			ProductsService: productsService,
 		},
 		Config: config{
-			Version:   version,
-			BuildDate: buildDate,
+			Version:    version,
+			BuildDate:  buildDate,
+			AdminToken: adminToken,
 		},
 		Router: router,
 	}
@@ -26,6 +27,9 @@ func New(productsService service.Service, router *mux.Router, path string, versi
 	// Initializing panic recovery middleware
 	router.Use(app.panicRecoveryMiddleware)
 
+	// Initializing admin authentication middleware
+	router.Use(app.adminMiddleware)
+
 	subrouter := app.Router.PathPrefix(path).Subrouter()
 
 	// Initializing healthcheck route
@@ -37,6 +41,39 @@ func New(productsService service.Service, router *mux.Router, path string, versi
 	// Initializing search products route
 	subrouter.HandleFunc("/v1", app.search).Methods(http.MethodGet)
 
+	// Initializing create category route
+	subrouter.HandleFunc("/v1/categories", app.createCategory).Methods(http.MethodPost)
+
+	// Initializing list categories route
+	subrouter.HandleFunc("/v1/categories", app.listCategories).Methods(http.MethodGet)
+
+	// Initializing get category by id
+	subrouter.HandleFunc("/v1/categories/{id}/", app.getCategory).Methods(http.MethodGet)
+
+	// Initializing update category
+	subrouter.HandleFunc("/v1/categories/{id}/", app.updateCategory).Methods(http.MethodPut)
+
+	// Initializing delete category
+	subrouter.HandleFunc("/v1/categories/{id}/", app.deleteCategory).Methods(http.MethodDelete)
+
+	// Initializing create brand route
+	subrouter.HandleFunc("/v1/brands", app.createBrand).Methods(http.MethodPost)
+
+	// Initializing list brands route
+	subrouter.HandleFunc("/v1/brands", app.listBrands).Methods(http.MethodGet)
+
+	// Initializing get brand by id
+	subrouter.HandleFunc("/v1/brands/{id}/", app.getBrand).Methods(http.MethodGet)
+
+	// Initializing update brand
+	subrouter.HandleFunc("/v1/brands/{id}/", app.updateBrand).Methods(http.MethodPut)
+
+	// Initializing delete brand
+	subrouter.HandleFunc("/v1/brands/{id}/", app.deleteBrand).Methods(http.MethodDelete)
+
+	// Initializing tag counts route
+	subrouter.HandleFunc("/v1/tags", app.tagCounts).Methods(http.MethodGet)
+
 	// Initializing get product by id
 	subrouter.HandleFunc("/v1/{id}/", app.getByID).Methods(http.MethodGet)
 
@@ -46,9 +83,90 @@ func New(productsService service.Service, router *mux.Router, path string, versi
 	// Initializing update product
 	subrouter.HandleFunc("/v1/{id}/", app.update).Methods(http.MethodPut)
 
+	// Initializing restore deleted product
+	subrouter.HandleFunc("/v1/{id}/restore", app.restore).Methods(http.MethodPost)
+
+	// Initializing publish product
+	subrouter.HandleFunc("/v1/{id}/publish", app.publish).Methods(http.MethodPost)
+
+	// Initializing unpublish product
+	subrouter.HandleFunc("/v1/{id}/unpublish", app.unpublish).Methods(http.MethodPost)
+
+	// Initializing archive product
+	subrouter.HandleFunc("/v1/{id}/archive", app.archive).Methods(http.MethodPost)
+
+	// Initializing unarchive product
+	subrouter.HandleFunc("/v1/{id}/unarchive", app.unarchive).Methods(http.MethodPost)
+
+	// Initializing set product translation
+	subrouter.HandleFunc("/v1/{id}/translations/{locale}", app.setTranslation).Methods(http.MethodPut)
+
+	// Initializing remove product translation
+	subrouter.HandleFunc("/v1/{id}/translations/{locale}", app.removeTranslation).Methods(http.MethodDelete)
+
+	// Initializing add product image
+	subrouter.HandleFunc("/v1/{id}/images", app.addImage).Methods(http.MethodPost)
+
+	// Initializing reorder product images
+	subrouter.HandleFunc("/v1/{id}/images/order", app.reorderImages).Methods(http.MethodPut)
+
+	// Initializing remove product image
+	subrouter.HandleFunc("/v1/{id}/images/{image_id}", app.removeImage).Methods(http.MethodDelete)
+
+	// Initializing change product slug
+	subrouter.HandleFunc("/v1/{id}/slug", app.changeSlug).Methods(http.MethodPut)
+
+	// Initializing get product relations
+	subrouter.HandleFunc("/v1/{id}/relations", app.getRelations).Methods(http.MethodGet)
+
+	// Initializing add product relation
+	subrouter.HandleFunc("/v1/{id}/relations", app.addRelation).Methods(http.MethodPost)
+
+	// Initializing reorder product relations
+	subrouter.HandleFunc("/v1/{id}/relations/{type}/order", app.reorderRelations).Methods(http.MethodPut)
+
+	// Initializing remove product relation
+	subrouter.HandleFunc("/v1/{id}/relations/{type}/{product_id}", app.removeRelation).Methods(http.MethodDelete)
+
+	// Initializing record product stock movement
+	subrouter.HandleFunc("/v1/{id}/stock/movements", app.recordMovement).Methods(http.MethodPost)
+
+	// Initializing list product stock movements
+	subrouter.HandleFunc("/v1/{id}/stock/movements", app.listStockMovements).Methods(http.MethodGet)
+
+	// Initializing increment product stock
+	subrouter.HandleFunc("/v1/{id}/stock/increment", app.incrementStock).Methods(http.MethodPost)
+
+	// Initializing decrement product stock
+	subrouter.HandleFunc("/v1/{id}/stock/decrement", app.decrementStock).Methods(http.MethodPost)
+
+	// Initializing verify product stock
+	subrouter.HandleFunc("/v1/{id}/stock/ledger", app.verifyStock).Methods(http.MethodGet)
+
+	// Initializing rebuild product stock
+	subrouter.HandleFunc("/v1/{id}/stock/rebuild", app.rebuildStock).Methods(http.MethodPost)
+
+	// Initializing get product price timeline
+	subrouter.HandleFunc("/v1/{id}/prices", app.getPriceTimeline).Methods(http.MethodGet)
+
+	// Initializing schedule product price
+	subrouter.HandleFunc("/v1/{id}/prices/schedule", app.schedulePrice).Methods(http.MethodPost)
+
+	// Initializing cancel scheduled product price
+	subrouter.HandleFunc("/v1/{id}/prices/schedule/{schedule_id}", app.cancelScheduledPrice).Methods(http.MethodDelete)
+
 	// Initializing get product by sku
 	subrouter.HandleFunc("/v1/sku/{sku}/", app.getBySKU).Methods(http.MethodGet)
 
+	// Initializing increment product stock by sku
+	subrouter.HandleFunc("/v1/sku/{sku}/stock/increment", app.incrementStock).Methods(http.MethodPost)
+
+	// Initializing decrement product stock by sku
+	subrouter.HandleFunc("/v1/sku/{sku}/stock/decrement", app.decrementStock).Methods(http.MethodPost)
+
+	// Initializing get product by slug
+	subrouter.HandleFunc("/v1/slug/{slug}/", app.getBySlug).Methods(http.MethodGet)
+
 	// Initializing swagger route
 	router.PathPrefix(fmt.Sprintf("%s/docs", path)).Handler(swagger.WrapHandler)
 
@@ -9,7 +9,9 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/gorilla/mux"
 	internalErrors "github.com/srodrmendz/api-product-catalog/errors"
@@ -32,6 +34,7 @@ func TestServer_HealthCheck(t *testing.T) {
 		"",
 		version,
 		buildDate,
+		"",
 	)
 
 	endpoint := "/health-check"
@@ -104,6 +107,163 @@ func TestServer_Create(t *testing.T) {
 				err: errors.New("error on service"),
 			},
 		},
+		{
+			name: "failed to create product, attribute value is not a string, number or bool",
+			body: mockRequest(model.Product{
+				Name:       "Name1",
+				Sku:        "Sku1",
+				Price:      500,
+				Attributes: map[string]interface{}{"material": []string{"cotton"}},
+			}),
+			expectedCode:    http.StatusBadRequest,
+			productsService: &mockService{},
+		},
+		{
+			name: "failed to create product, attributes do not follow the category definitions",
+			body: mockRequest(model.Product{
+				Name:       "Name1",
+				Sku:        "Sku1",
+				Price:      500,
+				Attributes: map[string]interface{}{"material": "cotton"},
+			}),
+			expectedCode: http.StatusBadRequest,
+			productsService: &mockService{
+				err: internalErrors.ErrAttributeInvalid,
+			},
+		},
+		{
+			name: "failed to create product, image url is not an absolute http url",
+			body: mockRequest(model.Product{
+				Name:   "Name1",
+				Sku:    "Sku1",
+				Price:  500,
+				Images: []model.Image{{URL: "/images/image1.png"}},
+			}),
+			expectedCode:    http.StatusBadRequest,
+			productsService: &mockService{},
+		},
+		{
+			name: "failed to create product, too many images",
+			body: mockRequest(model.Product{
+				Name:   "Name1",
+				Sku:    "Sku1",
+				Price:  500,
+				Images: []model.Image{{URL: "https://google.com/image1.png"}},
+			}),
+			expectedCode: http.StatusBadRequest,
+			productsService: &mockService{
+				err: internalErrors.ErrImageLimit,
+			},
+		},
+		{
+			name:            "successfully create product with images given by their url",
+			body:            strings.NewReader(`{"name": "Name1", "sku": "Sku1", "price": 500, "images": ["https://google.com/image1.png"]}`),
+			expectedCode:    http.StatusCreated,
+			productsService: &mockService{},
+		},
+		{
+			name: "failed to create product, negative weight",
+			body: mockRequest(model.Product{
+				Name:   "Name1",
+				Sku:    "Sku1",
+				Price:  500,
+				Weight: &model.Weight{Value: -1, Unit: model.Kilogram},
+			}),
+			expectedCode:    http.StatusBadRequest,
+			productsService: &mockService{},
+		},
+		{
+			name:            "failed to create product, unknown weight unit",
+			body:            strings.NewReader(`{"name": "Name1", "sku": "Sku1", "price": 500, "weight": {"value": 1, "unit": "stone"}}`),
+			expectedCode:    http.StatusBadRequest,
+			productsService: &mockService{},
+		},
+		{
+			name: "failed to create product, dimensions without height",
+			body: mockRequest(model.Product{
+				Name:       "Name1",
+				Sku:        "Sku1",
+				Price:      500,
+				Dimensions: &model.Dimensions{Length: 10, Width: 5, Unit: model.Inch},
+			}),
+			expectedCode:    http.StatusBadRequest,
+			productsService: &mockService{},
+		},
+		{
+			name: "failed to create product, unknown shipping class",
+			body: mockRequest(model.Product{
+				Name:          "Name1",
+				Sku:           "Sku1",
+				Price:         500,
+				ShippingClass: "express",
+			}),
+			expectedCode:    http.StatusBadRequest,
+			productsService: &mockService{},
+		},
+		{
+			name:            "successfully create product with weight and dimensions",
+			body:            strings.NewReader(`{"name": "Name1", "sku": "Sku1", "price": 500, "weight": {"value": 12, "unit": "oz"}, "dimensions": {"length": 10, "width": 5, "height": 1, "unit": "in"}, "shipping_class": "fragile"}`),
+			expectedCode:    http.StatusCreated,
+			productsService: &mockService{},
+		},
+		{
+			name: "failed to create product, repeated tags",
+			body: mockRequest(model.Product{
+				Name:  "Name1",
+				Sku:   "Sku1",
+				Price: 500,
+				Tags:  []string{"sale", "Sale"},
+			}),
+			expectedCode:    http.StatusBadRequest,
+			productsService: &mockService{},
+		},
+		{
+			name: "failed to create product, brand not found",
+			body: mockRequest(model.Product{
+				Name:    "Name1",
+				Sku:     "Sku1",
+				Price:   500,
+				BrandID: "fake",
+			}),
+			expectedCode: http.StatusBadRequest,
+			productsService: &mockService{
+				err: internalErrors.ErrBrandNotFound,
+			},
+		},
+		{
+			name: "failed to create product, bundle without components",
+			body: mockRequest(model.Product{
+				Name:   "Name1",
+				Sku:    "Sku1",
+				Price:  500,
+				Bundle: &model.Bundle{Pricing: model.BundlePricingFixed},
+			}),
+			expectedCode:    http.StatusBadRequest,
+			productsService: &mockService{},
+		},
+		{
+			name: "failed to create product, bundle priced on its components with a price",
+			body: mockRequest(model.Product{
+				Name:   "Name1",
+				Sku:    "Sku1",
+				Price:  500,
+				Bundle: &model.Bundle{Components: []model.BundleComponent{{ProductID: "1", Qty: 1}}, Pricing: model.BundlePricingComponents},
+			}),
+			expectedCode:    http.StatusBadRequest,
+			productsService: &mockService{},
+		},
+		{
+			name: "failed to create product, bundle component not found",
+			body: mockRequest(model.Product{
+				Name:   "Name1",
+				Sku:    "Sku1",
+				Bundle: &model.Bundle{Components: []model.BundleComponent{{ProductID: "fake", Qty: 1}}, Pricing: model.BundlePricingComponents},
+			}),
+			expectedCode: http.StatusBadRequest,
+			productsService: &mockService{
+				err: internalErrors.ErrComponentNotFound,
+			},
+		},
 		{
 			name: "failed to create product, product sku already exist",
 			body: mockRequest(model.Product{
@@ -135,6 +295,7 @@ func TestServer_Create(t *testing.T) {
 			mux.NewRouter(),
 			"",
 			"",
+			"",
 			"")
 
 		endpoint := "/v1"
@@ -155,6 +316,7 @@ func TestServer_Create(t *testing.T) {
 func TestServer_GetByID(t *testing.T) {
 	dataTable := []struct {
 		name            string
+		endpoint        string
 		productsService service.Service
 		expectedCode    int
 	}{
@@ -172,11 +334,61 @@ func TestServer_GetByID(t *testing.T) {
 				err: internalErrors.ErrProductNotFound,
 			},
 		},
+		{
+			name:            "failed to get product, incorrect currency",
+			endpoint:        "/v1/1/?currency=euro",
+			expectedCode:    http.StatusBadRequest,
+			productsService: &mockService{},
+		},
+		{
+			name:            "failed to get product, incorrect units",
+			endpoint:        "/v1/1/?units=nautical",
+			expectedCode:    http.StatusBadRequest,
+			productsService: &mockService{},
+		},
+		{
+			name:         "failed to get product, price not available on currency",
+			endpoint:     "/v1/1/?currency=EUR",
+			expectedCode: http.StatusBadRequest,
+			productsService: &mockService{
+				err: internalErrors.ErrPriceNotAvailable,
+			},
+		},
 		{
 			name:            "successfully get product",
 			expectedCode:    http.StatusOK,
 			productsService: &mockService{},
 		},
+		{
+			name:            "successfully get product on currency",
+			endpoint:        "/v1/1/?currency=eur",
+			expectedCode:    http.StatusOK,
+			productsService: &mockService{},
+		},
+		{
+			name:            "failed to get product, incorrect locale format",
+			endpoint:        "/v1/1/?locale=spanish_",
+			expectedCode:    http.StatusBadRequest,
+			productsService: &mockService{},
+		},
+		{
+			name:            "successfully get product on locale",
+			endpoint:        "/v1/1/?locale=es-ar",
+			expectedCode:    http.StatusOK,
+			productsService: &mockService{},
+		},
+		{
+			name:            "successfully get product with its relations expanded",
+			endpoint:        "/v1/1/?expand=relations",
+			expectedCode:    http.StatusOK,
+			productsService: &mockService{},
+		},
+		{
+			name:            "failed to get product, incorrect expand",
+			endpoint:        "/v1/1/?expand=variants",
+			expectedCode:    http.StatusBadRequest,
+			productsService: &mockService{},
+		},
 	}
 
 	for _, dt := range dataTable {
@@ -186,10 +398,15 @@ func TestServer_GetByID(t *testing.T) {
 			mux.NewRouter(),
 			"",
 			"",
+			"",
 			"")
 
 		endpoint := "/v1/1/"
 
+		if dt.endpoint != "" {
+			endpoint = dt.endpoint
+		}
+
 		w := httptest.NewRecorder()
 
 		req := httptest.NewRequest(http.MethodGet, endpoint, nil)
@@ -237,6 +454,7 @@ func TestServer_GetBySKU(t *testing.T) {
 			mux.NewRouter(),
 			"",
 			"",
+			"",
 			"")
 
 		endpoint := "/v1/sku/1/"
@@ -259,6 +477,7 @@ func TestServer_Delete(t *testing.T) {
 		name            string
 		productsService service.Service
 		expectedCode    int
+		ifMatch         string
 	}{
 		{
 			name:         "failed to delete product, error on service",
@@ -267,11 +486,45 @@ func TestServer_Delete(t *testing.T) {
 				err: errors.New("error on service"),
 			},
 		},
+		{
+			name:         "failed to delete product, product not found",
+			expectedCode: http.StatusNotFound,
+			productsService: &mockService{
+				err: internalErrors.ErrProductNotFound,
+			},
+		},
+		{
+			name:         "failed to delete product, product is a bundle component",
+			expectedCode: http.StatusConflict,
+			productsService: &mockService{
+				err: internalErrors.ErrProductInBundle,
+			},
+		},
+		{
+			name:         "failed to delete product, version conflict",
+			expectedCode: http.StatusConflict,
+			productsService: &mockService{
+				err: &internalErrors.VersionConflictError{Current: 2},
+			},
+			ifMatch: `"1"`,
+		},
+		{
+			name:            "failed to delete product, incorrect If-Match format",
+			expectedCode:    http.StatusBadRequest,
+			productsService: &mockService{},
+			ifMatch:         "fake",
+		},
 		{
 			name:            "successfully remove product",
 			expectedCode:    http.StatusNoContent,
 			productsService: &mockService{},
 		},
+		{
+			name:            "successfully remove product on its version",
+			expectedCode:    http.StatusNoContent,
+			productsService: &mockService{},
+			ifMatch:         `"1"`,
+		},
 	}
 
 	for _, dt := range dataTable {
@@ -281,6 +534,7 @@ func TestServer_Delete(t *testing.T) {
 			mux.NewRouter(),
 			"",
 			"",
+			"",
 			"")
 
 		endpoint := "/v1/1/"
@@ -289,6 +543,60 @@ func TestServer_Delete(t *testing.T) {
 
 		req := httptest.NewRequest(http.MethodDelete, endpoint, nil)
 
+		req.Header.Set("If-Match", dt.ifMatch)
+
+		// When
+		app.serveHTTP(w, req)
+
+		// Then
+		assert.Equal(t, dt.expectedCode, w.Code)
+	}
+}
+
+// Test Restore endpoint
+func TestServer_Restore(t *testing.T) {
+	dataTable := []struct {
+		name            string
+		productsService service.Service
+		expectedCode    int
+	}{
+		{
+			name:         "failed to restore product, error on service",
+			expectedCode: http.StatusInternalServerError,
+			productsService: &mockService{
+				err: errors.New("error on service"),
+			},
+		},
+		{
+			name:         "failed to restore product, product not found",
+			expectedCode: http.StatusNotFound,
+			productsService: &mockService{
+				err: internalErrors.ErrProductNotFound,
+			},
+		},
+		{
+			name:            "successfully restore product",
+			expectedCode:    http.StatusOK,
+			productsService: &mockService{},
+		},
+	}
+
+	for _, dt := range dataTable {
+		// Given
+		app := New(
+			dt.productsService,
+			mux.NewRouter(),
+			"",
+			"",
+			"",
+			"")
+
+		endpoint := "/v1/1/restore"
+
+		w := httptest.NewRecorder()
+
+		req := httptest.NewRequest(http.MethodPost, endpoint, nil)
+
 		// When
 		app.serveHTTP(w, req)
 
@@ -304,6 +612,7 @@ func TestServer_Update(t *testing.T) {
 		body            io.Reader
 		productsService service.Service
 		expectedCode    int
+		ifMatch         string
 	}{
 		{
 			name:            "failed to update product, incorrect request body format",
@@ -312,10 +621,61 @@ func TestServer_Update(t *testing.T) {
 			productsService: &mockService{},
 		},
 		{
-			name: "successfully update product",
-			body: mockRequest(model.UpdateRequest{
-				Qty: 100,
-			}),
+			name:            "failed to update product, incorrect If-Match format",
+			body:            mockRequest(map[string]any{"qty": 100}),
+			expectedCode:    http.StatusBadRequest,
+			productsService: &mockService{},
+			ifMatch:         `"one"`,
+		},
+		{
+			name:            "successfully update product with a weak If-Match",
+			body:            mockRequest(map[string]any{"qty": 100}),
+			expectedCode:    http.StatusCreated,
+			productsService: &mockService{},
+			ifMatch:         `W/"1"`,
+		},
+		{
+			name:         "failed to update product, product not found",
+			body:         mockRequest(map[string]any{"qty": 100}),
+			expectedCode: http.StatusNotFound,
+			productsService: &mockService{
+				err: internalErrors.ErrProductNotFound,
+			},
+		},
+		{
+			name:         "failed to update product, variant not found",
+			body:         mockRequest(map[string]any{"qty": 100, "variant_sku": "sku-m"}),
+			expectedCode: http.StatusNotFound,
+			productsService: &mockService{
+				err: internalErrors.ErrVariantNotFound,
+			},
+		},
+		{
+			name:         "failed to update product, variant sku required",
+			body:         mockRequest(map[string]any{"qty": 100}),
+			expectedCode: http.StatusBadRequest,
+			productsService: &mockService{
+				err: internalErrors.ErrVariantRequired,
+			},
+		},
+		{
+			name:         "failed to update product, version conflict",
+			body:         mockRequest(map[string]any{"qty": 100}),
+			expectedCode: http.StatusConflict,
+			productsService: &mockService{
+				err: &internalErrors.VersionConflictError{Current: 2},
+			},
+			ifMatch: `"1"`,
+		},
+		{
+			name:            "successfully update product without qty",
+			body:            mockRequest(map[string]any{"tags": []string{"sale"}}),
+			expectedCode:    http.StatusCreated,
+			productsService: &mockService{},
+		},
+		{
+			name:            "successfully update product",
+			body:            mockRequest(map[string]any{"qty": 100}),
 			expectedCode:    http.StatusCreated,
 			productsService: &mockService{},
 		},
@@ -328,6 +688,7 @@ func TestServer_Update(t *testing.T) {
 			mux.NewRouter(),
 			"",
 			"",
+			"",
 			"")
 
 		endpoint := "/v1/1/"
@@ -336,6 +697,8 @@ func TestServer_Update(t *testing.T) {
 
 		req := httptest.NewRequest(http.MethodPut, endpoint, dt.body)
 
+		req.Header.Set("If-Match", dt.ifMatch)
+
 		// When
 		app.serveHTTP(w, req)
 
@@ -352,6 +715,8 @@ func TestServer_Search(t *testing.T) {
 		expectedCode    int
 		limit           string
 		offset          string
+		cursor          string
+		filters         string
 	}{
 		{
 			name:            "failed to search product, incorrect limit format",
@@ -381,6 +746,85 @@ func TestServer_Search(t *testing.T) {
 			limit:           "10",
 			offset:          "-20",
 		},
+		{
+			name:            "failed to search product, incorrect cursor format",
+			expectedCode:    http.StatusBadRequest,
+			productsService: &mockService{},
+			limit:           "10",
+			cursor:          "fake",
+		},
+		{
+			name:            "failed to search product, incorrect attribute range format",
+			expectedCode:    http.StatusBadRequest,
+			productsService: &mockService{},
+			limit:           "10",
+			offset:          "0",
+			filters:         "&attr.screen_size_gte=big",
+		},
+		{
+			name:            "failed to search product, incorrect attribute name format",
+			expectedCode:    http.StatusBadRequest,
+			productsService: &mockService{},
+			limit:           "10",
+			offset:          "0",
+			filters:         "&attr.Screen.Size=13",
+		},
+		{
+			name:            "failed to search product, incorrect locale format",
+			expectedCode:    http.StatusBadRequest,
+			productsService: &mockService{},
+			limit:           "10",
+			offset:          "0",
+			filters:         "&locale=spanish_",
+		},
+		{
+			name:            "failed to search product, incorrect tag format",
+			expectedCode:    http.StatusBadRequest,
+			productsService: &mockService{},
+			limit:           "10",
+			offset:          "0",
+			filters:         "&tag=on%20sale",
+		},
+		{
+			name:            "failed to search product, incorrect tag match format",
+			expectedCode:    http.StatusBadRequest,
+			productsService: &mockService{},
+			limit:           "10",
+			offset:          "0",
+			filters:         "&tag=sale&tag_match=some",
+		},
+		{
+			name:            "failed to search product, incorrect units",
+			expectedCode:    http.StatusBadRequest,
+			productsService: &mockService{},
+			limit:           "10",
+			offset:          "0",
+			filters:         "&units=nautical",
+		},
+		{
+			name:            "failed to search product, incorrect weight range format",
+			expectedCode:    http.StatusBadRequest,
+			productsService: &mockService{},
+			limit:           "10",
+			offset:          "0",
+			filters:         "&weight_gte=heavy",
+		},
+		{
+			name:            "failed to search product, negative height range",
+			expectedCode:    http.StatusBadRequest,
+			productsService: &mockService{},
+			limit:           "10",
+			offset:          "0",
+			filters:         "&height_lte=-1",
+		},
+		{
+			name:            "failed to search product, length range minimum above its maximum",
+			expectedCode:    http.StatusBadRequest,
+			productsService: &mockService{},
+			limit:           "10",
+			offset:          "0",
+			filters:         "&length_gte=20&length_lte=10",
+		},
 		{
 			name:         "failed to search product, error on service",
 			expectedCode: http.StatusInternalServerError,
@@ -397,6 +841,37 @@ func TestServer_Search(t *testing.T) {
 			limit:           "10",
 			offset:          "0",
 		},
+		{
+			name:            "successfully search products by attributes",
+			expectedCode:    http.StatusOK,
+			productsService: &mockService{},
+			limit:           "10",
+			offset:          "0",
+			filters:         "&attr.material=cotton&attr.screen_size_gte=13",
+		},
+		{
+			name:            "successfully search products by brand and tags",
+			expectedCode:    http.StatusOK,
+			productsService: &mockService{},
+			limit:           "10",
+			offset:          "0",
+			filters:         "&brand=1&tag=sale&tag=New&tag_match=all",
+		},
+		{
+			name:            "successfully search products by weight and dimensions on imperial units",
+			expectedCode:    http.StatusOK,
+			productsService: &mockService{},
+			limit:           "10",
+			offset:          "0",
+			filters:         "&units=imperial&weight_lte=2.5&length_gte=10&width_lte=12.5",
+		},
+		{
+			name:            "successfully search products with cursor",
+			expectedCode:    http.StatusOK,
+			productsService: &mockService{},
+			limit:           "10",
+			cursor:          model.NewCursor(model.Product{ID: "id", Price: 100}, "asc", false).String(),
+		},
 	}
 
 	for _, dt := range dataTable {
@@ -406,9 +881,10 @@ func TestServer_Search(t *testing.T) {
 			mux.NewRouter(),
 			"",
 			"",
+			"",
 			"")
 
-		endpoint := fmt.Sprintf("/v1?limit=%s&offset=%s", dt.limit, dt.offset)
+		endpoint := fmt.Sprintf("/v1?limit=%s&offset=%s&cursor=%s%s", dt.limit, dt.offset, dt.cursor, dt.filters)
 
 		w := httptest.NewRecorder()
 
@@ -422,6 +898,37 @@ func TestServer_Search(t *testing.T) {
 	}
 }
 
+func TestServer_Update_VersionConflict(t *testing.T) {
+	t.Run("failed to update product, conflict returns current version", func(t *testing.T) {
+		// Given
+		app := New(
+			&mockService{
+				err: &internalErrors.VersionConflictError{Current: 3},
+			},
+			mux.NewRouter(),
+			"",
+			"",
+			"",
+			"")
+
+		w := httptest.NewRecorder()
+
+		req := httptest.NewRequest(http.MethodPut, "/v1/1/", mockRequest(map[string]any{"qty": 100}))
+
+		req.Header.Set("If-Match", `"2"`)
+
+		// When
+		app.serveHTTP(w, req)
+
+		// Then
+		assert.Equal(t, http.StatusConflict, w.Code)
+
+		assert.Equal(t, `"3"`, w.Header().Get("ETag"))
+
+		assert.Equal(t, float64(3), jsonResponse(t, w.Body.Bytes())["version"])
+	})
+}
+
 type mockService struct {
 	err error
 }
@@ -430,18 +937,34 @@ func (m *mockService) Create(ctx context.Context, product model.Product) (*model
 	return &model.Product{}, m.err
 }
 
-func (m *mockService) GetByID(ctx context.Context, id string) (*model.Product, error) {
+func (m *mockService) GetByID(ctx context.Context, id string, options model.ReadOptions) (*model.Product, error) {
 	return &model.Product{}, m.err
 }
 
-func (m *mockService) GetBySKU(ctx context.Context, sku string) (*model.Product, error) {
+func (m *mockService) GetBySKU(ctx context.Context, sku string, options model.ReadOptions) (*model.SKUProduct, error) {
+	return &model.SKUProduct{}, m.err
+}
+
+func (m *mockService) GetBySlug(ctx context.Context, slug string, options model.ReadOptions) (*model.Product, error) {
 	return &model.Product{}, m.err
 }
 
-func (m *mockService) Delete(ctx context.Context, id string) error {
+func (m *mockService) Delete(ctx context.Context, id string, version int64) error {
 	return m.err
 }
 
+func (m *mockService) Restore(ctx context.Context, id string) (*model.Product, error) {
+	return &model.Product{}, m.err
+}
+
+func (m *mockService) Purge(ctx context.Context, retention time.Duration) (int64, error) {
+	return 0, m.err
+}
+
+func (m *mockService) ApplyDuePrices(ctx context.Context) (int64, error) {
+	return 0, m.err
+}
+
 func (m *mockService) Update(ctx context.Context, request *model.Update) (*model.Product, error) {
 	return &model.Product{}, m.err
 }
@@ -450,6 +973,122 @@ func (m *mockService) Search(ctx context.Context, request model.SearchRequest) (
 	return &model.SearchResponse{}, m.err
 }
 
+func (m *mockService) ChangeStatus(ctx context.Context, request model.StatusRequest) (*model.Product, error) {
+	return &model.Product{ID: request.ID}, m.err
+}
+
+func (m *mockService) SetTranslation(ctx context.Context, request model.TranslationRequest) (*model.Product, error) {
+	return &model.Product{ID: request.ID}, m.err
+}
+
+func (m *mockService) RemoveTranslation(ctx context.Context, request model.TranslationRequest) (*model.Product, error) {
+	return &model.Product{ID: request.ID}, m.err
+}
+
+func (m *mockService) ChangeSlug(ctx context.Context, request model.SlugRequest) (*model.Product, error) {
+	return &model.Product{}, m.err
+}
+
+func (m *mockService) AddImage(ctx context.Context, request model.ImageRequest) (*model.Product, error) {
+	return &model.Product{ID: request.ID}, m.err
+}
+
+func (m *mockService) RemoveImage(ctx context.Context, request model.ImageRequest) (*model.Product, error) {
+	return &model.Product{ID: request.ID}, m.err
+}
+
+func (m *mockService) ReorderImages(ctx context.Context, request model.ImageOrderRequest) (*model.Product, error) {
+	return &model.Product{ID: request.ID}, m.err
+}
+
+func (m *mockService) GetRelations(ctx context.Context, id string, relationType model.RelationType) ([]model.Relation, error) {
+	return []model.Relation{}, m.err
+}
+
+func (m *mockService) AddRelation(ctx context.Context, request model.RelationRequest) (*model.Product, error) {
+	return &model.Product{ID: request.ID}, m.err
+}
+
+func (m *mockService) RemoveRelation(ctx context.Context, request model.RelationRequest) (*model.Product, error) {
+	return &model.Product{ID: request.ID}, m.err
+}
+
+func (m *mockService) ReorderRelations(ctx context.Context, request model.RelationOrderRequest) (*model.Product, error) {
+	return &model.Product{ID: request.ID}, m.err
+}
+
+func (m *mockService) GetPriceTimeline(ctx context.Context, id string) (*model.PriceTimeline, error) {
+	return &model.PriceTimeline{ProductID: id}, m.err
+}
+
+func (m *mockService) SchedulePrice(ctx context.Context, request model.SchedulePriceRequest) (*model.PriceTimeline, error) {
+	return &model.PriceTimeline{ProductID: request.ID}, m.err
+}
+
+func (m *mockService) CancelScheduledPrice(ctx context.Context, id string, scheduleID string) (*model.PriceTimeline, error) {
+	return &model.PriceTimeline{ProductID: id}, m.err
+}
+
+func (m *mockService) CreateCategory(ctx context.Context, category model.Category) (*model.Category, error) {
+	return &category, m.err
+}
+
+func (m *mockService) GetCategory(ctx context.Context, id string) (*model.Category, error) {
+	return &model.Category{ID: id}, m.err
+}
+
+func (m *mockService) ListCategories(ctx context.Context) ([]model.Category, error) {
+	return []model.Category{}, m.err
+}
+
+func (m *mockService) UpdateCategory(ctx context.Context, category model.Category) (*model.Category, error) {
+	return &category, m.err
+}
+
+func (m *mockService) DeleteCategory(ctx context.Context, id string) error {
+	return m.err
+}
+
+func (m *mockService) TagCounts(ctx context.Context, request model.SearchRequest) (*model.TagCountsResponse, error) {
+	return &model.TagCountsResponse{Tags: []model.TagCount{}}, m.err
+}
+
+func (m *mockService) CreateBrand(ctx context.Context, brand model.Brand) (*model.Brand, error) {
+	return &brand, m.err
+}
+
+func (m *mockService) GetBrand(ctx context.Context, id string) (*model.Brand, error) {
+	return &model.Brand{ID: id}, m.err
+}
+
+func (m *mockService) ListBrands(ctx context.Context) ([]model.Brand, error) {
+	return []model.Brand{}, m.err
+}
+
+func (m *mockService) UpdateBrand(ctx context.Context, brand model.Brand) (*model.Brand, error) {
+	return &brand, m.err
+}
+
+func (m *mockService) DeleteBrand(ctx context.Context, id string) error {
+	return m.err
+}
+
+func (m *mockService) RecordMovement(ctx context.Context, request model.MovementRequest) (*model.StockMovement, error) {
+	return &model.StockMovement{ProductID: request.ID, Type: request.Type, Delta: request.Delta}, m.err
+}
+
+func (m *mockService) ListStockMovements(ctx context.Context, request model.MovementSearchRequest) (*model.MovementsResponse, error) {
+	return &model.MovementsResponse{Movements: []model.StockMovement{}}, m.err
+}
+
+func (m *mockService) VerifyStock(ctx context.Context, id string) (*model.StockLedger, error) {
+	return &model.StockLedger{ProductID: id, Consistent: true}, m.err
+}
+
+func (m *mockService) RebuildStock(ctx context.Context, id string) (*model.Product, error) {
+	return &model.Product{ID: id}, m.err
+}
+
 func jsonResponse(t *testing.T, b []byte) map[string]any {
 	var res map[string]any
 
@@ -0,0 +1,156 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/gorilla/mux"
+	internalErrors "github.com/srodrmendz/api-product-catalog/errors"
+	"github.com/srodrmendz/api-product-catalog/model"
+	"github.com/srodrmendz/api-product-catalog/utils"
+)
+
+// Get By slug godoc
+// @Tags get by slug
+// @Description Get product by its slug or the slug of one of its translations, the product is localized to the locale of the slug
+// @Description A previous slug responds 301 with the route of the current slug of its locale on the Location header and body
+// @Accept  json
+// @Produce  json
+// @Param slug path string true "product or translation slug"
+// @Param currency query string false "ISO 4217 currency the price is returned on"
+// @Param units query string false "metric or imperial, unit system weight and dimensions are returned on, metric by default"
+// @Param expand query string false "relations inlines the related products on the product relations"
+// @Param Authorization header string false "Bearer admin token, admin callers find products on every status, others only the ones visible to shoppers"
+// @Success 200 {object} model.Product
+// @Success 301 {object} server.redirectResponse
+// @Failure 400
+// @Failure 404
+// @Failure 500
+// @Router /v1/slug/{slug}/ [get]
+func (a *App) getBySlug(w http.ResponseWriter, r *http.Request) {
+	slug := mux.Vars(r)["slug"]
+
+	if slug == "" {
+		utils.ErrJSON(w, http.StatusBadRequest, errors.New("slug must be provided"))
+
+		return
+	}
+
+	options, err := model.ParseReadOptions(r)
+	if err != nil {
+		utils.ErrJSON(w, http.StatusBadRequest, err)
+
+		return
+	}
+
+	product, err := a.Services.ProductsService.GetBySlug(r.Context(), slug, options)
+	if err != nil {
+		var moved *internalErrors.SlugMovedError
+
+		if errors.As(err, &moved) {
+			slugMoved(w, r, moved)
+
+			return
+		}
+
+		if errors.Is(err, internalErrors.ErrProductNotFound) {
+			utils.ErrJSON(w, http.StatusNotFound, err)
+
+			return
+		}
+
+		if errors.Is(err, internalErrors.ErrPriceNotAvailable) {
+			utils.ErrJSON(w, http.StatusBadRequest, err)
+
+			return
+		}
+
+		utils.ErrJSON(w, http.StatusInternalServerError, err)
+
+		return
+	}
+
+	w.Header().Set("ETag", model.ETag(product.Version))
+
+	if product.Locale != "" {
+		w.Header().Set("Content-Language", product.Locale)
+	}
+
+	utils.DataJSON(w, http.StatusOK, product)
+}
+
+// Respond a previous slug with a redirect to the route of the current one, keeping the request query
+func slugMoved(w http.ResponseWriter, r *http.Request, moved *internalErrors.SlugMovedError) {
+	location := url.URL{
+		Path:     path.Join(path.Dir(strings.TrimSuffix(r.URL.Path, "/")), moved.Slug) + "/",
+		RawQuery: r.URL.RawQuery,
+	}
+
+	response := redirectResponse{
+		Slug:      moved.Slug,
+		ProductID: moved.ProductID,
+		Location:  location.String(),
+	}
+
+	w.Header().Set("Location", response.Location)
+
+	utils.DataJSON(w, http.StatusMovedPermanently, response)
+}
+
+// Change Slug godoc
+// @Tags slugs
+// @Description Change the slug of the product content on a locale, the product own content when no locale is requested
+// @Description An empty slug is generated again from the content name, the replaced slug redirects to the new one
+// @Accept  json
+// @Produce  json
+// @Param request body model.SlugRequest true "Request body"
+// @Param id path string true "id"
+// @Param If-Match header string false "product version the slug change applies to"
+// @Success 200 {object} model.Product
+// @Failure 400
+// @Failure 404
+// @Failure 409
+// @Failure 500
+// @Router /v1/{id}/slug [put]
+func (a *App) changeSlug(w http.ResponseWriter, r *http.Request) {
+	builder := model.NewSlugBuilder(r)
+
+	request, err := builder.Build()
+	if err != nil {
+		utils.ErrJSON(w, http.StatusBadRequest, err)
+
+		return
+	}
+
+	product, err := a.Services.ProductsService.ChangeSlug(r.Context(), *request)
+	if err != nil {
+		if errors.Is(err, internalErrors.ErrProductNotFound) || errors.Is(err, internalErrors.ErrTranslationNotFound) {
+			utils.ErrJSON(w, http.StatusNotFound, err)
+
+			return
+		}
+
+		if errors.Is(err, internalErrors.ErrVersionConflict) {
+			versionConflict(w, err)
+
+			return
+		}
+
+		if errors.Is(err, internalErrors.ErrSlugAlreadyExist) {
+			utils.ErrJSON(w, http.StatusConflict, err)
+
+			return
+		}
+
+		utils.ErrJSON(w, http.StatusInternalServerError, err)
+
+		return
+	}
+
+	w.Header().Set("ETag", model.ETag(product.Version))
+
+	utils.DataJSON(w, http.StatusOK, product)
+}
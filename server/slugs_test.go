@@ -0,0 +1,150 @@
+package server
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	internalErrors "github.com/srodrmendz/api-product-catalog/errors"
+	"github.com/srodrmendz/api-product-catalog/model"
+	"github.com/srodrmendz/api-product-catalog/service"
+	"github.com/stretchr/testify/assert"
+)
+
+// Test product slug endpoints
+func TestServer_Slugs(t *testing.T) {
+	dataTable := []struct {
+		name             string
+		method           string
+		endpoint         string
+		body             io.Reader
+		productsService  service.Service
+		expectedCode     int
+		expectedLocation string
+	}{
+		{
+			name:            "successfully get product by slug",
+			method:          http.MethodGet,
+			endpoint:        "/v1/slug/running-shoes/",
+			productsService: &mockService{},
+			expectedCode:    http.StatusOK,
+		},
+		{
+			name:     "successfully redirect a previous product slug",
+			method:   http.MethodGet,
+			endpoint: "/v1/slug/old-shoes/?currency=EUR",
+			productsService: &mockService{
+				err: &internalErrors.SlugMovedError{Slug: "running-shoes", ProductID: "1"},
+			},
+			expectedCode:     http.StatusMovedPermanently,
+			expectedLocation: "/v1/slug/running-shoes/?currency=EUR",
+		},
+		{
+			name:            "failed to get product by slug, incorrect currency",
+			method:          http.MethodGet,
+			endpoint:        "/v1/slug/running-shoes/?currency=fake",
+			productsService: &mockService{},
+			expectedCode:    http.StatusBadRequest,
+		},
+		{
+			name:     "failed to get product by slug, product not found",
+			method:   http.MethodGet,
+			endpoint: "/v1/slug/running-shoes/",
+			productsService: &mockService{
+				err: internalErrors.ErrProductNotFound,
+			},
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:     "failed to get product by slug, error on service",
+			method:   http.MethodGet,
+			endpoint: "/v1/slug/running-shoes/",
+			productsService: &mockService{
+				err: errors.New("error on service"),
+			},
+			expectedCode: http.StatusInternalServerError,
+		},
+		{
+			name:            "successfully change product slug",
+			method:          http.MethodPut,
+			endpoint:        "/v1/1/slug",
+			body:            mockRequest(model.SlugRequest{Slug: "zapatillas", Locale: "es"}),
+			productsService: &mockService{},
+			expectedCode:    http.StatusOK,
+		},
+		{
+			name:            "successfully generate product slug again",
+			method:          http.MethodPut,
+			endpoint:        "/v1/1/slug",
+			body:            mockRequest(model.SlugRequest{}),
+			productsService: &mockService{},
+			expectedCode:    http.StatusOK,
+		},
+		{
+			name:            "failed to change product slug, incorrect slug",
+			method:          http.MethodPut,
+			endpoint:        "/v1/1/slug",
+			body:            mockRequest(model.SlugRequest{Slug: "Running Shoes"}),
+			productsService: &mockService{},
+			expectedCode:    http.StatusBadRequest,
+		},
+		{
+			name:     "failed to change product slug, translation not found",
+			method:   http.MethodPut,
+			endpoint: "/v1/1/slug",
+			body:     mockRequest(model.SlugRequest{Slug: "zapatillas", Locale: "es"}),
+			productsService: &mockService{
+				err: internalErrors.ErrTranslationNotFound,
+			},
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:     "failed to change product slug, slug already exist",
+			method:   http.MethodPut,
+			endpoint: "/v1/1/slug",
+			body:     mockRequest(model.SlugRequest{Slug: "running-shoes"}),
+			productsService: &mockService{
+				err: internalErrors.ErrSlugAlreadyExist,
+			},
+			expectedCode: http.StatusConflict,
+		},
+		{
+			name:     "failed to change product slug, version conflict",
+			method:   http.MethodPut,
+			endpoint: "/v1/1/slug",
+			body:     mockRequest(model.SlugRequest{Slug: "running-shoes"}),
+			productsService: &mockService{
+				err: &internalErrors.VersionConflictError{Current: 2},
+			},
+			expectedCode: http.StatusConflict,
+		},
+	}
+
+	for _, dt := range dataTable {
+		t.Run(dt.name, func(t *testing.T) {
+			// Given
+			app := New(
+				dt.productsService,
+				mux.NewRouter(),
+				"",
+				"",
+				"",
+				"")
+
+			w := httptest.NewRecorder()
+
+			req := httptest.NewRequest(dt.method, dt.endpoint, dt.body)
+
+			// When
+			app.serveHTTP(w, req)
+
+			// Then
+			assert.Equal(t, dt.expectedCode, w.Code)
+
+			assert.Equal(t, dt.expectedLocation, w.Header().Get("Location"))
+		})
+	}
+}
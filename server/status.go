@@ -0,0 +1,119 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+
+	internalErrors "github.com/srodrmendz/api-product-catalog/errors"
+	"github.com/srodrmendz/api-product-catalog/model"
+	"github.com/srodrmendz/api-product-catalog/utils"
+)
+
+// Publish godoc
+// @Tags status
+// @Description Publish a draft product, or change the publication window of a published one. Shoppers see it from publish_at until unpublish_at, when they are set
+// @Accept  json
+// @Produce  json
+// @Param request body model.StatusRequest false "Request body"
+// @Param id path string true "id"
+// @Param If-Match header string false "product version the transition applies to"
+// @Success 200 {object} model.Product
+// @Failure 400
+// @Failure 404
+// @Failure 409
+// @Failure 500
+// @Router /v1/{id}/publish [post]
+func (a *App) publish(w http.ResponseWriter, r *http.Request) {
+	a.changeStatus(w, r, model.TransitionPublish)
+}
+
+// Unpublish godoc
+// @Tags status
+// @Description Move a published product back to draft
+// @Accept  json
+// @Produce  json
+// @Param id path string true "id"
+// @Param If-Match header string false "product version the transition applies to"
+// @Success 200 {object} model.Product
+// @Failure 400
+// @Failure 404
+// @Failure 409
+// @Failure 500
+// @Router /v1/{id}/unpublish [post]
+func (a *App) unpublish(w http.ResponseWriter, r *http.Request) {
+	a.changeStatus(w, r, model.TransitionUnpublish)
+}
+
+// Archive godoc
+// @Tags status
+// @Description Archive a draft or published product, components of bundles that are not archived or deleted can not be archived
+// @Accept  json
+// @Produce  json
+// @Param id path string true "id"
+// @Param If-Match header string false "product version the transition applies to"
+// @Success 200 {object} model.Product
+// @Failure 400
+// @Failure 404
+// @Failure 409
+// @Failure 500
+// @Router /v1/{id}/archive [post]
+func (a *App) archive(w http.ResponseWriter, r *http.Request) {
+	a.changeStatus(w, r, model.TransitionArchive)
+}
+
+// Unarchive godoc
+// @Tags status
+// @Description Move an archived product back to draft
+// @Accept  json
+// @Produce  json
+// @Param id path string true "id"
+// @Param If-Match header string false "product version the transition applies to"
+// @Success 200 {object} model.Product
+// @Failure 400
+// @Failure 404
+// @Failure 409
+// @Failure 500
+// @Router /v1/{id}/unarchive [post]
+func (a *App) unarchive(w http.ResponseWriter, r *http.Request) {
+	a.changeStatus(w, r, model.TransitionUnarchive)
+}
+
+func (a *App) changeStatus(w http.ResponseWriter, r *http.Request, transition model.Transition) {
+	builder := model.NewStatusBuilder(r, transition)
+
+	request, err := builder.Build()
+	if err != nil {
+		utils.ErrJSON(w, http.StatusBadRequest, err)
+
+		return
+	}
+
+	product, err := a.Services.ProductsService.ChangeStatus(r.Context(), *request)
+	if err != nil {
+		if errors.Is(err, internalErrors.ErrProductNotFound) {
+			utils.ErrJSON(w, http.StatusNotFound, err)
+
+			return
+		}
+
+		if errors.Is(err, internalErrors.ErrStatusTransition) || errors.Is(err, internalErrors.ErrProductInBundle) {
+			utils.ErrJSON(w, http.StatusConflict, err)
+
+			return
+		}
+
+		if errors.Is(err, internalErrors.ErrVersionConflict) {
+			versionConflict(w, err)
+
+			return
+		}
+
+		utils.ErrJSON(w, http.StatusInternalServerError, err)
+
+		return
+	}
+
+	w.Header().Set("ETag", model.ETag(product.Version))
+
+	utils.DataJSON(w, http.StatusOK, product)
+}
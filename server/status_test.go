@@ -0,0 +1,196 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	internalErrors "github.com/srodrmendz/api-product-catalog/errors"
+	"github.com/srodrmendz/api-product-catalog/model"
+	"github.com/srodrmendz/api-product-catalog/service"
+	"github.com/stretchr/testify/assert"
+)
+
+// Test product status transition endpoints
+func TestServer_Status(t *testing.T) {
+	publishAt := time.Now().Add(time.Hour)
+
+	unpublishAt := publishAt.Add(time.Hour)
+
+	dataTable := []struct {
+		name            string
+		endpoint        string
+		body            io.Reader
+		productsService service.Service
+		expectedCode    int
+	}{
+		{
+			name:            "successfully publish product",
+			endpoint:        "/v1/1/publish",
+			productsService: &mockService{},
+			expectedCode:    http.StatusOK,
+		},
+		{
+			name:            "successfully publish product on a publication window",
+			endpoint:        "/v1/1/publish",
+			body:            mockRequest(model.StatusRequest{PublishAt: &publishAt, UnpublishAt: &unpublishAt}),
+			productsService: &mockService{},
+			expectedCode:    http.StatusOK,
+		},
+		{
+			name:            "failed to publish product, unpublish before publish",
+			endpoint:        "/v1/1/publish",
+			body:            mockRequest(model.StatusRequest{PublishAt: &unpublishAt, UnpublishAt: &publishAt}),
+			productsService: &mockService{},
+			expectedCode:    http.StatusBadRequest,
+		},
+		{
+			name:     "failed to publish product, product not found",
+			endpoint: "/v1/1/publish",
+			productsService: &mockService{
+				err: internalErrors.ErrProductNotFound,
+			},
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:     "failed to unpublish product, status does not allow it",
+			endpoint: "/v1/1/unpublish",
+			productsService: &mockService{
+				err: internalErrors.ErrStatusTransition,
+			},
+			expectedCode: http.StatusConflict,
+		},
+		{
+			name:     "failed to archive product, product is a bundle component",
+			endpoint: "/v1/1/archive",
+			productsService: &mockService{
+				err: internalErrors.ErrProductInBundle,
+			},
+			expectedCode: http.StatusConflict,
+		},
+		{
+			name:     "failed to archive product, version conflict",
+			endpoint: "/v1/1/archive",
+			productsService: &mockService{
+				err: &internalErrors.VersionConflictError{Current: 2},
+			},
+			expectedCode: http.StatusConflict,
+		},
+		{
+			name:     "failed to unarchive product, error on service",
+			endpoint: "/v1/1/unarchive",
+			productsService: &mockService{
+				err: errors.New("error on service"),
+			},
+			expectedCode: http.StatusInternalServerError,
+		},
+		{
+			name:            "successfully unarchive product",
+			endpoint:        "/v1/1/unarchive",
+			productsService: &mockService{},
+			expectedCode:    http.StatusOK,
+		},
+	}
+
+	for _, dt := range dataTable {
+		t.Run(dt.name, func(t *testing.T) {
+			// Given
+			app := New(
+				dt.productsService,
+				mux.NewRouter(),
+				"",
+				"",
+				"",
+				"")
+
+			w := httptest.NewRecorder()
+
+			req := httptest.NewRequest(http.MethodPost, dt.endpoint, dt.body)
+
+			// When
+			app.serveHTTP(w, req)
+
+			// Then
+			assert.Equal(t, dt.expectedCode, w.Code)
+		})
+	}
+}
+
+// Test admin requests are only authenticated with the configured admin token
+func TestServer_Admin(t *testing.T) {
+	dataTable := []struct {
+		name          string
+		adminToken    string
+		headers       map[string]string
+		expectedAdmin bool
+	}{
+		{
+			name:          "request with the admin token is admin",
+			adminToken:    "secret",
+			headers:       map[string]string{"Authorization": "Bearer secret"},
+			expectedAdmin: true,
+		},
+		{
+			name:       "request with another token is not admin",
+			adminToken: "secret",
+			headers:    map[string]string{"Authorization": "Bearer guess"},
+		},
+		{
+			name:       "request claiming the admin role on a header is not admin",
+			adminToken: "secret",
+			headers:    map[string]string{"X-Role": "admin"},
+		},
+		{
+			name:    "request without admin token configured is not admin",
+			headers: map[string]string{"Authorization": "Bearer "},
+		},
+	}
+
+	for _, dt := range dataTable {
+		t.Run(dt.name, func(t *testing.T) {
+			// Given
+			productsService := &adminMockService{}
+
+			app := New(
+				productsService,
+				mux.NewRouter(),
+				"",
+				"",
+				"",
+				dt.adminToken)
+
+			w := httptest.NewRecorder()
+
+			req := httptest.NewRequest(http.MethodGet, "/v1/sku/sku/", nil)
+
+			for key, value := range dt.headers {
+				req.Header.Set(key, value)
+			}
+
+			// When
+			app.serveHTTP(w, req)
+
+			// Then
+			assert.Equal(t, http.StatusOK, w.Code)
+
+			assert.Equal(t, dt.expectedAdmin, productsService.admin)
+		})
+	}
+}
+
+// Records whether the product was read by an admin caller
+type adminMockService struct {
+	mockService
+	admin bool
+}
+
+func (m *adminMockService) GetBySKU(ctx context.Context, sku string, options model.ReadOptions) (*model.SKUProduct, error) {
+	m.admin = options.Admin
+
+	return &model.SKUProduct{}, nil
+}
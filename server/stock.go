@@ -0,0 +1,245 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	internalErrors "github.com/srodrmendz/api-product-catalog/errors"
+	"github.com/srodrmendz/api-product-catalog/model"
+	"github.com/srodrmendz/api-product-catalog/utils"
+)
+
+// Record Movement godoc
+// @Tags stock
+// @Description Record a stock movement on the product, or on one of its variants, and change its qty by the movement delta
+// @Description Receipts and returns add stock, sales and damages take it and adjustments do either
+// @Accept  json
+// @Produce  json
+// @Param request body model.MovementRequest true "Request body"
+// @Param id path string true "id"
+// @Param X-Actor header string false "who moves the stock, recorded on the movement"
+// @Success 201 {object} model.StockMovement
+// @Failure 400
+// @Failure 404
+// @Failure 409
+// @Failure 500
+// @Router /v1/{id}/stock/movements [post]
+func (a *App) recordMovement(w http.ResponseWriter, r *http.Request) {
+	builder := model.NewMovementBuilder(r)
+
+	request, err := builder.Build()
+	if err != nil {
+		utils.ErrJSON(w, http.StatusBadRequest, err)
+
+		return
+	}
+
+	movement, err := a.Services.ProductsService.RecordMovement(r.Context(), *request)
+	if err != nil {
+		movementError(w, err)
+
+		return
+	}
+
+	utils.DataJSON(w, http.StatusCreated, movement)
+}
+
+// Increment Stock godoc
+// @Tags stock
+// @Description Add qty to the stock of the product, or of one of its variants, recording a receipt unless another type is requested
+// @Description The product is addressed by id, or by its sku or the sku of one of its variants on /v1/sku/{sku}/stock/increment
+// @Accept  json
+// @Produce  json
+// @Param request body model.StockChangeRequest true "Request body"
+// @Param id path string true "id"
+// @Param X-Actor header string false "who moves the stock, recorded on the movement"
+// @Success 200 {object} model.StockMovement
+// @Failure 400
+// @Failure 404
+// @Failure 409
+// @Failure 500
+// @Router /v1/{id}/stock/increment [post]
+func (a *App) incrementStock(w http.ResponseWriter, r *http.Request) {
+	a.changeStock(w, r, false)
+}
+
+// Decrement Stock godoc
+// @Tags stock
+// @Description Take qty from the stock of the product, or of one of its variants, recording a sale unless another type is requested
+// @Description The product is addressed by id, or by its sku or the sku of one of its variants on /v1/sku/{sku}/stock/decrement
+// @Description The stock is never taken below zero, a decrement of more qty than there is conflicts and changes nothing
+// @Accept  json
+// @Produce  json
+// @Param request body model.StockChangeRequest true "Request body"
+// @Param id path string true "id"
+// @Param X-Actor header string false "who moves the stock, recorded on the movement"
+// @Success 200 {object} model.StockMovement
+// @Failure 400
+// @Failure 404
+// @Failure 409
+// @Failure 500
+// @Router /v1/{id}/stock/decrement [post]
+func (a *App) decrementStock(w http.ResponseWriter, r *http.Request) {
+	a.changeStock(w, r, true)
+}
+
+// Record the stock movement of an increment or decrement request
+func (a *App) changeStock(w http.ResponseWriter, r *http.Request, decrement bool) {
+	builder := model.NewStockChangeBuilder(r, decrement)
+
+	request, err := builder.Build()
+	if err != nil {
+		utils.ErrJSON(w, http.StatusBadRequest, err)
+
+		return
+	}
+
+	movement, err := a.Services.ProductsService.RecordMovement(r.Context(), *request)
+	if err != nil {
+		movementError(w, err)
+
+		return
+	}
+
+	utils.DataJSON(w, http.StatusOK, movement)
+}
+
+// Respond the errors of recording stock movements
+func movementError(w http.ResponseWriter, err error) {
+	if errors.Is(err, internalErrors.ErrProductNotFound) || errors.Is(err, internalErrors.ErrVariantNotFound) {
+		utils.ErrJSON(w, http.StatusNotFound, err)
+
+		return
+	}
+
+	if errors.Is(err, internalErrors.ErrVariantRequired) || errors.Is(err, internalErrors.ErrBundleStock) {
+		utils.ErrJSON(w, http.StatusBadRequest, err)
+
+		return
+	}
+
+	if errors.Is(err, internalErrors.ErrInsufficientStock) ||
+		errors.Is(err, internalErrors.ErrStockLimit) ||
+		errors.Is(err, internalErrors.ErrVersionConflict) {
+		utils.ErrJSON(w, http.StatusConflict, err)
+
+		return
+	}
+
+	utils.ErrJSON(w, http.StatusInternalServerError, err)
+}
+
+// List Stock Movements godoc
+// @Tags stock
+// @Description List the product stock movements newest first, filtered by type and by the time they were recorded
+// @Accept  json
+// @Produce  json
+// @Param id path string true "id"
+// @Param type query []string false "receipt, sale, return, adjustment or damage, repeated to match any" collectionFormat(multi)
+// @Param from query string false "RFC 3339 time the movements are recorded at or after"
+// @Param to query string false "RFC 3339 time the movements are recorded before"
+// @Param limit query int true "limit, 0 lists every movement"
+// @Param offset query int true "offset"
+// @Success 200 {object} model.MovementsResponse
+// @Failure 400
+// @Failure 404
+// @Failure 500
+// @Router /v1/{id}/stock/movements [get]
+func (a *App) listStockMovements(w http.ResponseWriter, r *http.Request) {
+	builder := model.NewMovementSearchBuilder(r)
+
+	request, err := builder.Build()
+	if err != nil {
+		utils.ErrJSON(w, http.StatusBadRequest, err)
+
+		return
+	}
+
+	response, err := a.Services.ProductsService.ListStockMovements(r.Context(), *request)
+	if err != nil {
+		if errors.Is(err, internalErrors.ErrProductNotFound) {
+			utils.ErrJSON(w, http.StatusNotFound, err)
+
+			return
+		}
+
+		utils.ErrJSON(w, http.StatusInternalServerError, err)
+
+		return
+	}
+
+	utils.DataJSON(w, http.StatusOK, response)
+}
+
+// Verify Stock godoc
+// @Tags stock
+// @Description Compare the stored qty of the product and its variants with the qty their stock movements add up to
+// @Accept  json
+// @Produce  json
+// @Param id path string true "id"
+// @Success 200 {object} model.StockLedger
+// @Failure 400
+// @Failure 404
+// @Failure 500
+// @Router /v1/{id}/stock/ledger [get]
+func (a *App) verifyStock(w http.ResponseWriter, r *http.Request) {
+	ledger, err := a.Services.ProductsService.VerifyStock(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		stockError(w, err)
+
+		return
+	}
+
+	utils.DataJSON(w, http.StatusOK, ledger)
+}
+
+// Rebuild Stock godoc
+// @Tags stock
+// @Description Set the qty of the product and its variants to the qty their stock movements add up to
+// @Accept  json
+// @Produce  json
+// @Param id path string true "id"
+// @Success 200 {object} model.Product
+// @Failure 400
+// @Failure 404
+// @Failure 409
+// @Failure 500
+// @Router /v1/{id}/stock/rebuild [post]
+func (a *App) rebuildStock(w http.ResponseWriter, r *http.Request) {
+	product, err := a.Services.ProductsService.RebuildStock(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		stockError(w, err)
+
+		return
+	}
+
+	w.Header().Set("ETag", model.ETag(product.Version))
+
+	utils.DataJSON(w, http.StatusOK, product)
+}
+
+// Respond the errors of the stock ledger reads and rebuilds
+func stockError(w http.ResponseWriter, err error) {
+	if errors.Is(err, internalErrors.ErrProductNotFound) {
+		utils.ErrJSON(w, http.StatusNotFound, err)
+
+		return
+	}
+
+	if errors.Is(err, internalErrors.ErrBundleStock) {
+		utils.ErrJSON(w, http.StatusBadRequest, err)
+
+		return
+	}
+
+	if errors.Is(err, internalErrors.ErrInsufficientStock) ||
+		errors.Is(err, internalErrors.ErrStockLimit) ||
+		errors.Is(err, internalErrors.ErrVersionConflict) {
+		utils.ErrJSON(w, http.StatusConflict, err)
+
+		return
+	}
+
+	utils.ErrJSON(w, http.StatusInternalServerError, err)
+}
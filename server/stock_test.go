@@ -0,0 +1,284 @@
+package server
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	internalErrors "github.com/srodrmendz/api-product-catalog/errors"
+	"github.com/srodrmendz/api-product-catalog/service"
+	"github.com/stretchr/testify/assert"
+)
+
+// Test product stock ledger endpoints
+func TestServer_Stock(t *testing.T) {
+	dataTable := []struct {
+		name            string
+		method          string
+		endpoint        string
+		body            io.Reader
+		productsService service.Service
+		expectedCode    int
+	}{
+		{
+			name:            "successfully record stock movement",
+			method:          http.MethodPost,
+			endpoint:        "/v1/1/stock/movements",
+			body:            strings.NewReader(`{"type":"receipt","delta":10,"reference":"PO-1"}`),
+			productsService: &mockService{},
+			expectedCode:    http.StatusCreated,
+		},
+		{
+			name:            "successfully record stock adjustment taking stock",
+			method:          http.MethodPost,
+			endpoint:        "/v1/1/stock/movements",
+			body:            strings.NewReader(`{"type":"adjustment","delta":-2,"reason":"stock count"}`),
+			productsService: &mockService{},
+			expectedCode:    http.StatusCreated,
+		},
+		{
+			name:            "failed to record stock movement, incorrect type",
+			method:          http.MethodPost,
+			endpoint:        "/v1/1/stock/movements",
+			body:            strings.NewReader(`{"type":"theft","delta":-1}`),
+			productsService: &mockService{},
+			expectedCode:    http.StatusBadRequest,
+		},
+		{
+			name:            "failed to record stock movement, sale adding stock",
+			method:          http.MethodPost,
+			endpoint:        "/v1/1/stock/movements",
+			body:            strings.NewReader(`{"type":"sale","delta":1}`),
+			productsService: &mockService{},
+			expectedCode:    http.StatusBadRequest,
+		},
+		{
+			name:            "failed to record stock movement, zero delta",
+			method:          http.MethodPost,
+			endpoint:        "/v1/1/stock/movements",
+			body:            strings.NewReader(`{"type":"adjustment","delta":0}`),
+			productsService: &mockService{},
+			expectedCode:    http.StatusBadRequest,
+		},
+		{
+			name:            "failed to record stock movement, delta out of range",
+			method:          http.MethodPost,
+			endpoint:        "/v1/1/stock/movements",
+			body:            strings.NewReader(`{"type":"adjustment","delta":-9223372036854775808}`),
+			productsService: &mockService{},
+			expectedCode:    http.StatusBadRequest,
+		},
+		{
+			name:     "failed to record stock movement, stock over the maximum",
+			method:   http.MethodPost,
+			endpoint: "/v1/1/stock/movements",
+			body:     strings.NewReader(`{"type":"receipt","delta":10}`),
+			productsService: &mockService{
+				err: internalErrors.ErrStockLimit,
+			},
+			expectedCode: http.StatusConflict,
+		},
+		{
+			name:     "failed to record stock movement, variant not found",
+			method:   http.MethodPost,
+			endpoint: "/v1/1/stock/movements",
+			body:     strings.NewReader(`{"type":"receipt","delta":1,"variant_sku":"fake"}`),
+			productsService: &mockService{
+				err: internalErrors.ErrVariantNotFound,
+			},
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:     "failed to record stock movement, product is a bundle",
+			method:   http.MethodPost,
+			endpoint: "/v1/1/stock/movements",
+			body:     strings.NewReader(`{"type":"receipt","delta":1}`),
+			productsService: &mockService{
+				err: internalErrors.ErrBundleStock,
+			},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:     "failed to record stock movement, insufficient stock",
+			method:   http.MethodPost,
+			endpoint: "/v1/1/stock/movements",
+			body:     strings.NewReader(`{"type":"sale","delta":-5}`),
+			productsService: &mockService{
+				err: internalErrors.ErrInsufficientStock,
+			},
+			expectedCode: http.StatusConflict,
+		},
+		{
+			name:            "successfully increment product stock",
+			method:          http.MethodPost,
+			endpoint:        "/v1/1/stock/increment",
+			body:            strings.NewReader(`{"qty":5,"reference":"PO-2"}`),
+			productsService: &mockService{},
+			expectedCode:    http.StatusOK,
+		},
+		{
+			name:            "successfully increment product stock by sku",
+			method:          http.MethodPost,
+			endpoint:        "/v1/sku/shoes-42/stock/increment",
+			body:            strings.NewReader(`{"qty":1,"type":"return"}`),
+			productsService: &mockService{},
+			expectedCode:    http.StatusOK,
+		},
+		{
+			name:            "failed to increment product stock, zero qty",
+			method:          http.MethodPost,
+			endpoint:        "/v1/1/stock/increment",
+			body:            strings.NewReader(`{"qty":0}`),
+			productsService: &mockService{},
+			expectedCode:    http.StatusBadRequest,
+		},
+		{
+			name:            "failed to increment product stock, sale adding stock",
+			method:          http.MethodPost,
+			endpoint:        "/v1/1/stock/increment",
+			body:            strings.NewReader(`{"qty":1,"type":"sale"}`),
+			productsService: &mockService{},
+			expectedCode:    http.StatusBadRequest,
+		},
+		{
+			name:            "successfully decrement product stock",
+			method:          http.MethodPost,
+			endpoint:        "/v1/1/stock/decrement",
+			body:            strings.NewReader(`{"qty":2,"variant_sku":"shoes-42","reference":"SO-2"}`),
+			productsService: &mockService{},
+			expectedCode:    http.StatusOK,
+		},
+		{
+			name:            "successfully decrement product stock by sku",
+			method:          http.MethodPost,
+			endpoint:        "/v1/sku/shoes-42/stock/decrement",
+			body:            strings.NewReader(`{"qty":1,"type":"damage"}`),
+			productsService: &mockService{},
+			expectedCode:    http.StatusOK,
+		},
+		{
+			name:            "failed to decrement product stock, variant sku addressed by sku",
+			method:          http.MethodPost,
+			endpoint:        "/v1/sku/shoes/stock/decrement",
+			body:            strings.NewReader(`{"qty":1,"variant_sku":"shoes-42"}`),
+			productsService: &mockService{},
+			expectedCode:    http.StatusBadRequest,
+		},
+		{
+			name:     "failed to decrement product stock, product not found",
+			method:   http.MethodPost,
+			endpoint: "/v1/sku/fake/stock/decrement",
+			body:     strings.NewReader(`{"qty":1}`),
+			productsService: &mockService{
+				err: internalErrors.ErrProductNotFound,
+			},
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:     "failed to decrement product stock, insufficient stock",
+			method:   http.MethodPost,
+			endpoint: "/v1/1/stock/decrement",
+			body:     strings.NewReader(`{"qty":50}`),
+			productsService: &mockService{
+				err: internalErrors.ErrInsufficientStock,
+			},
+			expectedCode: http.StatusConflict,
+		},
+		{
+			name:            "successfully list stock movements",
+			method:          http.MethodGet,
+			endpoint:        "/v1/1/stock/movements?limit=10&offset=0&type=sale&type=return&from=2024-01-01T00:00:00Z&to=2024-02-01T00:00:00Z",
+			productsService: &mockService{},
+			expectedCode:    http.StatusOK,
+		},
+		{
+			name:            "failed to list stock movements, incorrect limit",
+			method:          http.MethodGet,
+			endpoint:        "/v1/1/stock/movements?offset=0",
+			productsService: &mockService{},
+			expectedCode:    http.StatusBadRequest,
+		},
+		{
+			name:            "failed to list stock movements, incorrect date",
+			method:          http.MethodGet,
+			endpoint:        "/v1/1/stock/movements?limit=10&offset=0&from=yesterday",
+			productsService: &mockService{},
+			expectedCode:    http.StatusBadRequest,
+		},
+		{
+			name:            "failed to list stock movements, incorrect dates range",
+			method:          http.MethodGet,
+			endpoint:        "/v1/1/stock/movements?limit=10&offset=0&from=2024-02-01T00:00:00Z&to=2024-01-01T00:00:00Z",
+			productsService: &mockService{},
+			expectedCode:    http.StatusBadRequest,
+		},
+		{
+			name:     "failed to list stock movements, product not found",
+			method:   http.MethodGet,
+			endpoint: "/v1/1/stock/movements?limit=10&offset=0",
+			productsService: &mockService{
+				err: internalErrors.ErrProductNotFound,
+			},
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:            "successfully verify product stock",
+			method:          http.MethodGet,
+			endpoint:        "/v1/1/stock/ledger",
+			productsService: &mockService{},
+			expectedCode:    http.StatusOK,
+		},
+		{
+			name:     "failed to verify product stock, error on service",
+			method:   http.MethodGet,
+			endpoint: "/v1/1/stock/ledger",
+			productsService: &mockService{
+				err: errors.New("error on service"),
+			},
+			expectedCode: http.StatusInternalServerError,
+		},
+		{
+			name:            "successfully rebuild product stock",
+			method:          http.MethodPost,
+			endpoint:        "/v1/1/stock/rebuild",
+			productsService: &mockService{},
+			expectedCode:    http.StatusOK,
+		},
+		{
+			name:     "failed to rebuild product stock, movements add up to a negative qty",
+			method:   http.MethodPost,
+			endpoint: "/v1/1/stock/rebuild",
+			productsService: &mockService{
+				err: internalErrors.ErrInsufficientStock,
+			},
+			expectedCode: http.StatusConflict,
+		},
+	}
+
+	for _, dt := range dataTable {
+		t.Run(dt.name, func(t *testing.T) {
+			// Given
+			app := New(
+				dt.productsService,
+				mux.NewRouter(),
+				"",
+				"",
+				"",
+				"")
+
+			w := httptest.NewRecorder()
+
+			req := httptest.NewRequest(dt.method, dt.endpoint, dt.body)
+
+			// When
+			app.serveHTTP(w, req)
+
+			// Then
+			assert.Equal(t, dt.expectedCode, w.Code)
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+
+	internalErrors "github.com/srodrmendz/api-product-catalog/errors"
+	"github.com/srodrmendz/api-product-catalog/model"
+	"github.com/srodrmendz/api-product-catalog/utils"
+)
+
+// Set Translation godoc
+// @Tags translations
+// @Description Set product name, description and slug on a locale, replacing the translation already on it
+// @Description The translation keeps its slug unless another one is requested, new translations get one from their name
+// @Accept  json
+// @Produce  json
+// @Param request body model.Translation true "Request body"
+// @Param id path string true "id"
+// @Param locale path string true "locale"
+// @Param If-Match header string false "product version the translation applies to"
+// @Success 200 {object} model.Product
+// @Failure 400
+// @Failure 404
+// @Failure 409
+// @Failure 500
+// @Router /v1/{id}/translations/{locale} [put]
+func (a *App) setTranslation(w http.ResponseWriter, r *http.Request) {
+	builder := model.NewTranslationBuilder(r)
+
+	request, err := builder.Build()
+	if err != nil {
+		utils.ErrJSON(w, http.StatusBadRequest, err)
+
+		return
+	}
+
+	product, err := a.Services.ProductsService.SetTranslation(r.Context(), *request)
+
+	a.translationResponse(w, product, err)
+}
+
+// Remove Translation godoc
+// @Tags translations
+// @Description Remove product translation to a locale
+// @Accept  json
+// @Produce  json
+// @Param id path string true "id"
+// @Param locale path string true "locale"
+// @Param If-Match header string false "product version the translation applies to"
+// @Success 200 {object} model.Product
+// @Failure 400
+// @Failure 404
+// @Failure 409
+// @Failure 500
+// @Router /v1/{id}/translations/{locale} [delete]
+func (a *App) removeTranslation(w http.ResponseWriter, r *http.Request) {
+	builder := model.NewTranslationBuilder(r)
+
+	request, err := builder.Build()
+	if err != nil {
+		utils.ErrJSON(w, http.StatusBadRequest, err)
+
+		return
+	}
+
+	product, err := a.Services.ProductsService.RemoveTranslation(r.Context(), *request)
+
+	a.translationResponse(w, product, err)
+}
+
+func (a *App) translationResponse(w http.ResponseWriter, product *model.Product, err error) {
+	if err != nil {
+		if errors.Is(err, internalErrors.ErrProductNotFound) || errors.Is(err, internalErrors.ErrTranslationNotFound) {
+			utils.ErrJSON(w, http.StatusNotFound, err)
+
+			return
+		}
+
+		if errors.Is(err, internalErrors.ErrTranslationLocale) {
+			utils.ErrJSON(w, http.StatusBadRequest, err)
+
+			return
+		}
+
+		if errors.Is(err, internalErrors.ErrVersionConflict) {
+			versionConflict(w, err)
+
+			return
+		}
+
+		if errors.Is(err, internalErrors.ErrSlugAlreadyExist) {
+			utils.ErrJSON(w, http.StatusConflict, err)
+
+			return
+		}
+
+		utils.ErrJSON(w, http.StatusInternalServerError, err)
+
+		return
+	}
+
+	w.Header().Set("ETag", model.ETag(product.Version))
+
+	utils.DataJSON(w, http.StatusOK, product)
+}
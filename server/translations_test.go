@@ -0,0 +1,138 @@
+package server
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	internalErrors "github.com/srodrmendz/api-product-catalog/errors"
+	"github.com/srodrmendz/api-product-catalog/model"
+	"github.com/srodrmendz/api-product-catalog/service"
+	"github.com/stretchr/testify/assert"
+)
+
+// Test product translation endpoints
+func TestServer_Translations(t *testing.T) {
+	dataTable := []struct {
+		name            string
+		method          string
+		endpoint        string
+		body            io.Reader
+		productsService service.Service
+		expectedCode    int
+	}{
+		{
+			name:            "successfully set product translation",
+			method:          http.MethodPut,
+			endpoint:        "/v1/1/translations/es",
+			body:            mockRequest(model.Translation{Name: "camisa", Slug: "camisa"}),
+			productsService: &mockService{},
+			expectedCode:    http.StatusOK,
+		},
+		{
+			name:            "failed to set product translation, invalid locale",
+			method:          http.MethodPut,
+			endpoint:        "/v1/1/translations/spanish_",
+			body:            mockRequest(model.Translation{Name: "camisa"}),
+			productsService: &mockService{},
+			expectedCode:    http.StatusBadRequest,
+		},
+		{
+			name:            "failed to set product translation, empty name",
+			method:          http.MethodPut,
+			endpoint:        "/v1/1/translations/es",
+			body:            mockRequest(model.Translation{}),
+			productsService: &mockService{},
+			expectedCode:    http.StatusBadRequest,
+		},
+		{
+			name:            "failed to set product translation, invalid slug",
+			method:          http.MethodPut,
+			endpoint:        "/v1/1/translations/es",
+			body:            mockRequest(model.Translation{Name: "camisa", Slug: "Camisa Roja"}),
+			productsService: &mockService{},
+			expectedCode:    http.StatusBadRequest,
+		},
+		{
+			name:     "failed to set product translation, product locale",
+			method:   http.MethodPut,
+			endpoint: "/v1/1/translations/en",
+			body:     mockRequest(model.Translation{Name: "shirt"}),
+			productsService: &mockService{
+				err: internalErrors.ErrTranslationLocale,
+			},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:     "failed to set product translation, slug already exist",
+			method:   http.MethodPut,
+			endpoint: "/v1/1/translations/es",
+			body:     mockRequest(model.Translation{Name: "camisa", Slug: "camisa"}),
+			productsService: &mockService{
+				err: internalErrors.ErrSlugAlreadyExist,
+			},
+			expectedCode: http.StatusConflict,
+		},
+		{
+			name:     "failed to set product translation, version conflict",
+			method:   http.MethodPut,
+			endpoint: "/v1/1/translations/es",
+			body:     mockRequest(model.Translation{Name: "camisa"}),
+			productsService: &mockService{
+				err: &internalErrors.VersionConflictError{Current: 2},
+			},
+			expectedCode: http.StatusConflict,
+		},
+		{
+			name:            "successfully remove product translation",
+			method:          http.MethodDelete,
+			endpoint:        "/v1/1/translations/es",
+			productsService: &mockService{},
+			expectedCode:    http.StatusOK,
+		},
+		{
+			name:     "failed to remove product translation, translation not found",
+			method:   http.MethodDelete,
+			endpoint: "/v1/1/translations/es",
+			productsService: &mockService{
+				err: internalErrors.ErrTranslationNotFound,
+			},
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:     "failed to remove product translation, error on service",
+			method:   http.MethodDelete,
+			endpoint: "/v1/1/translations/es",
+			productsService: &mockService{
+				err: errors.New("error on service"),
+			},
+			expectedCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, dt := range dataTable {
+		t.Run(dt.name, func(t *testing.T) {
+			// Given
+			app := New(
+				dt.productsService,
+				mux.NewRouter(),
+				"",
+				"",
+				"",
+				"")
+
+			w := httptest.NewRecorder()
+
+			req := httptest.NewRequest(dt.method, dt.endpoint, dt.body)
+
+			// When
+			app.serveHTTP(w, req)
+
+			// Then
+			assert.Equal(t, dt.expectedCode, w.Code)
+		})
+	}
+}
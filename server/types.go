@@ -12,6 +12,8 @@ type services struct {
 type config struct {
 	Version   string
 	BuildDate string
+	// Bearer token of the admin callers, no caller is admin when it is empty
+	AdminToken string
 }
 
 type App struct {
@@ -19,3 +21,16 @@ type App struct {
 	Config   config
 	Router   *mux.Router
 }
+
+// Represent version conflict response, Version is the current product version
+type conflictResponse struct {
+	Error   string `json:"error"`
+	Version int64  `json:"version"`
+}
+
+// Represent a redirect from a previous product slug, Slug is the current one and Location the route getting the product by it
+type redirectResponse struct {
+	Slug      string `json:"slug"`
+	ProductID string `json:"product_id"`
+	Location  string `json:"location"`
+}
@@ -0,0 +1,61 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	internalError "github.com/srodrmendz/api-product-catalog/errors"
+	"github.com/srodrmendz/api-product-catalog/model"
+	"github.com/srodrmendz/api-product-catalog/repository"
+)
+
+// Validate product attributes against the definitions of its categories and their ancestors, the categories must exist
+func validateAttributes(ctx context.Context, repo repository.Repository, categoryIDs []string, attributes map[string]interface{}) error {
+	definitions, err := attributeDefinitions(ctx, repo, categoryIDs)
+	if err != nil {
+		return err
+	}
+
+	if err := model.ValidateAttributes(attributes, definitions); err != nil {
+		return fmt.Errorf("%w, %s", internalError.ErrAttributeInvalid, err)
+	}
+
+	return nil
+}
+
+// Attribute definitions of the categories and their ancestors, each category is read once
+func attributeDefinitions(ctx context.Context, repo repository.Repository, categoryIDs []string) ([]model.AttributeDefinition, error) {
+	var definitions []model.AttributeDefinition
+
+	read := make(map[string]bool)
+
+	for _, id := range categoryIDs {
+		category, err := repo.GetCategory(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+
+		if !read[category.ID] {
+			read[category.ID] = true
+
+			definitions = append(definitions, category.Attributes...)
+		}
+
+		for _, ancestorID := range category.Path {
+			if read[ancestorID] {
+				continue
+			}
+
+			read[ancestorID] = true
+
+			ancestor, err := repo.GetCategory(ctx, ancestorID)
+			if err != nil {
+				return nil, err
+			}
+
+			definitions = append(definitions, ancestor.Attributes...)
+		}
+	}
+
+	return definitions, nil
+}
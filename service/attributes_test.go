@@ -0,0 +1,117 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	internalErrors "github.com/srodrmendz/api-product-catalog/errors"
+	"github.com/srodrmendz/api-product-catalog/model"
+	"github.com/srodrmendz/api-product-catalog/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test attribute validation on top of the in memory repository
+func TestService_Attributes(t *testing.T) {
+	ctx := context.TODO()
+
+	// Given electronics > laptops, with the voltage defined on electronics and the screen size on laptops
+	newTree := func(t *testing.T) (*ProductsCatalogService, *model.Category) {
+		srv := New(repository.NewMemory(), Config{})
+
+		electronics, err := srv.CreateCategory(ctx, model.Category{
+			Name:       "electronics",
+			Attributes: []model.AttributeDefinition{{Name: "voltage", Type: model.AttributeNumber, Unit: "V", Required: true}},
+		})
+		require.NoError(t, err)
+
+		laptops, err := srv.CreateCategory(ctx, model.Category{
+			Name:     "laptops",
+			ParentID: electronics.ID,
+			Attributes: []model.AttributeDefinition{
+				{Name: "screen_size", Type: model.AttributeNumber, Unit: "in"},
+				{Name: "color", Type: model.AttributeEnum, Values: []string{"black", "silver"}},
+			},
+		})
+		require.NoError(t, err)
+
+		return srv, laptops
+	}
+
+	laptop := func(category *model.Category, attributes map[string]interface{}) model.Product {
+		return model.Product{Name: "laptop", Sku: "laptop", Price: 100, CategoryIDs: []string{category.ID}, Attributes: attributes}
+	}
+
+	t.Run("create product with attributes of its category and ancestors", func(t *testing.T) {
+		srv, laptops := newTree(t)
+
+		product, err := srv.Create(ctx, laptop(laptops, map[string]interface{}{"voltage": 220.0, "screen_size": 13.3, "color": "black"}))
+		require.NoError(t, err)
+
+		assert.Equal(t, 13.3, product.Attributes["screen_size"])
+	})
+
+	t.Run("create product fails on invalid attributes", func(t *testing.T) {
+		srv, laptops := newTree(t)
+
+		for name, attributes := range map[string]map[string]interface{}{
+			"required attribute missing": {"screen_size": 13.3},
+			"wrong type":                 {"voltage": "220"},
+			"value not on enum":          {"voltage": 220.0, "color": "red"},
+			"attribute not defined":      {"voltage": 220.0, "material": "aluminium"},
+		} {
+			_, err := srv.Create(ctx, laptop(laptops, attributes))
+
+			assert.ErrorIs(t, err, internalErrors.ErrAttributeInvalid, name)
+		}
+	})
+
+	t.Run("create product without categories fails with attributes", func(t *testing.T) {
+		srv := New(repository.NewMemory(), Config{})
+
+		_, err := srv.Create(ctx, model.Product{Name: "laptop", Sku: "laptop", Price: 100, Attributes: map[string]interface{}{"voltage": 220.0}})
+
+		assert.ErrorIs(t, err, internalErrors.ErrAttributeInvalid)
+	})
+
+	t.Run("update product attributes", func(t *testing.T) {
+		srv, laptops := newTree(t)
+
+		product, err := srv.Create(ctx, laptop(laptops, map[string]interface{}{"voltage": 220.0}))
+		require.NoError(t, err)
+
+		_, err = srv.Update(ctx, &model.Update{ID: product.ID, UpdateRequest: model.UpdateRequest{Attributes: map[string]interface{}{"voltage": "high"}}})
+		assert.ErrorIs(t, err, internalErrors.ErrAttributeInvalid)
+
+		updated, err := srv.Update(ctx, &model.Update{
+			ID:            product.ID,
+			UpdateRequest: model.UpdateRequest{Qty: qtyOf(5), Attributes: map[string]interface{}{"voltage": 110.0, "color": "silver"}},
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, map[string]interface{}{"voltage": 110.0, "color": "silver"}, updated.Attributes)
+
+		ledger, err := srv.ListStockMovements(ctx, model.MovementSearchRequest{ProductID: product.ID})
+		require.NoError(t, err)
+
+		// Updates without qty keep the stock and record nothing on the ledger
+		updated, err = srv.Update(ctx, &model.Update{
+			ID:            product.ID,
+			UpdateRequest: model.UpdateRequest{Attributes: map[string]interface{}{"voltage": 110.0, "color": "silver"}},
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, uint64(5), updated.Qty)
+
+		response, err := srv.ListStockMovements(ctx, model.MovementSearchRequest{ProductID: product.ID})
+		require.NoError(t, err)
+
+		assert.Equal(t, ledger.Metadata.Total, response.Metadata.Total)
+
+		// Updates without attributes keep them
+		updated, err = srv.Update(ctx, &model.Update{ID: product.ID, UpdateRequest: model.UpdateRequest{Qty: qtyOf(1)}})
+		require.NoError(t, err)
+
+		assert.Equal(t, map[string]interface{}{"voltage": 110.0, "color": "silver"}, updated.Attributes)
+	})
+}
@@ -0,0 +1,135 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	internalError "github.com/srodrmendz/api-product-catalog/errors"
+	"github.com/srodrmendz/api-product-catalog/model"
+	"github.com/srodrmendz/api-product-catalog/repository"
+)
+
+// Create a new brand, its name must not be taken by another brand
+func (s *ProductsCatalogService) CreateBrand(ctx context.Context, brand model.Brand) (*model.Brand, error) {
+	var created *model.Brand
+
+	err := s.repository.WithTransaction(ctx, func(txRepo repository.Repository) error {
+		if err := validateBrandName(ctx, txRepo, brand); err != nil {
+			return err
+		}
+
+		var err error
+
+		created, err = txRepo.CreateBrand(ctx, brand)
+
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return created, nil
+}
+
+// Get a brand by id
+func (s *ProductsCatalogService) GetBrand(ctx context.Context, id string) (*model.Brand, error) {
+	return s.repository.GetBrand(ctx, id)
+}
+
+// List every brand sorted by name
+func (s *ProductsCatalogService) ListBrands(ctx context.Context) ([]model.Brand, error) {
+	return s.repository.ListBrands(ctx)
+}
+
+// Rename a brand or change its description, products keep its id so they follow it
+func (s *ProductsCatalogService) UpdateBrand(ctx context.Context, brand model.Brand) (*model.Brand, error) {
+	var updated *model.Brand
+
+	err := s.repository.WithTransaction(ctx, func(txRepo repository.Repository) error {
+		if err := validateBrandName(ctx, txRepo, brand); err != nil {
+			return err
+		}
+
+		var err error
+
+		updated, err = txRepo.UpdateBrand(ctx, brand)
+
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return updated, nil
+}
+
+// Delete a brand, brands of stored products are not deleted even when the products are
+func (s *ProductsCatalogService) DeleteBrand(ctx context.Context, id string) error {
+	return s.repository.WithTransaction(ctx, func(txRepo repository.Repository) error {
+		if _, err := txRepo.GetBrand(ctx, id); err != nil {
+			return err
+		}
+
+		// Searches match the in stock filter exactly, so both values are searched
+		for _, inStock := range []bool{true, false} {
+			page, err := txRepo.Search(ctx, model.SearchRequest{
+				Limit:          1,
+				InStock:        inStock,
+				IncludeDeleted: true,
+				Admin:          true,
+				BrandID:        id,
+			})
+			if err != nil {
+				return err
+			}
+
+			if page.Total > 0 {
+				return internalError.ErrBrandInUse
+			}
+		}
+
+		return txRepo.DeleteBrand(ctx, id)
+	})
+}
+
+// Count the tags of the products matching the search filters, callers other than admins only count the visible products
+func (s *ProductsCatalogService) TagCounts(ctx context.Context, request model.SearchRequest) (*model.TagCountsResponse, error) {
+	request, err := s.resolveSearch(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	tags, err := s.repository.CountTags(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.TagCountsResponse{Tags: tags}, nil
+}
+
+// Brand names are unique ignoring case, the brand itself is skipped when it is renamed
+func validateBrandName(ctx context.Context, repo repository.Repository, brand model.Brand) error {
+	brands, err := repo.ListBrands(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, stored := range brands {
+		if stored.ID != brand.ID && strings.EqualFold(stored.Name, brand.Name) {
+			return internalError.ErrBrandAlreadyExist
+		}
+	}
+
+	return nil
+}
+
+// Validate the brand of a product exists, an empty brand id is a product without brand
+func validateBrand(ctx context.Context, repo repository.Repository, brandID string) error {
+	if brandID == "" {
+		return nil
+	}
+
+	_, err := repo.GetBrand(ctx, brandID)
+
+	return err
+}
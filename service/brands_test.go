@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	internalErrors "github.com/srodrmendz/api-product-catalog/errors"
+	"github.com/srodrmendz/api-product-catalog/model"
+	"github.com/srodrmendz/api-product-catalog/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test brands and tags on top of the in memory repository
+func TestService_Brands(t *testing.T) {
+	ctx := context.TODO()
+
+	t.Run("brand names are unique ignoring case", func(t *testing.T) {
+		srv := New(repository.NewMemory(), Config{})
+
+		acme, err := srv.CreateBrand(ctx, model.Brand{Name: "Acme"})
+		require.NoError(t, err)
+
+		_, err = srv.CreateBrand(ctx, model.Brand{Name: "ACME"})
+		assert.ErrorIs(t, err, internalErrors.ErrBrandAlreadyExist)
+
+		mondo, err := srv.CreateBrand(ctx, model.Brand{Name: "Mondo"})
+		require.NoError(t, err)
+
+		_, err = srv.UpdateBrand(ctx, model.Brand{ID: mondo.ID, Name: "acme"})
+		assert.ErrorIs(t, err, internalErrors.ErrBrandAlreadyExist)
+
+		// Renaming a brand to its own name on another case is allowed
+		updated, err := srv.UpdateBrand(ctx, model.Brand{ID: acme.ID, Name: "ACME"})
+		require.NoError(t, err)
+
+		assert.Equal(t, "ACME", updated.Name)
+	})
+
+	t.Run("products must reference an existing brand", func(t *testing.T) {
+		srv := New(repository.NewMemory(), Config{})
+
+		_, err := srv.Create(ctx, model.Product{Name: "shoes", Sku: "shoes", Qty: 1, Price: 100, BrandID: "fake"})
+		assert.ErrorIs(t, err, internalErrors.ErrBrandNotFound)
+
+		product, err := srv.Create(ctx, model.Product{Name: "shoes", Sku: "shoes", Qty: 1, Price: 100})
+		require.NoError(t, err)
+
+		fake := "fake"
+
+		_, err = srv.Update(ctx, &model.Update{ID: product.ID, UpdateRequest: model.UpdateRequest{BrandID: &fake}})
+		assert.ErrorIs(t, err, internalErrors.ErrBrandNotFound)
+
+		brand, err := srv.CreateBrand(ctx, model.Brand{Name: "Acme"})
+		require.NoError(t, err)
+
+		updated, err := srv.Update(ctx, &model.Update{ID: product.ID, UpdateRequest: model.UpdateRequest{BrandID: &brand.ID}})
+		require.NoError(t, err)
+
+		assert.Equal(t, brand.ID, updated.BrandID)
+		assert.Equal(t, uint64(1), updated.Qty)
+
+		updated, err = srv.Update(ctx, &model.Update{ID: product.ID, UpdateRequest: model.UpdateRequest{Tags: []string{"sale"}}})
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"sale"}, updated.Tags)
+		assert.Equal(t, uint64(1), updated.Qty)
+		assert.Equal(t, true, updated.InStock)
+	})
+
+	t.Run("brands with products cannot be deleted, even deleted or out of stock ones", func(t *testing.T) {
+		srv := New(repository.NewMemory(), Config{})
+
+		brand, err := srv.CreateBrand(ctx, model.Brand{Name: "Acme"})
+		require.NoError(t, err)
+
+		product, err := srv.Create(ctx, model.Product{Name: "shoes", Sku: "shoes", Qty: 0, Price: 100, BrandID: brand.ID, Status: model.StatusDraft})
+		require.NoError(t, err)
+
+		require.NoError(t, srv.Delete(ctx, product.ID, 0))
+
+		assert.ErrorIs(t, srv.DeleteBrand(ctx, brand.ID), internalErrors.ErrBrandInUse)
+
+		_, err = srv.Purge(ctx, -time.Minute)
+		require.NoError(t, err)
+
+		require.NoError(t, srv.DeleteBrand(ctx, brand.ID))
+
+		assert.ErrorIs(t, srv.DeleteBrand(ctx, brand.ID), internalErrors.ErrBrandNotFound)
+	})
+
+	t.Run("tag counts only count the products visible to the caller", func(t *testing.T) {
+		srv := New(repository.NewMemory(), Config{})
+
+		for _, product := range []model.Product{
+			{Name: "shoes", Sku: "shoes", Qty: 1, Price: 100, Tags: []string{"sale", "running"}},
+			{Name: "shirt", Sku: "shirt", Qty: 1, Price: 100, Tags: []string{"sale"}},
+			{Name: "hat", Sku: "hat", Qty: 1, Price: 100, Tags: []string{"sale", "new"}, Status: model.StatusDraft},
+		} {
+			_, err := srv.Create(ctx, product)
+			require.NoError(t, err)
+		}
+
+		response, err := srv.TagCounts(ctx, model.SearchRequest{InStock: true})
+		require.NoError(t, err)
+
+		assert.Equal(t, []model.TagCount{{Tag: "sale", Count: 2}, {Tag: "running", Count: 1}}, response.Tags)
+
+		response, err = srv.TagCounts(ctx, model.SearchRequest{InStock: true, Admin: true})
+		require.NoError(t, err)
+
+		assert.Equal(t, []model.TagCount{{Tag: "sale", Count: 3}, {Tag: "new", Count: 1}, {Tag: "running", Count: 1}}, response.Tags)
+	})
+}
@@ -0,0 +1,178 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	internalError "github.com/srodrmendz/api-product-catalog/errors"
+	"github.com/srodrmendz/api-product-catalog/model"
+	"github.com/srodrmendz/api-product-catalog/repository"
+)
+
+// Validate the components of a new bundle and compute its qty, in_stock and price from them
+// Components must exist and be plain products on the bundle currency that are not archived
+func (s *ProductsCatalogService) validateBundle(ctx context.Context, repo repository.Repository, product *model.Product) error {
+	if !product.IsBundle() {
+		return nil
+	}
+
+	components := make(map[string]model.Product, len(product.Bundle.Components))
+
+	for _, id := range product.ComponentIDs() {
+		component, err := repo.GetByID(ctx, id)
+		if errors.Is(err, internalError.ErrProductNotFound) {
+			return internalError.ErrComponentNotFound
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if component.IsBundle() || len(component.Variants) > 0 || component.Status == model.StatusArchived ||
+			s.currencyOf(*component) != s.currencyOf(*product) {
+			return internalError.ErrComponentInvalid
+		}
+
+		component.ResolvePrice(time.Now())
+
+		components[id] = *component
+	}
+
+	product.ResolveBundle(components)
+
+	return nil
+}
+
+// Compute the bundle qty, in_stock and price from its components as they are now, products other than bundles are unchanged
+func resolveBundle(ctx context.Context, repo repository.Repository, product *model.Product) error {
+	if !product.IsBundle() {
+		return nil
+	}
+
+	now := time.Now()
+
+	components := make(map[string]model.Product, len(product.Bundle.Components))
+
+	for _, id := range product.ComponentIDs() {
+		component, err := repo.GetByID(ctx, id)
+		if errors.Is(err, internalError.ErrProductNotFound) {
+			continue
+		}
+
+		if err != nil {
+			return err
+		}
+
+		component.ResolvePrice(now)
+
+		components[id] = *component
+	}
+
+	product.ResolveBundle(components)
+
+	return nil
+}
+
+// Refresh the product when it is a bundle, or the bundles holding it otherwise, and get the product as refreshed
+func (s *ProductsCatalogService) refreshBundles(ctx context.Context, product *model.Product) (*model.Product, error) {
+	if product.IsBundle() {
+		return s.refreshBundle(ctx, product.ID)
+	}
+
+	if err := s.refreshBundlesOf(ctx, product.ID); err != nil {
+		return nil, err
+	}
+
+	return product, nil
+}
+
+// Store the qty, in_stock and price of the bundle computed from its components, so searches filter and sort it on them
+func (s *ProductsCatalogService) refreshBundle(ctx context.Context, id string) (*model.Product, error) {
+	var refreshed *model.Product
+
+	err := s.repository.WithTransaction(ctx, func(txRepo repository.Repository) error {
+		product, err := txRepo.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		stored := *product
+
+		if err := resolveBundle(ctx, txRepo, product); err != nil {
+			return err
+		}
+
+		if product.Qty == stored.Qty && product.InStock == stored.InStock && product.Price == stored.Price {
+			refreshed = product
+
+			return nil
+		}
+
+		refreshed, err = txRepo.Replace(ctx, *product)
+
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return refreshed, nil
+}
+
+// Refresh the stored qty, in_stock and price of the bundles holding the product after it changed
+// Bundles changed in the meantime are skipped, they are resolved again when they are read
+func (s *ProductsCatalogService) refreshBundlesOf(ctx context.Context, id string) error {
+	bundles, err := bundlesOf(ctx, s.repository, id, nil)
+	if err != nil {
+		return err
+	}
+
+	for _, bundle := range bundles {
+		_, err := s.refreshBundle(ctx, bundle.ID)
+		if err != nil && !errors.Is(err, internalError.ErrVersionConflict) && !errors.Is(err, internalError.ErrProductNotFound) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Fail with ErrProductInBundle when the product is a component of bundles that are not archived or deleted
+func checkNotInBundle(ctx context.Context, repo repository.Repository, id string) error {
+	bundles, err := bundlesOf(ctx, repo, id, []model.Status{model.StatusDraft, model.StatusPublished})
+	if err != nil {
+		return err
+	}
+
+	if len(bundles) > 0 {
+		return internalError.ErrProductInBundle
+	}
+
+	return nil
+}
+
+// Get the bundles holding the product that are not deleted, on any of the statuses or on every status when they are empty
+func bundlesOf(ctx context.Context, repo repository.Repository, id string, statuses []model.Status) ([]model.Product, error) {
+	var bundles []model.Product
+
+	for _, inStock := range []bool{true, false} {
+		page, err := repo.Search(ctx, model.SearchRequest{ComponentID: id, InStock: inStock, Admin: true, Statuses: statuses})
+		if err != nil {
+			return nil, err
+		}
+
+		bundles = append(bundles, page.Products...)
+	}
+
+	return bundles, nil
+}
+
+// Currency the product is priced on, products without one are on the default currency
+func (s *ProductsCatalogService) currencyOf(product model.Product) string {
+	if product.Currency == "" {
+		return s.config.DefaultCurrency
+	}
+
+	return product.Currency
+}
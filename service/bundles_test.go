@@ -0,0 +1,165 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	internalErrors "github.com/srodrmendz/api-product-catalog/errors"
+	"github.com/srodrmendz/api-product-catalog/model"
+	"github.com/srodrmendz/api-product-catalog/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test bundles take their qty, in_stock and price from their components on top of the in memory repository
+func TestService_Bundles(t *testing.T) {
+	ctx := context.TODO()
+
+	setup := func(t *testing.T) (*ProductsCatalogService, *model.Product, *model.Product) {
+		srv := New(repository.NewMemory(), Config{DefaultCurrency: "USD"})
+
+		shoes, err := srv.Create(ctx, model.Product{Name: "shoes", Sku: "shoes", Qty: 5, Price: 1000})
+		require.NoError(t, err)
+
+		socks, err := srv.Create(ctx, model.Product{Name: "socks", Sku: "socks", Qty: 7, Price: 200})
+		require.NoError(t, err)
+
+		return srv, shoes, socks
+	}
+
+	kit := func(pricing model.BundlePricing, discount int64, components ...string) model.Product {
+		bundle := &model.Bundle{Pricing: pricing, Discount: discount}
+
+		for i, id := range components {
+			bundle.Components = append(bundle.Components, model.BundleComponent{ProductID: id, Qty: uint64(i + 1)})
+		}
+
+		product := model.Product{Name: "running kit", Sku: "running-kit", Bundle: bundle}
+
+		if pricing == model.BundlePricingFixed {
+			product.Price = 1500
+		}
+
+		return product
+	}
+
+	t.Run("bundle qty and price are computed from the components", func(t *testing.T) {
+		srv, shoes, socks := setup(t)
+
+		bundle, err := srv.Create(ctx, kit(model.BundlePricingComponents, 100, shoes.ID, socks.ID))
+		require.NoError(t, err)
+
+		// 5 shoes and 7 socks make 3 kits of 1 shoe and 2 socks
+		assert.Equal(t, uint64(3), bundle.Qty)
+
+		assert.Equal(t, true, bundle.InStock)
+
+		assert.Equal(t, int64(1000+2*200-100), bundle.Price)
+
+		fixed, err := srv.Create(ctx, model.Product{Name: "fixed kit", Sku: "fixed-kit", Price: 1500, Bundle: &model.Bundle{
+			Components: []model.BundleComponent{{ProductID: shoes.ID, Qty: 1}},
+		}})
+		require.NoError(t, err)
+
+		assert.Equal(t, uint64(5), fixed.Qty)
+
+		assert.Equal(t, int64(1500), fixed.Price)
+	})
+
+	t.Run("bundles follow the stock and price of their components", func(t *testing.T) {
+		srv, shoes, socks := setup(t)
+
+		bundle, err := srv.Create(ctx, kit(model.BundlePricingComponents, 0, shoes.ID, socks.ID))
+		require.NoError(t, err)
+
+		price := int64(800)
+
+		_, err = srv.Update(ctx, &model.Update{ID: shoes.ID, UpdateRequest: model.UpdateRequest{Qty: qtyOf(0), Price: &price}})
+		require.NoError(t, err)
+
+		product, err := srv.GetByID(ctx, bundle.ID, model.ReadOptions{})
+		require.NoError(t, err)
+
+		assert.Equal(t, uint64(0), product.Qty)
+
+		assert.Equal(t, false, product.InStock)
+
+		assert.Equal(t, int64(800+2*200), product.Price)
+
+		// The stored bundle is refreshed, so searches filter it on its components stock
+		response, err := srv.Search(ctx, model.SearchRequest{InStock: false, Admin: true, Sort: "asc"})
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{shoes.ID, bundle.ID}, searchIDs(response.Products))
+	})
+
+	t.Run("bundle components must exist and be plain products on the bundle currency", func(t *testing.T) {
+		srv, shoes, _ := setup(t)
+
+		_, err := srv.Create(ctx, kit(model.BundlePricingFixed, 0, shoes.ID, "fake"))
+		assert.ErrorIs(t, err, internalErrors.ErrComponentNotFound)
+
+		euros, err := srv.Create(ctx, model.Product{Name: "hat", Sku: "hat", Qty: 1, Price: 100, Currency: "EUR"})
+		require.NoError(t, err)
+
+		_, err = srv.Create(ctx, kit(model.BundlePricingFixed, 0, shoes.ID, euros.ID))
+		assert.ErrorIs(t, err, internalErrors.ErrComponentInvalid)
+
+		bundle, err := srv.Create(ctx, kit(model.BundlePricingFixed, 0, shoes.ID))
+		require.NoError(t, err)
+
+		nested := kit(model.BundlePricingFixed, 0, bundle.ID)
+
+		nested.Sku = "nested-kit"
+
+		_, err = srv.Create(ctx, nested)
+		assert.ErrorIs(t, err, internalErrors.ErrComponentInvalid)
+	})
+
+	t.Run("components of bundles cannot be deleted or archived", func(t *testing.T) {
+		srv, shoes, socks := setup(t)
+
+		bundle, err := srv.Create(ctx, kit(model.BundlePricingFixed, 0, shoes.ID, socks.ID))
+		require.NoError(t, err)
+
+		assert.ErrorIs(t, srv.Delete(ctx, socks.ID, 0), internalErrors.ErrProductInBundle)
+
+		_, err = srv.ChangeStatus(ctx, model.StatusRequest{ID: socks.ID, Transition: model.TransitionArchive})
+		assert.ErrorIs(t, err, internalErrors.ErrProductInBundle)
+
+		// Archived bundles no longer hold their components
+		_, err = srv.ChangeStatus(ctx, model.StatusRequest{ID: bundle.ID, Transition: model.TransitionArchive})
+		require.NoError(t, err)
+
+		require.NoError(t, srv.Delete(ctx, socks.ID, 0))
+	})
+
+	t.Run("bundles priced on their components cannot change or schedule their price", func(t *testing.T) {
+		srv, shoes, _ := setup(t)
+
+		bundle, err := srv.Create(ctx, kit(model.BundlePricingComponents, 0, shoes.ID))
+		require.NoError(t, err)
+
+		price := int64(900)
+
+		_, err = srv.Update(ctx, &model.Update{ID: bundle.ID, UpdateRequest: model.UpdateRequest{Qty: qtyOf(1), Price: &price}})
+		assert.ErrorIs(t, err, internalErrors.ErrBundlePricing)
+
+		_, err = srv.SchedulePrice(ctx, model.SchedulePriceRequest{ID: bundle.ID, ScheduledPrice: model.ScheduledPrice{
+			Price:    900,
+			StartsAt: time.Now().Add(time.Hour),
+		}})
+		assert.ErrorIs(t, err, internalErrors.ErrBundlePricing)
+	})
+}
+
+func searchIDs(products []model.Product) []string {
+	var ids []string
+
+	for _, product := range products {
+		ids = append(ids, product.ID)
+	}
+
+	return ids
+}
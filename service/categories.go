@@ -0,0 +1,104 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	internalError "github.com/srodrmendz/api-product-catalog/errors"
+	"github.com/srodrmendz/api-product-catalog/model"
+	"github.com/srodrmendz/api-product-catalog/repository"
+)
+
+// Create a new category under its parent
+func (s *ProductsCatalogService) CreateCategory(ctx context.Context, category model.Category) (*model.Category, error) {
+	path, err := categoryPath(ctx, s.repository, category.ParentID)
+	if err != nil {
+		return nil, err
+	}
+
+	category.Path = path
+
+	return s.repository.CreateCategory(ctx, category)
+}
+
+// Get a category by id
+func (s *ProductsCatalogService) GetCategory(ctx context.Context, id string) (*model.Category, error) {
+	return s.repository.GetCategory(ctx, id)
+}
+
+// List every category sorted by name
+func (s *ProductsCatalogService) ListCategories(ctx context.Context) ([]model.Category, error) {
+	return s.repository.ListCategories(ctx)
+}
+
+// Rename or move a category, products keep their category ids so they move with it
+func (s *ProductsCatalogService) UpdateCategory(ctx context.Context, category model.Category) (*model.Category, error) {
+	var updated *model.Category
+
+	err := s.repository.WithTransaction(ctx, func(txRepo repository.Repository) error {
+		subtree, err := txRepo.GetCategorySubtree(ctx, category.ID)
+		if err != nil {
+			return err
+		}
+
+		for _, descendant := range subtree {
+			if descendant.ID == category.ParentID {
+				return internalError.ErrCategoryInvalidParent
+			}
+		}
+
+		path, err := categoryPath(ctx, txRepo, category.ParentID)
+		if err != nil {
+			return err
+		}
+
+		category.Path = path
+
+		updated, err = txRepo.UpdateCategory(ctx, category)
+
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return updated, nil
+}
+
+// Delete a category without subcategories, its products are moved to its parent or lose it when it is a root category
+func (s *ProductsCatalogService) DeleteCategory(ctx context.Context, id string) error {
+	return s.repository.WithTransaction(ctx, func(txRepo repository.Repository) error {
+		subtree, err := txRepo.GetCategorySubtree(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		if len(subtree) > 1 {
+			return internalError.ErrCategoryHasChildren
+		}
+
+		if _, err := txRepo.ReplaceProductCategory(ctx, id, subtree[0].ParentID); err != nil {
+			return err
+		}
+
+		return txRepo.DeleteCategory(ctx, id)
+	})
+}
+
+// Path of a category placed under parent, an empty parent is the root
+func categoryPath(ctx context.Context, repo repository.Repository, parentID string) ([]string, error) {
+	if parentID == "" {
+		return []string{}, nil
+	}
+
+	parent, err := repo.GetCategory(ctx, parentID)
+	if err != nil {
+		if errors.Is(err, internalError.ErrCategoryNotFound) {
+			return nil, internalError.ErrCategoryParentNotFound
+		}
+
+		return nil, err
+	}
+
+	return append(parent.Path, parent.ID), nil
+}
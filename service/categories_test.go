@@ -0,0 +1,157 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	internalErrors "github.com/srodrmendz/api-product-catalog/errors"
+	"github.com/srodrmendz/api-product-catalog/model"
+	"github.com/srodrmendz/api-product-catalog/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test category rules on top of the in memory repository
+func TestService_Categories(t *testing.T) {
+	ctx := context.TODO()
+
+	// Given a tree with clothing > shoes > running and a product on each of them
+	newTree := func(t *testing.T) (*ProductsCatalogService, map[string]*model.Category) {
+		srv := New(repository.NewMemory(), Config{})
+
+		clothing, err := srv.CreateCategory(ctx, model.Category{Name: "clothing"})
+		require.NoError(t, err)
+
+		shoes, err := srv.CreateCategory(ctx, model.Category{Name: "shoes", ParentID: clothing.ID})
+		require.NoError(t, err)
+
+		running, err := srv.CreateCategory(ctx, model.Category{Name: "running", ParentID: shoes.ID})
+		require.NoError(t, err)
+
+		categories := map[string]*model.Category{"clothing": clothing, "shoes": shoes, "running": running}
+
+		for name, category := range categories {
+			_, err := srv.Create(ctx, model.Product{Name: name, Sku: name, Qty: 1, CategoryIDs: []string{category.ID}})
+			require.NoError(t, err)
+		}
+
+		return srv, categories
+	}
+
+	search := func(t *testing.T, srv *ProductsCatalogService, category string) []string {
+		response, err := srv.Search(ctx, model.SearchRequest{InStock: true, Category: category})
+		require.NoError(t, err)
+
+		var names []string
+
+		for _, product := range response.Products {
+			names = append(names, product.Name)
+		}
+
+		return names
+	}
+
+	t.Run("create category under its parent path", func(t *testing.T) {
+		_, categories := newTree(t)
+
+		assert.Equal(t, []string{}, categories["clothing"].Path)
+
+		assert.Equal(t, []string{categories["clothing"].ID, categories["shoes"].ID}, categories["running"].Path)
+	})
+
+	t.Run("failed to create category, parent not found", func(t *testing.T) {
+		srv, _ := newTree(t)
+
+		_, err := srv.CreateCategory(ctx, model.Category{Name: "hats", ParentID: "fake"})
+
+		assert.ErrorIs(t, err, internalErrors.ErrCategoryParentNotFound)
+	})
+
+	t.Run("failed to create product, category not found", func(t *testing.T) {
+		srv, _ := newTree(t)
+
+		_, err := srv.Create(ctx, model.Product{Name: "hat", Sku: "hat", CategoryIDs: []string{"fake"}})
+
+		assert.ErrorIs(t, err, internalErrors.ErrCategoryNotFound)
+	})
+
+	t.Run("search category includes its descendants", func(t *testing.T) {
+		srv, categories := newTree(t)
+
+		assert.ElementsMatch(t, []string{"shoes", "running"}, search(t, srv, categories["shoes"].ID))
+
+		assert.ElementsMatch(t, []string{"clothing", "shoes", "running"}, search(t, srv, categories["clothing"].ID))
+	})
+
+	t.Run("failed to search, category not found", func(t *testing.T) {
+		srv, _ := newTree(t)
+
+		_, err := srv.Search(ctx, model.SearchRequest{InStock: true, Category: "fake"})
+
+		assert.ErrorIs(t, err, internalErrors.ErrCategoryNotFound)
+	})
+
+	t.Run("move category with its subcategories and products", func(t *testing.T) {
+		srv, categories := newTree(t)
+
+		sports, err := srv.CreateCategory(ctx, model.Category{Name: "sports"})
+		require.NoError(t, err)
+
+		moved, err := srv.UpdateCategory(ctx, model.Category{ID: categories["shoes"].ID, Name: "shoes", ParentID: sports.ID})
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{sports.ID}, moved.Path)
+
+		running, err := srv.GetCategory(ctx, categories["running"].ID)
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{sports.ID, moved.ID}, running.Path)
+
+		assert.ElementsMatch(t, []string{"shoes", "running"}, search(t, srv, sports.ID))
+
+		assert.ElementsMatch(t, []string{"clothing"}, search(t, srv, categories["clothing"].ID))
+	})
+
+	t.Run("failed to move category under its subcategory", func(t *testing.T) {
+		srv, categories := newTree(t)
+
+		_, err := srv.UpdateCategory(ctx, model.Category{ID: categories["clothing"].ID, Name: "clothing", ParentID: categories["running"].ID})
+
+		assert.ErrorIs(t, err, internalErrors.ErrCategoryInvalidParent)
+	})
+
+	t.Run("failed to delete category, it has subcategories", func(t *testing.T) {
+		srv, categories := newTree(t)
+
+		err := srv.DeleteCategory(ctx, categories["shoes"].ID)
+
+		assert.ErrorIs(t, err, internalErrors.ErrCategoryHasChildren)
+	})
+
+	t.Run("delete category moves its products to its parent", func(t *testing.T) {
+		srv, categories := newTree(t)
+
+		require.NoError(t, srv.DeleteCategory(ctx, categories["running"].ID))
+
+		assert.ElementsMatch(t, []string{"shoes", "running"}, search(t, srv, categories["shoes"].ID))
+
+		_, err := srv.GetCategory(ctx, categories["running"].ID)
+
+		assert.ErrorIs(t, err, internalErrors.ErrCategoryNotFound)
+	})
+
+	t.Run("delete root category removes it from its products", func(t *testing.T) {
+		srv, categories := newTree(t)
+
+		require.NoError(t, srv.DeleteCategory(ctx, categories["running"].ID))
+
+		require.NoError(t, srv.DeleteCategory(ctx, categories["shoes"].ID))
+
+		require.NoError(t, srv.DeleteCategory(ctx, categories["clothing"].ID))
+
+		product, err := srv.GetBySKU(ctx, "running", model.ReadOptions{})
+		require.NoError(t, err)
+
+		assert.Empty(t, product.CategoryIDs)
+	})
+}
@@ -0,0 +1,24 @@
+package service
+
+import (
+	internalError "github.com/srodrmendz/api-product-catalog/errors"
+	"github.com/srodrmendz/api-product-catalog/model"
+)
+
+// Selection of currency with the configured default currency and exchange rates
+func (s *ProductsCatalogService) currencySelection(currency string) model.CurrencySelection {
+	if currency == "" {
+		return model.CurrencySelection{}
+	}
+
+	return model.NewCurrencySelection(currency, s.config.DefaultCurrency, s.config.ExchangeRates)
+}
+
+// Price product on currency, an empty currency keeps the stored price
+func (s *ProductsCatalogService) selectCurrency(product *model.Product, currency string) error {
+	if !product.SelectCurrency(s.currencySelection(currency)) {
+		return internalError.ErrPriceNotAvailable
+	}
+
+	return nil
+}
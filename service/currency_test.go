@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	internalErrors "github.com/srodrmendz/api-product-catalog/errors"
+	"github.com/srodrmendz/api-product-catalog/model"
+	"github.com/srodrmendz/api-product-catalog/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test product prices on the requested currency on top of the in memory repository
+func TestService_Currency(t *testing.T) {
+	ctx := context.TODO()
+
+	// Given a catalog on dollars that converts to euros
+	newService := func() *ProductsCatalogService {
+		return New(repository.NewMemory(), Config{
+			DefaultCurrency: "USD",
+			ExchangeRates:   &model.ExchangeRates{Base: "USD", Rates: map[string]float64{"EUR": 0.9, "JPY": 150}},
+		})
+	}
+
+	t.Run("get product on its price list currency", func(t *testing.T) {
+		srv := newService()
+
+		created, err := srv.Create(ctx, model.Product{Name: "product", Sku: "sku", Qty: 1, Price: 1000, Prices: []model.Money{{Amount: 950, Currency: "EUR"}}})
+		require.NoError(t, err)
+
+		product, err := srv.GetByID(ctx, created.ID, model.ReadOptions{Currency: "EUR"})
+		require.NoError(t, err)
+
+		assert.Equal(t, int64(950), product.Price)
+
+		assert.Equal(t, "EUR", product.Currency)
+	})
+
+	t.Run("get product converted to a currency without minor units", func(t *testing.T) {
+		srv := newService()
+
+		created, err := srv.Create(ctx, model.Product{Name: "product", Sku: "sku", Qty: 1, Price: 1099})
+		require.NoError(t, err)
+
+		product, err := srv.GetBySKU(ctx, "sku", model.ReadOptions{Currency: "JPY"})
+		require.NoError(t, err)
+
+		assert.Equal(t, created.ID, product.ID)
+
+		assert.Equal(t, int64(1649), product.Price)
+	})
+
+	t.Run("get product fails without a price on the currency", func(t *testing.T) {
+		srv := newService()
+
+		created, err := srv.Create(ctx, model.Product{Name: "product", Sku: "sku", Qty: 1, Price: 1000, Currency: "GBP"})
+		require.NoError(t, err)
+
+		_, err = srv.GetByID(ctx, created.ID, model.ReadOptions{Currency: "EUR"})
+
+		assert.ErrorIs(t, err, internalErrors.ErrPriceNotAvailable)
+	})
+
+	t.Run("search products converted to the currency", func(t *testing.T) {
+		srv := newService()
+
+		_, err := srv.Create(ctx, model.Product{Name: "product", Sku: "sku", Qty: 1, Price: 1000})
+		require.NoError(t, err)
+
+		response, err := srv.Search(ctx, model.SearchRequest{InStock: true, Currency: model.CurrencySelection{Code: "EUR"}})
+		require.NoError(t, err)
+
+		require.Len(t, response.Products, 1)
+
+		assert.Equal(t, int64(900), response.Products[0].Price)
+	})
+}
@@ -0,0 +1,60 @@
+package service
+
+import (
+	"context"
+
+	internalError "github.com/srodrmendz/api-product-catalog/errors"
+	"github.com/srodrmendz/api-product-catalog/model"
+)
+
+// Add an image after the product images
+func (s *ProductsCatalogService) AddImage(ctx context.Context, request model.ImageRequest) (*model.Product, error) {
+	return s.replaceProduct(ctx, request.ID, request.Version, func(product *model.Product) error {
+		if !product.AddImage(request.Image) {
+			return internalError.ErrImageAlreadyExist
+		}
+
+		return s.validateImageLimit(*product)
+	})
+}
+
+// Remove a product image
+func (s *ProductsCatalogService) RemoveImage(ctx context.Context, request model.ImageRequest) (*model.Product, error) {
+	return s.replaceProduct(ctx, request.ID, request.Version, func(product *model.Product) error {
+		if !product.RemoveImage(request.Image.ID) {
+			return internalError.ErrImageNotFound
+		}
+
+		return nil
+	})
+}
+
+// Sort the product images on the requested order
+func (s *ProductsCatalogService) ReorderImages(ctx context.Context, request model.ImageOrderRequest) (*model.Product, error) {
+	return s.replaceProduct(ctx, request.ID, request.Version, func(product *model.Product) error {
+		if !product.ReorderImages(request.ImageIDs, request.PrimaryID) {
+			return internalError.ErrImageOrder
+		}
+
+		return nil
+	})
+}
+
+// Check the product and each of its variants do not have more images than the configured maximum, 0 allows any number
+func (s *ProductsCatalogService) validateImageLimit(product model.Product) error {
+	if s.config.MaxImages == 0 {
+		return nil
+	}
+
+	if len(product.Images) > s.config.MaxImages {
+		return internalError.ErrImageLimit
+	}
+
+	for _, variant := range product.Variants {
+		if len(variant.Images) > s.config.MaxImages {
+			return internalError.ErrImageLimit
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,122 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	internalErrors "github.com/srodrmendz/api-product-catalog/errors"
+	"github.com/srodrmendz/api-product-catalog/model"
+	"github.com/srodrmendz/api-product-catalog/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test product images on top of the in memory repository
+func TestService_Images(t *testing.T) {
+	ctx := context.TODO()
+
+	config := Config{MaxImages: 3}
+
+	image := func(id string) model.Image {
+		return model.Image{ID: id, URL: "https://cdn.example.com/" + id + ".png"}
+	}
+
+	newProduct := func(t *testing.T, srv *ProductsCatalogService, images ...model.Image) *model.Product {
+		product, err := srv.Create(ctx, model.Product{Name: "shirt", Sku: "shirt", Qty: 1, Price: 100, Images: images})
+		require.NoError(t, err)
+
+		return product
+	}
+
+	ids := func(product *model.Product) []string {
+		ids := []string{}
+
+		for _, image := range product.Images {
+			ids = append(ids, image.ID)
+		}
+
+		return ids
+	}
+
+	primary := func(product *model.Product) string {
+		for _, image := range product.Images {
+			if image.Primary {
+				return image.ID
+			}
+		}
+
+		return ""
+	}
+
+	t.Run("images are added, reordered and removed", func(t *testing.T) {
+		srv := New(repository.NewMemory(), config)
+
+		product := newProduct(t, srv)
+
+		updated, err := srv.AddImage(ctx, model.ImageRequest{ID: product.ID, Version: product.Version, Image: image("front")})
+		require.NoError(t, err)
+
+		assert.Equal(t, "front", primary(updated))
+
+		_, err = srv.AddImage(ctx, model.ImageRequest{ID: product.ID, Image: image("back")})
+		require.NoError(t, err)
+
+		side := image("side")
+		side.Primary = true
+
+		updated, err = srv.AddImage(ctx, model.ImageRequest{ID: product.ID, Image: side})
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"front", "back", "side"}, ids(updated))
+
+		assert.Equal(t, "side", primary(updated))
+
+		updated, err = srv.ReorderImages(ctx, model.ImageOrderRequest{ID: product.ID, ImageIDs: []string{"back", "side", "front"}, PrimaryID: "back"})
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"back", "side", "front"}, ids(updated))
+
+		assert.Equal(t, []int{0, 1, 2}, []int{updated.Images[0].Position, updated.Images[1].Position, updated.Images[2].Position})
+
+		assert.Equal(t, "back", primary(updated))
+
+		updated, err = srv.RemoveImage(ctx, model.ImageRequest{ID: product.ID, Image: model.Image{ID: "back"}})
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"side", "front"}, ids(updated))
+
+		assert.Equal(t, "side", primary(updated))
+	})
+
+	t.Run("image changes the product does not allow are rejected", func(t *testing.T) {
+		srv := New(repository.NewMemory(), config)
+
+		product := newProduct(t, srv, image("front"), image("back"), image("side"))
+
+		_, err := srv.AddImage(ctx, model.ImageRequest{ID: product.ID, Image: image("top")})
+		assert.ErrorIs(t, err, internalErrors.ErrImageLimit)
+
+		_, err = srv.AddImage(ctx, model.ImageRequest{ID: product.ID, Image: model.Image{ID: "copy", URL: image("front").URL}})
+		assert.ErrorIs(t, err, internalErrors.ErrImageAlreadyExist)
+
+		_, err = srv.RemoveImage(ctx, model.ImageRequest{ID: product.ID, Image: model.Image{ID: "top"}})
+		assert.ErrorIs(t, err, internalErrors.ErrImageNotFound)
+
+		_, err = srv.ReorderImages(ctx, model.ImageOrderRequest{ID: product.ID, ImageIDs: []string{"front", "back"}})
+		assert.ErrorIs(t, err, internalErrors.ErrImageOrder)
+
+		_, err = srv.ReorderImages(ctx, model.ImageOrderRequest{ID: product.ID, ImageIDs: []string{"front", "back", "side"}, PrimaryID: "top"})
+		assert.ErrorIs(t, err, internalErrors.ErrImageOrder)
+
+		_, err = srv.RemoveImage(ctx, model.ImageRequest{ID: product.ID, Version: product.Version + 1, Image: model.Image{ID: "front"}})
+		assert.ErrorIs(t, err, internalErrors.ErrVersionConflict)
+
+		_, err = srv.Create(ctx, model.Product{
+			Name:   "shoe",
+			Sku:    "shoe",
+			Price:  100,
+			Images: []model.Image{image("front"), image("back"), image("side"), image("top")},
+		})
+		assert.ErrorIs(t, err, internalErrors.ErrImageLimit)
+	})
+}
@@ -0,0 +1,80 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	internalError "github.com/srodrmendz/api-product-catalog/errors"
+	"github.com/srodrmendz/api-product-catalog/model"
+	"github.com/srodrmendz/api-product-catalog/repository"
+)
+
+// Set a product translation, replacing the one already on its locale
+// Products can not be translated to their own locale
+// The translation keeps its slug unless another one is requested, new translations get one from their name
+func (s *ProductsCatalogService) SetTranslation(ctx context.Context, request model.TranslationRequest) (*model.Product, error) {
+	return s.replaceProductWith(ctx, request.ID, request.Version, func(txRepo repository.Repository, product *model.Product) error {
+		if request.Locale == product.ContentLocale(s.config.DefaultLocale) {
+			return internalError.ErrTranslationLocale
+		}
+
+		translation := request.Translation
+
+		translation.Slug = ""
+
+		if replaced := product.TranslationOn(translation.Locale); replaced != nil {
+			translation.Slug = replaced.Slug
+		}
+
+		product.SetTranslation(translation)
+
+		if request.Slug == "" && translation.Slug != "" {
+			return nil
+		}
+
+		slug, err := resolveSlug(ctx, txRepo, *product, request.Slug, translation.Name, translation.Locale)
+		if err != nil {
+			return err
+		}
+
+		product.ChangeSlug(slug, translation.Locale, time.Now())
+
+		return nil
+	})
+}
+
+// Remove a product translation, its slug redirects to the product one
+func (s *ProductsCatalogService) RemoveTranslation(ctx context.Context, request model.TranslationRequest) (*model.Product, error) {
+	return s.replaceProduct(ctx, request.ID, request.Version, func(product *model.Product) error {
+		translation := product.TranslationOn(request.Locale)
+		if translation == nil {
+			return internalError.ErrTranslationNotFound
+		}
+
+		slug := translation.Slug
+
+		product.RemoveTranslation(request.Locale)
+
+		product.RetireSlug(slug, request.Locale, time.Now())
+
+		return nil
+	})
+}
+
+// Resolve the requested locales to the first one available on the configured locales, or to the default locale
+// Without configured locales the first requested locale is used
+func (s *ProductsCatalogService) negotiateLocale(requested []string) string {
+	if len(s.config.Locales) == 0 {
+		if len(requested) > 0 {
+			return requested[0]
+		}
+
+		return s.config.DefaultLocale
+	}
+
+	if locale, ok := model.NegotiateLocale(requested, s.config.Locales); ok {
+		return locale
+	}
+
+	return s.config.DefaultLocale
+}
@@ -0,0 +1,125 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	internalErrors "github.com/srodrmendz/api-product-catalog/errors"
+	"github.com/srodrmendz/api-product-catalog/model"
+	"github.com/srodrmendz/api-product-catalog/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test localized product content and locale negotiation on top of the in memory repository
+func TestService_Locale(t *testing.T) {
+	ctx := context.TODO()
+
+	config := Config{DefaultLocale: "en", Locales: []string{"en", "es", "pt-BR"}}
+
+	newProduct := func(t *testing.T, srv *ProductsCatalogService) *model.Product {
+		description := "camisa de algodón"
+
+		product, err := srv.Create(ctx, model.Product{
+			Name:         "cotton shirt",
+			Sku:          "shirt",
+			Qty:          1,
+			Price:        100,
+			Translations: []model.Translation{{Locale: "es", Name: "camisa", Description: &description}},
+		})
+		require.NoError(t, err)
+
+		return product
+	}
+
+	t.Run("products are created on the default locale", func(t *testing.T) {
+		srv := New(repository.NewMemory(), config)
+
+		product := newProduct(t, srv)
+
+		assert.Equal(t, "en", product.Locale)
+
+		_, err := srv.Create(ctx, model.Product{
+			Name:         "shoe",
+			Sku:          "shoe",
+			Translations: []model.Translation{{Locale: "en", Name: "shoe"}},
+		})
+		assert.ErrorIs(t, err, internalErrors.ErrTranslationLocale)
+	})
+
+	t.Run("product content is negotiated from the requested locales", func(t *testing.T) {
+		srv := New(repository.NewMemory(), config)
+
+		product := newProduct(t, srv)
+
+		for _, dt := range []struct {
+			locales        []string
+			expectedLocale string
+			expectedName   string
+		}{
+			{locales: []string{"es"}, expectedLocale: "es", expectedName: "camisa"},
+			{locales: []string{"es-AR"}, expectedLocale: "es", expectedName: "camisa"},
+			{locales: []string{"fr", "es"}, expectedLocale: "es", expectedName: "camisa"},
+			{locales: []string{"pt-BR"}, expectedLocale: "en", expectedName: "cotton shirt"},
+			{locales: []string{"fr"}, expectedLocale: "en", expectedName: "cotton shirt"},
+			{expectedLocale: "en", expectedName: "cotton shirt"},
+		} {
+			localized, err := srv.GetByID(ctx, product.ID, model.ReadOptions{Locales: dt.locales})
+			require.NoError(t, err)
+
+			assert.Equal(t, dt.expectedLocale, localized.Locale, dt.locales)
+
+			assert.Equal(t, dt.expectedName, localized.Name, dt.locales)
+		}
+	})
+
+	t.Run("searched products are localized", func(t *testing.T) {
+		srv := New(repository.NewMemory(), config)
+
+		newProduct(t, srv)
+
+		response, err := srv.Search(ctx, model.SearchRequest{InStock: true, Name: "camisa", Locales: []string{"es"}})
+		require.NoError(t, err)
+
+		require.Len(t, response.Products, 1)
+
+		assert.Equal(t, "camisa", response.Products[0].Name)
+
+		assert.Equal(t, "camisa de algodón", *response.Products[0].Description)
+	})
+
+	t.Run("translations are set and removed", func(t *testing.T) {
+		srv := New(repository.NewMemory(), config)
+
+		product := newProduct(t, srv)
+
+		updated, err := srv.SetTranslation(ctx, model.TranslationRequest{
+			ID:          product.ID,
+			Version:     product.Version,
+			Translation: model.Translation{Locale: "es", Name: "camiseta"},
+		})
+		require.NoError(t, err)
+
+		require.Len(t, updated.Translations, 1)
+
+		assert.Equal(t, "camiseta", updated.Translations[0].Name)
+
+		_, err = srv.SetTranslation(ctx, model.TranslationRequest{
+			ID:          product.ID,
+			Version:     product.Version,
+			Translation: model.Translation{Locale: "pt-BR", Name: "camisa"},
+		})
+		assert.ErrorIs(t, err, internalErrors.ErrVersionConflict)
+
+		_, err = srv.SetTranslation(ctx, model.TranslationRequest{ID: product.ID, Translation: model.Translation{Locale: "en", Name: "shirt"}})
+		assert.ErrorIs(t, err, internalErrors.ErrTranslationLocale)
+
+		updated, err = srv.RemoveTranslation(ctx, model.TranslationRequest{ID: product.ID, Translation: model.Translation{Locale: "es"}})
+		require.NoError(t, err)
+
+		assert.Empty(t, updated.Translations)
+
+		_, err = srv.RemoveTranslation(ctx, model.TranslationRequest{ID: product.ID, Translation: model.Translation{Locale: "es"}})
+		assert.ErrorIs(t, err, internalErrors.ErrTranslationNotFound)
+	})
+}
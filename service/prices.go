@@ -0,0 +1,172 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	internalError "github.com/srodrmendz/api-product-catalog/errors"
+	"github.com/srodrmendz/api-product-catalog/model"
+	"github.com/srodrmendz/api-product-catalog/repository"
+)
+
+// Get the product price timeline, resolved at the current time
+func (s *ProductsCatalogService) GetPriceTimeline(ctx context.Context, id string) (*model.PriceTimeline, error) {
+	product, err := s.repository.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	product.ResolvePrice(time.Now())
+
+	return product.PriceTimeline(), nil
+}
+
+// Schedule a product price, products with variants and bundles priced on their components can not schedule prices
+func (s *ProductsCatalogService) SchedulePrice(ctx context.Context, request model.SchedulePriceRequest) (*model.PriceTimeline, error) {
+	return s.writePricing(ctx, request.ID, func(product *model.Product, now time.Time) error {
+		if len(product.Variants) > 0 {
+			return internalError.ErrPriceScheduleVariants
+		}
+
+		if product.PricedOnComponents() {
+			return internalError.ErrBundlePricing
+		}
+
+		product.SchedulePrice(request.ScheduledPrice, now)
+
+		return nil
+	})
+}
+
+// Cancel a scheduled price that did not start yet
+func (s *ProductsCatalogService) CancelScheduledPrice(ctx context.Context, id string, scheduleID string) (*model.PriceTimeline, error) {
+	return s.writePricing(ctx, id, func(product *model.Product, now time.Time) error {
+		product.ResolvePrice(now)
+
+		if !product.CancelScheduledPrice(scheduleID, now) {
+			return internalError.ErrScheduledPriceNotFound
+		}
+
+		return nil
+	})
+}
+
+// Change the pricing of a product with fn and write it on the version read
+func (s *ProductsCatalogService) writePricing(
+	ctx context.Context,
+	id string,
+	fn func(product *model.Product, now time.Time) error,
+) (*model.PriceTimeline, error) {
+	var updated *model.Product
+
+	err := s.repository.WithTransaction(ctx, func(txRepo repository.Repository) error {
+		product, err := txRepo.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		if err := fn(product, time.Now()); err != nil {
+			return err
+		}
+
+		updated, err = txRepo.Update(ctx, pricingUpdate(*product))
+
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return updated.PriceTimeline(), nil
+}
+
+// Write the scheduled prices started or ended until now, so the stored prices search sorts on are the ones in effect
+// Products changed in the meantime are skipped, they are resolved again on the next call
+func (s *ProductsCatalogService) ApplyDuePrices(ctx context.Context) (int64, error) {
+	now := time.Now()
+
+	products, err := s.repository.ListDuePrices(ctx, now)
+	if err != nil {
+		return 0, err
+	}
+
+	var applied int64
+
+	for _, product := range products {
+		if !product.ResolvePrice(now) {
+			continue
+		}
+
+		_, err := s.repository.Update(ctx, pricingUpdate(product))
+		if errors.Is(err, internalError.ErrVersionConflict) || errors.Is(err, internalError.ErrProductNotFound) {
+			continue
+		}
+
+		if err != nil {
+			return applied, err
+		}
+
+		applied++
+	}
+
+	return applied, nil
+}
+
+// Resolve the price in effect of a searched product whose stored pricing is due to change, priced on the searched currency
+// It is read again since the searched one may be priced on another currency already
+func (s *ProductsCatalogService) resolveSearchedPrice(ctx context.Context, product *model.Product, currency model.CurrencySelection, at time.Time) error {
+	if product.PriceChangesAt == nil || product.PriceChangesAt.After(at) {
+		return nil
+	}
+
+	stored, err := s.repository.GetByID(ctx, product.ID)
+	if errors.Is(err, internalError.ErrProductNotFound) {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if stored.ResolvePrice(at) && stored.SelectCurrency(currency) {
+		*product = *stored
+	}
+
+	return nil
+}
+
+// Set the requested price on the product, or on its requested variant, recording it on the price history
+func changePrice(product model.Product, update model.Update) (model.Update, error) {
+	if update.VariantSKU == "" && len(product.Variants) > 0 {
+		return update, internalError.ErrVariantRequired
+	}
+
+	if update.VariantSKU != "" && product.Variant(update.VariantSKU) == nil {
+		return update, internalError.ErrVariantNotFound
+	}
+
+	product.ChangePrice(*update.Price, update.VariantSKU, update.Actor, time.Now())
+
+	update.Pricing = product.Pricing()
+
+	if update.VariantSKU != "" {
+		update.Pricing.Price = *update.Price
+	}
+
+	// The pricing is derived from the product read, so it is only written on that version
+	if update.Version == 0 {
+		update.Version = product.Version
+	}
+
+	return update, nil
+}
+
+// Update writing the product pricing on the version read, leaving the rest of the product unchanged
+func pricingUpdate(product model.Product) model.Update {
+	return model.Update{
+		ID:      product.ID,
+		Version: product.Version,
+		Pricing: product.Pricing(),
+	}
+}
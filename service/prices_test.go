@@ -0,0 +1,225 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	internalErrors "github.com/srodrmendz/api-product-catalog/errors"
+	"github.com/srodrmendz/api-product-catalog/model"
+	"github.com/srodrmendz/api-product-catalog/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test price changes and scheduled prices on top of the in memory repository
+func TestService_Prices(t *testing.T) {
+	ctx := context.TODO()
+
+	newProduct := func(t *testing.T, srv *ProductsCatalogService, sku string, price int64) *model.Product {
+		product, err := srv.Create(ctx, model.Product{Name: sku, Sku: sku, Qty: 1, Price: price})
+		require.NoError(t, err)
+
+		return product
+	}
+
+	schedule := func(id string, price int64, startsAt time.Time, endsAt *time.Time) model.SchedulePriceRequest {
+		return model.SchedulePriceRequest{
+			ID:             id,
+			ScheduledPrice: model.ScheduledPrice{ID: "schedule-" + id, Price: price, StartsAt: startsAt, EndsAt: endsAt, Actor: "merchandising"},
+		}
+	}
+
+	t.Run("update price records it on the history", func(t *testing.T) {
+		srv := New(repository.NewMemory(), Config{})
+
+		product := newProduct(t, srv, "sku", 1000)
+
+		price := int64(1200)
+
+		updated, err := srv.Update(ctx, &model.Update{ID: product.ID, UpdateRequest: model.UpdateRequest{Qty: qtyOf(1), Price: &price}, Actor: "pricing"})
+		require.NoError(t, err)
+
+		assert.Equal(t, int64(1200), updated.Price)
+
+		timeline, err := srv.GetPriceTimeline(ctx, product.ID)
+		require.NoError(t, err)
+
+		require.Len(t, timeline.History, 2)
+
+		assert.Equal(t, int64(1000), timeline.History[0].Price)
+
+		assert.Equal(t, model.PriceChange{Price: 1200, PreviousPrice: 1000, ChangedAt: timeline.History[1].ChangedAt, Actor: "pricing"}, timeline.History[1])
+	})
+
+	t.Run("update price keeps the stock", func(t *testing.T) {
+		srv := New(repository.NewMemory(), Config{})
+
+		product, err := srv.Create(ctx, model.Product{Name: "socks", Sku: "socks", Qty: 7, Price: 1000})
+		require.NoError(t, err)
+
+		shirt, err := srv.Create(ctx, model.Product{
+			Name:    "shirt",
+			Sku:     "shirt",
+			Options: []model.Option{{Name: "size", Values: []string{"m", "l"}}},
+			Variants: []model.Variant{
+				{Sku: "shirt-m", Options: map[string]string{"size": "m"}, Qty: 3, Price: 1000},
+				{Sku: "shirt-l", Options: map[string]string{"size": "l"}, Qty: 4, Price: 1000},
+			},
+		})
+		require.NoError(t, err)
+
+		price := int64(1200)
+
+		updated, err := srv.Update(ctx, &model.Update{ID: product.ID, UpdateRequest: model.UpdateRequest{Price: &price}})
+		require.NoError(t, err)
+
+		assert.Equal(t, int64(1200), updated.Price)
+		assert.Equal(t, uint64(7), updated.Qty)
+		assert.Equal(t, true, updated.InStock)
+
+		updated, err = srv.Update(ctx, &model.Update{ID: shirt.ID, UpdateRequest: model.UpdateRequest{VariantSKU: "shirt-m", Price: &price}})
+		require.NoError(t, err)
+
+		assert.Equal(t, uint64(3), updated.Variant("shirt-m").Qty)
+		assert.Equal(t, uint64(7), updated.Qty)
+
+		// Price changes are not stock changes, so the ledger only holds the opening balance
+		response, err := srv.ListStockMovements(ctx, model.MovementSearchRequest{ProductID: product.ID})
+		require.NoError(t, err)
+
+		assert.Equal(t, int64(1), response.Metadata.Total)
+	})
+
+	t.Run("update variant price records its sku", func(t *testing.T) {
+		srv := New(repository.NewMemory(), Config{})
+
+		product, err := srv.Create(ctx, model.Product{
+			Name:     "shirt",
+			Sku:      "shirt",
+			Options:  []model.Option{{Name: "size", Values: []string{"m"}}},
+			Variants: []model.Variant{{Sku: "shirt-m", Options: map[string]string{"size": "m"}, Qty: 1, Price: 1000}},
+		})
+		require.NoError(t, err)
+
+		price := int64(900)
+
+		updated, err := srv.Update(ctx, &model.Update{ID: product.ID, UpdateRequest: model.UpdateRequest{Qty: qtyOf(1), VariantSKU: "shirt-m", Price: &price}})
+		require.NoError(t, err)
+
+		assert.Equal(t, int64(900), updated.Price)
+
+		assert.Equal(t, "shirt-m", updated.PriceHistory[len(updated.PriceHistory)-1].Sku)
+
+		_, err = srv.SchedulePrice(ctx, schedule(product.ID, 800, time.Now().Add(time.Hour), nil))
+
+		assert.ErrorIs(t, err, internalErrors.ErrPriceScheduleVariants)
+	})
+
+	t.Run("running scheduled price is the product price until it ends", func(t *testing.T) {
+		srv := New(repository.NewMemory(), Config{})
+
+		product := newProduct(t, srv, "sku", 1000)
+
+		end := time.Now().Add(time.Hour)
+
+		timeline, err := srv.SchedulePrice(ctx, schedule(product.ID, 800, time.Now().Add(-time.Hour), &end))
+		require.NoError(t, err)
+
+		assert.Equal(t, int64(800), timeline.Price)
+
+		assert.Equal(t, int64(1000), timeline.RegularPrice)
+
+		// A price change while the scheduled price runs changes the regular price
+		price := int64(1100)
+
+		updated, err := srv.Update(ctx, &model.Update{ID: product.ID, UpdateRequest: model.UpdateRequest{Qty: qtyOf(1), Price: &price}})
+		require.NoError(t, err)
+
+		assert.Equal(t, int64(800), updated.Price)
+
+		assert.Equal(t, int64(1100), updated.RegularPrice)
+	})
+
+	t.Run("scheduled prices are resolved at read time", func(t *testing.T) {
+		repo := repository.NewMemory()
+
+		srv := New(repo, Config{})
+
+		sale := newProduct(t, srv, "sale", 1000)
+
+		regular := newProduct(t, srv, "regular", 900)
+
+		_, err := srv.SchedulePrice(ctx, schedule(sale.ID, 800, time.Now().Add(50*time.Millisecond), nil))
+		require.NoError(t, err)
+
+		product, err := srv.GetByID(ctx, sale.ID, model.ReadOptions{})
+		require.NoError(t, err)
+
+		assert.Equal(t, int64(1000), product.Price)
+
+		time.Sleep(100 * time.Millisecond)
+
+		product, err = srv.GetByID(ctx, sale.ID, model.ReadOptions{})
+		require.NoError(t, err)
+
+		assert.Equal(t, int64(800), product.Price)
+
+		// Search resolves the started price on the page, but sorts on the stored one until it is written
+		response, err := srv.Search(ctx, model.SearchRequest{Sort: "asc", InStock: true})
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{regular.ID, sale.ID}, []string{response.Products[0].ID, response.Products[1].ID})
+
+		assert.Equal(t, int64(800), response.Products[1].Price)
+
+		stored, err := repo.GetByID(ctx, sale.ID)
+		require.NoError(t, err)
+
+		assert.Equal(t, int64(1000), stored.Price)
+
+		applied, err := srv.ApplyDuePrices(ctx)
+		require.NoError(t, err)
+
+		assert.Equal(t, int64(1), applied)
+
+		response, err = srv.Search(ctx, model.SearchRequest{Sort: "asc", InStock: true})
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{sale.ID, regular.ID}, []string{response.Products[0].ID, response.Products[1].ID})
+
+		// The started price is written, it became the regular price since it has no end
+		stored, err = repo.GetByID(ctx, sale.ID)
+		require.NoError(t, err)
+
+		assert.Equal(t, int64(800), stored.Price)
+
+		assert.Empty(t, stored.PriceSchedule)
+
+		assert.Nil(t, stored.PriceChangesAt)
+
+		last := stored.PriceHistory[len(stored.PriceHistory)-1]
+
+		assert.Equal(t, "schedule-"+sale.ID, last.ScheduleID)
+
+		assert.Equal(t, "merchandising", last.Actor)
+	})
+
+	t.Run("cancel scheduled price that did not start", func(t *testing.T) {
+		srv := New(repository.NewMemory(), Config{})
+
+		product := newProduct(t, srv, "sku", 1000)
+
+		_, err := srv.SchedulePrice(ctx, schedule(product.ID, 800, time.Now().Add(time.Hour), nil))
+		require.NoError(t, err)
+
+		timeline, err := srv.CancelScheduledPrice(ctx, product.ID, "schedule-"+product.ID)
+		require.NoError(t, err)
+
+		assert.Empty(t, timeline.Scheduled)
+
+		_, err = srv.CancelScheduledPrice(ctx, product.ID, "schedule-"+product.ID)
+
+		assert.ErrorIs(t, err, internalErrors.ErrScheduledPriceNotFound)
+	})
+}
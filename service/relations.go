@@ -0,0 +1,137 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	internalError "github.com/srodrmendz/api-product-catalog/errors"
+	"github.com/srodrmendz/api-product-catalog/model"
+	"github.com/srodrmendz/api-product-catalog/repository"
+)
+
+// Get the product relations of a type, or every relation when it is empty
+func (s *ProductsCatalogService) GetRelations(ctx context.Context, id string, relationType model.RelationType) ([]model.Relation, error) {
+	product, err := s.repository.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return product.RelationsOf(relationType), nil
+}
+
+// Link a related product after the product relations of its type, a bidirectional relation is linked on the related product too
+func (s *ProductsCatalogService) AddRelation(ctx context.Context, request model.RelationRequest) (*model.Product, error) {
+	return s.replaceProductWith(ctx, request.ID, request.Version, func(txRepo repository.Repository, product *model.Product) error {
+		related, err := txRepo.GetByID(ctx, request.ProductID)
+		if errors.Is(err, internalError.ErrProductNotFound) {
+			return internalError.ErrRelatedNotFound
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if !product.AddRelation(request.Relation) {
+			return internalError.ErrRelationAlreadyExist
+		}
+
+		if !request.Bidirectional {
+			return nil
+		}
+
+		// A relation the related product already has becomes bidirectional
+		if !related.AddRelation(model.Relation{ProductID: product.ID, Type: request.Type, Bidirectional: true}) {
+			related.Relation(request.Type, product.ID).Bidirectional = true
+		}
+
+		_, err = txRepo.Replace(ctx, *related)
+
+		return err
+	})
+}
+
+// Unlink a related product, a bidirectional relation is unlinked from the related product too
+func (s *ProductsCatalogService) RemoveRelation(ctx context.Context, request model.RelationRequest) (*model.Product, error) {
+	return s.replaceProductWith(ctx, request.ID, request.Version, func(txRepo repository.Repository, product *model.Product) error {
+		relation, ok := product.RemoveRelation(request.Type, request.ProductID)
+		if !ok {
+			return internalError.ErrRelationNotFound
+		}
+
+		if !relation.Bidirectional {
+			return nil
+		}
+
+		related, err := txRepo.GetByID(ctx, relation.ProductID)
+		if errors.Is(err, internalError.ErrProductNotFound) {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if reverse := related.Relation(relation.Type, product.ID); reverse == nil || !reverse.Bidirectional {
+			return nil
+		}
+
+		related.RemoveRelation(relation.Type, product.ID)
+
+		_, err = txRepo.Replace(ctx, *related)
+
+		return err
+	})
+}
+
+// Sort the product relations of a type on the requested order
+func (s *ProductsCatalogService) ReorderRelations(ctx context.Context, request model.RelationOrderRequest) (*model.Product, error) {
+	return s.replaceProduct(ctx, request.ID, request.Version, func(product *model.Product) error {
+		if !product.ReorderRelations(request.Type, request.ProductIDs) {
+			return internalError.ErrRelationOrder
+		}
+
+		return nil
+	})
+}
+
+// Inline the related products on the product relations, read with the same options as the product
+// Related products not visible to the caller or without a price on the requested currency are left out
+func (s *ProductsCatalogService) expandRelations(ctx context.Context, product *model.Product, options model.ReadOptions) error {
+	now := time.Now()
+
+	locale := s.negotiateLocale(options.Locales)
+
+	for i := range product.Relations {
+		related, err := s.repository.GetByID(ctx, product.Relations[i].ProductID)
+		if errors.Is(err, internalError.ErrProductNotFound) {
+			continue
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if !options.Admin && !related.IsVisible(now) {
+			continue
+		}
+
+		related.ResolvePrice(now)
+
+		if err := resolveBundle(ctx, s.repository, related); err != nil {
+			return err
+		}
+
+		if !related.SelectCurrency(s.currencySelection(options.Currency)) {
+			continue
+		}
+
+		related.Localize(locale, s.config.DefaultLocale)
+
+		related.ConvertUnits(options.Units)
+
+		product.Relations[i].Product = related
+	}
+
+	return nil
+}
@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	internalErrors "github.com/srodrmendz/api-product-catalog/errors"
+	"github.com/srodrmendz/api-product-catalog/model"
+	"github.com/srodrmendz/api-product-catalog/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test product relations on top of the in memory repository
+func TestService_Relations(t *testing.T) {
+	ctx := context.TODO()
+
+	setup := func(t *testing.T) (*ProductsCatalogService, []*model.Product) {
+		srv := New(repository.NewMemory(), Config{})
+
+		var products []*model.Product
+
+		for _, product := range []model.Product{
+			{Name: "shoes", Sku: "shoes", Qty: 1, Price: 100, Status: model.StatusPublished},
+			{Name: "socks", Sku: "socks", Qty: 1, Price: 10, Status: model.StatusPublished},
+			{Name: "laces", Sku: "laces", Qty: 1, Price: 5, Status: model.StatusPublished},
+			{Name: "insoles", Sku: "insoles", Qty: 1, Price: 20, Status: model.StatusDraft},
+		} {
+			created, err := srv.Create(ctx, product)
+			require.NoError(t, err)
+
+			products = append(products, created)
+		}
+
+		return srv, products
+	}
+
+	link := func(t *testing.T, srv *ProductsCatalogService, id string, relation model.Relation) *model.Product {
+		product, err := srv.AddRelation(ctx, model.RelationRequest{ID: id, Relation: relation})
+		require.NoError(t, err)
+
+		return product
+	}
+
+	t.Run("relations are ordered within their type", func(t *testing.T) {
+		srv, products := setup(t)
+
+		shoes, socks, laces := products[0], products[1], products[2]
+
+		link(t, srv, shoes.ID, model.Relation{ProductID: socks.ID, Type: model.RelationCrossSell})
+
+		link(t, srv, shoes.ID, model.Relation{ProductID: socks.ID, Type: model.RelationAccessory})
+
+		product := link(t, srv, shoes.ID, model.Relation{ProductID: laces.ID, Type: model.RelationAccessory})
+
+		assert.Equal(t, []model.Relation{
+			{ProductID: socks.ID, Type: model.RelationAccessory, Position: 0},
+			{ProductID: laces.ID, Type: model.RelationAccessory, Position: 1},
+			{ProductID: socks.ID, Type: model.RelationCrossSell, Position: 0},
+		}, product.Relations)
+
+		_, err := srv.AddRelation(ctx, model.RelationRequest{ID: shoes.ID, Relation: model.Relation{ProductID: laces.ID, Type: model.RelationAccessory}})
+		assert.ErrorIs(t, err, internalErrors.ErrRelationAlreadyExist)
+
+		_, err = srv.AddRelation(ctx, model.RelationRequest{ID: shoes.ID, Relation: model.Relation{ProductID: "fake", Type: model.RelationAccessory}})
+		assert.ErrorIs(t, err, internalErrors.ErrRelatedNotFound)
+
+		_, err = srv.ReorderRelations(ctx, model.RelationOrderRequest{ID: shoes.ID, Type: model.RelationAccessory, ProductIDs: []string{laces.ID}})
+		assert.ErrorIs(t, err, internalErrors.ErrRelationOrder)
+
+		_, err = srv.ReorderRelations(ctx, model.RelationOrderRequest{ID: shoes.ID, Type: model.RelationAccessory, ProductIDs: []string{laces.ID, socks.ID}})
+		require.NoError(t, err)
+
+		relations, err := srv.GetRelations(ctx, shoes.ID, model.RelationAccessory)
+		require.NoError(t, err)
+
+		assert.Equal(t, []model.Relation{
+			{ProductID: laces.ID, Type: model.RelationAccessory, Position: 0},
+			{ProductID: socks.ID, Type: model.RelationAccessory, Position: 1},
+		}, relations)
+	})
+
+	t.Run("bidirectional relations are linked and unlinked on both products", func(t *testing.T) {
+		srv, products := setup(t)
+
+		shoes, socks := products[0], products[1]
+
+		link(t, srv, shoes.ID, model.Relation{ProductID: socks.ID, Type: model.RelationCrossSell, Bidirectional: true})
+
+		relations, err := srv.GetRelations(ctx, socks.ID, "")
+		require.NoError(t, err)
+
+		assert.Equal(t, []model.Relation{{ProductID: shoes.ID, Type: model.RelationCrossSell, Bidirectional: true}}, relations)
+
+		_, err = srv.RemoveRelation(ctx, model.RelationRequest{ID: shoes.ID, Relation: model.Relation{ProductID: socks.ID, Type: model.RelationCrossSell}})
+		require.NoError(t, err)
+
+		relations, err = srv.GetRelations(ctx, socks.ID, "")
+		require.NoError(t, err)
+
+		assert.Empty(t, relations)
+
+		_, err = srv.RemoveRelation(ctx, model.RelationRequest{ID: shoes.ID, Relation: model.Relation{ProductID: socks.ID, Type: model.RelationCrossSell}})
+		assert.ErrorIs(t, err, internalErrors.ErrRelationNotFound)
+	})
+
+	t.Run("expanded relations inline the related products visible to the caller", func(t *testing.T) {
+		srv, products := setup(t)
+
+		shoes, socks, insoles := products[0], products[1], products[3]
+
+		link(t, srv, shoes.ID, model.Relation{ProductID: socks.ID, Type: model.RelationAccessory})
+
+		link(t, srv, shoes.ID, model.Relation{ProductID: insoles.ID, Type: model.RelationAccessory})
+
+		product, err := srv.GetByID(ctx, shoes.ID, model.ReadOptions{ExpandRelations: true})
+		require.NoError(t, err)
+
+		require.Len(t, product.Relations, 2)
+
+		require.NotNil(t, product.Relations[0].Product)
+
+		assert.Equal(t, "socks", product.Relations[0].Product.Name)
+
+		// Draft products are only inlined for admin callers
+		assert.Nil(t, product.Relations[1].Product)
+
+		product, err = srv.GetByID(ctx, shoes.ID, model.ReadOptions{ExpandRelations: true, Admin: true})
+		require.NoError(t, err)
+
+		require.NotNil(t, product.Relations[1].Product)
+
+		assert.Equal(t, "insoles", product.Relations[1].Product.Name)
+
+		product, err = srv.GetByID(ctx, shoes.ID, model.ReadOptions{})
+		require.NoError(t, err)
+
+		assert.Nil(t, product.Relations[0].Product)
+	})
+
+	t.Run("deleted products are unlinked from the products related to them", func(t *testing.T) {
+		srv, products := setup(t)
+
+		shoes, socks := products[0], products[1]
+
+		link(t, srv, shoes.ID, model.Relation{ProductID: socks.ID, Type: model.RelationAccessory})
+
+		require.NoError(t, srv.Delete(ctx, socks.ID, 0))
+
+		relations, err := srv.GetRelations(ctx, shoes.ID, "")
+		require.NoError(t, err)
+
+		assert.Empty(t, relations)
+	})
+}
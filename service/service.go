@@ -2,56 +2,407 @@ package service
 
 import (
 	"context"
+	"errors"
+	"time"
 
+	internalError "github.com/srodrmendz/api-product-catalog/errors"
 	"github.com/srodrmendz/api-product-catalog/model"
 	"github.com/srodrmendz/api-product-catalog/repository"
 )
 
 // Create new product service
-func New(repository repository.Repository) *ProductsCatalogService {
+func New(repository repository.Repository, config Config) *ProductsCatalogService {
 	return &ProductsCatalogService{
 		repository: repository,
+		config:     config,
 	}
 }
 
-// Create a new product
+// Create a new product, its categories and brand must exist and its attributes must follow their definitions
+// The product price starts its price history and products without locale are on the default one
+// The product and its translations get a slug from their names unless a slug no other product holds is requested
+// Bundle components must exist, and the bundle qty, in_stock and price are computed from them
+// The stock ledger of the product starts with its qty as opening balance
 func (s *ProductsCatalogService) Create(ctx context.Context, product model.Product) (*model.Product, error) {
-	return s.repository.Create(ctx, product)
+	if err := s.validateImageLimit(product); err != nil {
+		return nil, err
+	}
+
+	if err := s.validateBundle(ctx, s.repository, &product); err != nil {
+		return nil, err
+	}
+
+	if err := validateBrand(ctx, s.repository, product.BrandID); err != nil {
+		return nil, err
+	}
+
+	if err := validateAttributes(ctx, s.repository, product.CategoryIDs, product.Attributes); err != nil {
+		return nil, err
+	}
+
+	product.PriceHistory = []model.PriceChange{{Price: product.Price, ChangedAt: time.Now()}}
+
+	// Products are stored on the default locale unless they are created on another one
+	if product.Locale == "" {
+		product.Locale = s.config.DefaultLocale
+	}
+
+	for _, translation := range product.Translations {
+		if translation.Locale == product.Locale {
+			return nil, internalError.ErrTranslationLocale
+		}
+	}
+
+	var created *model.Product
+
+	err := retrySlugs(func() error {
+		return s.repository.WithTransaction(ctx, func(txRepo repository.Repository) error {
+			// Slugs are assigned on a copy, so a retried transaction generates them again
+			candidate := product
+
+			candidate.Translations = append([]model.Translation(nil), product.Translations...)
+
+			if err := assignSlugs(ctx, txRepo, &candidate); err != nil {
+				return err
+			}
+
+			var err error
+
+			created, err = txRepo.Create(ctx, candidate)
+			if err != nil {
+				return err
+			}
+
+			return openLedger(ctx, txRepo, *created, "")
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return created, nil
+}
+
+// Get a product by id, bundles are resolved from their components as they are now
+// Related products are inlined on its relations when they are expanded
+// Products not visible to shoppers are only found by admin callers
+func (s *ProductsCatalogService) GetByID(ctx context.Context, id string, options model.ReadOptions) (*model.Product, error) {
+	product, err := s.repository.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+
+	if !options.Admin && !product.IsVisible(now) {
+		return nil, internalError.ErrProductNotFound
+	}
+
+	product.ResolvePrice(now)
+
+	if err := resolveBundle(ctx, s.repository, product); err != nil {
+		return nil, err
+	}
+
+	if err := s.selectCurrency(product, options.Currency); err != nil {
+		return nil, err
+	}
+
+	product.Localize(s.negotiateLocale(options.Locales), s.config.DefaultLocale)
+
+	product.ConvertUnits(options.Units)
+
+	if options.ExpandRelations {
+		if err := s.expandRelations(ctx, product, options); err != nil {
+			return nil, err
+		}
+	}
+
+	return product, nil
+}
+
+// Get a product by sku, a variant sku resolves to its parent product with the variant selected
+// Products not visible to shoppers are only found by admin callers
+func (s *ProductsCatalogService) GetBySKU(ctx context.Context, sku string, options model.ReadOptions) (*model.SKUProduct, error) {
+	product, err := s.repository.GetBySKU(ctx, sku)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+
+	if !options.Admin && !product.IsVisible(now) {
+		return nil, internalError.ErrProductNotFound
+	}
+
+	product.ResolvePrice(now)
+
+	if err := resolveBundle(ctx, s.repository, product); err != nil {
+		return nil, err
+	}
+
+	if err := s.selectCurrency(product, options.Currency); err != nil {
+		return nil, err
+	}
+
+	product.Localize(s.negotiateLocale(options.Locales), s.config.DefaultLocale)
+
+	product.ConvertUnits(options.Units)
+
+	return &model.SKUProduct{
+		Product: *product,
+		Variant: product.Variant(sku),
+	}, nil
 }
 
-// Get a product by id
-func (s *ProductsCatalogService) GetByID(ctx context.Context, id string) (*model.Product, error) {
-	return s.repository.GetByID(ctx, id)
+// Delete a product, components of bundles that are not archived or deleted can not be deleted
+func (s *ProductsCatalogService) Delete(ctx context.Context, id string, version int64) error {
+	return s.repository.WithTransaction(ctx, func(txRepo repository.Repository) error {
+		if err := checkNotInBundle(ctx, txRepo, id); err != nil {
+			return err
+		}
+
+		return txRepo.Delete(ctx, id, version)
+	})
 }
 
-// Get a product by sku
-func (s *ProductsCatalogService) GetBySKU(ctx context.Context, sku string) (*model.Product, error) {
-	return s.repository.GetBySKU(ctx, sku)
+// Restore a deleted product
+func (s *ProductsCatalogService) Restore(ctx context.Context, id string) (*model.Product, error) {
+	return s.repository.Restore(ctx, id)
 }
 
-// Delete a product
-func (s *ProductsCatalogService) Delete(ctx context.Context, id string) error {
-	return s.repository.Delete(ctx, id)
+// Permanently remove the products deleted longer than retention ago
+func (s *ProductsCatalogService) Purge(ctx context.Context, retention time.Duration) (int64, error) {
+	return s.repository.Purge(ctx, time.Now().Add(-retention))
 }
 
-// Update a product
+// Update a product, requested attributes must follow the definitions of the product categories and a requested brand must exist
+// A requested price is recorded on the product price history, and a requested qty that changes the stock as an adjustment on the stock ledger
+// An updated bundle is resolved from its components again, so are the bundles holding an updated product
 func (s *ProductsCatalogService) Update(ctx context.Context, request *model.Update) (*model.Product, error) {
-	return s.repository.Update(ctx, request.ID, request.Qty)
+	updated, err := s.update(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.refreshBundles(ctx, updated)
+}
+
+// Times an update without a version is tried, another write can change the product between its read and its write
+const versionAttempts = 3
+
+// Update the product on the version read, updates without a version are tried again when another write changed it first
+func (s *ProductsCatalogService) update(ctx context.Context, request *model.Update) (updated *model.Product, err error) {
+	for attempt := 0; attempt < versionAttempts; attempt++ {
+		updated, err = s.updateRead(ctx, request)
+		if request.Version != 0 || !errors.Is(err, internalError.ErrVersionConflict) {
+			return updated, err
+		}
+	}
+
+	return nil, err
+}
+
+func (s *ProductsCatalogService) updateRead(ctx context.Context, request *model.Update) (*model.Product, error) {
+	var updated *model.Product
+
+	err := s.repository.WithTransaction(ctx, func(txRepo repository.Repository) error {
+		product, err := txRepo.GetByID(ctx, request.ID)
+		if err != nil {
+			return err
+		}
+
+		if request.Version != 0 && request.Version != product.Version {
+			return &internalError.VersionConflictError{Current: product.Version}
+		}
+
+		if request.Attributes != nil {
+			if err := validateAttributes(ctx, txRepo, product.CategoryIDs, request.Attributes); err != nil {
+				return err
+			}
+		}
+
+		if request.BrandID != nil {
+			if err := validateBrand(ctx, txRepo, *request.BrandID); err != nil {
+				return err
+			}
+		}
+
+		update := *request
+
+		if request.Price != nil && product.PricedOnComponents() {
+			return internalError.ErrBundlePricing
+		}
+
+		if request.Price != nil {
+			if update, err = changePrice(*product, update); err != nil {
+				return err
+			}
+		}
+
+		// Bundles have no stock of their own, their qty is resolved from the components
+		// Updates without qty leave the stock as it is
+		if product.IsBundle() || request.Qty == nil {
+			updated, err = txRepo.Update(ctx, update)
+
+			return err
+		}
+
+		current, err := stockOf(*product, request.VariantSKU)
+		if err != nil {
+			return err
+		}
+
+		qty := *request.Qty
+
+		// The movement delta is taken from the qty read, so the qty is only written on that version
+		update.Version = product.Version
+
+		if qty != current {
+			if err := openLedger(ctx, txRepo, *product, request.Actor); err != nil {
+				return err
+			}
+		}
+
+		if updated, err = txRepo.Update(ctx, update); err != nil || qty == current {
+			return err
+		}
+
+		_, err = txRepo.CreateStockMovement(ctx, model.StockMovement{
+			ProductID:  product.ID,
+			VariantSKU: request.VariantSKU,
+			Type:       model.MovementAdjustment,
+			Delta:      int64(qty) - int64(current),
+			Qty:        qty,
+			Reason:     model.UpdateReason,
+			Actor:      request.Actor,
+		})
+
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return updated, nil
+}
+
+// Change a product with fn and replace it on the version read, a version other than 0 must match the one read
+func (s *ProductsCatalogService) replaceProduct(
+	ctx context.Context,
+	id string,
+	version int64,
+	fn func(product *model.Product) error,
+) (*model.Product, error) {
+	return s.replaceProductWith(ctx, id, version, func(_ repository.Repository, product *model.Product) error {
+		return fn(product)
+	})
 }
 
-// Search products
+// Same as replaceProduct, fn also gets the transaction repository to write other products on it
+func (s *ProductsCatalogService) replaceProductWith(
+	ctx context.Context,
+	id string,
+	version int64,
+	fn func(txRepo repository.Repository, product *model.Product) error,
+) (*model.Product, error) {
+	var replaced *model.Product
+
+	// The product is read again on each try, so the slugs fn generates are generated again
+	err := retrySlugs(func() error {
+		return s.repository.WithTransaction(ctx, func(txRepo repository.Repository) error {
+			product, err := txRepo.GetByID(ctx, id)
+			if err != nil {
+				return err
+			}
+
+			if version != 0 && version != product.Version {
+				return &internalError.VersionConflictError{Current: product.Version}
+			}
+
+			if err := fn(txRepo, product); err != nil {
+				return err
+			}
+
+			replaced, err = txRepo.Replace(ctx, *product)
+
+			return err
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return replaced, nil
+}
+
+// Search products, callers other than admins only match the products visible to shoppers
 func (s *ProductsCatalogService) Search(ctx context.Context, request model.SearchRequest) (*model.SearchResponse, error) {
-	products, total, err := s.repository.Search(ctx, request.Name, request.Sort, request.InStock, request.Limit, request.Offset)
+	request, err := s.resolveSearch(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	page, err := s.repository.Search(ctx, request)
 	if err != nil {
 		return nil, err
 	}
 
+	// Stored prices are sorted on, the scheduled ones due since ApplyDuePrices last ran are only resolved on the page
+	now := time.Now()
+
+	for i := range page.Products {
+		if err := s.resolveSearchedPrice(ctx, &page.Products[i], request.Currency, now); err != nil {
+			return nil, err
+		}
+
+		if err := resolveBundle(ctx, s.repository, &page.Products[i]); err != nil {
+			return nil, err
+		}
+
+		page.Products[i].Localize(request.Locale, s.config.DefaultLocale)
+
+		page.Products[i].ConvertUnits(request.Units)
+	}
+
 	return &model.SearchResponse{
-		Products: products,
+		Products: page.Products,
 		Metadata: model.Metadata{
-			Total:  *total,
-			Limit:  request.Limit,
-			Offset: request.Offset,
+			Total:      page.Total,
+			Limit:      request.Limit,
+			Offset:     request.Offset,
+			NextCursor: page.Next.String(),
+			PrevCursor: page.Prev.String(),
 		},
 	}, nil
 }
+
+// Resolve the search filters repositories match, the visibility of the caller, the category subtree, the currency and the locale
+func (s *ProductsCatalogService) resolveSearch(ctx context.Context, request model.SearchRequest) (model.SearchRequest, error) {
+	// Publication windows are applied with minute precision, so cached search pages are shared within a minute
+	// Deleted products are only listed to admin callers
+	if !request.Admin {
+		visibleAt := time.Now().Truncate(time.Minute)
+
+		request.Statuses, request.VisibleAt, request.IncludeDeleted = nil, &visibleAt, false
+	}
+
+	if request.Category != "" {
+		categories, err := s.repository.GetCategorySubtree(ctx, request.Category)
+		if err != nil {
+			return request, err
+		}
+
+		request.CategoryIDs = make([]string, 0, len(categories))
+
+		for _, category := range categories {
+			request.CategoryIDs = append(request.CategoryIDs, category.ID)
+		}
+	}
+
+	request.Currency = s.currencySelection(request.Currency.Code)
+
+	request.Locale = s.negotiateLocale(request.Locales)
+
+	return request, nil
+}
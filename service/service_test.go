@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
 	internalErrors "github.com/srodrmendz/api-product-catalog/errors"
 	"github.com/srodrmendz/api-product-catalog/model"
@@ -36,7 +37,7 @@ func TestService_Create(t *testing.T) {
 	for _, dt := range dataTable {
 		t.Run(dt.name, func(t *testing.T) {
 			// Given
-			srv := New(dt.repository)
+			srv := New(dt.repository, Config{})
 
 			// When
 			product, err := srv.Create(context.TODO(), model.Product{
@@ -82,10 +83,10 @@ func TestService_GetByID(t *testing.T) {
 	for _, dt := range dataTable {
 		t.Run(dt.name, func(t *testing.T) {
 			// Given
-			srv := New(dt.repository)
+			srv := New(dt.repository, Config{})
 
 			// When
-			product, err := srv.GetByID(context.TODO(), "")
+			product, err := srv.GetByID(context.TODO(), "", model.ReadOptions{})
 
 			// Then
 			if err != nil {
@@ -102,9 +103,11 @@ func TestService_GetByID(t *testing.T) {
 // Test Get By SKU method
 func TestService_GetBySKU(t *testing.T) {
 	dataTable := []struct {
-		name        string
-		repository  repository.Repository
-		expectedErr error
+		name            string
+		sku             string
+		repository      repository.Repository
+		expectedErr     error
+		expectedVariant string
 	}{
 		{
 			name:        "failed to get product by sku, product not found",
@@ -121,15 +124,26 @@ func TestService_GetBySKU(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "successfully get product by variant sku",
+			sku:  "sku-l",
+			repository: &mockRepository{
+				product: &model.Product{
+					Name:     "Product",
+					Variants: []model.Variant{{Sku: "sku-m"}, {Sku: "sku-l"}},
+				},
+			},
+			expectedVariant: "sku-l",
+		},
 	}
 
 	for _, dt := range dataTable {
 		t.Run(dt.name, func(t *testing.T) {
 			// Given
-			srv := New(dt.repository)
+			srv := New(dt.repository, Config{})
 
 			// When
-			product, err := srv.GetBySKU(context.TODO(), "")
+			product, err := srv.GetBySKU(context.TODO(), dt.sku, model.ReadOptions{})
 
 			// Then
 			if err != nil {
@@ -139,6 +153,14 @@ func TestService_GetBySKU(t *testing.T) {
 			}
 
 			assert.NotEmpty(t, product.Name)
+
+			if dt.expectedVariant == "" {
+				assert.Nil(t, product.Variant)
+
+				return
+			}
+
+			assert.Equal(t, dt.expectedVariant, product.Variant.Sku)
 		})
 	}
 }
@@ -166,10 +188,10 @@ func TestService_Delete(t *testing.T) {
 	for _, dt := range dataTable {
 		t.Run(dt.name, func(t *testing.T) {
 			// Given
-			srv := New(dt.repository)
+			srv := New(dt.repository, Config{})
 
 			// When
-			err := srv.Delete(context.TODO(), "")
+			err := srv.Delete(context.TODO(), "", 0)
 
 			// Then
 			assert.Equal(t, dt.expectedErr, err)
@@ -177,6 +199,78 @@ func TestService_Delete(t *testing.T) {
 	}
 }
 
+// Test Restore method
+func TestService_Restore(t *testing.T) {
+	dataTable := []struct {
+		name        string
+		repository  repository.Repository
+		expectedErr error
+	}{
+		{
+			name:        "failed to restore product, product not found",
+			expectedErr: internalErrors.ErrProductNotFound,
+			repository: &mockRepository{
+				err: internalErrors.ErrProductNotFound,
+			},
+		},
+		{
+			name: "successfully restore product",
+			repository: &mockRepository{
+				product: &model.Product{
+					Name: "Product 1",
+				},
+			},
+		},
+	}
+
+	for _, dt := range dataTable {
+		t.Run(dt.name, func(t *testing.T) {
+			// Given
+			srv := New(dt.repository, Config{})
+
+			// When
+			product, err := srv.Restore(context.TODO(), "")
+
+			// Then
+			if dt.expectedErr != nil {
+				assert.Equal(t, dt.expectedErr, err)
+
+				assert.Nil(t, product)
+
+				return
+			}
+
+			assert.NoError(t, err)
+
+			assert.NotNil(t, product)
+		})
+	}
+}
+
+// Test Purge method
+func TestService_Purge(t *testing.T) {
+	t.Run("successfully purge products deleted before retention", func(t *testing.T) {
+		// Given
+		repository := &mockRepository{
+			purged: 2,
+		}
+
+		srv := New(repository, Config{})
+
+		retention := 24 * time.Hour
+
+		// When
+		purged, err := srv.Purge(context.TODO(), retention)
+
+		// Then
+		assert.NoError(t, err)
+
+		assert.Equal(t, int64(2), purged)
+
+		assert.WithinDuration(t, time.Now().Add(-retention), repository.before, time.Minute)
+	})
+}
+
 // Test Update method
 func TestService_Update(t *testing.T) {
 	dataTable := []struct {
@@ -204,7 +298,7 @@ func TestService_Update(t *testing.T) {
 	for _, dt := range dataTable {
 		t.Run(dt.name, func(t *testing.T) {
 			// Given
-			srv := New(dt.repository)
+			srv := New(dt.repository, Config{})
 
 			// When
 			product, err := srv.Update(context.TODO(), &model.Update{})
@@ -248,7 +342,7 @@ func TestService_Search(t *testing.T) {
 	for _, dt := range dataTable {
 		t.Run(dt.name, func(t *testing.T) {
 			// Given
-			srv := New(dt.repository)
+			srv := New(dt.repository, Config{})
 
 			request := model.SearchRequest{
 				Limit:  10,
@@ -279,6 +373,9 @@ type mockRepository struct {
 	product  *model.Product
 	products []model.Product
 	total    int64
+	next     *model.Cursor
+	purged   int64
+	before   time.Time
 }
 
 func (m *mockRepository) Create(ctx context.Context, product model.Product) (*model.Product, error) {
@@ -293,14 +390,116 @@ func (m *mockRepository) GetBySKU(ctx context.Context, sku string) (*model.Produ
 	return m.product, m.err
 }
 
-func (m *mockRepository) Delete(ctx context.Context, id string) error {
+func (m *mockRepository) GetBySlug(ctx context.Context, slug string) (*model.Product, error) {
+	return m.product, m.err
+}
+
+func (m *mockRepository) Delete(ctx context.Context, id string, version int64) error {
 	return m.err
 }
 
-func (m *mockRepository) Update(ctx context.Context, id string, qty uint64) (*model.Product, error) {
+func (m *mockRepository) Restore(ctx context.Context, id string) (*model.Product, error) {
 	return m.product, m.err
 }
 
-func (m *mockRepository) Search(ctx context.Context, name string, sort string, inStock bool, limit int, offset int) ([]model.Product, *int64, error) {
-	return m.products, &m.total, m.err
+func (m *mockRepository) Purge(ctx context.Context, before time.Time) (int64, error) {
+	m.before = before
+
+	return m.purged, m.err
+}
+
+func (m *mockRepository) Replace(ctx context.Context, product model.Product) (*model.Product, error) {
+	return &product, m.err
+}
+
+func (m *mockRepository) ListDuePrices(ctx context.Context, at time.Time) ([]model.Product, error) {
+	return nil, m.err
+}
+
+func (m *mockRepository) Update(ctx context.Context, update model.Update) (*model.Product, error) {
+	return m.product, m.err
+}
+
+func (m *mockRepository) AdjustStock(ctx context.Context, adjustment model.StockAdjustment) (*model.Product, error) {
+	return m.product, m.err
+}
+
+func (m *mockRepository) WithTransaction(ctx context.Context, fn func(txRepo repository.Repository) error) error {
+	return fn(m)
+}
+
+func (m *mockRepository) Search(ctx context.Context, request model.SearchRequest) (*model.Page, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+
+	return &model.Page{
+		Products: m.products,
+		Total:    m.total,
+		Next:     m.next,
+	}, nil
+}
+
+func (m *mockRepository) CreateCategory(ctx context.Context, category model.Category) (*model.Category, error) {
+	return &category, m.err
+}
+
+func (m *mockRepository) GetCategory(ctx context.Context, id string) (*model.Category, error) {
+	return &model.Category{ID: id}, m.err
+}
+
+func (m *mockRepository) ListCategories(ctx context.Context) ([]model.Category, error) {
+	return []model.Category{}, m.err
+}
+
+func (m *mockRepository) GetCategorySubtree(ctx context.Context, id string) ([]model.Category, error) {
+	return []model.Category{{ID: id}}, m.err
+}
+
+func (m *mockRepository) UpdateCategory(ctx context.Context, category model.Category) (*model.Category, error) {
+	return &category, m.err
+}
+
+func (m *mockRepository) DeleteCategory(ctx context.Context, id string) error {
+	return m.err
+}
+
+func (m *mockRepository) ReplaceProductCategory(ctx context.Context, from string, to string) (int64, error) {
+	return 0, m.err
+}
+
+func (m *mockRepository) CountTags(ctx context.Context, request model.SearchRequest) ([]model.TagCount, error) {
+	return []model.TagCount{}, m.err
+}
+
+func (m *mockRepository) CreateBrand(ctx context.Context, brand model.Brand) (*model.Brand, error) {
+	return &brand, m.err
+}
+
+func (m *mockRepository) GetBrand(ctx context.Context, id string) (*model.Brand, error) {
+	return &model.Brand{ID: id}, m.err
+}
+
+func (m *mockRepository) ListBrands(ctx context.Context) ([]model.Brand, error) {
+	return []model.Brand{}, m.err
+}
+
+func (m *mockRepository) UpdateBrand(ctx context.Context, brand model.Brand) (*model.Brand, error) {
+	return &brand, m.err
+}
+
+func (m *mockRepository) DeleteBrand(ctx context.Context, id string) error {
+	return m.err
+}
+
+func (m *mockRepository) CreateStockMovement(ctx context.Context, movement model.StockMovement) (*model.StockMovement, error) {
+	return &movement, m.err
+}
+
+func (m *mockRepository) ListStockMovements(ctx context.Context, request model.MovementSearchRequest) (*model.MovementPage, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+
+	return &model.MovementPage{Movements: []model.StockMovement{}}, nil
 }
@@ -0,0 +1,67 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/srodrmendz/api-product-catalog/model"
+	"github.com/srodrmendz/api-product-catalog/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test product weight and dimensions are read on the requested unit system
+func TestService_Units(t *testing.T) {
+	ctx := context.TODO()
+
+	srv := New(repository.NewMemory(), Config{})
+
+	created, err := srv.Create(ctx, model.Product{
+		Name:       "boots",
+		Sku:        "boots",
+		Qty:        1,
+		Price:      100,
+		Status:     model.StatusPublished,
+		Weight:     &model.Weight{Value: 0.5, Unit: model.Kilogram},
+		Dimensions: &model.Dimensions{Length: 25.4, Width: 12.7, Height: 2.54, Unit: model.Centimeter},
+	})
+	require.NoError(t, err)
+
+	imperialWeight := &model.Weight{Value: 1.102311, Unit: model.Pound}
+
+	imperialDimensions := &model.Dimensions{Length: 10, Width: 5, Height: 1, Unit: model.Inch}
+
+	t.Run("products are read on the metric units they are stored on", func(t *testing.T) {
+		product, err := srv.GetByID(ctx, created.ID, model.ReadOptions{Units: model.UnitsMetric})
+		require.NoError(t, err)
+
+		assert.Equal(t, created.Weight, product.Weight)
+
+		assert.Equal(t, created.Dimensions, product.Dimensions)
+	})
+
+	t.Run("products are read on imperial units", func(t *testing.T) {
+		product, err := srv.GetByID(ctx, created.ID, model.ReadOptions{Units: model.UnitsImperial})
+		require.NoError(t, err)
+
+		assert.Equal(t, imperialWeight, product.Weight)
+
+		assert.Equal(t, imperialDimensions, product.Dimensions)
+
+		sku, err := srv.GetBySKU(ctx, "boots", model.ReadOptions{Units: model.UnitsImperial})
+		require.NoError(t, err)
+
+		assert.Equal(t, imperialWeight, sku.Weight)
+	})
+
+	t.Run("searched products are read on imperial units", func(t *testing.T) {
+		response, err := srv.Search(ctx, model.SearchRequest{InStock: true, Units: model.UnitsImperial})
+		require.NoError(t, err)
+
+		require.Len(t, response.Products, 1)
+
+		assert.Equal(t, imperialWeight, response.Products[0].Weight)
+
+		assert.Equal(t, imperialDimensions, response.Products[0].Dimensions)
+	})
+}
@@ -0,0 +1,191 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	internalError "github.com/srodrmendz/api-product-catalog/errors"
+	"github.com/srodrmendz/api-product-catalog/model"
+	"github.com/srodrmendz/api-product-catalog/repository"
+)
+
+// Get a product by one of its slugs, the current slug of a locale gets the product localized to that locale
+// A previous slug fails with a SlugMovedError holding the current slug of its locale
+// Products not visible to shoppers are only found by admin callers
+func (s *ProductsCatalogService) GetBySlug(ctx context.Context, slug string, options model.ReadOptions) (*model.Product, error) {
+	product, err := s.repository.GetBySlug(ctx, slug)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+
+	if product.DeletedAt != nil || !options.Admin && !product.IsVisible(now) {
+		return nil, internalError.ErrProductNotFound
+	}
+
+	locale, current, _ := product.FindSlug(slug)
+
+	if locale == "" {
+		locale = product.ContentLocale(s.config.DefaultLocale)
+	}
+
+	if !current {
+		moved := product.SlugOn(locale)
+		if moved == "" {
+			return nil, internalError.ErrProductNotFound
+		}
+
+		return nil, &internalError.SlugMovedError{Slug: moved, ProductID: product.ID}
+	}
+
+	product.ResolvePrice(now)
+
+	if err := resolveBundle(ctx, s.repository, product); err != nil {
+		return nil, err
+	}
+
+	if err := s.selectCurrency(product, options.Currency); err != nil {
+		return nil, err
+	}
+
+	product.Localize(locale, s.config.DefaultLocale)
+
+	product.ConvertUnits(options.Units)
+
+	if options.ExpandRelations {
+		if err := s.expandRelations(ctx, product, options); err != nil {
+			return nil, err
+		}
+	}
+
+	return product, nil
+}
+
+// Change the slug of the product content on the request locale, the product own content when it is empty
+// An empty slug is generated again from the content name, the replaced slug redirects to the new one
+func (s *ProductsCatalogService) ChangeSlug(ctx context.Context, request model.SlugRequest) (*model.Product, error) {
+	return s.replaceProductWith(ctx, request.ID, request.Version, func(txRepo repository.Repository, product *model.Product) error {
+		locale, name := request.Locale, product.Name
+
+		if locale == "" || locale == product.ContentLocale(s.config.DefaultLocale) {
+			locale = ""
+		} else if translation := product.TranslationOn(locale); translation != nil {
+			name = translation.Name
+		} else {
+			return internalError.ErrTranslationNotFound
+		}
+
+		contentLocale := locale
+		if contentLocale == "" {
+			contentLocale = product.ContentLocale(s.config.DefaultLocale)
+		}
+
+		slug, err := resolveSlug(ctx, txRepo, *product, request.Slug, name, contentLocale)
+		if err != nil {
+			return err
+		}
+
+		product.ChangeSlug(slug, locale, time.Now())
+
+		return nil
+	})
+}
+
+// Give a new product and its translations their slugs, the requested ones must be free and the missing ones are generated
+func assignSlugs(ctx context.Context, repo repository.Repository, product *model.Product) error {
+	slug, err := resolveSlug(ctx, repo, *product, product.Slug, product.Name, product.Locale)
+	if err != nil {
+		return err
+	}
+
+	product.Slug = slug
+
+	for i := range product.Translations {
+		translation := &product.Translations[i]
+
+		if translation.Slug, err = resolveSlug(ctx, repo, *product, translation.Slug, translation.Name, translation.Locale); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Get the slug of the product content on locale, a requested slug must not be held by another product
+// Without one it is generated from the content name, or from the sku when the name has no letters or numbers,
+// and numbered from 2 until no other product holds it
+func resolveSlug(ctx context.Context, repo repository.Repository, product model.Product, requested string, name string, locale string) (string, error) {
+	if requested != "" {
+		free, err := isSlugFree(ctx, repo, product.ID, requested)
+		if err != nil {
+			return "", err
+		}
+
+		if !free {
+			return "", internalError.ErrSlugAlreadyExist
+		}
+
+		return requested, nil
+	}
+
+	base := model.Slugify(name, locale)
+
+	if base == "" {
+		base = model.Slugify(product.Sku, locale)
+	}
+
+	if base == "" {
+		base = "product"
+	}
+
+	for number := 1; ; number++ {
+		slug := base
+
+		if number > 1 {
+			slug = fmt.Sprintf("%s-%d", base, number)
+		}
+
+		free, err := isSlugFree(ctx, repo, product.ID, slug)
+		if err != nil {
+			return "", err
+		}
+
+		if free {
+			return slug, nil
+		}
+	}
+}
+
+// Reports whether no product other than the one with id holds the slug, previous slugs and the ones of deleted products included
+func isSlugFree(ctx context.Context, repo repository.Repository, id string, slug string) (bool, error) {
+	holder, err := repo.GetBySlug(ctx, slug)
+	if errors.Is(err, internalError.ErrProductNotFound) {
+		return true, nil
+	}
+
+	if err != nil {
+		return false, err
+	}
+
+	return holder.ID == id, nil
+}
+
+// Times a write assigning slugs is tried, the store rejects the slugs another write took since they were checked free
+const slugAttempts = 3
+
+// Run a write assigning slugs again while the store rejects them, so the slugs another write took are generated again
+// A requested slug held by another product keeps failing with ErrSlugAlreadyExist
+func retrySlugs(write func() error) error {
+	var err error
+
+	for attempt := 0; attempt < slugAttempts; attempt++ {
+		if err = write(); !errors.Is(err, internalError.ErrSlugAlreadyExist) {
+			return err
+		}
+	}
+
+	return err
+}
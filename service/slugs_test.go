@@ -0,0 +1,209 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	internalErrors "github.com/srodrmendz/api-product-catalog/errors"
+	"github.com/srodrmendz/api-product-catalog/model"
+	"github.com/srodrmendz/api-product-catalog/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test product slugs on top of the in memory repository
+func TestService_Slugs(t *testing.T) {
+	ctx := context.TODO()
+
+	t.Run("slugs are generated from the name on the product locale", func(t *testing.T) {
+		srv := New(repository.NewMemory(), Config{DefaultLocale: "en"})
+
+		for i, dt := range []struct {
+			name     string
+			locale   string
+			expected string
+		}{
+			{name: "Crème Brûlée  Set", locale: "fr", expected: "creme-brulee-set"},
+			{name: "Größe Übergröße", locale: "de", expected: "groesse-uebergroesse"},
+			{name: "IŞIK Lamba", locale: "tr", expected: "isik-lamba"},
+			{name: "T-Shirt (XL) 100% Cotton", expected: "t-shirt-xl-100-cotton"},
+			{name: "運動靴", locale: "ja", expected: "運動靴"},
+			// Names without letters or numbers take the slug from the sku
+			{name: "***", expected: "sku-5"},
+		} {
+			product, err := srv.Create(ctx, model.Product{Name: dt.name, Sku: fmt.Sprintf("SKU %d", i), Qty: 1, Price: 100, Locale: dt.locale})
+			require.NoError(t, err)
+
+			assert.Equal(t, dt.expected, product.Slug, dt.name)
+		}
+	})
+
+	t.Run("generated slugs are numbered and requested slugs must be free", func(t *testing.T) {
+		srv := New(repository.NewMemory(), Config{})
+
+		first, err := srv.Create(ctx, model.Product{Name: "Shoes", Sku: "shoes-1", Qty: 1, Price: 100})
+		require.NoError(t, err)
+
+		second, err := srv.Create(ctx, model.Product{
+			Name:         "Shoes",
+			Sku:          "shoes-2",
+			Qty:          1,
+			Price:        100,
+			Translations: []model.Translation{{Locale: "es", Name: "Zapatos"}},
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, "shoes", first.Slug)
+		assert.Equal(t, "shoes-2", second.Slug)
+		assert.Equal(t, "zapatos", second.Translations[0].Slug)
+
+		_, err = srv.Create(ctx, model.Product{Name: "Boots", Sku: "boots", Qty: 1, Price: 100, Slug: "zapatos"})
+		assert.ErrorIs(t, err, internalErrors.ErrSlugAlreadyExist)
+
+		_, err = srv.ChangeSlug(ctx, model.SlugRequest{ID: first.ID, Slug: "shoes-2"})
+		assert.ErrorIs(t, err, internalErrors.ErrSlugAlreadyExist)
+	})
+
+	t.Run("previous slugs redirect to the current one and stay reserved", func(t *testing.T) {
+		srv := New(repository.NewMemory(), Config{DefaultLocale: "en"})
+
+		product, err := srv.Create(ctx, model.Product{Name: "Running Shoes", Sku: "shoes", Qty: 1, Price: 100})
+		require.NoError(t, err)
+
+		changed, err := srv.ChangeSlug(ctx, model.SlugRequest{ID: product.ID, Version: product.Version, Slug: "trail-shoes"})
+		require.NoError(t, err)
+
+		assert.Equal(t, "trail-shoes", changed.Slug)
+		require.Len(t, changed.SlugHistory, 1)
+		assert.Equal(t, "running-shoes", changed.SlugHistory[0].Slug)
+
+		found, err := srv.GetBySlug(ctx, "trail-shoes", model.ReadOptions{})
+		require.NoError(t, err)
+
+		assert.Equal(t, product.ID, found.ID)
+
+		_, err = srv.GetBySlug(ctx, "running-shoes", model.ReadOptions{})
+
+		var moved *internalErrors.SlugMovedError
+
+		require.ErrorAs(t, err, &moved)
+		assert.Equal(t, internalErrors.SlugMovedError{Slug: "trail-shoes", ProductID: product.ID}, *moved)
+
+		_, err = srv.Create(ctx, model.Product{Name: "Boots", Sku: "boots", Qty: 1, Price: 100, Slug: "running-shoes"})
+		assert.ErrorIs(t, err, internalErrors.ErrSlugAlreadyExist)
+
+		// Generating the slug again takes the previous one back
+		reverted, err := srv.ChangeSlug(ctx, model.SlugRequest{ID: product.ID})
+		require.NoError(t, err)
+
+		assert.Equal(t, "running-shoes", reverted.Slug)
+		require.Len(t, reverted.SlugHistory, 1)
+		assert.Equal(t, "trail-shoes", reverted.SlugHistory[0].Slug)
+	})
+
+	t.Run("translation slugs get the product on their locale and redirect once removed", func(t *testing.T) {
+		srv := New(repository.NewMemory(), Config{DefaultLocale: "en"})
+
+		product, err := srv.Create(ctx, model.Product{Name: "Shirt", Sku: "shirt", Qty: 1, Price: 100})
+		require.NoError(t, err)
+
+		translated, err := srv.SetTranslation(ctx, model.TranslationRequest{ID: product.ID, Translation: model.Translation{Locale: "es", Name: "Camisa"}})
+		require.NoError(t, err)
+
+		assert.Equal(t, "camisa", translated.SlugOn("es"))
+
+		// Translations keep their slug when they are set again without one
+		translated, err = srv.SetTranslation(ctx, model.TranslationRequest{ID: product.ID, Translation: model.Translation{Locale: "es", Name: "Camiseta"}})
+		require.NoError(t, err)
+
+		assert.Equal(t, "camisa", translated.SlugOn("es"))
+
+		found, err := srv.GetBySlug(ctx, "camisa", model.ReadOptions{})
+		require.NoError(t, err)
+
+		assert.Equal(t, "es", found.Locale)
+		assert.Equal(t, "Camiseta", found.Name)
+
+		translated, err = srv.ChangeSlug(ctx, model.SlugRequest{ID: product.ID, Locale: "es"})
+		require.NoError(t, err)
+
+		assert.Equal(t, "camiseta", translated.SlugOn("es"))
+
+		_, err = srv.ChangeSlug(ctx, model.SlugRequest{ID: product.ID, Locale: "fr", Slug: "chemise"})
+		assert.ErrorIs(t, err, internalErrors.ErrTranslationNotFound)
+
+		_, err = srv.RemoveTranslation(ctx, model.TranslationRequest{ID: product.ID, Translation: model.Translation{Locale: "es"}})
+		require.NoError(t, err)
+
+		for _, slug := range []string{"camisa", "camiseta"} {
+			_, err = srv.GetBySlug(ctx, slug, model.ReadOptions{})
+
+			var moved *internalErrors.SlugMovedError
+
+			require.ErrorAs(t, err, &moved)
+			assert.Equal(t, "shirt", moved.Slug)
+		}
+	})
+
+	t.Run("generated slugs another write takes are generated again", func(t *testing.T) {
+		repo := repository.NewMemory()
+
+		srv := New(repo, Config{})
+
+		_, err := srv.Create(ctx, model.Product{Name: "Shoes", Sku: "shoes-1", Qty: 1, Price: 100})
+		require.NoError(t, err)
+
+		// The first slug read misses the product holding it, as if that product was created after the read
+		stale := 1
+
+		srv = New(&staleSlugsRepository{Repository: repo, stale: &stale}, Config{})
+
+		product, err := srv.Create(ctx, model.Product{Name: "Shoes", Sku: "shoes-2", Qty: 1, Price: 100})
+		require.NoError(t, err)
+
+		assert.Equal(t, "shoes-2", product.Slug)
+	})
+
+	t.Run("products not visible to shoppers are only found by admin callers", func(t *testing.T) {
+		srv := New(repository.NewMemory(), Config{})
+
+		product, err := srv.Create(ctx, model.Product{Name: "Hat", Sku: "hat", Qty: 1, Price: 100, Status: model.StatusDraft})
+		require.NoError(t, err)
+
+		_, err = srv.GetBySlug(ctx, "hat", model.ReadOptions{})
+		assert.ErrorIs(t, err, internalErrors.ErrProductNotFound)
+
+		found, err := srv.GetBySlug(ctx, "hat", model.ReadOptions{Admin: true})
+		require.NoError(t, err)
+
+		assert.Equal(t, product.ID, found.ID)
+
+		require.NoError(t, srv.Delete(ctx, product.ID, 0))
+
+		_, err = srv.GetBySlug(ctx, "hat", model.ReadOptions{Admin: true})
+		assert.ErrorIs(t, err, internalErrors.ErrProductNotFound)
+	})
+}
+
+// Misses the products holding the slugs on its first stale slug reads, transactions included
+type staleSlugsRepository struct {
+	repository.Repository
+	stale *int
+}
+
+func (r *staleSlugsRepository) GetBySlug(ctx context.Context, slug string) (*model.Product, error) {
+	if *r.stale > 0 {
+		*r.stale--
+
+		return nil, internalErrors.ErrProductNotFound
+	}
+
+	return r.Repository.GetBySlug(ctx, slug)
+}
+
+func (r *staleSlugsRepository) WithTransaction(ctx context.Context, fn func(txRepo repository.Repository) error) error {
+	return r.Repository.WithTransaction(ctx, func(txRepo repository.Repository) error {
+		return fn(&staleSlugsRepository{Repository: txRepo, stale: r.stale})
+	})
+}
@@ -0,0 +1,26 @@
+package service
+
+import (
+	"context"
+
+	internalError "github.com/srodrmendz/api-product-catalog/errors"
+	"github.com/srodrmendz/api-product-catalog/model"
+)
+
+// Change the product publication status, written on the version read or on the requested one when it is set
+// Components of bundles that are not archived or deleted can not be archived
+func (s *ProductsCatalogService) ChangeStatus(ctx context.Context, request model.StatusRequest) (*model.Product, error) {
+	if request.Transition == model.TransitionArchive {
+		if err := checkNotInBundle(ctx, s.repository, request.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.replaceProduct(ctx, request.ID, request.Version, func(product *model.Product) error {
+		if !product.Transition(request) {
+			return internalError.ErrStatusTransition
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,173 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	internalErrors "github.com/srodrmendz/api-product-catalog/errors"
+	"github.com/srodrmendz/api-product-catalog/model"
+	"github.com/srodrmendz/api-product-catalog/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test publication status transitions and visibility on top of the in memory repository
+func TestService_Status(t *testing.T) {
+	ctx := context.TODO()
+
+	newProduct := func(t *testing.T, srv *ProductsCatalogService, sku string, status model.Status) *model.Product {
+		product, err := srv.Create(ctx, model.Product{Name: sku, Sku: sku, Qty: 1, Price: 100, Status: status})
+		require.NoError(t, err)
+
+		return product
+	}
+
+	transition := func(id string, transition model.Transition) model.StatusRequest {
+		return model.StatusRequest{ID: id, Transition: transition}
+	}
+
+	search := func(t *testing.T, srv *ProductsCatalogService, request model.SearchRequest) []string {
+		request.InStock = true
+
+		response, err := srv.Search(ctx, request)
+		require.NoError(t, err)
+
+		skus := []string{}
+
+		for _, product := range response.Products {
+			skus = append(skus, product.Sku)
+		}
+
+		return skus
+	}
+
+	t.Run("draft product is published, unpublished, archived and unarchived", func(t *testing.T) {
+		srv := New(repository.NewMemory(), Config{})
+
+		product := newProduct(t, srv, "sku", model.StatusDraft)
+
+		for _, step := range []struct {
+			transition model.Transition
+			expected   model.Status
+		}{
+			{model.TransitionPublish, model.StatusPublished},
+			{model.TransitionUnpublish, model.StatusDraft},
+			{model.TransitionArchive, model.StatusArchived},
+			{model.TransitionUnarchive, model.StatusDraft},
+		} {
+			updated, err := srv.ChangeStatus(ctx, transition(product.ID, step.transition))
+			require.NoError(t, err)
+
+			assert.Equal(t, step.expected, updated.Status)
+		}
+
+		stored, err := srv.GetByID(ctx, product.ID, model.ReadOptions{Admin: true})
+		require.NoError(t, err)
+
+		assert.Equal(t, model.StatusDraft, stored.Status)
+
+		assert.Equal(t, product.Version+4, stored.Version)
+	})
+
+	t.Run("transitions the status does not allow are rejected", func(t *testing.T) {
+		srv := New(repository.NewMemory(), Config{})
+
+		draft := newProduct(t, srv, "draft", model.StatusDraft)
+
+		_, err := srv.ChangeStatus(ctx, transition(draft.ID, model.TransitionUnpublish))
+		assert.ErrorIs(t, err, internalErrors.ErrStatusTransition)
+
+		_, err = srv.ChangeStatus(ctx, transition(draft.ID, model.TransitionUnarchive))
+		assert.ErrorIs(t, err, internalErrors.ErrStatusTransition)
+
+		_, err = srv.ChangeStatus(ctx, transition(draft.ID, model.TransitionArchive))
+		require.NoError(t, err)
+
+		_, err = srv.ChangeStatus(ctx, transition(draft.ID, model.TransitionPublish))
+		assert.ErrorIs(t, err, internalErrors.ErrStatusTransition)
+	})
+
+	t.Run("transition on another version is a conflict", func(t *testing.T) {
+		srv := New(repository.NewMemory(), Config{})
+
+		product := newProduct(t, srv, "sku", model.StatusDraft)
+
+		request := transition(product.ID, model.TransitionPublish)
+
+		request.Version = product.Version + 1
+
+		_, err := srv.ChangeStatus(ctx, request)
+
+		assert.ErrorIs(t, err, internalErrors.ErrVersionConflict)
+	})
+
+	t.Run("public callers only find visible products", func(t *testing.T) {
+		srv := New(repository.NewMemory(), Config{})
+
+		newProduct(t, srv, "draft", model.StatusDraft)
+
+		newProduct(t, srv, "published", model.StatusPublished)
+
+		archived := newProduct(t, srv, "archived", model.StatusDraft)
+
+		_, err := srv.ChangeStatus(ctx, transition(archived.ID, model.TransitionArchive))
+		require.NoError(t, err)
+
+		scheduled := newProduct(t, srv, "scheduled", model.StatusDraft)
+
+		publishAt := time.Now().Add(time.Hour)
+
+		_, err = srv.ChangeStatus(ctx, model.StatusRequest{ID: scheduled.ID, Transition: model.TransitionPublish, PublishAt: &publishAt})
+		require.NoError(t, err)
+
+		expired := newProduct(t, srv, "expired", model.StatusDraft)
+
+		unpublishAt := time.Now().Add(-time.Hour)
+
+		_, err = srv.ChangeStatus(ctx, model.StatusRequest{ID: expired.ID, Transition: model.TransitionPublish, UnpublishAt: &unpublishAt})
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"published"}, search(t, srv, model.SearchRequest{}))
+
+		assert.Equal(t, []string{"published"}, search(t, srv, model.SearchRequest{Statuses: []model.Status{model.StatusDraft}}))
+
+		assert.ElementsMatch(t, []string{"draft", "published", "archived", "scheduled", "expired"}, search(t, srv, model.SearchRequest{Admin: true}))
+
+		assert.ElementsMatch(t, []string{"draft"}, search(t, srv, model.SearchRequest{Admin: true, Statuses: []model.Status{model.StatusDraft}}))
+
+		_, err = srv.GetBySKU(ctx, "scheduled", model.ReadOptions{})
+		assert.ErrorIs(t, err, internalErrors.ErrProductNotFound)
+
+		_, err = srv.GetBySKU(ctx, "draft", model.ReadOptions{})
+		assert.ErrorIs(t, err, internalErrors.ErrProductNotFound)
+
+		product, err := srv.GetBySKU(ctx, "draft", model.ReadOptions{Admin: true})
+		require.NoError(t, err)
+
+		assert.Equal(t, model.StatusDraft, product.Status)
+
+		_, err = srv.GetByID(ctx, scheduled.ID, model.ReadOptions{})
+		assert.ErrorIs(t, err, internalErrors.ErrProductNotFound)
+
+		_, err = srv.GetByID(ctx, scheduled.ID, model.ReadOptions{Admin: true})
+		assert.NoError(t, err)
+
+		_, err = srv.GetBySKU(ctx, "published", model.ReadOptions{})
+		assert.NoError(t, err)
+	})
+
+	t.Run("deleted products are only listed to admin callers", func(t *testing.T) {
+		srv := New(repository.NewMemory(), Config{})
+
+		newProduct(t, srv, "published", model.StatusPublished)
+
+		deleted := newProduct(t, srv, "deleted", model.StatusPublished)
+
+		require.NoError(t, srv.Delete(ctx, deleted.ID, 0))
+
+		assert.Equal(t, []string{"published"}, search(t, srv, model.SearchRequest{IncludeDeleted: true}))
+
+		assert.ElementsMatch(t, []string{"published", "deleted"}, search(t, srv, model.SearchRequest{Admin: true, IncludeDeleted: true}))
+	})
+}
@@ -0,0 +1,260 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	internalError "github.com/srodrmendz/api-product-catalog/errors"
+	"github.com/srodrmendz/api-product-catalog/model"
+	"github.com/srodrmendz/api-product-catalog/repository"
+)
+
+// Record a stock movement on a product, or on one of its variants, and change its qty by the movement delta on the same transaction
+// The qty changes on a single conditional write, so movements can never take more stock than there is
+// A product found by sku through a variant sku moves the stock of that variant, bundles have no stock of their own to move
+// The bundles holding the product are resolved again
+func (s *ProductsCatalogService) RecordMovement(ctx context.Context, request model.MovementRequest) (*model.StockMovement, error) {
+	var recorded *model.StockMovement
+
+	err := s.repository.WithTransaction(ctx, func(txRepo repository.Repository) error {
+		product, variantSKU, err := movementProduct(ctx, txRepo, request)
+		if err != nil {
+			return err
+		}
+
+		if product.IsBundle() {
+			return internalError.ErrBundleStock
+		}
+
+		if err := openLedger(ctx, txRepo, *product, request.Actor); err != nil {
+			return err
+		}
+
+		adjusted, err := txRepo.AdjustStock(ctx, model.StockAdjustment{ID: product.ID, VariantSKU: variantSKU, Delta: request.Delta})
+		if err != nil {
+			return err
+		}
+
+		qty, err := stockOf(*adjusted, variantSKU)
+		if err != nil {
+			return err
+		}
+
+		recorded, err = txRepo.CreateStockMovement(ctx, model.StockMovement{
+			ProductID:  product.ID,
+			VariantSKU: variantSKU,
+			Type:       request.Type,
+			Delta:      request.Delta,
+			Qty:        qty,
+			Reason:     request.Reason,
+			Reference:  request.Reference,
+			Actor:      request.Actor,
+		})
+
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.refreshBundlesOf(ctx, recorded.ProductID); err != nil {
+		return nil, err
+	}
+
+	return recorded, nil
+}
+
+// Get the product a movement request addresses by id or by sku, and the sku of the variant it moves
+func movementProduct(ctx context.Context, repo repository.Repository, request model.MovementRequest) (*model.Product, string, error) {
+	if request.ID != "" {
+		product, err := repo.GetByID(ctx, request.ID)
+
+		return product, request.VariantSKU, err
+	}
+
+	product, err := repo.GetBySKU(ctx, request.SKU)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if request.SKU == product.Sku {
+		return product, "", nil
+	}
+
+	return product, request.SKU, nil
+}
+
+// List the stock movements of a product matching the request filters, newest first
+func (s *ProductsCatalogService) ListStockMovements(ctx context.Context, request model.MovementSearchRequest) (*model.MovementsResponse, error) {
+	if _, err := s.repository.GetByID(ctx, request.ProductID); err != nil {
+		return nil, err
+	}
+
+	page, err := s.repository.ListStockMovements(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.MovementsResponse{
+		Movements: page.Movements,
+		Metadata: model.Metadata{
+			Total:  page.Total,
+			Limit:  request.Limit,
+			Offset: request.Offset,
+		},
+	}, nil
+}
+
+// Compare the stored qty of a product and its variants with the qty its stock movements add up to
+// A product whose ledger did not start yet is compared with the opening balance it would start with
+func (s *ProductsCatalogService) VerifyStock(ctx context.Context, id string) (*model.StockLedger, error) {
+	product, err := s.repository.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if product.IsBundle() {
+		return nil, internalError.ErrBundleStock
+	}
+
+	page, err := s.repository.ListStockMovements(ctx, model.MovementSearchRequest{ProductID: id})
+	if err != nil {
+		return nil, err
+	}
+
+	movements := page.Movements
+
+	if page.Total == 0 {
+		movements = openingBalances(*product, "")
+	}
+
+	ledger := model.NewStockLedger(*product, movements)
+
+	return &ledger, nil
+}
+
+// Set the qty of a product and its variants to the qty their stock movements add up to, the ledger is left unchanged
+// The bundles holding the product are resolved again
+func (s *ProductsCatalogService) RebuildStock(ctx context.Context, id string) (*model.Product, error) {
+	rebuilt, err := s.replaceProductWith(ctx, id, 0, func(txRepo repository.Repository, product *model.Product) error {
+		if product.IsBundle() {
+			return internalError.ErrBundleStock
+		}
+
+		if err := openLedger(ctx, txRepo, *product, ""); err != nil {
+			return err
+		}
+
+		page, err := txRepo.ListStockMovements(ctx, model.MovementSearchRequest{ProductID: id})
+		if err != nil {
+			return err
+		}
+
+		ledger := model.NewStockLedger(*product, page.Movements)
+
+		if len(product.Variants) == 0 {
+			if ledger.LedgerQty < 0 {
+				return fmt.Errorf("%w, stock movements add up to %d", internalError.ErrInsufficientStock, ledger.LedgerQty)
+			}
+
+			product.Qty = uint64(ledger.LedgerQty)
+
+			return nil
+		}
+
+		for i, variant := range ledger.Variants {
+			if variant.LedgerQty < 0 {
+				return fmt.Errorf("%w, stock movements of %s add up to %d", internalError.ErrInsufficientStock, variant.SKU, variant.LedgerQty)
+			}
+
+			product.Variants[i].Qty = uint64(variant.LedgerQty)
+		}
+
+		product.ApplyVariants()
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.refreshBundlesOf(ctx, id); err != nil {
+		return nil, err
+	}
+
+	return rebuilt, nil
+}
+
+// Record the opening balance of a product whose stock was set before its ledger started, so its movements add up to its stored qty
+// Products with movements already started theirs, and bundles have no stock of their own
+func openLedger(ctx context.Context, repo repository.Repository, product model.Product, actor string) error {
+	if product.IsBundle() {
+		return nil
+	}
+
+	page, err := repo.ListStockMovements(ctx, model.MovementSearchRequest{ProductID: product.ID, Limit: 1})
+	if err != nil {
+		return err
+	}
+
+	if page.Total > 0 {
+		return nil
+	}
+
+	for _, movement := range openingBalances(product, actor) {
+		if _, err := repo.CreateStockMovement(ctx, movement); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Adjustments adding the stored qty of the product, one for each variant in stock when it has variants
+func openingBalances(product model.Product, actor string) []model.StockMovement {
+	var movements []model.StockMovement
+
+	balance := func(sku string, qty uint64) {
+		if qty == 0 {
+			return
+		}
+
+		movements = append(movements, model.StockMovement{
+			ProductID:  product.ID,
+			VariantSKU: sku,
+			Type:       model.MovementAdjustment,
+			Delta:      int64(qty),
+			Qty:        qty,
+			Reason:     model.OpeningBalanceReason,
+			Actor:      actor,
+		})
+	}
+
+	if len(product.Variants) == 0 {
+		balance("", product.Qty)
+	}
+
+	for _, variant := range product.Variants {
+		balance(variant.Sku, variant.Qty)
+	}
+
+	return movements
+}
+
+// Get the qty of the product, or of its variant with sku, the same way updates address them
+func stockOf(product model.Product, sku string) (uint64, error) {
+	if sku == "" {
+		if len(product.Variants) > 0 {
+			return 0, internalError.ErrVariantRequired
+		}
+
+		return product.Qty, nil
+	}
+
+	variant := product.Variant(sku)
+	if variant == nil {
+		return 0, internalError.ErrVariantNotFound
+	}
+
+	return variant.Qty, nil
+}
@@ -0,0 +1,301 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	internalErrors "github.com/srodrmendz/api-product-catalog/errors"
+	"github.com/srodrmendz/api-product-catalog/model"
+	"github.com/srodrmendz/api-product-catalog/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test the stock ledger on top of the in memory repository
+func TestService_Stock(t *testing.T) {
+	ctx := context.TODO()
+
+	types := func(movements []model.StockMovement) []model.MovementType {
+		var types []model.MovementType
+
+		for _, movement := range movements {
+			types = append(types, movement.Type)
+		}
+
+		return types
+	}
+
+	t.Run("movements change the product qty and are listed newest first", func(t *testing.T) {
+		srv := New(repository.NewMemory(), Config{})
+
+		product, err := srv.Create(ctx, model.Product{Name: "shoes", Sku: "shoes", Qty: 5, Price: 1000})
+		require.NoError(t, err)
+
+		receipt, err := srv.RecordMovement(ctx, model.MovementRequest{ID: product.ID, Type: model.MovementReceipt, Delta: 10, Reference: "PO-1", Actor: "warehouse"})
+		require.NoError(t, err)
+
+		assert.Equal(t, uint64(15), receipt.Qty)
+		assert.Equal(t, "warehouse", receipt.Actor)
+
+		sale, err := srv.RecordMovement(ctx, model.MovementRequest{ID: product.ID, Type: model.MovementSale, Delta: -15, Reference: "SO-1"})
+		require.NoError(t, err)
+
+		assert.Equal(t, uint64(0), sale.Qty)
+
+		stored, err := srv.GetByID(ctx, product.ID, model.ReadOptions{})
+		require.NoError(t, err)
+
+		assert.Equal(t, uint64(0), stored.Qty)
+		assert.Equal(t, false, stored.InStock)
+
+		_, err = srv.RecordMovement(ctx, model.MovementRequest{ID: product.ID, Type: model.MovementDamage, Delta: -1})
+		assert.ErrorIs(t, err, internalErrors.ErrInsufficientStock)
+
+		response, err := srv.ListStockMovements(ctx, model.MovementSearchRequest{ProductID: product.ID})
+		require.NoError(t, err)
+
+		// Creating the product opened its ledger with the qty it was created with
+		assert.Equal(t, []model.MovementType{model.MovementSale, model.MovementReceipt, model.MovementAdjustment}, types(response.Movements))
+		assert.Equal(t, model.OpeningBalanceReason, response.Movements[2].Reason)
+		assert.Equal(t, int64(5), response.Movements[2].Delta)
+		assert.Equal(t, int64(3), response.Metadata.Total)
+
+		_, err = srv.ListStockMovements(ctx, model.MovementSearchRequest{ProductID: "fake"})
+		assert.ErrorIs(t, err, internalErrors.ErrProductNotFound)
+	})
+
+	t.Run("updates record the qty they change and open the ledger of products stored before it", func(t *testing.T) {
+		repo := repository.NewMemory()
+
+		srv := New(repo, Config{})
+
+		// Stored without the service, as products were before the ledger
+		product, err := repo.Create(ctx, model.Product{Name: "socks", Sku: "socks", Qty: 8, Price: 200})
+		require.NoError(t, err)
+
+		_, err = srv.Update(ctx, &model.Update{ID: product.ID, UpdateRequest: model.UpdateRequest{Qty: qtyOf(6)}, Actor: "jane"})
+		require.NoError(t, err)
+
+		response, err := srv.ListStockMovements(ctx, model.MovementSearchRequest{ProductID: product.ID})
+		require.NoError(t, err)
+
+		require.Len(t, response.Movements, 2)
+
+		adjustment := response.Movements[0]
+
+		assert.Equal(t, model.MovementAdjustment, adjustment.Type)
+		assert.Equal(t, int64(-2), adjustment.Delta)
+		assert.Equal(t, uint64(6), adjustment.Qty)
+		assert.Equal(t, model.UpdateReason, adjustment.Reason)
+		assert.Equal(t, "jane", adjustment.Actor)
+
+		assert.Equal(t, model.OpeningBalanceReason, response.Movements[1].Reason)
+		assert.Equal(t, int64(8), response.Movements[1].Delta)
+
+		// Updates leaving the qty unchanged record nothing
+		tags := []string{"cotton"}
+
+		_, err = srv.Update(ctx, &model.Update{ID: product.ID, UpdateRequest: model.UpdateRequest{Qty: qtyOf(6), Tags: tags}})
+		require.NoError(t, err)
+
+		response, err = srv.ListStockMovements(ctx, model.MovementSearchRequest{ProductID: product.ID})
+		require.NoError(t, err)
+
+		assert.Equal(t, int64(2), response.Metadata.Total)
+	})
+
+	t.Run("the ledger verifies and rebuilds the qty of every variant", func(t *testing.T) {
+		repo := repository.NewMemory()
+
+		srv := New(repo, Config{})
+
+		product, err := srv.Create(ctx, model.Product{Name: "shirt", Sku: "shirt", Price: 100, Variants: []model.Variant{
+			{Sku: "shirt-s", Qty: 2, Price: 100},
+			{Sku: "shirt-m", Qty: 3, Price: 100},
+		}})
+		require.NoError(t, err)
+
+		_, err = srv.RecordMovement(ctx, model.MovementRequest{ID: product.ID, Type: model.MovementReceipt, Delta: 4})
+		assert.ErrorIs(t, err, internalErrors.ErrVariantRequired)
+
+		_, err = srv.RecordMovement(ctx, model.MovementRequest{ID: product.ID, VariantSKU: "shirt-m", Type: model.MovementReturn, Delta: 1})
+		require.NoError(t, err)
+
+		ledger, err := srv.VerifyStock(ctx, product.ID)
+		require.NoError(t, err)
+
+		assert.Equal(t, true, ledger.Consistent)
+		assert.Equal(t, int64(6), ledger.LedgerQty)
+
+		// Written without the service, so the ledger does not know about it
+		_, err = repo.Update(ctx, model.Update{ID: product.ID, UpdateRequest: model.UpdateRequest{Qty: qtyOf(9), VariantSKU: "shirt-s"}})
+		require.NoError(t, err)
+
+		ledger, err = srv.VerifyStock(ctx, product.ID)
+		require.NoError(t, err)
+
+		assert.Equal(t, false, ledger.Consistent)
+		assert.Equal(t, []model.VariantLedger{{SKU: "shirt-s", Qty: 9, LedgerQty: 2}, {SKU: "shirt-m", Qty: 4, LedgerQty: 4}}, ledger.Variants)
+
+		rebuilt, err := srv.RebuildStock(ctx, product.ID)
+		require.NoError(t, err)
+
+		assert.Equal(t, uint64(6), rebuilt.Qty)
+		assert.Equal(t, uint64(2), rebuilt.Variant("shirt-s").Qty)
+
+		ledger, err = srv.VerifyStock(ctx, product.ID)
+		require.NoError(t, err)
+
+		assert.Equal(t, true, ledger.Consistent)
+	})
+
+	t.Run("bundles have no stock of their own and follow the movements of their components", func(t *testing.T) {
+		repo := repository.NewMemory()
+
+		srv := New(repo, Config{DefaultCurrency: "USD"})
+
+		shoes, err := srv.Create(ctx, model.Product{Name: "shoes", Sku: "shoes", Qty: 2, Price: 1000})
+		require.NoError(t, err)
+
+		bundle, err := srv.Create(ctx, model.Product{Name: "kit", Sku: "kit", Bundle: &model.Bundle{
+			Pricing:    model.BundlePricingComponents,
+			Components: []model.BundleComponent{{ProductID: shoes.ID, Qty: 1}},
+		}})
+		require.NoError(t, err)
+
+		_, err = srv.RecordMovement(ctx, model.MovementRequest{ID: bundle.ID, Type: model.MovementReceipt, Delta: 1})
+		assert.ErrorIs(t, err, internalErrors.ErrBundleStock)
+
+		_, err = srv.VerifyStock(ctx, bundle.ID)
+		assert.ErrorIs(t, err, internalErrors.ErrBundleStock)
+
+		_, err = srv.RecordMovement(ctx, model.MovementRequest{ID: shoes.ID, Type: model.MovementReceipt, Delta: 3})
+		require.NoError(t, err)
+
+		// The stored bundle is refreshed, not only the one read
+		stored, err := repo.GetByID(ctx, bundle.ID)
+		require.NoError(t, err)
+
+		assert.Equal(t, uint64(5), stored.Qty)
+	})
+
+	t.Run("movements address products by sku and concurrent sales never take more stock than there is", func(t *testing.T) {
+		srv := New(repository.NewMemory(), Config{})
+
+		_, err := srv.Create(ctx, model.Product{Name: "shirt", Sku: "shirt", Price: 100, Variants: []model.Variant{
+			{Sku: "shirt-s", Qty: 2, Price: 100},
+			{Sku: "shirt-m", Qty: 3, Price: 100},
+		}})
+		require.NoError(t, err)
+
+		socks, err := srv.Create(ctx, model.Product{Name: "socks", Sku: "socks", Qty: 10, Price: 200})
+		require.NoError(t, err)
+
+		// A variant sku moves the stock of that variant
+		movement, err := srv.RecordMovement(ctx, model.MovementRequest{SKU: "shirt-m", Type: model.MovementSale, Delta: -3})
+		require.NoError(t, err)
+
+		assert.Equal(t, "shirt-m", movement.VariantSKU)
+		assert.Equal(t, uint64(0), movement.Qty)
+
+		_, err = srv.RecordMovement(ctx, model.MovementRequest{SKU: "shirt-m", Type: model.MovementSale, Delta: -1})
+		assert.ErrorIs(t, err, internalErrors.ErrInsufficientStock)
+
+		_, err = srv.RecordMovement(ctx, model.MovementRequest{SKU: "shirt", Type: model.MovementReceipt, Delta: 1})
+		assert.ErrorIs(t, err, internalErrors.ErrVariantRequired)
+
+		_, err = srv.RecordMovement(ctx, model.MovementRequest{SKU: "fake", Type: model.MovementReceipt, Delta: 1})
+		assert.ErrorIs(t, err, internalErrors.ErrProductNotFound)
+
+		var wg sync.WaitGroup
+
+		var sold int64
+
+		for i := 0; i < 25; i++ {
+			wg.Add(1)
+
+			go func() {
+				defer wg.Done()
+
+				if _, err := srv.RecordMovement(ctx, model.MovementRequest{SKU: "socks", Type: model.MovementSale, Delta: -1}); err == nil {
+					atomic.AddInt64(&sold, 1)
+				}
+			}()
+		}
+
+		wg.Wait()
+
+		assert.Equal(t, int64(10), sold)
+
+		stored, err := srv.GetByID(ctx, socks.ID, model.ReadOptions{})
+		require.NoError(t, err)
+
+		assert.Equal(t, uint64(0), stored.Qty)
+		assert.Equal(t, false, stored.InStock)
+
+		ledger, err := srv.VerifyStock(ctx, socks.ID)
+		require.NoError(t, err)
+
+		assert.Equal(t, true, ledger.Consistent)
+	})
+
+	t.Run("updates write the qty on the version they read, so the ledger adds up to it", func(t *testing.T) {
+		repo := repository.NewMemory()
+
+		srv := New(repo, Config{})
+
+		product, err := srv.Create(ctx, model.Product{Name: "shoes", Sku: "shoes", Qty: 10, Price: 1000})
+		require.NoError(t, err)
+
+		// The update first reads the product as it was before this receipt
+		_, err = srv.RecordMovement(ctx, model.MovementRequest{ID: product.ID, Type: model.MovementReceipt, Delta: 5})
+		require.NoError(t, err)
+
+		srv = New(&staleReadRepository{Repository: repo, stale: product}, Config{})
+
+		updated, err := srv.Update(ctx, &model.Update{ID: product.ID, UpdateRequest: model.UpdateRequest{Qty: qtyOf(20)}})
+		require.NoError(t, err)
+
+		assert.Equal(t, uint64(20), updated.Qty)
+
+		ledger, err := srv.VerifyStock(ctx, product.ID)
+		require.NoError(t, err)
+
+		assert.True(t, ledger.Consistent)
+	})
+
+}
+
+func qtyOf(qty uint64) *uint64 {
+	return &qty
+}
+
+// Returns stale on the first product read of its transactions, as if another write changed it after the read
+type staleReadRepository struct {
+	repository.Repository
+	stale *model.Product
+}
+
+func (r *staleReadRepository) WithTransaction(ctx context.Context, fn func(txRepo repository.Repository) error) error {
+	return r.Repository.WithTransaction(ctx, func(txRepo repository.Repository) error {
+		return fn(&staleReadRepository{Repository: txRepo, stale: r.takeStale()})
+	})
+}
+
+func (r *staleReadRepository) GetByID(ctx context.Context, id string) (*model.Product, error) {
+	if stale := r.takeStale(); stale != nil {
+		return stale, nil
+	}
+
+	return r.Repository.GetByID(ctx, id)
+}
+
+func (r *staleReadRepository) takeStale() *model.Product {
+	stale := r.stale
+
+	r.stale = nil
+
+	return stale
+}
@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"time"
 
 	"github.com/srodrmendz/api-product-catalog/model"
 	"github.com/srodrmendz/api-product-catalog/repository"
@@ -12,32 +13,196 @@ var _ Service = (*ProductsCatalogService)(nil)
 
 // Service defines the methods that should be implemented by a Product Catalog Service.
 type Service interface {
-	// Create a new product
-	// Returns error if sku already exists or there is an error in the system
+	// Create a new product, the product and its translations get a slug from their names unless one is requested
+	// Returns error if sku already exists, another product holds a requested slug or there is an error in the system
 	Create(ctx context.Context, product model.Product) (*model.Product, error)
 
-	// Get a product by id
-	// Returns error if there is an error in the system
-	GetByID(ctx context.Context, id string) (*model.Product, error)
+	// Get a product by id, priced on the requested currency, localized to the requested locale and measured on the requested unit system
+	// Returns error if the product has no price on the currency or there is an error in the system
+	GetByID(ctx context.Context, id string, options model.ReadOptions) (*model.Product, error)
 
-	// Get a product by sku
-	// Returns error if there is an error in the system
-	GetBySKU(ctx context.Context, sku string) (*model.Product, error)
+	// Get a product by its sku or the sku of one of its variants, the variant is selected on the second case
+	// Products not visible to shoppers are only found by admin callers
+	// Returns error if the product has no price on the requested currency or there is an error in the system
+	GetBySKU(ctx context.Context, sku string, options model.ReadOptions) (*model.SKUProduct, error)
+
+	// Get a product by its slug or the slug of one of its translations, localized to the locale of the slug
+	// Products not visible to shoppers are only found by admin callers
+	// Returns a SlugMovedError holding the current slug if the slug is a previous one of its locale,
+	// error if product not found, it has no price on the requested currency or there is an error in the system
+	GetBySlug(ctx context.Context, slug string, options model.ReadOptions) (*model.Product, error)
+
+	// Delete a product, it can be restored until purged
+	// A version other than 0 must match the stored product version
+	// Returns error if product not found, the version does not match or there is an error in the system
+	Delete(ctx context.Context, id string, version int64) error
+
+	// Restore a deleted product
+	// Returns error if product not found or there is an error in the system
+	Restore(ctx context.Context, id string) (*model.Product, error)
 
-	// Delete a product
+	// Permanently remove the products deleted longer than retention ago, returns how many were removed
 	// Returns error if there is an error in the system
-	Delete(ctx context.Context, id string) error
+	Purge(ctx context.Context, retention time.Duration) (int64, error)
 
-	// Update a product
+	// Write the scheduled prices started or ended until now, returns how many products were written
 	// Returns error if there is an error in the system
+	ApplyDuePrices(ctx context.Context) (int64, error)
+
+	// Update a product, a request version other than 0 must match the stored product version
+	// A changed qty is recorded as an adjustment on the product stock ledger
+	// Returns error if the version does not match or there is an error in the system
 	Update(ctx context.Context, request *model.Update) (*model.Product, error)
 
-	// Search products
-	// Returns error if there is an error in the system
+	// Search products, a requested category matches its products and the ones of all its descendants
+	// Products are priced on the requested currency, the ones without a price on it are not matched
+	// Products are measured on the requested unit system, the ones without a filtered weight or dimension are not matched
+	// Callers other than admins only match the published products inside their publication window
+	// Returns error if the requested category not found or there is an error in the system
 	Search(ctx context.Context, request model.SearchRequest) (*model.SearchResponse, error)
+
+	// Count how many of the products matching the search filters have each tag, sorted by count
+	// Callers other than admins only count the published products inside their publication window
+	// Returns error if the requested category not found or there is an error in the system
+	TagCounts(ctx context.Context, request model.SearchRequest) (*model.TagCountsResponse, error)
+
+	// Move the product to the status the requested transition ends on
+	// Returns error if product not found, its status does not allow the transition, the version does not match or there is an error in the system
+	ChangeStatus(ctx context.Context, request model.StatusRequest) (*model.Product, error)
+
+	// Set the product translation to the request locale, replacing the one already on it
+	// The translation keeps its slug unless another one is requested, new translations get one from their name
+	// Returns error if product not found, the locale is the product one, another product holds the slug,
+	// the version does not match or there is an error in the system
+	SetTranslation(ctx context.Context, request model.TranslationRequest) (*model.Product, error)
+
+	// Remove the product translation to the request locale
+	// Returns error if product or translation not found, the version does not match or there is an error in the system
+	RemoveTranslation(ctx context.Context, request model.TranslationRequest) (*model.Product, error)
+
+	// Change the slug of the product content on the request locale, an empty slug is generated again from the content name
+	// The replaced slug is kept on the product slug history and redirects to the new one
+	// Returns error if product or translation not found, another product holds the slug, the version does not match or there is an error in the system
+	ChangeSlug(ctx context.Context, request model.SlugRequest) (*model.Product, error)
+
+	// Add an image after the product images, a primary image replaces the product primary one
+	// Returns error if product not found, it already has an image with the url, it would exceed the maximum images,
+	// the version does not match or there is an error in the system
+	AddImage(ctx context.Context, request model.ImageRequest) (*model.Product, error)
+
+	// Remove a product image, the first image becomes the primary one when the primary one is removed
+	// Returns error if product or image not found, the version does not match or there is an error in the system
+	RemoveImage(ctx context.Context, request model.ImageRequest) (*model.Product, error)
+
+	// Sort the product images on the requested order, and change its primary image when one is requested
+	// Returns error if product not found, the order does not list every image once, the version does not match or there is an error in the system
+	ReorderImages(ctx context.Context, request model.ImageOrderRequest) (*model.Product, error)
+
+	// Get the product relations of a type, or every relation when it is empty
+	// Returns error if product not found or there is an error in the system
+	GetRelations(ctx context.Context, id string, relationType model.RelationType) ([]model.Relation, error)
+
+	// Link a related product after the product relations of its type, a bidirectional relation is linked on the related product too
+	// Returns error if product or related product not found, the product already has the relation,
+	// the version does not match or there is an error in the system
+	AddRelation(ctx context.Context, request model.RelationRequest) (*model.Product, error)
+
+	// Unlink a related product, a bidirectional relation is unlinked from the related product too
+	// Returns error if product or relation not found, the version does not match or there is an error in the system
+	RemoveRelation(ctx context.Context, request model.RelationRequest) (*model.Product, error)
+
+	// Sort the product relations of a type on the requested order
+	// Returns error if product not found, the order does not list every product related on the type once,
+	// the version does not match or there is an error in the system
+	ReorderRelations(ctx context.Context, request model.RelationOrderRequest) (*model.Product, error)
+
+	// Get the product price timeline, its price changes and scheduled prices
+	// Returns error if product not found or there is an error in the system
+	GetPriceTimeline(ctx context.Context, id string) (*model.PriceTimeline, error)
+
+	// Schedule a product price from its start until its end, without end it becomes the regular price once started
+	// Returns error if product not found, it has variants or there is an error in the system
+	SchedulePrice(ctx context.Context, request model.SchedulePriceRequest) (*model.PriceTimeline, error)
+
+	// Cancel a scheduled price that did not start yet
+	// Returns error if product or scheduled price not found or there is an error in the system
+	CancelScheduledPrice(ctx context.Context, id string, scheduleID string) (*model.PriceTimeline, error)
+
+	// Create a new category under its parent, an empty parent creates a root category
+	// Returns error if parent not found or there is an error in the system
+	CreateCategory(ctx context.Context, category model.Category) (*model.Category, error)
+
+	// Get a category by id
+	// Returns error if category not found or there is an error in the system
+	GetCategory(ctx context.Context, id string) (*model.Category, error)
+
+	// List every category sorted by name
+	// Returns error if there is an error in the system
+	ListCategories(ctx context.Context) ([]model.Category, error)
+
+	// Rename or move a category, its descendants and products move with it
+	// Returns error if category or parent not found, the parent is on the category subtree or there is an error in the system
+	UpdateCategory(ctx context.Context, category model.Category) (*model.Category, error)
+
+	// Delete a category without subcategories, its products are moved to its parent
+	// Returns error if category not found, it has subcategories or there is an error in the system
+	DeleteCategory(ctx context.Context, id string) error
+
+	// Create a new brand
+	// Returns error if another brand has its name or there is an error in the system
+	CreateBrand(ctx context.Context, brand model.Brand) (*model.Brand, error)
+
+	// Get a brand by id
+	// Returns error if brand not found or there is an error in the system
+	GetBrand(ctx context.Context, id string) (*model.Brand, error)
+
+	// List every brand sorted by name
+	// Returns error if there is an error in the system
+	ListBrands(ctx context.Context) ([]model.Brand, error)
+
+	// Rename a brand or change its description
+	// Returns error if brand not found, another brand has its name or there is an error in the system
+	UpdateBrand(ctx context.Context, brand model.Brand) (*model.Brand, error)
+
+	// Delete a brand without products, deleted products not purged yet still hold it
+	// Returns error if brand not found, it has products or there is an error in the system
+	DeleteBrand(ctx context.Context, id string) error
+
+	// Record a stock movement on a product or one of its variants, its qty changes by the movement delta on the same transaction
+	// The product is found by sku when the request has no id, a variant sku moves the stock of that variant
+	// Returns error if product or variant not found, the product is a bundle, the movement takes more stock than there is
+	// or there is an error in the system
+	RecordMovement(ctx context.Context, request model.MovementRequest) (*model.StockMovement, error)
+
+	// List the stock movements of a product matching the request types and dates, newest first
+	// Returns error if product not found or there is an error in the system
+	ListStockMovements(ctx context.Context, request model.MovementSearchRequest) (*model.MovementsResponse, error)
+
+	// Compare the stored qty of a product and its variants with the qty its stock movements add up to
+	// Returns error if product not found, the product is a bundle or there is an error in the system
+	VerifyStock(ctx context.Context, id string) (*model.StockLedger, error)
+
+	// Set the qty of a product and its variants to the qty their stock movements add up to
+	// Returns error if product not found, the product is a bundle, the movements add up to a negative qty or there is an error in the system
+	RebuildStock(ctx context.Context, id string) (*model.Product, error)
 }
 
 // Service Implementation
 type ProductsCatalogService struct {
 	repository repository.Repository
+	config     Config
+}
+
+// Configure the service
+type Config struct {
+	// Currency of the products stored without one
+	DefaultCurrency string
+	// Rates used to convert prices to currencies products have no price on, nil disables conversion
+	ExchangeRates *model.ExchangeRates
+	// Locale of the products stored without one, and the one used when no requested locale is available
+	DefaultLocale string
+	// Locales products are localized to, empty accepts any requested locale
+	Locales []string
+	// Maximum number of images of a product and of each of its variants, 0 allows any number
+	MaxImages int
 }